@@ -0,0 +1,110 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+
+	"github.com/guregu/dynamo"
+	"github.com/memememomo/nomof"
+	"github.com/pkg/errors"
+)
+
+// APIKeyOperator APIキーを操作する構造体
+type APIKeyOperator struct {
+	Client *ResourceTableOperator
+	Mapper *DynamoModelMapper
+}
+
+func (a *APIKeyOperator) getAPIKeyResourceByID(id uint64) (*APIKeyResource, error) {
+	var apiKeyResource APIKeyResource
+	_, err := a.Mapper.GetEntityByID(id, &APIKeyResource{}, &apiKeyResource)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &apiKeyResource, nil
+}
+
+// CreateAPIKey 新規作成する
+func (a *APIKeyOperator) CreateAPIKey(apiKeyModel *domain.APIKeyModel) (*domain.APIKeyModel, error) {
+	apiKeyResource := NewAPIKeyResource(apiKeyModel, a.Mapper)
+	err := a.Mapper.PutResource(apiKeyResource)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return apiKeyResource.ToModel(), nil
+}
+
+// GetAPIKeyByID IDでAPIキーを取得する
+func (a *APIKeyOperator) GetAPIKeyByID(id uint64) (*domain.APIKeyModel, error) {
+	apiKeyResource, err := a.getAPIKeyResourceByID(id)
+	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return apiKeyResource.ToModel(), nil
+}
+
+// GetAPIKeys APIキーの一覧を取得する。続きがある場合は次のページのトークンも返す
+func (a *APIKeyOperator) GetAPIKeys(page *domain.Page) ([]*domain.APIKeyModel, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
+	table, err := a.Client.ConnectTable()
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	startKey, err := DecodePagingKey(page.NextToken)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.BeginsWith("PK", a.Mapper.GetEntityNameFromStruct(APIKeyResource{}))
+
+	var apiKeyResources []APIKeyResource
+	lastKey, err := table.
+		Scan().
+		Filter(fb.JoinAnd(), fb.Arg...).
+		StartFrom(startKey).
+		Limit(int64(page.Limit)).
+		AllWithLastEvaluatedKey(&apiKeyResources)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	nextToken, err := EncodePagingKey(lastKey)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	var apiKeys = make([]*domain.APIKeyModel, len(apiKeyResources))
+	for i := range apiKeyResources {
+		apiKeys[i] = apiKeyResources[i].ToModel()
+	}
+
+	return apiKeys, nextToken, nil
+}
+
+// DeleteAPIKey APIキーを削除して失効させる。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (a *APIKeyOperator) DeleteAPIKey(apiKeyModel *domain.APIKeyModel) error {
+	apiKey, err := a.getAPIKeyResourceByID(apiKeyModel.ID)
+	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return errors.WithStack(domain.ErrNotFound)
+		}
+		return errors.WithStack(err)
+	}
+	if apiKeyModel.Version != 0 {
+		apiKey.SetVersion(apiKeyModel.Version)
+	}
+
+	err = a.Mapper.DeleteResource(apiKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
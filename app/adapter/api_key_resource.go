@@ -0,0 +1,93 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"time"
+)
+
+// APIKeyResource DynamoDB上のデータ構造を表した構造体。
+// ExpiresAtは文字列で保存されるため、テーブルのTTLでは削除されず、期限切れのキーも一覧で確認できる
+type APIKeyResource struct {
+	ResourceSchema
+	DynamoResourceBase
+	domain.APIKeyModel
+	Mapper *DynamoModelMapper `dynamo:"-"`
+}
+
+func NewAPIKeyResource(apiKeyModel *domain.APIKeyModel, mapper *DynamoModelMapper) *APIKeyResource {
+	return &APIKeyResource{
+		DynamoResourceBase: DynamoResourceBase{Version: apiKeyModel.Version},
+		APIKeyModel:        *apiKeyModel,
+		Mapper:             mapper,
+	}
+}
+
+// ToModel ドメインモデルに変換する
+func (a *APIKeyResource) ToModel() *domain.APIKeyModel {
+	model := a.APIKeyModel
+	model.Version = a.Version()
+	return &model
+}
+
+// DynamoResourceインタフェースの実装
+
+func (a *APIKeyResource) EntityName() string {
+	return a.Mapper.GetEntityNameFromStruct(*a)
+}
+
+func (a *APIKeyResource) PK() string {
+	return a.Mapper.GetPK(a)
+}
+
+func (a *APIKeyResource) SetPK() {
+	a.ResourceSchema.PK = a.PK()
+}
+
+func (a *APIKeyResource) SK() string {
+	return a.Mapper.GetSK(a)
+}
+
+func (a *APIKeyResource) SetSK() {
+	a.ResourceSchema.SK = a.SK()
+}
+
+func (a *APIKeyResource) SetID(id uint64) {
+	a.APIKeyModel.ID = id
+}
+
+func (a *APIKeyResource) ID() uint64 {
+	return a.APIKeyModel.ID
+}
+
+func (a *APIKeyResource) SetVersion(v int) {
+	a.DynamoResourceBase.Version = v
+	a.APIKeyModel.Version = v
+}
+
+func (a *APIKeyResource) Version() int {
+	return a.DynamoResourceBase.Version
+}
+
+func (a *APIKeyResource) CreatedAt() time.Time {
+	return a.DynamoResourceBase.CreatedAt
+}
+
+func (a *APIKeyResource) SetCreatedAt(t time.Time) {
+	a.DynamoResourceBase.CreatedAt = t
+}
+
+func (a *APIKeyResource) UpdatedAt() time.Time {
+	return a.DynamoResourceBase.UpdatedAt
+}
+
+func (a *APIKeyResource) SetUpdatedAt(t time.Time) {
+	a.DynamoResourceBase.UpdatedAt = t
+}
+
+func (a *APIKeyResource) DeletedAt() time.Time {
+	return a.DynamoResourceBase.DeletedAt
+}
+
+func (a *APIKeyResource) SetDeletedAt(t time.Time) {
+	a.DynamoResourceBase.DeletedAt = t
+}
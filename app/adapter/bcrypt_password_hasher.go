@@ -0,0 +1,42 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptPasswordHasher bcryptでパスワードをハッシュ化する。bcryptは先頭72バイトまでしか使わないため、長さはバリデーションで制限する
+type BcryptPasswordHasher struct {
+	Cost int
+}
+
+// NewBcryptPasswordHasher BcryptPasswordHasherのインスタンスを生成。costが範囲外の場合はbcrypt.DefaultCostを使う
+func NewBcryptPasswordHasher(cost int) *BcryptPasswordHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptPasswordHasher{Cost: cost}
+}
+
+// HashPassword パスワードをハッシュ化する。ソルトはハッシュ値に含まれる
+func (b *BcryptPasswordHasher) HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(hash), nil
+}
+
+// ComparePassword ハッシュ値とパスワードを照合する
+func (b *BcryptPasswordHasher) ComparePassword(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return errors.WithStack(domain.ErrUnauthorized)
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
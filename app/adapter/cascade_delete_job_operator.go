@@ -0,0 +1,101 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/pkg/errors"
+)
+
+// cascadeDeleteJobEntityName 削除の継続ジョブのレコードのSK。GSI1PKにも使い、ジョブを登録順に一覧できるようにする
+const cascadeDeleteJobEntityName = "CascadeDeleteJob"
+
+// CascadeDeleteJobResource 削除の継続ジョブのレコードを表した構造体
+type CascadeDeleteJobResource struct {
+	ResourceSchema
+	UserID    uint64    `dynamo:"UserID"`
+	CreatedAt time.Time `dynamo:"CreatedAt"`
+}
+
+// CascadeDeleteJobOperator 削除の継続ジョブを操作する構造体
+type CascadeDeleteJobOperator struct {
+	Client *ResourceTableOperator
+	PKName string
+	SKName string
+}
+
+func (o *CascadeDeleteJobOperator) getPK(userID uint64) string {
+	return fmt.Sprintf("%s-%011d", cascadeDeleteJobEntityName, userID)
+}
+
+// PutCascadeDeleteJob ジョブを登録する。同じユーザーのジョブは上書きする
+func (o *CascadeDeleteJobOperator) PutCascadeDeleteJob(job *domain.CascadeDeleteJobModel) error {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	resource := &CascadeDeleteJobResource{
+		ResourceSchema: ResourceSchema{
+			PK:     o.getPK(job.UserID),
+			SK:     cascadeDeleteJobEntityName,
+			GSI1PK: cascadeDeleteJobEntityName,
+			GSI1SK: fmt.Sprintf("%s#%011d", job.CreatedAt.UTC().Format(userIndexTimeFormat), job.UserID),
+		},
+		UserID:    job.UserID,
+		CreatedAt: job.CreatedAt,
+	}
+
+	err = table.Put(resource).Run()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// GetCascadeDeleteJobs 登録が古い順にジョブを最大limit件取得する
+func (o *CascadeDeleteJobOperator) GetCascadeDeleteJobs(limit int) ([]*domain.CascadeDeleteJobModel, error) {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var resources []CascadeDeleteJobResource
+	err = table.
+		Get("GSI1PK", cascadeDeleteJobEntityName).
+		Index(UserIndexName).
+		Order(dynamo.Ascending).
+		Limit(int64(limit)).
+		All(&resources)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	jobs := make([]*domain.CascadeDeleteJobModel, len(resources))
+	for i, r := range resources {
+		jobs[i] = domain.NewCascadeDeleteJobModel(r.UserID, r.CreatedAt)
+	}
+
+	return jobs, nil
+}
+
+// DeleteCascadeDeleteJob ジョブを削除する
+func (o *CascadeDeleteJobOperator) DeleteCascadeDeleteJob(userID uint64) error {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = table.
+		Delete(o.PKName, o.getPK(userID)).
+		Range(o.SKName, cascadeDeleteJobEntityName).
+		Run()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/adapter/validation"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"clean-serverless-book-sample/utils"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	log           *slog.Logger
+	issueAPIKey   usecase.IIssueAPIKey
+	getAPIKeyList usecase.IGetAPIKeyList
+	revokeAPIKey  usecase.IRevokeAPIKey
+}
+
+// NewAPIKeyController APIKeyControllerのインスタンスを生成
+func NewAPIKeyController(f *registry.Factory, log *slog.Logger) *APIKeyController {
+	return &APIKeyController{
+		log:           log,
+		issueAPIKey:   f.BuildIssueAPIKey(),
+		getAPIKeyList: f.BuildGetAPIKeyList(),
+		revokeAPIKey:  f.BuildRevokeAPIKey(),
+	}
+}
+
+// APIKeySettingValidator バリデーション設定
+func APIKeySettingValidator() *validation.Validator {
+	return &validation.Validator{
+		Settings: []*validation.ValidatorSetting{
+			{ArgName: "name", ValidateTags: "required,max=100"},
+			{ArgName: "scopes", ValidateTags: "scopes"},
+			{ArgName: "expires_at", ValidateTags: "required,date"},
+		},
+	}
+}
+
+// RequestPostAPIKey PostAPIKeysのリクエスト
+type RequestPostAPIKey struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+// APIKeyResponse レスポンス用のJSON形式を表した構造体。シークレットのハッシュ値は返さない
+type APIKeyResponse struct {
+	ID        uint64   `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+// APIKeysResponse APIキーリストレスポンス用のJSON形式を表した構造体
+type APIKeysResponse struct {
+	APIKeys   []*APIKeyResponse `json:"api_keys"`
+	NextToken string            `json:"next_token"`
+}
+
+// IssuedAPIKeyResponse 発行したAPIキーのレスポンス。keyは発行時にだけ返す
+type IssuedAPIKeyResponse struct {
+	Message string `json:"message"`
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// NewAPIKeyResponse ドメインモデルからレスポンス用の構造体に詰め替える
+func NewAPIKeyResponse(k *domain.APIKeyModel) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:        k.ID,
+		Name:      k.Name,
+		Scopes:    k.Scopes,
+		ExpiresAt: k.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// PostAPIKeys 発行
+func (ctrl *APIKeyController) PostAPIKeys(ctx *gin.Context) {
+	ctrl.log.Info("Starting PostAPIKeys handler")
+
+	// リクエストボディを取得
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctrl.log.Error("Failed to get request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// バリデーション処理
+	validator := APIKeySettingValidator()
+	validErr := validator.ValidateBody(string(body))
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// JSON形式から構造体に変換
+	var req RequestPostAPIKey
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		ctrl.log.Error("Failed to unmarshal request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	expiresAt, err := validation.ParseDate(req.ExpiresAt)
+	if err != nil {
+		ctrl.log.Error("Failed to parse expires_at", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// 発行処理
+	ctrl.log.Info("Issuing new API key", "name", req.Name, "scopes", req.Scopes)
+	res, err := ctrl.issueAPIKey.Execute(&usecase.IssueAPIKeyRequest{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		ctrl.log.Error("Failed to issue API key", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("API key issued successfully", "apiKeyID", res.APIKey.ID)
+	// 201レスポンス。キーはこのレスポンスでしか取得できない
+	commonHeaders(ctx)
+	ctx.JSON(http.StatusCreated, &IssuedAPIKeyResponse{
+		Message:        "OK",
+		APIKeyResponse: *NewAPIKeyResponse(res.APIKey),
+		Key:            res.Key,
+	})
+}
+
+// GetAPIKeys 一覧取得処理
+func (ctrl *APIKeyController) GetAPIKeys(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetAPIKeys handler")
+
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// 一覧取得処理
+	res, err := ctrl.getAPIKeyList.Execute(&usecase.GetAPIKeyListRequest{
+		Limit:     page.Limit,
+		NextToken: page.NextToken,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
+		ctrl.log.Error("Failed to get API key list", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// ドメインモデルからレスポンス用の構造体に詰め替える
+	var resAPIKeys = make([]*APIKeyResponse, len(res.APIKeys))
+	for i, k := range res.APIKeys {
+		resAPIKeys[i] = NewAPIKeyResponse(k)
+	}
+
+	ctrl.log.Info("API key list retrieved successfully", "count", len(resAPIKeys))
+	// レスポンス処理
+	Response200(ctx, &APIKeysResponse{
+		APIKeys:   resAPIKeys,
+		NextToken: res.NextToken,
+	})
+}
+
+// DeleteAPIKey 失効処理
+func (ctrl *APIKeyController) DeleteAPIKey(ctx *gin.Context) {
+	ctrl.log.Info("Starting DeleteAPIKey handler")
+
+	// パスパラメータからAPIキーIDを取得する
+	apiKeyID, err := utils.ParseUint(ctx.Param("api_key_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse api_key_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// If-Matchヘッダーから楽観的ロック用のバージョンを取得する
+	version, ok := parseIfMatch(ctx)
+	if !ok {
+		ctrl.log.Warn("Invalid If-Match header", "ifMatch", ctx.GetHeader("If-Match"))
+		Response412(ctx)
+		return
+	}
+
+	// 失効処理
+	ctrl.log.Info("Revoking API key", "apiKeyID", apiKeyID)
+	_, err = ctrl.revokeAPIKey.Execute(&usecase.RevokeAPIKeyRequest{
+		APIKeyID: apiKeyID,
+		Version:  version,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("API key not found", "apiKeyID", apiKeyID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == domain.ErrConflict.Error() {
+			ctrl.log.Warn("API key version conflict", "apiKeyID", apiKeyID, "version", version)
+			Response412(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to revoke API key", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("API key revoked successfully", "apiKeyID", apiKeyID)
+	// レスポンス
+	Response200OK(ctx)
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueTestAPIKey 指定したスコープと有効期限のAPIキーを発行する
+func issueTestAPIKey(t *testing.T, f *registry.Factory, expiresAt time.Time, scopes ...string) *usecase.IssueAPIKeyResponse {
+	t.Helper()
+	res, err := f.BuildIssueAPIKey().Execute(&usecase.IssueAPIKeyRequest{
+		Name:      "test",
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	return res
+}
+
+// setTestAPIKey 指定したスコープのAPIキーを発行してX-API-Keyヘッダーに設定する
+func setTestAPIKey(t *testing.T, f *registry.Factory, req *http.Request, scopes ...string) {
+	t.Helper()
+	req.Header.Set("X-API-Key", issueTestAPIKey(t, f, time.Now().Add(time.Hour), scopes...).Key)
+}
+
+// serveWithAPIKey X-API-Keyヘッダーを付けてリクエストする
+func serveWithAPIKey(router *gin.Engine, method, path, key string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestAPIKeyController 発行・一覧・失効の一連の流れ
+func TestAPIKeyController(t *testing.T) {
+	router, f := setupMemoryRouter()
+	admin := issueTestAPIKey(t, f, time.Now().Add(time.Hour), domain.ScopeAPIKeysAdmin)
+
+	// 発行
+	w := serveWithAPIKey(router, "POST", "/v1/admin/api_keys", admin.Key, map[string]interface{}{
+		"name":       "batch",
+		"scopes":     []string{domain.ScopeUsersRead, domain.ScopeMicropostsWrite},
+		"expires_at": "2099-01-01T00:00:00Z",
+	})
+	require.Equal(t, 201, w.Code)
+
+	var issued IssuedAPIKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+	assert.Equal(t, "batch", issued.Name)
+	assert.Equal(t, []string{domain.ScopeUsersRead, domain.ScopeMicropostsWrite}, issued.Scopes)
+	assert.Equal(t, "2099-01-01T00:00:00Z", issued.ExpiresAt)
+	assert.NotEmpty(t, issued.Key)
+
+	// 一覧にはキーもハッシュ値も含めない
+	w = serveWithAPIKey(router, "GET", "/v1/admin/api_keys", admin.Key, nil)
+	require.Equal(t, 200, w.Code)
+
+	var list APIKeysResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.APIKeys, 2)
+	stored, err := f.APIKeyRepository.GetAPIKeyByID(issued.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, w.Body.String(), stored.SecretHash)
+	assert.NotContains(t, w.Body.String(), issued.Key)
+
+	// 発行したキーで呼び出せる
+	w = serveWithAPIKey(router, "GET", "/v1/users", issued.Key, nil)
+	assert.Equal(t, 200, w.Code)
+
+	// バージョンが一致しない場合は失効できない
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/v1/admin/api_keys/%d", issued.ID), nil)
+	req.Header.Set("X-API-Key", admin.Key)
+	req.Header.Set("If-Match", `"2"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 412, w.Code)
+
+	// 失効
+	w = serveWithAPIKey(router, "DELETE", fmt.Sprintf("/v1/admin/api_keys/%d", issued.ID), admin.Key, nil)
+	assert.Equal(t, 200, w.Code)
+	w = serveWithAPIKey(router, "DELETE", fmt.Sprintf("/v1/admin/api_keys/%d", issued.ID), admin.Key, nil)
+	assert.Equal(t, 404, w.Code)
+
+	// 失効したキーは使えない
+	w = serveWithAPIKey(router, "GET", "/v1/users", issued.Key, nil)
+	assert.Equal(t, 401, w.Code)
+}
+
+// TestPostAPIKeys_400 発行時のバリデーション
+func TestPostAPIKeys_400(t *testing.T) {
+	router, f := setupMemoryRouter()
+	admin := issueTestAPIKey(t, f, time.Now().Add(time.Hour), domain.ScopeAPIKeysAdmin)
+
+	w := serveWithAPIKey(router, "POST", "/v1/admin/api_keys", admin.Key, map[string]interface{}{
+		"name":       "",
+		"scopes":     []string{"users:read", "unknown:write"},
+		"expires_at": "tomorrow",
+	})
+	require.Equal(t, 400, w.Code)
+
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	errs := res["errors"].(map[string]interface{})
+	assert.Equal(t, "名前を入力してください。", errs["name"])
+	assert.Equal(t, "スコープに発行できないスコープが含まれています。", errs["scopes"])
+	assert.Equal(t, "有効期限の形式が不正です。", errs["expires_at"])
+
+	w = serveWithAPIKey(router, "POST", "/v1/admin/api_keys", admin.Key, map[string]interface{}{
+		"name":       "batch",
+		"scopes":     []string{},
+		"expires_at": "2099-01-01",
+	})
+	require.Equal(t, 400, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	errs = res["errors"].(map[string]interface{})
+	assert.Equal(t, "スコープを入力してください。", errs["scopes"])
+}
+
+// TestAPIKeyMiddleware ルートごとのスコープと有効期限の確認
+func TestAPIKeyMiddleware(t *testing.T) {
+	router, f := setupMemoryRouter()
+	user, err := f.UserRepository.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+
+	reader := issueTestAPIKey(t, f, time.Now().Add(time.Hour), domain.ScopeUsersRead)
+	writer := issueTestAPIKey(t, f, time.Now().Add(time.Hour), domain.ScopeMicropostsWrite)
+	expired := issueTestAPIKey(t, f, time.Now().Add(-time.Second), domain.ScopeUsersRead)
+
+	// 読み取りのスコープだけでは書き込めない
+	w := serveWithAPIKey(router, "GET", fmt.Sprintf("/v1/users/%d", user.ID), reader.Key, nil)
+	assert.Equal(t, 200, w.Code)
+	w = serveWithAPIKey(router, "POST", fmt.Sprintf("/v1/users/%d/microposts", user.ID), reader.Key,
+		map[string]interface{}{"content": "hello"})
+	assert.Equal(t, 403, w.Code)
+
+	// 書き込みのスコープがあれば、ユーザーのトークンが無くても投稿できる
+	w = serveWithAPIKey(router, "POST", fmt.Sprintf("/v1/users/%d/microposts", user.ID), writer.Key,
+		map[string]interface{}{"content": "hello"})
+	assert.Equal(t, 201, w.Code)
+
+	// 有効期限切れや改ざんしたキーは401
+	w = serveWithAPIKey(router, "GET", "/v1/users", expired.Key, nil)
+	assert.Equal(t, 401, w.Code)
+	w = serveWithAPIKey(router, "GET", "/v1/users", reader.Key+"x", nil)
+	assert.Equal(t, 401, w.Code)
+
+	// 管理用のエンドポイントはadminスコープのAPIキーが必要
+	w = serveWithAPIKey(router, "GET", "/v1/admin/api_keys", "", nil)
+	assert.Equal(t, 401, w.Code)
+	w = serveWithAPIKey(router, "GET", "/v1/admin/api_keys", reader.Key, nil)
+	assert.Equal(t, 403, w.Code)
+}
+
+// TestAPIKeyMiddleware_adminKey 環境変数の管理用キーで最初のAPIキーを発行できる
+func TestAPIKeyMiddleware_adminKey(t *testing.T) {
+	t.Setenv("DISABLE_ENV_DECRYPT", "1")
+	t.Setenv("ADMIN_API_KEY", "bootstrap-admin-key")
+	router, _ := setupMemoryRouter()
+
+	w := serveWithAPIKey(router, "POST", "/v1/admin/api_keys", "bootstrap-admin-key", map[string]interface{}{
+		"name":       "batch",
+		"scopes":     []string{domain.ScopeAPIKeysAdmin},
+		"expires_at": "2099-01-01",
+	})
+	assert.Equal(t, 201, w.Code)
+
+	// 管理用キーはadmin以外のスコープを持たない
+	w = serveWithAPIKey(router, "GET", "/v1/users", "bootstrap-admin-key", nil)
+	assert.Equal(t, 403, w.Code)
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/adapter/validation"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentController struct {
+	log                   *slog.Logger
+	issueAttachmentUpload usecase.IIssueAttachmentUpload
+}
+
+// NewAttachmentController AttachmentControllerのインスタンスを生成
+func NewAttachmentController(f *registry.Factory, log *slog.Logger) *AttachmentController {
+	return &AttachmentController{
+		log:                   log,
+		issueAttachmentUpload: f.BuildIssueAttachmentUpload(),
+	}
+}
+
+// PostAttachmentSettingsValidator 画像添付時のバリデーション設定
+func PostAttachmentSettingsValidator() *validation.Validator {
+	return &validation.Validator{
+		Settings: []*validation.ValidatorSetting{
+			{ArgName: "content_type", ValidateTags: "required,image_type"},
+		},
+	}
+}
+
+// RequestPostAttachment PostAttachmentのリクエスト。アップロードする画像のContent-Typeを指定する
+type RequestPostAttachment struct {
+	ContentType string `json:"content_type"`
+}
+
+// ResponseAttachmentUpload 画像のアップロード先を表したレスポンス。
+// upload_urlへcontent_typeと同じContent-TypeヘッダーでPUTすると、完了後にマイクロポストのattachmentsに追加される
+type ResponseAttachmentUpload struct {
+	Key       string `json:"key"`
+	UploadURL string `json:"upload_url"`
+	Method    string `json:"method"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// ResponseAttachment レスポンス用の添付画像のJSON形式を表した構造体
+type ResponseAttachment struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	UploadedAt  string `json:"uploaded_at"`
+}
+
+// NewResponseAttachments ドメインモデルからレスポンス用の構造体に詰め替える
+func NewResponseAttachments(attachments []domain.AttachmentModel) []*ResponseAttachment {
+	res := make([]*ResponseAttachment, len(attachments))
+	for i, a := range attachments {
+		res[i] = &ResponseAttachment{
+			Key:         a.Key,
+			Size:        a.Size,
+			ContentType: a.ContentType,
+			UploadedAt:  a.UploadedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	return res
+}
+
+// PostAttachment 画像を添付するためのアップロードURLを発行する
+func (ctrl *AttachmentController) PostAttachment(ctx *gin.Context) {
+	ctrl.log.Info("Starting PostAttachment handler")
+
+	// リクエストボディを取得
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctrl.log.Error("Failed to get request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// バリデーション処理
+	validator := PostAttachmentSettingsValidator()
+	validErr := validator.ValidateBody(string(body))
+	if validErr != nil {
+		ctrl.log.Warn("Validation error", "error", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// パスパラメータからユーザーIDとマイクロポストIDを取得する
+	userID, micropostID, err := parseMicropostPath(ctx)
+	if err != nil {
+		ctrl.log.Error("Failed to parse path parameters", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// JSON形式から構造体に変換
+	var req RequestPostAttachment
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		ctrl.log.Error("Failed to unmarshal request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// アップロードURLの発行処理
+	ctrl.log.Info("Issuing attachment upload", "micropostID", micropostID, "userID", userID, "contentType", req.ContentType)
+	res, err := ctrl.issueAttachmentUpload.Execute(&usecase.IssueAttachmentUploadRequest{
+		UserID:      userID,
+		MicropostID: micropostID,
+		ContentType: req.ContentType,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrTooManyAttachments.Error() {
+			ctrl.log.Warn("Too many attachments", "micropostID", micropostID)
+			Response400(ctx, map[string]error{"attachments": validation.ErrMaxAttachments})
+			return
+		}
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Micropost not found", "micropostID", micropostID, "userID", userID)
+			Response404(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to issue attachment upload", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Issued attachment upload successfully", "micropostID", micropostID, "key", res.Key)
+	Response200(ctx, &ResponseAttachmentUpload{
+		Key:       res.Key,
+		UploadURL: res.UploadURL,
+		Method:    http.MethodPut,
+		ExpiresAt: res.ExpiresAt.UTC().Format(time.RFC3339Nano),
+	})
+}
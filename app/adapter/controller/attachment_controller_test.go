@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/usecase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAttachmentController アップロードURLの発行と、アップロード完了後の添付画像の取得
+func TestAttachmentController(t *testing.T) {
+	const bucket = "test-bucket"
+	t.Setenv("S3_BUCKET_NAME", bucket)
+
+	router, f := setupMemoryRouter()
+	createTestUsers(t, f.UserRepository, 2)
+
+	m, err := f.MicropostRepository.CreateMicropost(domain.NewMicropostModel("Content", 1))
+	require.NoError(t, err)
+	attachmentsPath := fmt.Sprintf("/v1/users/1/microposts/%d/attachments", m.ID)
+
+	post := func(path, body string, authUserID uint64) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		setAuth(req, authUserID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	upload := func() string {
+		w := post(attachmentsPath, `{"content_type": "image/png"}`, 1)
+		require.Equal(t, 200, w.Code)
+		var res ResponseAttachmentUpload
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		// バケットとキーに向けた、Content-Typeを含めて署名したPUTのURLを返す
+		assert.Equal(t, "PUT", res.Method)
+		assert.True(t, strings.HasPrefix(res.Key, domain.NewAttachmentKey(m.ID, "")))
+		u, err := url.Parse(res.UploadURL)
+		require.NoError(t, err)
+		assert.Contains(t, u.Host+u.Path, bucket)
+		assert.True(t, strings.HasSuffix(u.Path, res.Key))
+		assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+		assert.Contains(t, u.Query().Get("X-Amz-SignedHeaders"), "content-type")
+
+		// クライアントがアップロードし、S3のイベントで添付を記録する
+		f.S3Client.(*mocks.FakeS3Client).PutTestObject(bucket, res.Key, "image/png", []byte("png"))
+		_, err = f.BuildRecordAttachment().Execute(&usecase.RecordAttachmentRequest{Key: res.Key})
+		require.NoError(t, err)
+		return res.Key
+	}
+
+	key := upload()
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/users/1/microposts/%d", m.ID), nil)
+	setAuth(req, 1)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	var res ResponseMicropost
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Attachments, 1)
+	assert.Equal(t, key, res.Attachments[0].Key)
+	assert.Equal(t, int64(3), res.Attachments[0].Size)
+	assert.Equal(t, "image/png", res.Attachments[0].ContentType)
+	assert.NotEmpty(t, res.Attachments[0].UploadedAt)
+
+	// 画像以外の形式・他のユーザーのマイクロポスト・存在しないマイクロポスト
+	assert.Equal(t, 400, post(attachmentsPath, `{"content_type": "text/html"}`, 1).Code)
+	assert.Equal(t, 400, post(attachmentsPath, `{}`, 1).Code)
+	assert.Equal(t, 403, post(attachmentsPath, `{"content_type": "image/png"}`, 2).Code)
+	assert.Equal(t, 404, post("/v1/users/1/microposts/999/attachments", `{"content_type": "image/png"}`, 1).Code)
+
+	// 添付数の上限に達すると発行できない
+	for i := 1; i < domain.MaxAttachmentsPerMicropost; i++ {
+		upload()
+	}
+	w = post(attachmentsPath, `{"content_type": "image/png"}`, 1)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "attachments")
+}
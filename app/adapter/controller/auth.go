@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"clean-serverless-book-sample/utils"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authUserIDKey 認証した呼び出し元のユーザーIDをgin.Contextに保存するキー
+const authUserIDKey = "authUserID"
+
+// authAPIKeyKey 認証したAPIキーをgin.Contextに保存するキー
+const authAPIKeyKey = "authAPIKey"
+
+// apiKeyHeader サービス間の呼び出しでAPIキーを送るヘッダー
+const apiKeyHeader = "X-API-Key"
+
+// APIKeyMiddleware X-API-KeyヘッダーのAPIキーを検証し、ルートに必要なスコープを持っていればAPIキーをgin.Contextに保存する。
+// ヘッダーが無い場合は何もせず次に進む。認証が必要なルートでは、後にAuthMiddlewareかRequireAPIKeyを続けて認証情報の無い呼び出しを拒否する。
+// キーが無効な場合は401、スコープが足りない場合は403を返す
+func APIKeyMiddleware(authenticator usecase.IAuthenticateAPIKey, scope string, log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apiKey, ok := authenticateAPIKey(ctx, authenticator, log)
+		if !ok {
+			return
+		}
+		if apiKey == nil {
+			ctx.Next()
+			return
+		}
+
+		if !apiKey.HasScope(scope) {
+			log.Warn("API key lacks required scope", "apiKeyID", apiKey.ID, "scope", scope)
+			Response403(ctx)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(authAPIKeyKey, apiKey)
+		ctx.Next()
+	}
+}
+
+// APIKeyResourceScopeMiddleware APIKeyMiddlewareと同じくAPIキーを検証するが、スコープは確認しない。
+// 必要なスコープが参照するリソースによって決まるルートに使い、スコープはハンドラーで確認する
+func APIKeyResourceScopeMiddleware(authenticator usecase.IAuthenticateAPIKey, log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apiKey, ok := authenticateAPIKey(ctx, authenticator, log)
+		if !ok {
+			return
+		}
+		if apiKey != nil {
+			ctx.Set(authAPIKeyKey, apiKey)
+		}
+		ctx.Next()
+	}
+}
+
+// authenticateAPIKey ヘッダーのAPIキーを検証する。ヘッダーが無い場合はnilを返す。
+// 検証できない場合はレスポンスを返して中断し、falseを返す
+func authenticateAPIKey(ctx *gin.Context, authenticator usecase.IAuthenticateAPIKey, log *slog.Logger) (*domain.APIKeyModel, bool) {
+	key := strings.TrimSpace(ctx.GetHeader(apiKeyHeader))
+	if key == "" {
+		return nil, true
+	}
+
+	res, err := authenticator.Execute(&usecase.AuthenticateAPIKeyRequest{Key: key})
+	if err != nil {
+		if err.Error() == domain.ErrUnauthorized.Error() {
+			log.Warn("Invalid API key", "path", ctx.FullPath())
+			Response401(ctx)
+			ctx.Abort()
+			return nil, false
+		}
+		log.Error("Failed to authenticate API key", "error", err)
+		Response500(ctx, err)
+		ctx.Abort()
+		return nil, false
+	}
+	return res.APIKey, true
+}
+
+// RequireAPIKey APIKeyMiddlewareで認証したAPIキーが無い場合は401を返す。APIキーでしか呼び出せないルートに使う
+func RequireAPIKey(log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := AuthAPIKey(ctx); !ok {
+			log.Warn("Missing API key", "path", ctx.FullPath())
+			Response401(ctx)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// AuthAPIKey APIKeyMiddlewareで認証したAPIキーを取得する
+func AuthAPIKey(ctx *gin.Context) (*domain.APIKeyModel, bool) {
+	v, ok := ctx.Get(authAPIKeyKey)
+	if !ok {
+		return nil, false
+	}
+	apiKey, ok := v.(*domain.APIKeyModel)
+	return apiKey, ok
+}
+
+// AuthMiddleware AuthorizationヘッダーのBearerトークンを検証し、呼び出し元のユーザーIDをgin.Contextに保存する。
+// トークンが無い場合と検証できない場合は401を返す。APIKeyMiddlewareでAPIキーを認証済みの場合は検証しない
+func AuthMiddleware(verifier domain.TokenVerifier, log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := AuthAPIKey(ctx); ok {
+			ctx.Next()
+			return
+		}
+
+		token, ok := bearerToken(ctx.GetHeader("Authorization"))
+		if !ok {
+			log.Warn("Missing bearer token", "path", ctx.FullPath())
+			Response401(ctx)
+			ctx.Abort()
+			return
+		}
+
+		claims, err := verifier.VerifyToken(token)
+		if err != nil {
+			log.Warn("Invalid bearer token", "path", ctx.FullPath(), "error", err)
+			Response401(ctx)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(authUserIDKey, claims.UserID)
+		ctx.Next()
+	}
+}
+
+// RequireSameUser パスパラメータのuser_idが呼び出し元のユーザーIDと一致しない場合は403を返す。AuthMiddlewareの後に使う。
+// APIキーで呼び出された場合は、スコープの確認で済んでいるので検証しない
+func RequireSameUser(log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := AuthAPIKey(ctx); ok {
+			ctx.Next()
+			return
+		}
+
+		authUserID, ok := AuthUserID(ctx)
+		if !ok {
+			Response401(ctx)
+			ctx.Abort()
+			return
+		}
+
+		userID, err := utils.ParseUint(ctx.Param("user_id"))
+		if err != nil || userID != authUserID {
+			log.Warn("Forbidden access to other user's resource", "authUserID", authUserID, "user_id", ctx.Param("user_id"))
+			Response403(ctx)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// AuthUserID AuthMiddlewareで認証した呼び出し元のユーザーIDを取得する
+func AuthUserID(ctx *gin.Context) (uint64, bool) {
+	v, ok := ctx.Get(authUserIDKey)
+	if !ok {
+		return 0, false
+	}
+	userID, ok := v.(uint64)
+	return userID, ok
+}
+
+// bearerToken Authorizationヘッダーからトークンを取り出す。スキーム名の大文字小文字は区別しない
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", false
+	}
+	return token, true
+}
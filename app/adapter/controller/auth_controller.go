@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/adapter/validation"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	log   *slog.Logger
+	login usecase.ILogin
+}
+
+// NewAuthController AuthControllerのインスタンスを生成
+func NewAuthController(f *registry.Factory, log *slog.Logger) *AuthController {
+	return &AuthController{
+		log:   log,
+		login: f.BuildLogin(),
+	}
+}
+
+// LoginSettingValidator バリデーション設定
+func LoginSettingValidator() *validation.Validator {
+	return &validation.Validator{
+		Settings: []*validation.ValidatorSetting{
+			{ArgName: "email", ValidateTags: "required,email"},
+			{ArgName: "password", ValidateTags: "required"},
+		},
+	}
+}
+
+// RequestLogin Loginのリクエスト
+type RequestLogin struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginResponse ログイン成功時のレスポンス。tokenはAuthorizationヘッダーにBearerトークンとして設定する
+type LoginResponse struct {
+	UserID    uint64 `json:"user_id"`
+	Token     string `json:"token"`
+	TokenType string `json:"token_type"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// Login ログイン
+func (ctrl *AuthController) Login(ctx *gin.Context) {
+	ctrl.log.Info("Starting Login handler")
+
+	// リクエストボディを取得
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctrl.log.Error("Failed to get request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// バリデーション処理
+	validator := LoginSettingValidator()
+	validErr := validator.ValidateBody(string(body))
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// JSON形式から構造体に変換
+	var req RequestLogin
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		ctrl.log.Error("Failed to unmarshal request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// ログイン処理
+	res, err := ctrl.login.Execute(&usecase.LoginRequest{
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrUnauthorized.Error() {
+			ctrl.log.Warn("Login failed", "email", req.Email)
+			Response401(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to login", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Login succeeded", "userID", res.UserID)
+	// 200レスポンス
+	Response200(ctx, &LoginResponse{
+		UserID:    res.UserID,
+		Token:     res.Token,
+		TokenType: "Bearer",
+		ExpiresAt: res.ExpiresAt.Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestUserWithPassword パスワードを設定したユーザーを作成する
+func createTestUserWithPassword(t *testing.T, f *registry.Factory, email, password string) uint64 {
+	t.Helper()
+	res, err := f.BuildCreateUser().Execute(&usecase.CreateUserRequest{
+		Name:     "Name_1",
+		Email:    email,
+		Password: password,
+	})
+	require.NoError(t, err)
+	return res.GetUserID()
+}
+
+// postLogin ログインのリクエストを送る
+func postLogin(router *gin.Engine, email, password string) *httptest.ResponseRecorder {
+	bodyStr, _ := json.Marshal(map[string]interface{}{"email": email, "password": password})
+	req, _ := http.NewRequest("POST", "/v1/auth/login", bytes.NewBuffer(bodyStr))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestLogin ログインで発行したトークンで本人のリソースを変更できる
+func TestLogin(t *testing.T) {
+	router, f := setupMemoryRouter()
+	userID := createTestUserWithPassword(t, f, "test1@example.com", "passw0rd!")
+
+	w := postLogin(router, "test1@example.com", "passw0rd!")
+	require.Equal(t, 200, w.Code)
+
+	var res LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, userID, res.UserID)
+	assert.Equal(t, "Bearer", res.TokenType)
+	assert.NotEmpty(t, res.ExpiresAt)
+
+	bodyStr, _ := json.Marshal(map[string]interface{}{"user_name": "Name_1_updated", "email": "test1@example.com"})
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/users/%d", userID), bytes.NewBuffer(bodyStr))
+	req.Header.Set("Authorization", "Bearer "+res.Token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	// ユーザーのレスポンスにパスワードは含めない
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/v1/users/%d", userID), nil)
+	req.Header.Set("Authorization", "Bearer "+res.Token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.NotContains(t, w.Body.String(), "password")
+	assert.NotContains(t, w.Body.String(), "$2a$")
+}
+
+// TestLogin_401 ユーザーが存在しない・パスワードが違う・パスワード未設定の場合は401
+func TestLogin_401(t *testing.T) {
+	router, f := setupMemoryRouter()
+	createTestUserWithPassword(t, f, "test1@example.com", "passw0rd!")
+	_, err := f.UserRepository.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 401, postLogin(router, "test1@example.com", "wrong-passw0rd").Code)
+	assert.Equal(t, 401, postLogin(router, "unknown@example.com", "passw0rd!").Code)
+	assert.Equal(t, 401, postLogin(router, "test2@example.com", "passw0rd!").Code)
+	assert.Equal(t, 400, postLogin(router, "test1@example.com", "").Code)
+}
+
+// TestPostUsers_password 新規作成時のパスワードの強度チェック
+func TestPostUsers_password(t *testing.T) {
+	router, f := setupMemoryRouter()
+
+	for _, password := range []string{"short1", "onlyletters", "1234567890"} {
+		bodyStr, _ := json.Marshal(map[string]interface{}{
+			"user_name": "Name_1",
+			"email":     "test1@example.com",
+			"password":  password,
+		})
+		req, _ := http.NewRequest("POST", "/v1/users", bytes.NewBuffer(bodyStr))
+		setTestAPIKey(t, f, req, domain.ScopeUsersWrite)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, 400, w.Code, password)
+
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		errs := res["errors"].(map[string]interface{})
+		assert.Equal(t, "パスワードは8文字以上72バイト以下で、英字と数字を両方含めてください。", errs["password"])
+	}
+}
+
+// TestPutPassword 現在のパスワードが一致する場合だけ変更できる
+func TestPutPassword(t *testing.T) {
+	router, f := setupMemoryRouter()
+	userID := createTestUserWithPassword(t, f, "test1@example.com", "passw0rd!")
+
+	put := func(userID uint64, current, next string) *httptest.ResponseRecorder {
+		bodyStr, _ := json.Marshal(map[string]interface{}{"current_password": current, "new_password": next})
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/users/%d/password", userID), bytes.NewBuffer(bodyStr))
+		setAuth(req, userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := put(userID, "wrong-passw0rd", "new-passw0rd")
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "現在のパスワードが一致しません。")
+
+	w = put(userID, "passw0rd!", "weak")
+	assert.Equal(t, 400, w.Code)
+
+	w = put(userID, "passw0rd!", "new-passw0rd")
+	assert.Equal(t, 200, w.Code)
+
+	assert.Equal(t, 401, postLogin(router, "test1@example.com", "passw0rd!").Code)
+	assert.Equal(t, 200, postLogin(router, "test1@example.com", "new-passw0rd").Code)
+
+	// 他のユーザーのパスワードは変更できない
+	bodyStr, _ := json.Marshal(map[string]interface{}{"current_password": "new-passw0rd", "new_password": "other-passw0rd1"})
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/users/%d/password", userID), bytes.NewBuffer(bodyStr))
+	setAuth(req, userID+1)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 403, w.Code)
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag 楽観的ロック用のバージョンからETagヘッダーを設定する
+func setETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", fmt.Sprintf(`"%d"`, version))
+	ctx.Header("Access-Control-Expose-Headers", "ETag")
+}
+
+// parseIfMatch If-Matchヘッダーから楽観的ロック用のバージョンを取得する。
+// ヘッダーが無い場合と"*"の場合は0(チェックしない)を返す。ETagとして解釈できない場合はokがfalseになる
+func parseIfMatch(ctx *gin.Context) (version int, ok bool) {
+	ifMatch := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+
+	// If-Matchは強い比較なので、弱いETag(W/"...")は一致しないものとして扱う
+	unquoted, err := strconv.Unquote(ifMatch)
+	if err != nil || !strings.HasPrefix(ifMatch, `"`) {
+		return 0, false
+	}
+
+	version, err = strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/interactor"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"clean-serverless-book-sample/utils"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type FollowController struct {
+	log           *slog.Logger
+	followUser    usecase.IFollowUser
+	unfollowUser  usecase.IUnfollowUser
+	getFollowList usecase.IGetFollowList
+}
+
+// NewFollowController FollowControllerのインスタンスを生成
+func NewFollowController(f *registry.Factory, log *slog.Logger) *FollowController {
+	return &FollowController{
+		log:           log,
+		followUser:    f.BuildFollowUser(),
+		unfollowUser:  f.BuildUnfollowUser(),
+		getFollowList: f.BuildGetFollowList(),
+	}
+}
+
+// FollowResponse フォロー・フォロワー一覧の1件分を表した構造体
+type FollowResponse struct {
+	UserID     uint64 `json:"user_id"`
+	FollowedAt string `json:"followed_at"`
+}
+
+// FollowsResponse フォロー・フォロワー一覧レスポンス用のJSON形式を表した構造体
+type FollowsResponse struct {
+	Users     []*FollowResponse `json:"users"`
+	NextToken string            `json:"next_token"`
+}
+
+// parseFollowPath パスパラメータからユーザーIDとフォローするユーザーIDを取得する
+func parseFollowPath(ctx *gin.Context) (uint64, uint64, error) {
+	userID, err := utils.ParseUint(ctx.Param("user_id"))
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+	targetID, err := utils.ParseUint(ctx.Param("target_id"))
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+	return userID, targetID, nil
+}
+
+// PostFollowing フォロー
+func (ctrl *FollowController) PostFollowing(ctx *gin.Context) {
+	ctrl.log.Info("Starting PostFollowing handler")
+
+	// パスパラメータからユーザーIDを取得する
+	userID, targetID, err := parseFollowPath(ctx)
+	if err != nil {
+		ctrl.log.Error("Failed to parse path parameters", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// フォロー処理
+	ctrl.log.Info("Following user", "userID", userID, "targetID", targetID)
+	_, err = ctrl.followUser.Execute(&usecase.FollowUserRequest{
+		UserID:       userID,
+		TargetUserID: targetID,
+	})
+	if err != nil {
+		if err.Error() == interactor.ErrFollowSelf.Error() {
+			ctrl.log.Warn("Cannot follow yourself", "userID", userID)
+			Response400(ctx, map[string]error{
+				"target_id": errors.New("自分自身はフォローできません。"),
+			})
+			return
+		}
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("User not found", "userID", userID, "targetID", targetID)
+			Response404(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to follow user", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Followed user successfully", "userID", userID, "targetID", targetID)
+	// 200レスポンス
+	Response200OK(ctx)
+}
+
+// DeleteFollowing フォロー解除
+func (ctrl *FollowController) DeleteFollowing(ctx *gin.Context) {
+	ctrl.log.Info("Starting DeleteFollowing handler")
+
+	// パスパラメータからユーザーIDを取得する
+	userID, targetID, err := parseFollowPath(ctx)
+	if err != nil {
+		ctrl.log.Error("Failed to parse path parameters", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// フォロー解除処理
+	ctrl.log.Info("Unfollowing user", "userID", userID, "targetID", targetID)
+	_, err = ctrl.unfollowUser.Execute(&usecase.UnfollowUserRequest{
+		UserID:       userID,
+		TargetUserID: targetID,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Follow not found", "userID", userID, "targetID", targetID)
+			Response404(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to unfollow user", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Unfollowed user successfully", "userID", userID, "targetID", targetID)
+	// レスポンス
+	Response200OK(ctx)
+}
+
+// GetFollowing フォローしているユーザーの一覧取得
+func (ctrl *FollowController) GetFollowing(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetFollowing handler")
+	ctrl.getFollows(ctx, usecase.FollowDirectionFollowing)
+}
+
+// GetFollowers フォロワーの一覧取得
+func (ctrl *FollowController) GetFollowers(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetFollowers handler")
+	ctrl.getFollows(ctx, usecase.FollowDirectionFollowers)
+}
+
+// getFollows フォロー・フォロワー一覧取得の共通処理
+func (ctrl *FollowController) getFollows(ctx *gin.Context, direction usecase.FollowDirection) {
+	// パスパラメータからユーザーIDを取得
+	userID, err := utils.ParseUint(ctx.Param("user_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse user_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// 一覧取得処理
+	res, err := ctrl.getFollowList.Execute(&usecase.GetFollowListRequest{
+		UserID:    userID,
+		Direction: direction,
+		Limit:     page.Limit,
+		NextToken: page.NextToken,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("User not found", "userID", userID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
+		ctrl.log.Error("Failed to get follow list", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// ドメインモデルからレスポンス用の構造体に詰め替える。一覧の向きに応じて相手のユーザーIDを返す
+	var resUsers = make([]*FollowResponse, len(res.Follows))
+	for i, f := range res.Follows {
+		otherID := f.TargetUserID
+		if direction == usecase.FollowDirectionFollowers {
+			otherID = f.UserID
+		}
+		resUsers[i] = &FollowResponse{
+			UserID:     otherID,
+			FollowedAt: f.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	ctrl.log.Info("Follow list retrieved successfully", "userID", userID, "count", len(resUsers))
+	// レスポンス処理
+	Response200(ctx, &FollowsResponse{
+		Users:     resUsers,
+		NextToken: res.NextToken,
+	})
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFollowController フォロー・フォロー解除と一覧・件数の取得
+func TestFollowController(t *testing.T) {
+	router, f := setupMemoryRouter()
+	createTestUsers(t, f.UserRepository, 3)
+
+	serve := func(method, path string, authUserID uint64) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		if authUserID != 0 {
+			setAuth(req, authUserID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, 200, serve("POST", "/v1/users/1/following/2", 1).Code)
+	assert.Equal(t, 200, serve("POST", "/v1/users/3/following/2", 3).Code)
+	// 2回目は何もしない
+	assert.Equal(t, 200, serve("POST", "/v1/users/1/following/2", 1).Code)
+
+	// 本人以外・自分自身・存在しないユーザー
+	assert.Equal(t, 403, serve("POST", "/v1/users/1/following/3", 2).Code)
+	assert.Equal(t, 400, serve("POST", "/v1/users/1/following/1", 1).Code)
+	assert.Equal(t, 404, serve("POST", "/v1/users/1/following/999", 1).Code)
+
+	w := serve("GET", "/v1/users/2/followers", 2)
+	require.Equal(t, 200, w.Code)
+	var followers FollowsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &followers))
+	require.Len(t, followers.Users, 2)
+	assert.Equal(t, uint64(3), followers.Users[0].UserID)
+	assert.Equal(t, uint64(1), followers.Users[1].UserID)
+
+	w = serve("GET", "/v1/users/1/following", 2)
+	require.Equal(t, 200, w.Code)
+	var following FollowsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &following))
+	require.Len(t, following.Users, 1)
+	assert.Equal(t, uint64(2), following.Users[0].UserID)
+
+	w = serve("GET", "/v1/users/2", 2)
+	require.Equal(t, 200, w.Code)
+	var user UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	assert.Equal(t, 2, user.FollowerCount)
+	assert.Equal(t, 0, user.FollowingCount)
+
+	// フォロー解除
+	assert.Equal(t, 200, serve("DELETE", "/v1/users/1/following/2", 1).Code)
+	assert.Equal(t, 404, serve("DELETE", "/v1/users/1/following/2", 1).Code)
+
+	w = serve("GET", "/v1/users/2", 2)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	assert.Equal(t, 1, user.FollowerCount)
+
+	assert.Equal(t, 404, serve("GET", fmt.Sprintf("/v1/users/%d/followers", 999), 2).Code)
+}
+
+// TestFollowController_ifMatch フォローされてもETagは変わらず、取得した時のETagでプロフィールを更新できる
+func TestFollowController_ifMatch(t *testing.T) {
+	router, f := setupMemoryRouter()
+	createTestUsers(t, f.UserRepository, 2)
+
+	req, _ := http.NewRequest("GET", "/v1/users/2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	etag := w.Header().Get("ETag")
+
+	req, _ = http.NewRequest("POST", "/v1/users/1/following/2", nil)
+	setAuth(req, 1)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"user_name": "Name_2_updated",
+		"email":     "test2@example.com",
+	})
+	require.NoError(t, err)
+	req, _ = http.NewRequest("PUT", "/v1/users/2", bytes.NewBuffer(bodyBytes))
+	setAuth(req, 2)
+	req.Header.Set("If-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	// 更新してもフォロワー数は変わらない
+	req, _ = http.NewRequest("GET", "/v1/users/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	var user UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	assert.Equal(t, "Name_2_updated", user.Name)
+	assert.Equal(t, 1, user.FollowerCount)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"clean-serverless-book-sample/adapter/validation"
 	"clean-serverless-book-sample/registry"
 	"clean-serverless-book-sample/usecase"
 	"encoding/json"
@@ -10,7 +11,16 @@ import (
 )
 
 type HelloController struct {
-	log *slog.Logger
+	log                *slog.Logger
+	createHelloMessage usecase.ICreateHelloMessage
+}
+
+// NewHelloController HelloControllerのインスタンスを生成
+func NewHelloController(f *registry.Factory, log *slog.Logger) *HelloController {
+	return &HelloController{
+		log:                log,
+		createHelloMessage: f.BuildCreateHelloMessage(),
+	}
 }
 
 // PostHelloRequest HTTPリクエストのJSON形式を表した構造体
@@ -24,9 +34,9 @@ type HelloMessageResponse struct {
 }
 
 // ValidateHelloMessageSettings バリデーションの設定
-func ValidateHelloMessageSettings() *Validator {
-	return &Validator{
-		Settings: []*ValidatorSetting{
+func ValidateHelloMessageSettings() *validation.Validator {
+	return &validation.Validator{
+		Settings: []*validation.ValidatorSetting{
 			{ArgName: "name", ValidateTags: "required"},
 		},
 	}
@@ -61,8 +71,7 @@ func (ctrl *HelloController) PostHello(ctx *gin.Context) {
 		return
 	}
 	// UseCaseを実⾏
-	h := registry.GetFactory().BuildCreateHelloMessage()
-	res, err := h.Execute(&usecase.CreateHelloMessageRequest{
+	res, err := ctrl.createHelloMessage.Execute(&usecase.CreateHelloMessageRequest{
 		Name: req.Name,
 	})
 	if err != nil {
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/domain"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/validator.v2"
+)
+
+const (
+	// idempotencyKeyHeader 冪等キーを受け取るリクエストヘッダー
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotencyKeyMaxLength 冪等キーの最大文字数
+	idempotencyKeyMaxLength = 255
+)
+
+// idempotencyResponseWriter ハンドラーが書き込んだレスポンスボディを保存するために記録するResponseWriter
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyKeyMiddleware Idempotency-Keyヘッダーが指定された場合に、同じリクエストの再送で処理が重複しないようにするミドルウェア。
+// 処理が成功した場合はレスポンスを保存しておき、同じキーと同じリクエストの再送には保存したレスポンスを返す。
+// 同じキーで異なるリクエストが送られた場合は422、最初のリクエストがまだ処理中の場合は409を返す。
+// タイムアウトなどで完了しないまま占有期限(domain.IdempotencyKeyLease)が過ぎたキーは、同じリクエストの再送が引き継いで処理する。
+// キーは呼び出し元ごとに区別するため、認証のミドルウェアより後に置く
+func IdempotencyKeyMiddleware(repos domain.IdempotencyKeyRepository, log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > idempotencyKeyMaxLength {
+			log.Warn("Idempotency key is too long", "length", len(key))
+			Response400(ctx, map[string]error{"idempotency_key": validator.ErrMax})
+			ctx.Abort()
+			return
+		}
+
+		// リクエストボディはハンドラーでも読み込むため、読み込んだ後に戻しておく
+		body, err := ctx.GetRawData()
+		if err != nil {
+			log.Error("Failed to get request body", "error", err)
+			Response500(ctx, err)
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := domain.NewIdempotencyKeyModel(idempotencyKeyOwner(ctx), key, hashRequest(ctx.Request, body), time.Now())
+		err = repos.ReserveIdempotencyKey(record)
+		if err != nil {
+			if err.Error() == domain.ErrConflict.Error() {
+				replayIdempotentResponse(ctx, repos, record, log)
+				ctx.Abort()
+				return
+			}
+			log.Error("Failed to reserve idempotency key", "error", err)
+			Response500(ctx, err)
+			ctx.Abort()
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+
+		// 失敗した場合は同じキーで再実行できるように削除する。引き継いだ再送のレコードは削除しない
+		status := writer.Status()
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			if err := repos.DeleteIdempotencyKey(record); err != nil {
+				if err.Error() == domain.ErrConflict.Error() {
+					log.Warn("Idempotency key was taken over by a retry", "key", key)
+					return
+				}
+				log.Error("Failed to delete idempotency key", "error", err)
+			}
+			return
+		}
+
+		record.StatusCode = status
+		record.ResponseBody = writer.body.String()
+		if err := repos.CompleteIdempotencyKey(record); err != nil {
+			if err.Error() == domain.ErrConflict.Error() {
+				log.Warn("Idempotency key was taken over by a retry", "key", key)
+				return
+			}
+			log.Error("Failed to complete idempotency key", "error", err)
+		}
+	}
+}
+
+// replayIdempotentResponse 登録済みの冪等キーに保存されているレスポンスを返す
+func replayIdempotentResponse(ctx *gin.Context, repos domain.IdempotencyKeyRepository, record *domain.IdempotencyKeyModel, log *slog.Logger) {
+	existing, err := repos.GetIdempotencyKey(record.Owner, record.Key)
+	if err != nil {
+		// 登録と取得の間に削除された場合は、処理中として扱う
+		if err.Error() == domain.ErrNotFound.Error() {
+			Response409(ctx)
+			return
+		}
+		log.Error("Failed to get idempotency key", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	if existing.RequestHash != record.RequestHash {
+		log.Warn("Idempotency key reused with a different request", "key", record.Key)
+		Response422(ctx)
+		return
+	}
+	if !existing.IsCompleted() {
+		log.Warn("Idempotency key is still in progress", "key", record.Key)
+		Response409(ctx)
+		return
+	}
+
+	log.Info("Replaying idempotent response", "key", record.Key, "status", existing.StatusCode)
+	commonHeaders(ctx)
+	ctx.Header("Idempotent-Replayed", "true")
+	ctx.Data(existing.StatusCode, "application/json; charset=utf-8", []byte(existing.ResponseBody))
+}
+
+// idempotencyKeyOwner 冪等キーを区別する呼び出し元。認証したユーザー、APIキーの順に使い、
+// どちらもない場合は認証なしの呼び出し元で1つの範囲を共有する
+func idempotencyKeyOwner(ctx *gin.Context) string {
+	if userID, ok := AuthUserID(ctx); ok {
+		return fmt.Sprintf("User-%d", userID)
+	}
+	if apiKey, ok := AuthAPIKey(ctx); ok {
+		return fmt.Sprintf("APIKey-%d", apiKey.ID)
+	}
+	return "Anonymous"
+}
+
+// hashRequest 冪等キーに紐づけるリクエストのハッシュ値。メソッドとパスも含めて、別のエンドポイントでの使い回しも検出する
+func hashRequest(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + "\n" + req.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/adapter/validation"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"log/slog"
+	"mime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importFileExtensions 受け付けるファイルのContent-Typeと、保存する時の拡張子
+var importFileExtensions = map[string]string{
+	"text/csv":             ".csv",
+	"application/x-ndjson": ".jsonl",
+}
+
+type ImportJobController struct {
+	log              *slog.Logger
+	submitUserImport usecase.ISubmitUserImport
+	getImportJob     usecase.IGetImportJob
+}
+
+// NewImportJobController ImportJobControllerのインスタンスを生成
+func NewImportJobController(f *registry.Factory, log *slog.Logger) *ImportJobController {
+	return &ImportJobController{
+		log:              log,
+		submitUserImport: f.BuildSubmitUserImport(),
+		getImportJob:     f.BuildGetImportJob(),
+	}
+}
+
+// ResponseImportJob レスポンス用の取り込みジョブのJSON形式を表した構造体。
+// 失敗した行はreport_keyのファイルに1行ずつ書き出す
+type ResponseImportJob struct {
+	ID        string `json:"id"`
+	Source    string `json:"source"`
+	Status    string `json:"status"`
+	Processed int    `json:"processed"`
+	Failed    int    `json:"failed"`
+	ReportKey string `json:"report_key"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// NewResponseImportJob ドメインモデルからレスポンス用の構造体に詰め替える
+func NewResponseImportJob(job *domain.ImportJobModel) *ResponseImportJob {
+	return &ResponseImportJob{
+		ID:        job.ID,
+		Source:    job.Source,
+		Status:    job.Status,
+		Processed: job.Processed,
+		Failed:    job.Failed,
+		ReportKey: job.ReportKey,
+		CreatedAt: job.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt: job.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// PostUserBatchImport リクエストボディのCSVかJSON Linesのファイルからユーザーを一括で取り込む。
+// 取り込みはS3に置いたファイルと同じく非同期で行うため、進捗を参照するジョブを202で返す
+func (ctrl *ImportJobController) PostUserBatchImport(ctx *gin.Context) {
+	ctrl.log.Info("Starting PostUserBatchImport handler")
+
+	// Content-Typeからファイルの形式を判別する
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	ext, ok := importFileExtensions[mediaType]
+	if !ok {
+		ctrl.log.Warn("Unsupported import file type", "contentType", ctx.GetHeader("Content-Type"))
+		Response400(ctx, map[string]error{"file_type": validation.ErrImportFileType})
+		return
+	}
+
+	// リクエストボディを取得
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctrl.log.Error("Failed to get request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+	if len(body) == 0 {
+		ctrl.log.Warn("Empty import file")
+		Response400(ctx, map[string]error{"file": validation.ErrRequired})
+		return
+	}
+
+	// 取り込みの受付処理
+	res, err := ctrl.submitUserImport.Execute(&usecase.SubmitUserImportRequest{
+		Extension:   ext,
+		ContentType: mediaType,
+		Body:        bytes.NewReader(body),
+	})
+	if err != nil {
+		ctrl.log.Error("Failed to submit user import", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("User import submitted successfully", "jobID", res.Job.ID, "source", res.Job.Source)
+	Response202(ctx, NewResponseImportJob(res.Job))
+}
+
+// GetImportJob 一括取り込みのジョブの進捗を取得する。APIキーには取り込み先のリソースに書き込めるスコープが必要
+func (ctrl *ImportJobController) GetImportJob(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetImportJob handler")
+
+	jobID := ctx.Param("job_id")
+	res, err := ctrl.getImportJob.Execute(&usecase.GetImportJobRequest{JobID: jobID})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Import job not found", "jobID", jobID)
+			Response404(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to get import job", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	apiKey, _ := AuthAPIKey(ctx)
+	if scope := res.Job.RequiredScope(); apiKey == nil || !apiKey.HasScope(scope) {
+		ctrl.log.Warn("API key lacks required scope", "jobID", jobID, "scope", scope)
+		Response403(ctx)
+		return
+	}
+
+	Response200(ctx, NewResponseImportJob(res.Job))
+}
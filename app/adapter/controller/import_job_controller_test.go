@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/adapter/importer"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/usecase"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserBatchImport APIで受け付けたファイルからのユーザーの一括取り込みと、ジョブの進捗の参照
+func TestUserBatchImport(t *testing.T) {
+	const bucket = "test-bucket"
+	t.Setenv("S3_BUCKET_NAME", bucket)
+
+	router, f := setupMemoryRouter()
+	client := f.S3Client.(*mocks.FakeS3Client)
+	key := issueTestAPIKey(t, f, time.Now().Add(time.Hour), domain.ScopeUsersWrite).Key
+	_, err := f.UserRepository.CreateUser(domain.NewUserModel("既存のユーザー", "exists@example.com"))
+	require.NoError(t, err)
+
+	serve := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-API-Key", key)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	getJob := func(id string) *ResponseImportJob {
+		w := serve("GET", "/v1/import_jobs/"+id, "", "")
+		require.Equal(t, 200, w.Code)
+		var job ResponseImportJob
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		return &job
+	}
+
+	w := serve("POST", "/v1/users/batch_import", "text/csv; charset=utf-8", strings.Join([]string{
+		"user_name,email,password",
+		"新しいユーザー,new1@example.com,",
+		"既存と重複,exists@example.com,",
+		"パスワードあり,new2@example.com,password123",
+		"ファイル内で重複,new1@example.com,",
+		"メールアドレスが不正,invalid,",
+	}, "\n"))
+	require.Equal(t, 202, w.Code)
+	var submitted ResponseImportJob
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &submitted))
+	assert.True(t, strings.HasPrefix(submitted.Source, domain.UserImportKeyPrefix))
+	assert.True(t, strings.HasSuffix(submitted.Source, ".csv"))
+	assert.Equal(t, domain.ImportJobStatusRunning, submitted.Status)
+
+	// 受け付けた時点で進捗を参照できる
+	assert.Equal(t, 0, getJob(submitted.ID).Processed)
+
+	// S3のイベントで取り込む。イベントのETagには引用符が付かない
+	obj := client.GetTestObject(bucket, submitted.Source)
+	require.NotNil(t, obj)
+	job, err := f.BuildImportJobRepository().GetImportJob(submitted.ID)
+	require.NoError(t, err)
+	res, err := f.BuildImportUsers().Execute(&usecase.ImportUsersRequest{
+		Key:         submitted.Source,
+		ETag:        strings.Trim(job.ETag, `"`),
+		Decoder:     &importer.UserImportDecoder{},
+		LockedUntil: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.False(t, res.Skipped)
+
+	completed := getJob(submitted.ID)
+	assert.Equal(t, domain.ImportJobStatusCompleted, completed.Status)
+	assert.Equal(t, 5, completed.Processed)
+	assert.Equal(t, 3, completed.Failed)
+
+	report := client.ReadTestImportReport(t, bucket, completed.ReportKey)
+	assert.Len(t, report, 3)
+	assert.Equal(t, "すでに登録されているメールアドレスです。", report[2]["email"])
+	assert.Equal(t, "メールアドレスがファイル内の前の行と重複しています。", report[4]["email"])
+	assert.Equal(t, "メールアドレスの形式が不正です。", report[5]["email"])
+
+	user, err := f.UserRepository.GetUserByEmail("new1@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "新しいユーザー", user.Name)
+	assert.False(t, user.HasPassword())
+	user, err = f.UserRepository.GetUserByEmail("new2@example.com")
+	require.NoError(t, err)
+	assert.True(t, user.HasPassword())
+
+	t.Run("形式がわからないファイルは受け付けない", func(t *testing.T) {
+		w := serve("POST", "/v1/users/batch_import", "application/json", `{}`)
+		assert.Equal(t, 400, w.Code)
+		w = serve("POST", "/v1/users/batch_import", "text/csv", "")
+		assert.Equal(t, 400, w.Code)
+	})
+
+	t.Run("不明なジョブは404", func(t *testing.T) {
+		w := serve("GET", "/v1/import_jobs/unknown", "", "")
+		assert.Equal(t, 404, w.Code)
+	})
+
+	t.Run("取り込み先に書き込めるスコープが無いジョブは参照できない", func(t *testing.T) {
+		now := time.Now()
+		job, err := f.ImportJobRepository.StartImportJob(domain.NewImportJobModel(domain.ProductImportKeyPrefix+"products.csv", "etag", now, now))
+		require.NoError(t, err)
+
+		w := serve("GET", "/v1/import_jobs/"+job.ID, "", "")
+		assert.Equal(t, 403, w.Code)
+
+		req, _ := http.NewRequest("GET", "/v1/import_jobs/"+job.ID, nil)
+		req.Header.Set("X-API-Key", issueTestAPIKey(t, f, now.Add(time.Hour), domain.ScopeProductsWrite).Key)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+	})
+
+	t.Run("APIキーが無い場合は401", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v1/users/batch_import", bytes.NewBufferString("user_name,email\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 401, w.Code)
+	})
+}
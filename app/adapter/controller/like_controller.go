@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"clean-serverless-book-sample/utils"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type LikeController struct {
+	log             *slog.Logger
+	likeMicropost   usecase.ILikeMicropost
+	unlikeMicropost usecase.IUnlikeMicropost
+}
+
+// NewLikeController LikeControllerのインスタンスを生成
+func NewLikeController(f *registry.Factory, log *slog.Logger) *LikeController {
+	return &LikeController{
+		log:             log,
+		likeMicropost:   f.BuildLikeMicropost(),
+		unlikeMicropost: f.BuildUnlikeMicropost(),
+	}
+}
+
+// parseMicropostPath パスパラメータからユーザーIDとマイクロポストIDを取得する
+func parseMicropostPath(ctx *gin.Context) (uint64, uint64, error) {
+	userID, err := utils.ParseUint(ctx.Param("user_id"))
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+	micropostID, err := utils.ParseUint(ctx.Param("micropost_id"))
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+	return userID, micropostID, nil
+}
+
+// PostLike いいね。トークンのユーザーとしていいねする
+func (ctrl *LikeController) PostLike(ctx *gin.Context) {
+	ctrl.log.Info("Starting PostLike handler")
+
+	// パスパラメータからユーザーIDとマイクロポストIDを取得する
+	userID, micropostID, err := parseMicropostPath(ctx)
+	if err != nil {
+		ctrl.log.Error("Failed to parse path parameters", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// いいねするユーザーはトークンから取得する
+	likerID, ok := AuthUserID(ctx)
+	if !ok {
+		ctrl.log.Warn("Missing authenticated user")
+		Response401(ctx)
+		return
+	}
+
+	// いいね処理
+	ctrl.log.Info("Liking micropost", "micropostID", micropostID, "likerID", likerID)
+	_, err = ctrl.likeMicropost.Execute(&usecase.LikeMicropostRequest{
+		UserID:      userID,
+		MicropostID: micropostID,
+		LikerID:     likerID,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Micropost not found", "micropostID", micropostID, "userID", userID)
+			Response404(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to like micropost", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Liked micropost successfully", "micropostID", micropostID, "likerID", likerID)
+	// 200レスポンス
+	Response200OK(ctx)
+}
+
+// DeleteLike いいね取り消し。トークンのユーザーのいいねを取り消す
+func (ctrl *LikeController) DeleteLike(ctx *gin.Context) {
+	ctrl.log.Info("Starting DeleteLike handler")
+
+	// パスパラメータからユーザーIDとマイクロポストIDを取得する
+	userID, micropostID, err := parseMicropostPath(ctx)
+	if err != nil {
+		ctrl.log.Error("Failed to parse path parameters", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// いいねを取り消すユーザーはトークンから取得する
+	likerID, ok := AuthUserID(ctx)
+	if !ok {
+		ctrl.log.Warn("Missing authenticated user")
+		Response401(ctx)
+		return
+	}
+
+	// いいね取り消し処理
+	ctrl.log.Info("Unliking micropost", "micropostID", micropostID, "likerID", likerID)
+	_, err = ctrl.unlikeMicropost.Execute(&usecase.UnlikeMicropostRequest{
+		UserID:      userID,
+		MicropostID: micropostID,
+		LikerID:     likerID,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Like not found", "micropostID", micropostID, "likerID", likerID)
+			Response404(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to unlike micropost", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Unliked micropost successfully", "micropostID", micropostID, "likerID", likerID)
+	// レスポンス
+	Response200OK(ctx)
+}
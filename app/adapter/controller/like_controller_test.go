@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/domain"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLikeController いいね・いいね取り消しといいね数の取得
+func TestLikeController(t *testing.T) {
+	router, f := setupMemoryRouter()
+	createTestUsers(t, f.UserRepository, 3)
+
+	m, err := f.MicropostRepository.CreateMicropost(domain.NewMicropostModel("Content", 1))
+	require.NoError(t, err)
+	likesPath := fmt.Sprintf("/v1/users/1/microposts/%d/likes", m.ID)
+
+	serve := func(method, path string, authUserID uint64) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		if authUserID != 0 {
+			setAuth(req, authUserID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	likeCount := func() int {
+		w := serve("GET", fmt.Sprintf("/v1/users/1/microposts/%d", m.ID), 1)
+		require.Equal(t, 200, w.Code)
+		var res ResponseMicropost
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return res.LikeCount
+	}
+
+	assert.Equal(t, 200, serve("POST", likesPath, 2).Code)
+	assert.Equal(t, 200, serve("POST", likesPath, 3).Code)
+	// 同じユーザーは1回だけ数える
+	assert.Equal(t, 200, serve("POST", likesPath, 2).Code)
+	assert.Equal(t, 2, likeCount())
+
+	// トークンが無い場合・マイクロポストのユーザーが異なる場合・存在しないマイクロポスト
+	assert.Equal(t, 401, serve("POST", likesPath, 0).Code)
+	assert.Equal(t, 404, serve("POST", fmt.Sprintf("/v1/users/2/microposts/%d/likes", m.ID), 2).Code)
+	assert.Equal(t, 404, serve("POST", "/v1/users/1/microposts/999/likes", 2).Code)
+
+	// いいね取り消し
+	assert.Equal(t, 200, serve("DELETE", likesPath, 2).Code)
+	assert.Equal(t, 404, serve("DELETE", likesPath, 2).Code)
+	assert.Equal(t, 1, likeCount())
+}
@@ -1,27 +1,61 @@
 package controller
 
 import (
+	"clean-serverless-book-sample/adapter/validation"
 	"clean-serverless-book-sample/domain"
 	"clean-serverless-book-sample/registry"
 	"clean-serverless-book-sample/usecase"
 	"clean-serverless-book-sample/utils"
 	"encoding/json"
 	"log/slog"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type MicropostController struct {
-	log *slog.Logger
+	log              *slog.Logger
+	createMicropost  usecase.ICreateMicropost
+	updateMicropost  usecase.IUpdateMicropost
+	getMicropostList usecase.IGetMicropostList
+	getMicropostByID usecase.IGetMicropostByID
+	deleteMicropost  usecase.IDeleteMicropost
+	getFeed          usecase.IGetFeed
+	getReplyList     usecase.IGetReplyList
+	getHashtagList   usecase.IGetHashtagMicropostList
+	getMentionList   usecase.IGetMentionList
+}
+
+// NewMicropostController MicropostControllerのインスタンスを生成
+func NewMicropostController(f *registry.Factory, log *slog.Logger) *MicropostController {
+	return &MicropostController{
+		log:              log,
+		createMicropost:  f.BuildCreateMicropost(),
+		updateMicropost:  f.BuildUpdateMicropost(),
+		getMicropostList: f.BuildGetMicropostList(),
+		getMicropostByID: f.BuildGetMicropostByID(),
+		deleteMicropost:  f.BuildDeleteMicropost(),
+		getFeed:          f.BuildGetFeed(),
+		getReplyList:     f.BuildGetReplyList(),
+		getHashtagList:   f.BuildGetHashtagMicropostList(),
+		getMentionList:   f.BuildGetMentionList(),
+	}
 }
 
 // MicropostSettingsValidator バリデーション設定
-func MicropostSettingsValidator() *Validator {
-	return &Validator{
-		Settings: []*ValidatorSetting{{ArgName: "content", ValidateTags: "required,max=140"}},
+func MicropostSettingsValidator() *validation.Validator {
+	return &validation.Validator{
+		Settings: []*validation.ValidatorSetting{{ArgName: "content", ValidateTags: "required,max=140"}},
 	}
 }
 
+// PostMicropostSettingsValidator 新規作成時のバリデーション設定。返信先は任意で指定できる
+func PostMicropostSettingsValidator() *validation.Validator {
+	v := MicropostSettingsValidator()
+	v.Settings = append(v.Settings, &validation.ValidatorSetting{ArgName: "in_reply_to", ValidateTags: "int,uint"})
+	return v
+}
+
 // RequestMicropost HTTPリクエストで送られてくるJSON形式を表した構造体
 type RequestMicropost struct {
 	Content string `json:"content"`
@@ -30,6 +64,7 @@ type RequestMicropost struct {
 // RequestPostMicropost PostMicropostのリクエスト
 type RequestPostMicropost struct {
 	RequestMicropost
+	InReplyTo uint64 `json:"in_reply_to"`
 }
 
 // RequestPutMicropost PutMicropostのリクエスト
@@ -39,14 +74,37 @@ type RequestPutMicropost struct {
 
 // ResponseMicropost レスポンス用のJSON形式を表した構造体
 type ResponseMicropost struct {
-	ID      uint64 `json:"id"`
-	UserID  uint64 `json:"user_id"`
-	Content string `json:"content"`
+	ID         uint64 `json:"id"`
+	UserID     uint64 `json:"user_id"`
+	Content    string `json:"content"`
+	LikeCount  int    `json:"like_count"`
+	InReplyTo  uint64 `json:"in_reply_to,omitempty"`
+	ReplyCount int    `json:"reply_count"`
+	// Orphaned 返信先が削除された返信の場合にtrue
+	Orphaned    bool                  `json:"orphaned,omitempty"`
+	Attachments []*ResponseAttachment `json:"attachments"`
+	CreatedAt   string                `json:"created_at"`
 }
 
 // ResponseMicroposts Micropostリストレスポンス用のJSON形式を表した構造体
 type ResponseMicroposts struct {
 	Microposts []*ResponseMicropost `json:"microposts"`
+	NextToken  string               `json:"next_token"`
+}
+
+// NewResponseMicropost ドメインモデルからレスポンス用の構造体に詰め替える
+func NewResponseMicropost(m *domain.MicropostModel) *ResponseMicropost {
+	return &ResponseMicropost{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Content:     m.Content,
+		LikeCount:   m.LikeCount,
+		InReplyTo:   m.InReplyToID,
+		ReplyCount:  m.ReplyCount,
+		Orphaned:    m.Orphaned,
+		Attachments: NewResponseAttachments(m.Attachments),
+		CreatedAt:   m.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
 }
 
 // PostMicroposts 新規作成
@@ -62,7 +120,7 @@ func (ctrl *MicropostController) PostMicroposts(ctx *gin.Context) {
 	}
 
 	// バリデーション処理
-	validator := MicropostSettingsValidator()
+	validator := PostMicropostSettingsValidator()
 	validErr := validator.ValidateBody(string(body))
 	if validErr != nil {
 		ctrl.log.Warn("Validation error", "error", validErr)
@@ -88,13 +146,23 @@ func (ctrl *MicropostController) PostMicroposts(ctx *gin.Context) {
 	}
 
 	// 新規作成処理
-	ctrl.log.Info("Creating new micropost", "userID", userID, "content", req.Content)
-	creator := registry.GetFactory().BuildCreateMicropost()
-	res, err := creator.Execute(&usecase.CreateMicropostRequest{
-		Content: req.Content,
-		UserID:  userID,
+	ctrl.log.Info("Creating new micropost", "userID", userID, "content", req.Content, "inReplyTo", req.InReplyTo)
+	res, err := ctrl.createMicropost.Execute(&usecase.CreateMicropostRequest{
+		Content:     req.Content,
+		UserID:      userID,
+		InReplyToID: req.InReplyTo,
 	})
 	if err != nil {
+		if err.Error() == domain.ErrInReplyToNotFound.Error() {
+			ctrl.log.Warn("Reply target not found", "inReplyTo", req.InReplyTo)
+			Response400(ctx, map[string]error{"in_reply_to": validation.ErrNotExist})
+			return
+		}
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("User not found", "userID", userID)
+			Response404(ctx)
+			return
+		}
 		ctrl.log.Error("Failed to create micropost", "error", err)
 		Response500(ctx, err)
 		return
@@ -151,15 +219,28 @@ func (ctrl *MicropostController) PutMicropost(ctx *gin.Context) {
 		return
 	}
 
+	// If-Matchヘッダーから楽観的ロック用のバージョンを取得する
+	version, ok := parseIfMatch(ctx)
+	if !ok {
+		ctrl.log.Warn("Invalid If-Match header", "ifMatch", ctx.GetHeader("If-Match"))
+		Response412(ctx)
+		return
+	}
+
 	// 更新処理
 	ctrl.log.Info("Updating micropost", "micropostID", micropostID, "userID", userID)
-	updater := registry.GetFactory().BuildUpdateMicropost()
-	_, err = updater.Execute(&usecase.UpdateMicropostRequest{
+	_, err = ctrl.updateMicropost.Execute(&usecase.UpdateMicropostRequest{
 		Content:     req.Content,
 		UserID:      userID,
 		MicropostID: micropostID,
+		Version:     version,
 	})
 	if err != nil {
+		if err.Error() == domain.ErrConflict.Error() {
+			ctrl.log.Warn("Micropost version conflict", "micropostID", micropostID, "version", version)
+			Response412(ctx)
+			return
+		}
 		ctrl.log.Error("Failed to update micropost", "error", err)
 		Response500(ctx, err)
 		return
@@ -182,13 +263,27 @@ func (ctrl *MicropostController) GetMicroposts(ctx *gin.Context) {
 		return
 	}
 
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation error", "error", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
 	// マイクロポスト取得処理
 	ctrl.log.Info("Getting micropost list", "userID", userID)
-	getter := registry.GetFactory().BuildGetMicropostList()
-	res, err := getter.Execute(&usecase.GetMicropostListRequest{
-		UserID: userID,
+	res, err := ctrl.getMicropostList.Execute(&usecase.GetMicropostListRequest{
+		UserID:    userID,
+		Limit:     page.Limit,
+		NextToken: page.NextToken,
 	})
 	if err != nil {
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
 		ctrl.log.Error("Failed to get micropost list", "error", err)
 		Response500(ctx, err)
 		return
@@ -197,17 +292,241 @@ func (ctrl *MicropostController) GetMicroposts(ctx *gin.Context) {
 	// ドメインモデルからレスポンス用の構造体に詰め替える
 	var resMicroposts = make([]*ResponseMicropost, len(res.Microposts))
 	for i, m := range res.Microposts {
-		resMicroposts[i] = &ResponseMicropost{
-			ID:      m.ID,
-			UserID:  m.UserID,
-			Content: m.Content,
-		}
+		resMicroposts[i] = NewResponseMicropost(m)
 	}
 
 	ctrl.log.Info("Successfully retrieved micropost list", "count", len(resMicroposts))
 	// レスポンス処理
 	Response200(ctx, &ResponseMicroposts{
 		Microposts: resMicroposts,
+		NextToken:  res.NextToken,
+	})
+}
+
+// GetFeed フォローしているユーザーのマイクロポストを新しい順に取得
+func (ctrl *MicropostController) GetFeed(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetFeed handler")
+
+	// パスパラメータからユーザーIDを取得
+	userID, err := utils.ParseUint(ctx.Param("user_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse user_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation error", "error", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// ホームタイムライン取得処理
+	ctrl.log.Info("Getting feed", "userID", userID)
+	res, err := ctrl.getFeed.Execute(&usecase.GetFeedRequest{
+		UserID:    userID,
+		Limit:     page.Limit,
+		NextToken: page.NextToken,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("User not found", "userID", userID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
+		ctrl.log.Error("Failed to get feed", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// ドメインモデルからレスポンス用の構造体に詰め替える
+	var resMicroposts = make([]*ResponseMicropost, len(res.Microposts))
+	for i, m := range res.Microposts {
+		resMicroposts[i] = NewResponseMicropost(m)
+	}
+
+	ctrl.log.Info("Successfully retrieved feed", "count", len(resMicroposts))
+	// レスポンス処理
+	Response200(ctx, &ResponseMicroposts{
+		Microposts: resMicroposts,
+		NextToken:  res.NextToken,
+	})
+}
+
+// GetReplies マイクロポストへの返信を古い順に取得
+func (ctrl *MicropostController) GetReplies(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetReplies handler")
+
+	// パスパラメータからユーザーIDを取得する
+	userID, err := utils.ParseUint(ctx.Param("user_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse user_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// パスパラメータからマイクロポストIDを取得する
+	micropostID, err := utils.ParseUint(ctx.Param("micropost_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse micropost_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation error", "error", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// 返信取得処理
+	ctrl.log.Info("Getting replies", "micropostID", micropostID, "userID", userID)
+	res, err := ctrl.getReplyList.Execute(&usecase.GetReplyListRequest{
+		UserID:      userID,
+		MicropostID: micropostID,
+		Limit:       page.Limit,
+		NextToken:   page.NextToken,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Micropost not found", "micropostID", micropostID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
+		ctrl.log.Error("Failed to get replies", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// ドメインモデルからレスポンス用の構造体に詰め替える
+	var resMicroposts = make([]*ResponseMicropost, len(res.Replies))
+	for i, m := range res.Replies {
+		resMicroposts[i] = NewResponseMicropost(m)
+	}
+
+	ctrl.log.Info("Successfully retrieved replies", "count", len(resMicroposts))
+	// レスポンス処理
+	Response200(ctx, &ResponseMicroposts{
+		Microposts: resMicroposts,
+		NextToken:  res.NextToken,
+	})
+}
+
+// GetHashtagMicroposts ハッシュタグを含むマイクロポストを新しい順に取得
+func (ctrl *MicropostController) GetHashtagMicroposts(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetHashtagMicroposts handler")
+
+	// パスパラメータからハッシュタグを取得する
+	tag := ctx.Param("tag")
+
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation error", "error", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// ハッシュタグのマイクロポスト取得処理
+	ctrl.log.Info("Getting hashtag microposts", "tag", tag)
+	res, err := ctrl.getHashtagList.Execute(&usecase.GetHashtagMicropostListRequest{
+		Tag:       tag,
+		Limit:     page.Limit,
+		NextToken: page.NextToken,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
+		ctrl.log.Error("Failed to get hashtag microposts", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// ドメインモデルからレスポンス用の構造体に詰め替える
+	var resMicroposts = make([]*ResponseMicropost, len(res.Microposts))
+	for i, m := range res.Microposts {
+		resMicroposts[i] = NewResponseMicropost(m)
+	}
+
+	ctrl.log.Info("Successfully retrieved hashtag microposts", "count", len(resMicroposts))
+	// レスポンス処理
+	Response200(ctx, &ResponseMicroposts{
+		Microposts: resMicroposts,
+		NextToken:  res.NextToken,
+	})
+}
+
+// GetMentions ユーザーをメンションしているマイクロポストを新しい順に取得
+func (ctrl *MicropostController) GetMentions(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetMentions handler")
+
+	// パスパラメータからユーザーIDを取得
+	userID, err := utils.ParseUint(ctx.Param("user_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse user_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation error", "error", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// メンション取得処理
+	ctrl.log.Info("Getting mentions", "userID", userID)
+	res, err := ctrl.getMentionList.Execute(&usecase.GetMentionListRequest{
+		UserID:    userID,
+		Limit:     page.Limit,
+		NextToken: page.NextToken,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("User not found", "userID", userID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
+		ctrl.log.Error("Failed to get mentions", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// ドメインモデルからレスポンス用の構造体に詰め替える
+	var resMicroposts = make([]*ResponseMicropost, len(res.Microposts))
+	for i, m := range res.Microposts {
+		resMicroposts[i] = NewResponseMicropost(m)
+	}
+
+	ctrl.log.Info("Successfully retrieved mentions", "count", len(resMicroposts))
+	// レスポンス処理
+	Response200(ctx, &ResponseMicroposts{
+		Microposts: resMicroposts,
+		NextToken:  res.NextToken,
 	})
 }
 
@@ -233,8 +552,7 @@ func (ctrl *MicropostController) GetMicropost(ctx *gin.Context) {
 
 	// マイクロポスト取得処理
 	ctrl.log.Info("Getting micropost by ID", "micropostID", micropostID, "userID", userID)
-	getter := registry.GetFactory().BuildGetMicropostByID()
-	res, err := getter.Execute(&usecase.GetMicropostByIDRequest{
+	res, err := ctrl.getMicropostByID.Execute(&usecase.GetMicropostByIDRequest{
 		MicropostID: micropostID,
 		UserID:      userID,
 	})
@@ -250,12 +568,9 @@ func (ctrl *MicropostController) GetMicropost(ctx *gin.Context) {
 	}
 
 	ctrl.log.Info("Successfully retrieved micropost", "micropostID", res.Micropost.ID)
+	setETag(ctx, res.Micropost.Version)
 	// ドメインモデルからレスポンス用構造体に詰め替えて、レスポンス
-	Response200(ctx, &ResponseMicropost{
-		ID:      res.Micropost.ID,
-		Content: res.Micropost.Content,
-		UserID:  res.Micropost.UserID,
-	})
+	Response200(ctx, NewResponseMicropost(res.Micropost))
 }
 
 // DeleteMicropost 削除処理
@@ -278,16 +593,30 @@ func (ctrl *MicropostController) DeleteMicropost(ctx *gin.Context) {
 		return
 	}
 
+	// If-Matchヘッダーから楽観的ロック用のバージョンを取得する
+	version, ok := parseIfMatch(ctx)
+	if !ok {
+		ctrl.log.Warn("Invalid If-Match header", "ifMatch", ctx.GetHeader("If-Match"))
+		Response412(ctx)
+		return
+	}
+
 	// 削除処理
 	ctrl.log.Info("Deleting micropost", "micropostID", micropostID, "userID", userID)
-	deleter := registry.GetFactory().BuildDeleteMicropost()
-	_, err = deleter.Execute(&usecase.DeleteMicropostRequest{
+	_, err = ctrl.deleteMicropost.Execute(&usecase.DeleteMicropostRequest{
 		MicropostID: micropostID,
 		UserID:      userID,
+		Version:     version,
 	})
 	if err != nil {
+		if err.Error() == domain.ErrConflict.Error() {
+			ctrl.log.Warn("Micropost version conflict", "micropostID", micropostID, "version", version)
+			Response412(ctx)
+			return
+		}
 		ctrl.log.Error("Failed to delete micropost", "error", err)
 		Response500(ctx, err)
+		return
 	}
 
 	ctrl.log.Info("Successfully deleted micropost", "micropostID", micropostID)
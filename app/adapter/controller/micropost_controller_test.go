@@ -2,8 +2,11 @@ package controller
 
 import (
 	"bytes"
+	"clean-serverless-book-sample/adapter"
 	"clean-serverless-book-sample/domain"
 	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/mocks/memory"
+	"clean-serverless-book-sample/registry"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,11 +18,52 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// setupRouter DynamoDBのリポジトリと、テスト用の鍵で署名したトークンを受け付けるルーターを生成
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	return Routes()
+	f := registry.GetFactory()
+	f.TokenVerifier = mocks.GetJWTTestKeys().Verifier()
+	return Routes(f)
+}
+
+// setupMemoryRouter DynamoDBの代わりにインメモリのリポジトリを使うルーターを生成
+func setupMemoryRouter() (*gin.Engine, *registry.Factory) {
+	gin.SetMode(gin.TestMode)
+	f := registry.NewFactory(registry.NewEnvs())
+	users := memory.NewUserRepository()
+	f.UserRepository = users
+	microposts := memory.NewMicropostRepository(users)
+	f.MicropostRepository = microposts
+	f.ProductRepository = memory.NewProductRepository()
+	f.IdempotencyKeyRepository = memory.NewIdempotencyKeyRepository()
+	f.CascadeDeleteJobRepository = memory.NewCascadeDeleteJobRepository()
+	f.FollowRepository = memory.NewFollowRepository(users)
+	f.LikeRepository = memory.NewLikeRepository(microposts, users)
+	f.APIKeyRepository = memory.NewAPIKeyRepository()
+	f.TokenVerifier = mocks.GetJWTTestKeys().Verifier()
+	f.TokenIssuer = mocks.GetJWTTestKeys().Issuer()
+	f.PasswordHasher = adapter.NewBcryptPasswordHasher(bcrypt.MinCost)
+	f.S3Client = mocks.NewFakeS3Client()
+	f.ImportJobRepository = memory.NewImportJobRepository()
+	return Routes(f), f
+}
+
+// setAuth 指定したユーザーとして認証するAuthorizationヘッダーを設定する
+func setAuth(req *http.Request, userID uint64) {
+	req.Header.Set("Authorization", mocks.GetJWTTestKeys().BearerToken(userID))
+}
+
+// createTestUsers マイクロポストを投稿するユーザーをn人作成する。IDは1から連番になる
+func createTestUsers(t *testing.T, repos domain.UserRepository, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		_, err := repos.CreateUser(domain.NewUserModel(fmt.Sprintf("Name_%d", i), fmt.Sprintf("test%d@example.com", i)))
+		assert.NoError(t, err)
+	}
 }
 
 // TestPostMicroposts_201 新規作成処理 正常時
@@ -29,6 +73,7 @@ func TestPostMicroposts_201(t *testing.T) {
 	defer tables.Cleanup()
 
 	router := setupRouter()
+	createTestUsers(t, tables.UserOperator, 1)
 
 	// リクエスト用パラメータ
 	body := map[string]interface{}{
@@ -41,6 +86,7 @@ func TestPostMicroposts_201(t *testing.T) {
 	req, _ := http.NewRequest("POST", fmt.Sprintf("/v1/users/%d/microposts", userID), bytes.NewBuffer(bodyBytes))
 	req.Header.Set("Content-Type", "application/json")
 
+	setAuth(req, userID)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -62,6 +108,29 @@ func TestPostMicroposts_201(t *testing.T) {
 	assert.Equal(t, userID, micropost.UserID)
 }
 
+// TestPostMicroposts_404 存在しないユーザーのマイクロポストは作成できない
+func TestPostMicroposts_404(t *testing.T) {
+	router, f := setupMemoryRouter()
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{"content": "Content_1"})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/v1/users/999/microposts", bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	setAuth(req, 999)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスコードチェック
+	assert.Equal(t, 404, w.Code)
+
+	// 保存されていないことをチェック
+	microposts, _, err := f.MicropostRepository.GetMicropostsByUserID(999, nil)
+	assert.NoError(t, err)
+	assert.Len(t, microposts, 0)
+}
+
 // TestPostMicroposts_400 新規作成処理 バリデーションエラー時
 func TestPostMicroposts_400(t *testing.T) {
 	// テスト用DynamoDB設定
@@ -103,6 +172,7 @@ func TestPostMicroposts_400(t *testing.T) {
 		req, _ := http.NewRequest("POST", "/v1/users/1/microposts", bytes.NewBuffer(bodyBytes))
 		req.Header.Set("Content-Type", "application/json")
 
+		setAuth(req, 1)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 
@@ -125,6 +195,7 @@ func TestPutMicropost_200(t *testing.T) {
 	defer tables.Cleanup()
 
 	router := setupRouter()
+	createTestUsers(t, tables.UserOperator, 1)
 
 	// 更新用モックデータを作成
 	micropostMock, err := tables.MicropostOperator.CreateMicropost(&domain.MicropostModel{
@@ -145,6 +216,7 @@ func TestPutMicropost_200(t *testing.T) {
 		bytes.NewBuffer(bodyBytes))
 	req.Header.Set("Content-Type", "application/json")
 
+	setAuth(req, micropostMock.UserID)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -164,6 +236,7 @@ func TestGetMicropost(t *testing.T) {
 	defer tables.Cleanup()
 
 	router := setupRouter()
+	createTestUsers(t, tables.UserOperator, 1)
 
 	// 取得用のモックデータを作成
 	micropostMock, err := tables.MicropostOperator.CreateMicropost(&domain.MicropostModel{
@@ -175,6 +248,7 @@ func TestGetMicropost(t *testing.T) {
 	req, _ := http.NewRequest("GET",
 		fmt.Sprintf("/v1/users/%d/microposts/%d", micropostMock.UserID, micropostMock.ID),
 		nil)
+	setAuth(req, micropostMock.UserID)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -197,6 +271,7 @@ func TestGetMicroposts(t *testing.T) {
 	defer tables.Cleanup()
 
 	router := setupRouter()
+	createTestUsers(t, tables.UserOperator, 2)
 
 	// 取得用のモックデータを作成
 	micropostMock1, err := tables.MicropostOperator.CreateMicropost(&domain.MicropostModel{
@@ -219,6 +294,7 @@ func TestGetMicroposts(t *testing.T) {
 	assert.NoError(t, err)
 
 	req, _ := http.NewRequest("GET", "/v1/users/1/microposts", nil)
+	setAuth(req, 1)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -231,16 +307,17 @@ func TestGetMicroposts(t *testing.T) {
 	err = json.Unmarshal(w.Body.Bytes(), &body)
 	assert.NoError(t, err)
 
-	// 取得したデータをチェック
+	// 取得したデータをチェック(新しい順に並んでいること)
 	actualMicroposts := body["microposts"].([]interface{})
+	assert.Len(t, actualMicroposts, 2)
 
-	expected1 := micropostMock1
+	expected1 := micropostMock2
 	actual1 := actualMicroposts[0].(map[string]interface{})
 	assert.Equal(t, float64(expected1.ID), actual1["id"])
 	assert.Equal(t, expected1.Content, actual1["content"])
 	assert.Equal(t, float64(expected1.UserID), actual1["user_id"])
 
-	expected2 := micropostMock2
+	expected2 := micropostMock1
 	actual2 := actualMicroposts[1].(map[string]interface{})
 	assert.Equal(t, float64(expected2.ID), actual2["id"])
 	assert.Equal(t, expected2.Content, actual2["content"])
@@ -254,6 +331,7 @@ func TestDeleteMicropost(t *testing.T) {
 	defer tables.Cleanup()
 
 	router := setupRouter()
+	createTestUsers(t, tables.UserOperator, 1)
 
 	// 削除用モックデータを作成
 	micropostMock, err := tables.MicropostOperator.CreateMicropost(&domain.MicropostModel{
@@ -266,6 +344,7 @@ func TestDeleteMicropost(t *testing.T) {
 		fmt.Sprintf("/v1/users/%d/microposts/%d", micropostMock.UserID, micropostMock.ID),
 		nil)
 
+	setAuth(req, micropostMock.UserID)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -273,7 +352,147 @@ func TestDeleteMicropost(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 
 	// DynamoDBからデータが削除されているかチェック
-	microposts, err := tables.MicropostOperator.GetMicropostsByUserID(micropostMock.UserID)
+	microposts, _, err := tables.MicropostOperator.GetMicropostsByUserID(micropostMock.UserID, nil)
 	assert.NoError(t, err)
 	assert.Len(t, microposts, 0)
 }
+
+// TestGetFeed フォローしているユーザーのマイクロポストを新しい順に取得する
+func TestGetFeed(t *testing.T) {
+	router, f := setupMemoryRouter()
+	createTestUsers(t, f.UserRepository, 3)
+	require.NoError(t, f.FollowRepository.Follow(1, 2))
+	require.NoError(t, f.FollowRepository.Follow(1, 3))
+
+	var created []*domain.MicropostModel
+	for _, userID := range []uint64{2, 3, 2} {
+		m, err := f.MicropostRepository.CreateMicropost(domain.NewMicropostModel("Content", userID))
+		require.NoError(t, err)
+		created = append(created, m)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		setAuth(req, 1)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/v1/users/1/feed?limit=2")
+	require.Equal(t, 200, w.Code)
+	var page1 ResponseMicroposts
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page1))
+	require.Len(t, page1.Microposts, 2)
+	assert.Equal(t, created[2].ID, page1.Microposts[0].ID)
+	assert.Equal(t, created[1].ID, page1.Microposts[1].ID)
+	assert.NotEmpty(t, page1.Microposts[0].CreatedAt)
+	require.NotEmpty(t, page1.NextToken)
+
+	w = get("/v1/users/1/feed?limit=2&next_token=" + page1.NextToken)
+	require.Equal(t, 200, w.Code)
+	var page2 ResponseMicroposts
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page2))
+	require.Len(t, page2.Microposts, 1)
+	assert.Equal(t, created[0].ID, page2.Microposts[0].ID)
+	assert.Empty(t, page2.NextToken)
+
+	assert.Equal(t, 400, get("/v1/users/1/feed?next_token=invalid").Code)
+	assert.Equal(t, 404, get("/v1/users/999/feed").Code)
+}
+
+// TestGetReplies 返信を投稿し、返信先ごとに古い順に取得する
+func TestGetReplies(t *testing.T) {
+	router, f := setupMemoryRouter()
+	createTestUsers(t, f.UserRepository, 2)
+
+	parent, err := f.MicropostRepository.CreateMicropost(domain.NewMicropostModel("Parent", 1))
+	require.NoError(t, err)
+
+	serve := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		bodyBytes, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		setAuth(req, 2)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, content := range []string{"Reply_1", "Reply_2", "Reply_3"} {
+		w := serve("POST", "/v1/users/2/microposts", map[string]interface{}{"content": content, "in_reply_to": parent.ID})
+		require.Equal(t, 201, w.Code)
+	}
+
+	// 返信先が存在しない場合と、数値でない場合
+	w := serve("POST", "/v1/users/2/microposts", map[string]interface{}{"content": "Reply", "in_reply_to": 999})
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "返信先のマイクロポストIDが存在しません。")
+	assert.Equal(t, 400, serve("POST", "/v1/users/2/microposts", map[string]interface{}{"content": "Reply", "in_reply_to": "1"}).Code)
+
+	repliesPath := fmt.Sprintf("/v1/users/1/microposts/%d/replies", parent.ID)
+	w = serve("GET", repliesPath+"?limit=2", nil)
+	require.Equal(t, 200, w.Code)
+	var page1 ResponseMicroposts
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page1))
+	require.Len(t, page1.Microposts, 2)
+	assert.Equal(t, "Reply_1", page1.Microposts[0].Content)
+	assert.Equal(t, "Reply_2", page1.Microposts[1].Content)
+	assert.Equal(t, parent.ID, page1.Microposts[0].InReplyTo)
+	require.NotEmpty(t, page1.NextToken)
+
+	w = serve("GET", repliesPath+"?limit=2&next_token="+page1.NextToken, nil)
+	require.Equal(t, 200, w.Code)
+	var page2 ResponseMicroposts
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page2))
+	require.Len(t, page2.Microposts, 1)
+	assert.Equal(t, "Reply_3", page2.Microposts[0].Content)
+
+	// 返信先には返信数が付く
+	w = serve("GET", fmt.Sprintf("/v1/users/1/microposts/%d", parent.ID), nil)
+	require.Equal(t, 200, w.Code)
+	var res ResponseMicropost
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, 3, res.ReplyCount)
+
+	// マイクロポストのユーザーが異なる場合
+	assert.Equal(t, 404, serve("GET", fmt.Sprintf("/v1/users/2/microposts/%d/replies", parent.ID), nil).Code)
+}
+
+// TestGetHashtagMicropostsAndMentions ハッシュタグとメンションからマイクロポストを取得する
+func TestGetHashtagMicropostsAndMentions(t *testing.T) {
+	router, f := setupMemoryRouter()
+	createTestUsers(t, f.UserRepository, 2)
+
+	m, err := f.MicropostRepository.CreateMicropost(domain.NewMicropostModel("＃東京 で #Go の勉強会 @2", 1))
+	require.NoError(t, err)
+
+	get := func(path string, authUserID uint64) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		setAuth(req, authUserID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// #の有無や全角・大文字を問わずに探せる
+	for _, tag := range []string{"go", "GO", "%23go", "%E6%9D%B1%E4%BA%AC"} {
+		w := get("/v1/hashtags/"+tag+"/microposts", 1)
+		require.Equal(t, 200, w.Code, tag)
+		var res ResponseMicroposts
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		require.Len(t, res.Microposts, 1, tag)
+		assert.Equal(t, m.ID, res.Microposts[0].ID)
+	}
+
+	w := get("/v1/users/2/mentions", 2)
+	require.Equal(t, 200, w.Code)
+	var res ResponseMicroposts
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Microposts, 1)
+	assert.Equal(t, m.ID, res.Microposts[0].ID)
+
+	// 他のユーザーへのメンションは見られない
+	assert.Equal(t, 403, get("/v1/users/2/mentions", 1).Code)
+}
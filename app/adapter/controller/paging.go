@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/adapter/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/validator.v2"
+)
+
+// PageQueryValidator ページング用クエリパラメータのバリデーション設定
+func PageQueryValidator() *validation.Validator {
+	return &validation.Validator{
+		Settings: []*validation.ValidatorSetting{
+			{ArgName: "limit", ValidateTags: "uint"},
+		},
+	}
+}
+
+// PageQuery クエリパラメータで指定されたページング条件
+type PageQuery struct {
+	Limit     int
+	NextToken string
+}
+
+// parsePageQuery クエリパラメータ(limit, next_token)からページング条件を取得する
+func parsePageQuery(ctx *gin.Context) (*PageQuery, map[string]error) {
+	limitStr := ctx.Query("limit")
+
+	validErr := PageQueryValidator().Validate(map[string]interface{}{
+		"limit": limitStr,
+	})
+	if validErr != nil {
+		return nil, validErr
+	}
+
+	query := &PageQuery{NextToken: ctx.Query("next_token")}
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, map[string]error{"limit": validator.ErrUnsupported}
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+// invalidPageTokenErrors ページトークンが不正な場合のエラー
+func invalidPageTokenErrors() map[string]error {
+	return map[string]error{"next_token": validator.ErrUnsupported}
+}
@@ -0,0 +1,335 @@
+package controller
+
+import (
+	"clean-serverless-book-sample/adapter/validation"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"clean-serverless-book-sample/utils"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductController struct {
+	log            *slog.Logger
+	createProduct  usecase.ICreateProduct
+	updateProduct  usecase.IUpdateProduct
+	getProductList usecase.IGetProductList
+	getProductByID usecase.IGetProductByID
+	deleteProduct  usecase.IDeleteProduct
+}
+
+// NewProductController ProductControllerのインスタンスを生成
+func NewProductController(f *registry.Factory, log *slog.Logger) *ProductController {
+	return &ProductController{
+		log:            log,
+		createProduct:  f.BuildCreateProduct(),
+		updateProduct:  f.BuildUpdateProduct(),
+		getProductList: f.BuildGetProductList(),
+		getProductByID: f.BuildGetProductByID(),
+		deleteProduct:  f.BuildDeleteProduct(),
+	}
+}
+
+// RequestProduct HTTPリクエストで送られてくるJSON形式を表した構造体
+type RequestProduct struct {
+	Name        string `json:"name"`
+	Price       int    `json:"price"`
+	ReleaseDate string `json:"release_date"`
+}
+
+// RequestPostProduct PostProductsのリクエスト
+type RequestPostProduct struct {
+	RequestProduct
+}
+
+// RequestPutProduct PutProductのリクエスト
+type RequestPutProduct struct {
+	RequestProduct
+}
+
+// ProductResponse レスポンス用のJSON形式を表した構造体
+type ProductResponse struct {
+	ID          uint64 `json:"id"`
+	Name        string `json:"name"`
+	Price       int    `json:"price"`
+	ReleaseDate string `json:"release_date"`
+}
+
+// ProductsResponse Productリストレスポンス用のJSON形式を表した構造体
+type ProductsResponse struct {
+	Products  []*ProductResponse `json:"products"`
+	NextToken string             `json:"next_token"`
+}
+
+// NewProductResponse ドメインモデルからレスポンス用の構造体に詰め替える
+func NewProductResponse(p *domain.ProductModel) *ProductResponse {
+	return &ProductResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		Price:       p.Price,
+		ReleaseDate: p.ReleaseDate.Format(time.RFC3339),
+	}
+}
+
+// PostProducts 新規作成
+func (ctrl *ProductController) PostProducts(ctx *gin.Context) {
+	ctrl.log.Info("Starting PostProducts handler")
+
+	// リクエストボディを取得
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctrl.log.Error("Failed to get request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// バリデーション処理
+	validator := validation.ProductSettingValidator()
+	validErr := validator.ValidateBody(string(body))
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// JSON形式から構造体に変換
+	var req RequestPostProduct
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		ctrl.log.Error("Failed to unmarshal request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	releaseDate, err := validation.ParseDate(req.ReleaseDate)
+	if err != nil {
+		ctrl.log.Error("Failed to parse release_date", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// 新規作成処理
+	ctrl.log.Info("Creating new product", "name", req.Name, "price", req.Price)
+	res, err := ctrl.createProduct.Execute(&usecase.CreateProductRequest{
+		Name:        req.Name,
+		Price:       req.Price,
+		ReleaseDate: releaseDate,
+	})
+	if err != nil {
+		ctrl.log.Error("Failed to create product", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Product created successfully", "productID", res.GetProductID())
+	// 201レスポンス
+	Response201(ctx, res.GetProductID())
+}
+
+// PutProduct 更新
+func (ctrl *ProductController) PutProduct(ctx *gin.Context) {
+	ctrl.log.Info("Starting PutProduct handler")
+
+	// リクエストボディを取得
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctrl.log.Error("Failed to get request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// バリデーション処理
+	validator := validation.ProductSettingValidator()
+	validErr := validator.ValidateBody(string(body))
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// JSON形式から構造体に変換
+	var req RequestPutProduct
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		ctrl.log.Error("Failed to unmarshal request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	releaseDate, err := validation.ParseDate(req.ReleaseDate)
+	if err != nil {
+		ctrl.log.Error("Failed to parse release_date", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// パスパラメータから製品IDを取得する
+	productID, err := utils.ParseUint(ctx.Param("product_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse product_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// If-Matchヘッダーから楽観的ロック用のバージョンを取得する
+	version, ok := parseIfMatch(ctx)
+	if !ok {
+		ctrl.log.Warn("Invalid If-Match header", "ifMatch", ctx.GetHeader("If-Match"))
+		Response412(ctx)
+		return
+	}
+
+	// 更新処理
+	ctrl.log.Info("Updating product", "productID", productID, "name", req.Name, "price", req.Price)
+	_, err = ctrl.updateProduct.Execute(&usecase.UpdateProductRequest{
+		ID:          productID,
+		Name:        req.Name,
+		Price:       req.Price,
+		ReleaseDate: releaseDate,
+		Version:     version,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Product not found", "productID", productID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == domain.ErrConflict.Error() {
+			ctrl.log.Warn("Product version conflict", "productID", productID, "version", version)
+			Response412(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to update product", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Product updated successfully", "productID", productID)
+	// 200レスポンス
+	Response200OK(ctx)
+}
+
+// GetProducts 一覧取得処理
+func (ctrl *ProductController) GetProducts(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetProducts handler")
+
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// 一覧取得処理
+	res, err := ctrl.getProductList.Execute(&usecase.GetProductListRequest{
+		Limit:     page.Limit,
+		NextToken: page.NextToken,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
+		ctrl.log.Error("Failed to get product list", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// ドメインモデルからレスポンス用の構造体に詰め替える
+	var resProducts = make([]*ProductResponse, len(res.Products))
+	for i, p := range res.Products {
+		resProducts[i] = NewProductResponse(p)
+	}
+
+	ctrl.log.Info("Product list retrieved successfully", "count", len(resProducts))
+	// レスポンス処理
+	Response200(ctx, &ProductsResponse{
+		Products:  resProducts,
+		NextToken: res.NextToken,
+	})
+}
+
+// GetProduct IDから取得
+func (ctrl *ProductController) GetProduct(ctx *gin.Context) {
+	ctrl.log.Info("Starting GetProduct handler")
+
+	// パスパラメータから製品IDを取得する
+	productID, err := utils.ParseUint(ctx.Param("product_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse product_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// 製品取得処理
+	ctrl.log.Info("Getting product by ID", "productID", productID)
+	res, err := ctrl.getProductByID.Execute(&usecase.GetProductByIDRequest{ProductID: productID})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Product not found", "productID", productID)
+			Response404(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to get product", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Product retrieved successfully", "productID", res.Product.ID)
+	setETag(ctx, res.Product.Version)
+	// ドメインモデルからレスポンス用構造体に詰め替えて、レスポンス
+	Response200(ctx, NewProductResponse(res.Product))
+}
+
+// DeleteProduct 削除処理
+func (ctrl *ProductController) DeleteProduct(ctx *gin.Context) {
+	ctrl.log.Info("Starting DeleteProduct handler")
+
+	// パスパラメータから製品IDを取得する
+	productID, err := utils.ParseUint(ctx.Param("product_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse product_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// If-Matchヘッダーから楽観的ロック用のバージョンを取得する
+	version, ok := parseIfMatch(ctx)
+	if !ok {
+		ctrl.log.Warn("Invalid If-Match header", "ifMatch", ctx.GetHeader("If-Match"))
+		Response412(ctx)
+		return
+	}
+
+	// 削除処理
+	ctrl.log.Info("Deleting product", "productID", productID)
+	_, err = ctrl.deleteProduct.Execute(&usecase.DeleteProductRequest{
+		ProductID: productID,
+		Version:   version,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("Product not found", "productID", productID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == domain.ErrConflict.Error() {
+			ctrl.log.Warn("Product version conflict", "productID", productID, "version", version)
+			Response412(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to delete product", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Product deleted successfully", "productID", productID)
+	// レスポンス
+	Response200OK(ctx)
+}
@@ -0,0 +1,434 @@
+package controller
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/registry"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setProductsAPIKey 商品の参照と変更ができるAPIキーを発行してX-API-Keyヘッダーに設定する
+func setProductsAPIKey(t *testing.T, f *registry.Factory, req *http.Request) {
+	t.Helper()
+	setTestAPIKey(t, f, req, domain.ScopeProductsRead, domain.ScopeProductsWrite)
+}
+
+// TestPostProducts_201 新規作成 成功時
+func TestPostProducts_201(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	// リクエストパラメータ設定
+	body := map[string]interface{}{
+		"name":         "テスト製品",
+		"price":        1000,
+		"release_date": "2024-04-01",
+	}
+	bodyStr, err := json.Marshal(body)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/v1/products", bytes.NewBuffer(bodyStr))
+	setProductsAPIKey(t, registry.GetFactory(), req)
+	req.Header.Set("Content-Type", "application/json")
+
+	// 新規作成処理
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスコードをチェック
+	assert.Equal(t, 201, w.Code)
+
+	// JSONからmap型に変換
+	var resBody map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &resBody)
+	assert.NoError(t, err)
+
+	// IDをチェック
+	assert.Equal(t, "1", resBody["id"])
+
+	// DynamoDBに保存されたデータをチェック
+	product, err := tables.ProductOperator.GetProductByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, body["name"].(string), product.Name)
+	assert.Equal(t, 1000, product.Price)
+	assert.Equal(t, "2024-04-01", product.ReleaseDate.Format("2006-01-02"))
+}
+
+// TestPostProducts_400 新規作成 バリデーションエラー時
+func TestPostProducts_400(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	cases := []struct {
+		Request  map[string]interface{}
+		Expected map[string]interface{}
+	}{
+		// 未入力の場合
+		{
+			Request: map[string]interface{}{},
+			Expected: map[string]interface{}{
+				"name":         "名前を入力してください。",
+				"price":        "価格を入力してください。",
+				"release_date": "発売日を入力してください。",
+			},
+		},
+		// 価格が負の数、発売日の形式が不正な場合
+		{
+			Request: map[string]interface{}{
+				"name":         "テスト製品",
+				"price":        -1,
+				"release_date": "2024/04/01",
+			},
+			Expected: map[string]interface{}{
+				"price":        "価格は0以上の数値を入力してください。",
+				"release_date": "発売日の形式が不正です。",
+			},
+		},
+		// 価格が整数でない場合
+		{
+			Request: map[string]interface{}{
+				"name":         "テスト製品",
+				"price":        "100",
+				"release_date": "2024-04-01T10:00:00+09:00",
+			},
+			Expected: map[string]interface{}{
+				"price": "価格は不正な値です。",
+			},
+		},
+	}
+
+	for i, c := range cases {
+		msg := fmt.Sprintf("Case:%d", i+1)
+
+		bodyStr, err := json.Marshal(c.Request)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("POST", "/v1/products", bytes.NewBuffer(bodyStr))
+		setProductsAPIKey(t, registry.GetFactory(), req)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 400, w.Code, msg)
+
+		var resBody map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &resBody)
+		assert.NoError(t, err)
+
+		errors := resBody["errors"].(map[string]interface{})
+		assert.Equal(t, c.Expected, errors, msg)
+	}
+}
+
+// TestPutProduct_200 更新 正常時
+func TestPutProduct_200(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	// 更新用モックデータを作成
+	productMock, err := tables.ProductOperator.CreateProduct(
+		domain.NewProductModel("テスト製品", 100, time.Now()))
+	assert.NoError(t, err)
+
+	// 更新リクエストパラメータ
+	body := map[string]interface{}{
+		"name":         "テスト製品(更新)",
+		"price":        200,
+		"release_date": "2025-01-01T00:00:00Z",
+	}
+	bodyStr, err := json.Marshal(body)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/products/%d", productMock.ID), bytes.NewBuffer(bodyStr))
+	setProductsAPIKey(t, registry.GetFactory(), req)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスコードをチェック
+	assert.Equal(t, 200, w.Code)
+
+	// DynamoDBのデータが更新されているかをチェック
+	product, err := tables.ProductOperator.GetProductByID(productMock.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, body["name"].(string), product.Name)
+	assert.Equal(t, 200, product.Price)
+	assert.Equal(t, "2025-01-01", product.ReleaseDate.Format("2006-01-02"))
+}
+
+// TestPutProduct_404 更新 存在しない場合
+func TestPutProduct_404(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	body := map[string]interface{}{
+		"name":         "テスト製品",
+		"price":        200,
+		"release_date": "2025-01-01",
+	}
+	bodyStr, err := json.Marshal(body)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("PUT", "/v1/products/999", bytes.NewBuffer(bodyStr))
+	setProductsAPIKey(t, registry.GetFactory(), req)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// レスポンスコードをチェック
+	assert.Equal(t, 404, w.Code)
+}
+
+// TestGetProduct 取得 正常時
+func TestGetProduct(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	// 取得用モックデータを作成
+	releaseDate := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	productMock, err := tables.ProductOperator.CreateProduct(
+		domain.NewProductModel("テスト製品", 100, releaseDate))
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/products/%d", productMock.ID), nil)
+	setProductsAPIKey(t, registry.GetFactory(), req)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// ステータスコードをチェック
+	assert.Equal(t, 200, w.Code)
+
+	// 取得したデータをチェック
+	var body map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(productMock.ID), body["id"])
+	assert.Equal(t, productMock.Name, body["name"])
+	assert.Equal(t, float64(productMock.Price), body["price"])
+	assert.Equal(t, "2024-04-01T00:00:00Z", body["release_date"])
+}
+
+// TestGetProduct_404 取得 存在しない場合
+func TestGetProduct_404(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	req, _ := http.NewRequest("GET", "/v1/products/999", nil)
+	setProductsAPIKey(t, registry.GetFactory(), req)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// ステータスコードをチェック
+	assert.Equal(t, 404, w.Code)
+}
+
+// TestGetProducts 一覧取得
+func TestGetProducts(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	// モックデータを作成
+	_, err := tables.ProductOperator.CreateProduct(
+		domain.NewProductModel("テスト製品1", 100, time.Now()))
+	assert.NoError(t, err)
+
+	_, err = tables.ProductOperator.CreateProduct(
+		domain.NewProductModel("テスト製品2", 200, time.Now()))
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/v1/products", nil)
+	setProductsAPIKey(t, registry.GetFactory(), req)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// ステータスコードをチェック
+	assert.Equal(t, 200, w.Code)
+
+	// 取得したデータをチェック
+	var body map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+
+	products := body["products"].([]interface{})
+	assert.Len(t, products, 2)
+	assert.Equal(t, "", body["next_token"])
+}
+
+// TestDeleteProduct 削除
+func TestDeleteProduct(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	// 削除用モックデータを作成
+	productMock, err := tables.ProductOperator.CreateProduct(
+		domain.NewProductModel("テスト製品", 100, time.Now()))
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/v1/products/%d", productMock.ID), nil)
+	setProductsAPIKey(t, registry.GetFactory(), req)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// ステータスコードをチェック
+	assert.Equal(t, 200, w.Code)
+
+	// DynamoDBからデータが削除されているかをチェック
+	_, err = tables.ProductOperator.GetProductByID(productMock.ID)
+	assert.Equal(t, domain.ErrNotFound.Error(), err.Error())
+}
+
+// TestPutProduct_412 If-Matchのバージョンが古い場合は更新・削除できない
+func TestPutProduct_412(t *testing.T) {
+	router, f := setupMemoryRouter()
+
+	// 更新用モックデータを作成
+	productMock, err := f.ProductRepository.CreateProduct(
+		domain.NewProductModel("製品1", 100, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)))
+	assert.NoError(t, err)
+
+	// 取得時のETagを確認
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/products/%d", productMock.ID), nil)
+	setProductsAPIKey(t, f, req)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	put := func(ifMatch string) int {
+		bodyBytes, err := json.Marshal(map[string]interface{}{
+			"name":         "製品1(更新)",
+			"price":        150,
+			"release_date": "2024-04-01",
+		})
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/products/%d", productMock.ID), bytes.NewBuffer(bodyBytes))
+		setProductsAPIKey(t, f, req)
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 取得時のETagで更新できる
+	assert.Equal(t, 200, put(etag))
+
+	// 同じETagでもう一度更新すると競合する
+	assert.Equal(t, 412, put(etag))
+
+	// ETagとして解釈できない値も競合として扱う
+	assert.Equal(t, 412, put(`W/"2"`))
+	assert.Equal(t, 412, put("2"))
+
+	// 古いETagでは削除できない
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/v1/products/%d", productMock.ID), nil)
+	setProductsAPIKey(t, f, req)
+	req.Header.Set("If-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 412, w.Code)
+
+	// 最新のETagで削除できる
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/v1/products/%d", productMock.ID), nil)
+	setProductsAPIKey(t, f, req)
+	req.Header.Set("If-Match", `"2"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+}
+
+// TestProducts_withoutAPIKey 製品の参照は認証情報なしでも行えるが、変更にはproducts:writeのAPIキーが必要
+func TestProducts_withoutAPIKey(t *testing.T) {
+	router, f := setupMemoryRouter()
+
+	productMock, err := f.ProductRepository.CreateProduct(
+		domain.NewProductModel("製品1", 100, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)))
+	assert.NoError(t, err)
+
+	serve := func(method, path, key string, body interface{}) int {
+		bodyBytes, err := json.Marshal(body)
+		assert.NoError(t, err)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(bodyBytes))
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	body := map[string]interface{}{
+		"name":         "テスト製品",
+		"price":        1000,
+		"release_date": "2024-04-01",
+	}
+	productPath := fmt.Sprintf("/v1/products/%d", productMock.ID)
+
+	assert.Equal(t, 401, serve("POST", "/v1/products", "", body))
+	assert.Equal(t, 401, serve("PUT", productPath, "", body))
+	assert.Equal(t, 401, serve("DELETE", productPath, "", nil))
+	assert.Equal(t, 200, serve("GET", "/v1/products", "", nil))
+	assert.Equal(t, 200, serve("GET", productPath, "", nil))
+
+	// 製品の変更はユーザーのトークンではできない
+	createTestUsers(t, f.UserRepository, 1)
+	bodyBytes, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req, _ := http.NewRequest("POST", "/v1/products", bytes.NewBuffer(bodyBytes))
+	setAuth(req, 1)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 401, w.Code)
+
+	// 読み取りのスコープのAPIキーでは変更できない
+	reader := issueTestAPIKey(t, f, time.Now().Add(time.Hour), domain.ScopeProductsRead)
+	assert.Equal(t, 200, serve("GET", productPath, reader.Key, nil))
+	assert.Equal(t, 403, serve("POST", "/v1/products", reader.Key, body))
+	assert.Equal(t, 403, serve("DELETE", productPath, reader.Key, nil))
+
+	writer := issueTestAPIKey(t, f, time.Now().Add(time.Hour), domain.ScopeProductsWrite)
+	assert.Equal(t, 201, serve("POST", "/v1/products", writer.Key, body))
+
+	products, _, err := f.ProductRepository.GetProducts(nil)
+	assert.NoError(t, err)
+	assert.Len(t, products, 2)
+}
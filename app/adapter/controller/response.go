@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"clean-serverless-book-sample/adapter/validation"
 	"clean-serverless-book-sample/logger"
 	"fmt"
 	"net/http"
@@ -37,6 +38,12 @@ func Response201(ctx *gin.Context, id uint64) {
 	})
 }
 
+// Response202 受け付けた処理の状態を含めた202レスポンス
+func Response202(ctx *gin.Context, body interface{}) {
+	commonHeaders(ctx)
+	ctx.JSON(http.StatusAccepted, body)
+}
+
 // Response400 エラーメッセージを含めた400レスポンス
 func Response400(ctx *gin.Context, errs map[string]error) {
 	log := logger.GetLogger()
@@ -44,7 +51,24 @@ func Response400(ctx *gin.Context, errs map[string]error) {
 	commonHeaders(ctx)
 	ctx.JSON(http.StatusBadRequest, gin.H{
 		"message": "入力値を確認してください。",
-		"errors":  ConvertErrorsToMessage(errs),
+		"errors":  validation.ConvertErrorsToMessage(errs),
+	})
+}
+
+// Response401 認証が必要なことを表す401レスポンス
+func Response401(ctx *gin.Context) {
+	commonHeaders(ctx)
+	ctx.Header("WWW-Authenticate", "Bearer")
+	ctx.JSON(http.StatusUnauthorized, gin.H{
+		"message": "認証が必要です。",
+	})
+}
+
+// Response403 権限がないことを表す403レスポンス
+func Response403(ctx *gin.Context) {
+	commonHeaders(ctx)
+	ctx.JSON(http.StatusForbidden, gin.H{
+		"message": "この操作を行う権限がありません。",
 	})
 }
 
@@ -56,6 +80,30 @@ func Response404(ctx *gin.Context) {
 	})
 }
 
+// Response409 同じ冪等キーのリクエストが処理中の場合の409レスポンス
+func Response409(ctx *gin.Context) {
+	commonHeaders(ctx)
+	ctx.JSON(http.StatusConflict, gin.H{
+		"message": "同じリクエストを処理中です。しばらくしてから再度実行してください。",
+	})
+}
+
+// Response412 更新の競合を表す412レスポンス
+func Response412(ctx *gin.Context) {
+	commonHeaders(ctx)
+	ctx.JSON(http.StatusPreconditionFailed, gin.H{
+		"message": "他の更新と競合しました。最新の内容を取得してから再度実行してください。",
+	})
+}
+
+// Response422 同じ冪等キーで異なるリクエストが送られた場合の422レスポンス
+func Response422(ctx *gin.Context) {
+	commonHeaders(ctx)
+	ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+		"message": "同じIdempotency-Keyで異なるリクエストが送信されました。",
+	})
+}
+
 // Response500 500レスポンス
 func Response500(ctx *gin.Context, err error) {
 	log := logger.GetLogger()
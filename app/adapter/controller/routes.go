@@ -1,37 +1,97 @@
 package controller
 
 import (
+	"clean-serverless-book-sample/domain"
 	"clean-serverless-book-sample/logger"
+	"clean-serverless-book-sample/registry"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Routes() *gin.Engine {
+// Routes ルーティングを設定する。各ControllerのUseCaseは引数のFactoryから生成する
+func Routes(f *registry.Factory) *gin.Engine {
 	r := gin.Default()
 
 	log := logger.GetLogger()
 
-	userCtrl := &UserController{
-		log: log,
-	}
-	r.POST("/v1/users", userCtrl.PostUsers)
-	r.GET("/v1/users", userCtrl.GetUsers)
-	r.GET("/v1/users/:user_id", userCtrl.GetUser)
-	r.PUT("/v1/users/:user_id", userCtrl.PutUser)
-	r.DELETE("/v1/users/:user_id", userCtrl.DeleteUser)
-
-	micropostCtrl := &MicropostController{
-		log: log,
-	}
-	r.POST("/v1/users/:user_id/microposts", micropostCtrl.PostMicroposts)
-	r.GET("/v1/users/:user_id/microposts", micropostCtrl.GetMicroposts)
-	r.GET("/v1/users/:user_id/microposts/:micropost_id", micropostCtrl.GetMicropost)
-	r.PUT("/v1/users/:user_id/microposts/:micropost_id", micropostCtrl.PutMicropost)
-	r.DELETE("/v1/users/:user_id/microposts/:micropost_id", micropostCtrl.DeleteMicropost)
-
-	helloCtrl := &HelloController{
-		log: log,
+	// 新規作成はIdempotency-Keyによるリトライでの重複作成を防ぐ
+	idempotency := IdempotencyKeyMiddleware(f.BuildIdempotencyKeyRepository(), log)
+	// ユーザー本人のリソースを変更する操作は、トークンのユーザーとパスのuser_idが一致する場合だけ許可する
+	auth := AuthMiddleware(f.BuildTokenVerifier(), log)
+	self := RequireSameUser(log)
+	// サービス間の呼び出しはX-API-KeyヘッダーのAPIキーで認証し、ルートごとに必要なスコープを確認する。
+	// APIキーはユーザーのトークンの代わりに使える認証情報で、APIキーが無い場合は次に進む。
+	// 認証が必要なルートはauthかrequireKeyを続けて、認証情報の無い呼び出しを401にする
+	authenticator := f.BuildAuthenticateAPIKey()
+	apiKey := func(scope string) gin.HandlerFunc {
+		return APIKeyMiddleware(authenticator, scope, log)
 	}
+	requireKey := RequireAPIKey(log)
+
+	// ユーザー登録と参照は認証情報なしで行える
+	userCtrl := NewUserController(f, log)
+	r.POST("/v1/users", apiKey(domain.ScopeUsersWrite), idempotency, userCtrl.PostUsers)
+	r.GET("/v1/users", apiKey(domain.ScopeUsersRead), userCtrl.GetUsers)
+	r.GET("/v1/users/:user_id", apiKey(domain.ScopeUsersRead), userCtrl.GetUser)
+	r.PUT("/v1/users/:user_id", apiKey(domain.ScopeUsersWrite), auth, self, userCtrl.PutUser)
+	r.DELETE("/v1/users/:user_id", apiKey(domain.ScopeUsersWrite), auth, self, userCtrl.DeleteUser)
+	r.POST("/v1/users/:user_id/restore", apiKey(domain.ScopeUsersWrite), auth, self, userCtrl.RestoreUser)
+	// パスワードの変更はAPIキーでは行えず、本人のトークンが必要
+	r.PUT("/v1/users/:user_id/password", auth, self, userCtrl.PutPassword)
+
+	// ユーザーの一括取り込みはS3にファイルを置いた場合と同じく非同期で行い、受け付けたジョブの進捗を参照する
+	importJobCtrl := NewImportJobController(f, log)
+	r.POST("/v1/users/batch_import", apiKey(domain.ScopeUsersWrite), requireKey, importJobCtrl.PostUserBatchImport)
+	// ジョブにはユーザーと製品の取り込みがあるため、取り込み先に書き込めるスコープをハンドラーで確認する
+	r.GET("/v1/import_jobs/:job_id", APIKeyResourceScopeMiddleware(authenticator, log), requireKey, importJobCtrl.GetImportJob)
+
+	followCtrl := NewFollowController(f, log)
+	r.POST("/v1/users/:user_id/following/:target_id", apiKey(domain.ScopeUsersWrite), auth, self, followCtrl.PostFollowing)
+	r.DELETE("/v1/users/:user_id/following/:target_id", apiKey(domain.ScopeUsersWrite), auth, self, followCtrl.DeleteFollowing)
+	r.GET("/v1/users/:user_id/following", apiKey(domain.ScopeUsersRead), followCtrl.GetFollowing)
+	r.GET("/v1/users/:user_id/followers", apiKey(domain.ScopeUsersRead), followCtrl.GetFollowers)
+
+	authCtrl := NewAuthController(f, log)
+	r.POST("/v1/auth/login", authCtrl.Login)
+
+	micropostCtrl := NewMicropostController(f, log)
+	r.POST("/v1/users/:user_id/microposts", apiKey(domain.ScopeMicropostsWrite), auth, self, idempotency, micropostCtrl.PostMicroposts)
+	r.GET("/v1/users/:user_id/microposts", apiKey(domain.ScopeMicropostsRead), micropostCtrl.GetMicroposts)
+	r.GET("/v1/users/:user_id/feed", apiKey(domain.ScopeMicropostsRead), auth, micropostCtrl.GetFeed)
+	// メンションの一覧は本人だけが見られる
+	r.GET("/v1/users/:user_id/mentions", apiKey(domain.ScopeMicropostsRead), auth, self, micropostCtrl.GetMentions)
+	r.GET("/v1/users/:user_id/microposts/:micropost_id", apiKey(domain.ScopeMicropostsRead), micropostCtrl.GetMicropost)
+	r.GET("/v1/users/:user_id/microposts/:micropost_id/replies", apiKey(domain.ScopeMicropostsRead), micropostCtrl.GetReplies)
+	r.PUT("/v1/users/:user_id/microposts/:micropost_id", apiKey(domain.ScopeMicropostsWrite), auth, self, micropostCtrl.PutMicropost)
+	r.DELETE("/v1/users/:user_id/microposts/:micropost_id", apiKey(domain.ScopeMicropostsWrite), auth, self, micropostCtrl.DeleteMicropost)
+
+	// 画像はAPIを経由せず、発行した署名付きURLへ直接アップロードする。アップロード完了後にS3のイベントで添付される
+	attachmentCtrl := NewAttachmentController(f, log)
+	r.POST("/v1/users/:user_id/microposts/:micropost_id/attachments", apiKey(domain.ScopeMicropostsWrite), auth, self, attachmentCtrl.PostAttachment)
+
+	r.GET("/v1/hashtags/:tag/microposts", apiKey(domain.ScopeMicropostsRead), micropostCtrl.GetHashtagMicroposts)
+
+	// いいねはトークンのユーザーとして行うため、APIキーでは行えない
+	likeCtrl := NewLikeController(f, log)
+	r.POST("/v1/users/:user_id/microposts/:micropost_id/likes", auth, likeCtrl.PostLike)
+	r.DELETE("/v1/users/:user_id/microposts/:micropost_id/likes", auth, likeCtrl.DeleteLike)
+
+	// 製品の変更はサービスからだけ行い、参照は認証情報なしで行える
+	productCtrl := NewProductController(f, log)
+	r.POST("/v1/products", apiKey(domain.ScopeProductsWrite), requireKey, productCtrl.PostProducts)
+	r.GET("/v1/products", apiKey(domain.ScopeProductsRead), productCtrl.GetProducts)
+	r.GET("/v1/products/:product_id", apiKey(domain.ScopeProductsRead), productCtrl.GetProduct)
+	r.PUT("/v1/products/:product_id", apiKey(domain.ScopeProductsWrite), requireKey, productCtrl.PutProduct)
+	r.DELETE("/v1/products/:product_id", apiKey(domain.ScopeProductsWrite), requireKey, productCtrl.DeleteProduct)
+
+	// APIキーの管理はapikeys:adminスコープを持つAPIキーでだけ行える
+	admin := apiKey(domain.ScopeAPIKeysAdmin)
+	apiKeyCtrl := NewAPIKeyController(f, log)
+	r.POST("/v1/admin/api_keys", admin, requireKey, apiKeyCtrl.PostAPIKeys)
+	r.GET("/v1/admin/api_keys", admin, requireKey, apiKeyCtrl.GetAPIKeys)
+	r.DELETE("/v1/admin/api_keys/:api_key_id", admin, requireKey, apiKeyCtrl.DeleteAPIKey)
+
+	helloCtrl := NewHelloController(f, log)
 	r.POST("/v1/hello", helloCtrl.PostHello)
 	return r
 }
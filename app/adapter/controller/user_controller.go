@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"clean-serverless-book-sample/adapter/validation"
 	"clean-serverless-book-sample/domain"
 	"clean-serverless-book-sample/interactor"
 	"clean-serverless-book-sample/registry"
@@ -14,23 +15,51 @@ import (
 )
 
 type UserController struct {
-	log *slog.Logger
+	log            *slog.Logger
+	createUser     usecase.ICreateUser
+	updateUser     usecase.IUpdateUser
+	getUserList    usecase.IGetUserList
+	getUserByID    usecase.IGetUserByID
+	deleteUser     usecase.IDeleteUser
+	restoreUser    usecase.IRestoreUser
+	changePassword usecase.IChangePassword
 }
 
-// PostSettingValidator バリデーション設定
-func PostSettingValidator() *Validator {
-	return &Validator{
-		Settings: []*ValidatorSetting{
-			{ArgName: "user_name", ValidateTags: "required"},
-			{ArgName: "email", ValidateTags: "required,email"},
+// NewUserController UserControllerのインスタンスを生成
+func NewUserController(f *registry.Factory, log *slog.Logger) *UserController {
+	return &UserController{
+		log:            log,
+		createUser:     f.BuildCreateUser(),
+		updateUser:     f.BuildUpdateUser(),
+		getUserList:    f.BuildGetUserList(),
+		getUserByID:    f.BuildGetUserByID(),
+		deleteUser:     f.BuildUserDeleter(),
+		restoreUser:    f.BuildRestoreUser(),
+		changePassword: f.BuildChangePassword(),
+	}
+}
+
+// PasswordSettingValidator パスワード変更時のバリデーション設定
+func PasswordSettingValidator() *validation.Validator {
+	return &validation.Validator{
+		Settings: []*validation.ValidatorSetting{
+			{ArgName: "current_password", ValidateTags: "required"},
+			{ArgName: "new_password", ValidateTags: "required,password"},
 		},
 	}
 }
 
 // RequestPostUser PostUserのリクエスト
 type RequestPostUser struct {
-	Name  string `json:"user_name"`
-	Email string `json:"email"`
+	Name     string `json:"user_name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// RequestPutPassword PutPasswordのリクエスト
+type RequestPutPassword struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
 }
 
 // RequestPutUser PutUserのリクエスト
@@ -41,14 +70,28 @@ type RequestPutUser struct {
 
 // UserResponse レスポンス用のJSON形式を表した構造体
 type UserResponse struct {
-	ID    uint64 `json:"id"`
-	Name  string `json:"user_name"`
-	Email string `json:"email"`
+	ID             uint64 `json:"id"`
+	Name           string `json:"user_name"`
+	Email          string `json:"email"`
+	FollowerCount  int    `json:"follower_count"`
+	FollowingCount int    `json:"following_count"`
+}
+
+// NewUserResponse ドメインモデルからレスポンス用の構造体に詰め替える
+func NewUserResponse(u *domain.UserModel) *UserResponse {
+	return &UserResponse{
+		ID:             u.ID,
+		Name:           u.Name,
+		Email:          u.Email,
+		FollowerCount:  u.FollowerCount,
+		FollowingCount: u.FollowingCount,
+	}
 }
 
 // UsersResponse Userリストレスポンス用のJSON形式を表した構造体
 type UsersResponse struct {
-	Users []*UserResponse `json:"users"`
+	Users     []*UserResponse `json:"users"`
+	NextToken string          `json:"next_token"`
 }
 
 // PostUsers 新規作成
@@ -64,7 +107,7 @@ func (ctrl *UserController) PostUsers(ctx *gin.Context) {
 	}
 
 	// バリデーション処理
-	validator := PostSettingValidator()
+	validator := validation.PostUserSettingValidator()
 	validErr := validator.ValidateBody(string(body))
 	if validErr != nil {
 		ctrl.log.Warn("Validation failed", "errors", validErr)
@@ -83,10 +126,10 @@ func (ctrl *UserController) PostUsers(ctx *gin.Context) {
 
 	// 新規作成処理
 	ctrl.log.Info("Creating new user", "user_name", req.Name, "email", req.Email)
-	creator := registry.GetFactory().BuildCreateUser()
-	res, err := creator.Execute(&usecase.CreateUserRequest{
-		Name:  req.Name,
-		Email: req.Email,
+	res, err := ctrl.createUser.Execute(&usecase.CreateUserRequest{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
 	})
 	if err != nil {
 		if err.Error() == interactor.ErrUniqEmail.Error() {
@@ -119,7 +162,7 @@ func (ctrl *UserController) PutUser(ctx *gin.Context) {
 	}
 
 	// バリデーション処理
-	validator := PostSettingValidator()
+	validator := validation.PostSettingValidator()
 	validErr := validator.ValidateBody(string(body))
 	if validErr != nil {
 		ctrl.log.Warn("Validation failed", "errors", validErr)
@@ -144,15 +187,28 @@ func (ctrl *UserController) PutUser(ctx *gin.Context) {
 		return
 	}
 
+	// If-Matchヘッダーから楽観的ロック用のバージョンを取得する
+	version, ok := parseIfMatch(ctx)
+	if !ok {
+		ctrl.log.Warn("Invalid If-Match header", "ifMatch", ctx.GetHeader("If-Match"))
+		Response412(ctx)
+		return
+	}
+
 	// 更新処理
 	ctrl.log.Info("Updating user", "userID", userID, "user_name", req.Name, "email", req.Email)
-	updater := registry.GetFactory().BuildUpdateUser()
-	_, err = updater.Execute(&usecase.UpdateUserRequest{
-		ID:    userID,
-		Name:  req.Name,
-		Email: req.Email,
+	_, err = ctrl.updateUser.Execute(&usecase.UpdateUserRequest{
+		ID:      userID,
+		Name:    req.Name,
+		Email:   req.Email,
+		Version: version,
 	})
 	if err != nil {
+		if err.Error() == domain.ErrConflict.Error() {
+			ctrl.log.Warn("User version conflict", "userID", userID, "version", version)
+			Response412(ctx)
+			return
+		}
 		if err.Error() == interactor.ErrUniqEmail.Error() {
 			ctrl.log.Warn("Email already registered", "email", req.Email)
 			Response400(ctx, map[string]error{
@@ -175,10 +231,25 @@ func (ctrl *UserController) PutUser(ctx *gin.Context) {
 func (ctrl *UserController) GetUsers(ctx *gin.Context) {
 	ctrl.log.Info("Starting GetUsers handler")
 
+	// クエリパラメータからページング条件を取得する
+	page, validErr := parsePageQuery(ctx)
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
 	// 一覧取得処理
-	getter := registry.GetFactory().BuildGetUserList()
-	res, err := getter.Execute(&usecase.GetUserListRequest{})
+	res, err := ctrl.getUserList.Execute(&usecase.GetUserListRequest{
+		Limit:     page.Limit,
+		NextToken: page.NextToken,
+	})
 	if err != nil {
+		if err.Error() == domain.ErrInvalidPageToken.Error() {
+			ctrl.log.Warn("Invalid page token", "next_token", page.NextToken)
+			Response400(ctx, invalidPageTokenErrors())
+			return
+		}
 		ctrl.log.Error("Failed to get user list", "error", err)
 		Response500(ctx, err)
 		return
@@ -187,17 +258,14 @@ func (ctrl *UserController) GetUsers(ctx *gin.Context) {
 	// ドメインモデルからレスポンス用の構造体に詰め替える
 	var resUsers = make([]*UserResponse, res.UserCount())
 	for i, u := range res.Users {
-		resUsers[i] = &UserResponse{
-			ID:    u.ID,
-			Name:  u.Name,
-			Email: u.Email,
-		}
+		resUsers[i] = NewUserResponse(u)
 	}
 
 	ctrl.log.Info("User list retrieved successfully", "count", len(resUsers))
 	// レスポンス処理
 	Response200(ctx, &UsersResponse{
-		Users: resUsers,
+		Users:     resUsers,
+		NextToken: res.NextToken,
 	})
 }
 
@@ -215,8 +283,7 @@ func (ctrl *UserController) GetUser(ctx *gin.Context) {
 
 	// ユーザー取得処理
 	ctrl.log.Info("Getting user by ID", "userID", userID)
-	getter := registry.GetFactory().BuildGetUserByID()
-	res, err := getter.Execute(&usecase.GetUserByIDRequest{UserID: userID})
+	res, err := ctrl.getUserByID.Execute(&usecase.GetUserByIDRequest{UserID: userID})
 	if err != nil {
 		if err.Error() == domain.ErrNotFound.Error() {
 			ctrl.log.Warn("User not found", "userID", userID)
@@ -229,12 +296,9 @@ func (ctrl *UserController) GetUser(ctx *gin.Context) {
 	}
 
 	ctrl.log.Info("User retrieved successfully", "userID", res.User.ID)
+	setETag(ctx, res.User.Version)
 	// ドメインモデルからレスポンス用構造体に詰め替えて、レスポンス
-	Response200(ctx, &UserResponse{
-		ID:    res.User.ID,
-		Name:  res.User.Name,
-		Email: res.User.Email,
-	})
+	Response200(ctx, NewUserResponse(res.User))
 }
 
 // DeleteUser 削除処理
@@ -249,13 +313,26 @@ func (ctrl *UserController) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
+	// If-Matchヘッダーから楽観的ロック用のバージョンを取得する
+	version, ok := parseIfMatch(ctx)
+	if !ok {
+		ctrl.log.Warn("Invalid If-Match header", "ifMatch", ctx.GetHeader("If-Match"))
+		Response412(ctx)
+		return
+	}
+
 	// 削除処理
 	ctrl.log.Info("Deleting user", "userID", userID)
-	deleter := registry.GetFactory().BuildUserDeleter()
-	_, err = deleter.Execute(&usecase.DeleteUserRequest{
-		UserID: userID,
+	_, err = ctrl.deleteUser.Execute(&usecase.DeleteUserRequest{
+		UserID:  userID,
+		Version: version,
 	})
 	if err != nil {
+		if err.Error() == domain.ErrConflict.Error() {
+			ctrl.log.Warn("User version conflict", "userID", userID, "version", version)
+			Response412(ctx)
+			return
+		}
 		ctrl.log.Error("Failed to delete user", "error", err)
 		Response500(ctx, err)
 		return
@@ -265,3 +342,115 @@ func (ctrl *UserController) DeleteUser(ctx *gin.Context) {
 	// レスポンス
 	Response200OK(ctx)
 }
+
+// RestoreUser 論理削除したユーザーの復元処理
+func (ctrl *UserController) RestoreUser(ctx *gin.Context) {
+	ctrl.log.Info("Starting RestoreUser handler")
+
+	// パスパラメータからユーザーIDを取得する
+	userID, err := utils.ParseUint(ctx.Param("user_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse user_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// 復元処理
+	ctrl.log.Info("Restoring user", "userID", userID)
+	res, err := ctrl.restoreUser.Execute(&usecase.RestoreUserRequest{UserID: userID})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("User not found", "userID", userID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == interactor.ErrUniqEmail.Error() {
+			ctrl.log.Warn("Email already registered by another user", "userID", userID)
+			Response400(ctx, map[string]error{
+				"email": errors.New("すでに登録されているメールアドレスです。"),
+			})
+			return
+		}
+		ctrl.log.Error("Failed to restore user", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("User restored successfully", "userID", res.User.ID)
+	setETag(ctx, res.User.Version)
+	// ドメインモデルからレスポンス用構造体に詰め替えて、レスポンス
+	Response200(ctx, NewUserResponse(res.User))
+}
+
+// PutPassword パスワード変更。現在のパスワードが一致する場合だけ変更する
+func (ctrl *UserController) PutPassword(ctx *gin.Context) {
+	ctrl.log.Info("Starting PutPassword handler")
+
+	// リクエストボディを取得
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctrl.log.Error("Failed to get request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// バリデーション処理
+	validator := PasswordSettingValidator()
+	validErr := validator.ValidateBody(string(body))
+	if validErr != nil {
+		ctrl.log.Warn("Validation failed", "errors", validErr)
+		Response400(ctx, validErr)
+		return
+	}
+
+	// JSON形式から構造体に変換
+	var req RequestPutPassword
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		ctrl.log.Error("Failed to unmarshal request body", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// パスパラメータからユーザーIDを取得する
+	userID, err := utils.ParseUint(ctx.Param("user_id"))
+	if err != nil {
+		ctrl.log.Error("Failed to parse user_id", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	// 変更処理
+	ctrl.log.Info("Changing password", "userID", userID)
+	_, err = ctrl.changePassword.Execute(&usecase.ChangePasswordRequest{
+		UserID:          userID,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	})
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			ctrl.log.Warn("User not found", "userID", userID)
+			Response404(ctx)
+			return
+		}
+		if err.Error() == interactor.ErrPasswordMismatch.Error() {
+			ctrl.log.Warn("Current password mismatch", "userID", userID)
+			Response400(ctx, map[string]error{
+				"current_password": errors.New("現在のパスワードが一致しません。"),
+			})
+			return
+		}
+		if err.Error() == domain.ErrConflict.Error() {
+			ctrl.log.Warn("User version conflict", "userID", userID)
+			Response412(ctx)
+			return
+		}
+		ctrl.log.Error("Failed to change password", "error", err)
+		Response500(ctx, err)
+		return
+	}
+
+	ctrl.log.Info("Password changed successfully", "userID", userID)
+	// 200レスポンス
+	Response200OK(ctx)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -140,6 +141,7 @@ func TestPutUser_200(t *testing.T) {
 	req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/users/%d", userMock.ID), bytes.NewBuffer(bodyStr))
 	req.Header.Set("Content-Type", "application/json")
 
+	setAuth(req, userMock.ID)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -185,6 +187,7 @@ func TestPutUser_200_dup(t *testing.T) {
 	req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/users/%d", userMock.ID), bytes.NewBuffer(bodyStr))
 	req.Header.Set("Content-Type", "application/json")
 
+	setAuth(req, userMock.ID)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -256,6 +259,7 @@ func TestPutUser_400(t *testing.T) {
 		req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/users/%d", userMock.ID), bytes.NewBuffer(bodyStr))
 		req.Header.Set("Content-Type", "application/json")
 
+		setAuth(req, userMock.ID)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 
@@ -287,6 +291,7 @@ func TestGetUser(t *testing.T) {
 	assert.NoError(t, err)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/users/%d", userMock.ID), nil)
+	setAuth(req, 1)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -303,6 +308,39 @@ func TestGetUser(t *testing.T) {
 	assert.Equal(t, userMock.Email, body["email"])
 }
 
+// TestGetUser_memory インメモリのリポジトリを差し込んだ場合も同様に取得できる
+func TestGetUser_memory(t *testing.T) {
+	router, f := setupMemoryRouter()
+
+	// 取得用モックデータを作成
+	userMock, err := f.UserRepository.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/users/%d", userMock.ID), nil)
+	setAuth(req, 1)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// ステータスコードをチェック
+	assert.Equal(t, 200, w.Code)
+
+	// 取得したデータをチェック
+	var body map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(userMock.ID), body["id"])
+	assert.Equal(t, userMock.Name, body["user_name"])
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	// 存在しないユーザーは404
+	req, _ = http.NewRequest("GET", "/v1/users/999", nil)
+	setAuth(req, 1)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+}
+
 // TestGetUsers 一覧取得
 func TestGetUsers(t *testing.T) {
 	// テスト用DynamoDBを設定
@@ -327,6 +365,7 @@ func TestGetUsers(t *testing.T) {
 	assert.NoError(t, err)
 
 	req, _ := http.NewRequest("GET", "/v1/users", nil)
+	setAuth(req, 1)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -353,6 +392,106 @@ func TestGetUsers(t *testing.T) {
 	assert.Equal(t, userMock2.Email, user2["email"])
 }
 
+// TestGetUsers_paging 一覧取得 ページング
+func TestGetUsers_paging(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	// モックデータを3件作成
+	for i := 1; i <= 3; i++ {
+		_, err := tables.UserOperator.CreateUser(&domain.UserModel{
+			Name:  fmt.Sprintf("Name_%d", i),
+			Email: fmt.Sprintf("test%d@example.com", i),
+		})
+		assert.NoError(t, err)
+	}
+
+	// 1ページ目を取得
+	req, _ := http.NewRequest("GET", "/v1/users?limit=2", nil)
+	setAuth(req, 1)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	var body map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Len(t, body["users"].([]interface{}), 2)
+
+	nextToken := body["next_token"].(string)
+	assert.NotEmpty(t, nextToken)
+
+	// 2ページ目を取得
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/v1/users?limit=2&next_token=%s", nextToken), nil)
+	setAuth(req, 1)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	err = json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Len(t, body["users"].([]interface{}), 1)
+	assert.Equal(t, "", body["next_token"])
+}
+
+// TestGetUsers_400 一覧取得 ページング条件が不正な場合
+func TestGetUsers_400(t *testing.T) {
+	// テスト用DynamoDBを設定
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	router := setupRouter()
+
+	cases := []struct {
+		Query    string
+		Expected map[string]interface{}
+	}{
+		// 件数が数値でない場合
+		{
+			Query: "limit=abc",
+			Expected: map[string]interface{}{
+				"limit": "取得件数は不正な値です。",
+			},
+		},
+		// 件数が負の数の場合
+		{
+			Query: "limit=-1",
+			Expected: map[string]interface{}{
+				"limit": "取得件数は0以上の数値を入力してください。",
+			},
+		},
+		// トークンが不正な場合
+		{
+			Query: "next_token=invalid",
+			Expected: map[string]interface{}{
+				"next_token": "ページトークンは不正な値です。",
+			},
+		},
+	}
+
+	for i, c := range cases {
+		msg := fmt.Sprintf("Case:%d", i+1)
+
+		req, _ := http.NewRequest("GET", "/v1/users?"+c.Query, nil)
+		setAuth(req, 1)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 400, w.Code, msg)
+
+		var resBody map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &resBody)
+		assert.NoError(t, err)
+
+		errors := resBody["errors"].(map[string]interface{})
+		assert.Equal(t, c.Expected, errors, msg)
+	}
+}
+
 // TestDeleteUser 削除
 func TestDeleteUser(t *testing.T) {
 	// テスト用DynamoDBを設定
@@ -371,6 +510,7 @@ func TestDeleteUser(t *testing.T) {
 
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/v1/users/%d", userMock.ID), nil)
 
+	setAuth(req, userMock.ID)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -378,7 +518,186 @@ func TestDeleteUser(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 
 	// DynamoDBからデータが削除されているかをチェック
-	users, err := tables.UserOperator.GetUsers()
+	users, _, err := tables.UserOperator.GetUsers(nil)
 	assert.NoError(t, err)
 	assert.Len(t, users, 0)
 }
+
+// TestPostUsers_idempotency 同じIdempotency-Keyでの再送は最初のレスポンスを返す
+func TestPostUsers_idempotency(t *testing.T) {
+	router, f := setupMemoryRouter()
+
+	post := func(key string, body map[string]interface{}) *httptest.ResponseRecorder {
+		bodyBytes, err := json.Marshal(body)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("POST", "/v1/users", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	body := map[string]interface{}{
+		"user_name": "Name_1",
+		"email":     "test1@example.com",
+	}
+
+	// 初回は作成される
+	w := post("key-1", body)
+	assert.Equal(t, 201, w.Code)
+	first := w.Body.String()
+
+	// 再送すると同じIDで201が返り、新たには作成されない
+	w = post("key-1", body)
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, first, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+
+	users, _, err := f.UserRepository.GetUsers(nil)
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	// 同じキーで異なるリクエストは422
+	w = post("key-1", map[string]interface{}{
+		"user_name": "Name_2",
+		"email":     "test2@example.com",
+	})
+	assert.Equal(t, 422, w.Code)
+
+	// 失敗したリクエストのキーは再利用できる
+	w = post("key-2", map[string]interface{}{"user_name": "Name_2"})
+	assert.Equal(t, 400, w.Code)
+	w = post("key-2", map[string]interface{}{
+		"user_name": "Name_2",
+		"email":     "test2@example.com",
+	})
+	assert.Equal(t, 201, w.Code)
+
+	// 完了しないまま占有期限が過ぎたキーは、同じリクエストの再送で引き継げる
+	staleBody := map[string]interface{}{
+		"user_name": "Name_3",
+		"email":     "test3@example.com",
+	}
+	staleBytes, err := json.Marshal(staleBody)
+	assert.NoError(t, err)
+	staleReq, _ := http.NewRequest("POST", "/v1/users", nil)
+	assert.NoError(t, f.IdempotencyKeyRepository.ReserveIdempotencyKey(domain.NewIdempotencyKeyModel(
+		"Anonymous", "key-3", hashRequest(staleReq, staleBytes), time.Now().Add(-2*domain.IdempotencyKeyLease))))
+	w = post("key-3", staleBody)
+	assert.Equal(t, 201, w.Code)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+
+	// 別の呼び出し元が同じキーを使っても422にならない
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"user_name": "Name_4",
+		"email":     "test4@example.com",
+	})
+	assert.NoError(t, err)
+	req, _ := http.NewRequest("POST", "/v1/users", bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Idempotency-Key", "key-1")
+	setTestAPIKey(t, f, req, domain.ScopeUsersWrite)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 201, w.Code)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+}
+
+// TestRestoreUser 論理削除したユーザーの復元
+func TestRestoreUser(t *testing.T) {
+	router, f := setupMemoryRouter()
+
+	userMock, err := f.UserRepository.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	assert.NoError(t, err)
+	_, err = f.MicropostRepository.CreateMicropost(domain.NewMicropostModel("Content", userMock.ID))
+	assert.NoError(t, err)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		setAuth(req, userMock.ID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 削除したユーザーは取得できない
+	w := do("DELETE", fmt.Sprintf("/v1/users/%d", userMock.ID))
+	assert.Equal(t, 200, w.Code)
+	w = do("GET", fmt.Sprintf("/v1/users/%d", userMock.ID))
+	assert.Equal(t, 404, w.Code)
+
+	// 復元すると再び取得できる
+	w = do("POST", fmt.Sprintf("/v1/users/%d/restore", userMock.ID))
+	assert.Equal(t, 200, w.Code)
+	var body map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(userMock.ID), body["id"])
+	assert.Equal(t, userMock.Email, body["email"])
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	w = do("GET", fmt.Sprintf("/v1/users/%d", userMock.ID))
+	assert.Equal(t, 200, w.Code)
+
+	// ユーザーと一緒に削除したマイクロポストも戻る
+	microposts, _, err := f.MicropostRepository.GetMicropostsByUserID(userMock.ID, nil)
+	assert.NoError(t, err)
+	assert.Len(t, microposts, 1)
+
+	// 削除中にメールアドレスが別のユーザーに使われた場合は400
+	w = do("DELETE", fmt.Sprintf("/v1/users/%d", userMock.ID))
+	assert.Equal(t, 200, w.Code)
+	_, err = f.UserRepository.CreateUser(domain.NewUserModel("Name_2", "test1@example.com"))
+	assert.NoError(t, err)
+	w = do("POST", fmt.Sprintf("/v1/users/%d/restore", userMock.ID))
+	assert.Equal(t, 400, w.Code)
+
+	// 存在しないユーザーは404
+	req, _ := http.NewRequest("POST", "/v1/users/999/restore", nil)
+	setAuth(req, 999)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+}
+
+// TestPutUser_auth 本人のトークンでなければ更新できない
+func TestPutUser_auth(t *testing.T) {
+	router, f := setupMemoryRouter()
+
+	userMock, err := f.UserRepository.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	assert.NoError(t, err)
+
+	put := func(authorization string) *httptest.ResponseRecorder {
+		bodyStr, err := json.Marshal(map[string]interface{}{
+			"user_name": "Name_1_updated",
+			"email":     "test1@example.com",
+		})
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/v1/users/%d", userMock.ID), bytes.NewBuffer(bodyStr))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	keys := mocks.GetJWTTestKeys()
+
+	// トークンが無い場合と検証できない場合は401
+	w := put("")
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	w = put("Bearer invalid")
+	assert.Equal(t, 401, w.Code)
+
+	// 他のユーザーのトークンは403
+	w = put(keys.BearerToken(userMock.ID + 1))
+	assert.Equal(t, 403, w.Code)
+
+	// RS256で署名したトークンも受け付ける
+	w = put("Bearer " + keys.SignRS256(map[string]interface{}{
+		"sub": fmt.Sprintf("%d", userMock.ID),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}))
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,195 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"context"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/memememomo/nomof"
+	"github.com/pkg/errors"
+)
+
+// DynamoCounterReconciler ユーザーとマイクロポストに保存している件数を、同じPKの下の子レコードとGSI1から数え直す
+type DynamoCounterReconciler struct {
+	Client *ResourceTableOperator
+	Mapper *DynamoModelMapper
+}
+
+// ReconcileUserCounters 全ユーザーを走査し、フォロー数をフォロー関係のレコードから、フォロワー数をGSI1の逆引きから数え直す
+func (r *DynamoCounterReconciler) ReconcileUserCounters(ctx context.Context, dryRun bool) (*domain.CounterReconcileResult, error) {
+	table, err := r.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	filterUserItems(fb, r.Mapper)
+
+	result := &domain.CounterReconcileResult{}
+	iter := table.Scan().Filter(fb.JoinAnd(), fb.Arg...).Iter()
+	for {
+		user := UserResource{Mapper: r.Mapper}
+		if !iter.NextWithContext(ctx, &user) {
+			break
+		}
+		result.Scanned++
+
+		following, err := table.
+			Get(r.Mapper.PKName, user.PK()).
+			Range(r.Mapper.SKName, dynamo.BeginsWith, followEntityName+"#").
+			CountWithContext(ctx)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		followers, err := table.
+			Get("GSI1PK", followerIndexPK(user.UserModel.ID)).
+			Index(UserIndexName).
+			CountWithContext(ctx)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if int(following) == user.FollowingCount && int(followers) == user.FollowerCount {
+			continue
+		}
+		err = r.setCounters(ctx, table, &user, dryRun, result, map[string]int{
+			"FollowingCount": int(following),
+			"FollowerCount":  int(followers),
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return result, nil
+}
+
+// ReconcileMicropostCounters 全マイクロポストを走査し、いいね数と返信数を子レコードから数え直す。
+// 返信数は返信のレコードのうち、返信が残っていて個別に削除されていないものを数える。
+// ユーザーと一緒に削除した返信はユーザーを復元すると戻るため、返信数に含めたままにする
+func (r *DynamoCounterReconciler) ReconcileMicropostCounters(ctx context.Context, dryRun bool) (*domain.CounterReconcileResult, error) {
+	table, err := r.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	filterMicropostItems(fb, r.Mapper)
+
+	result := &domain.CounterReconcileResult{}
+	iter := table.Scan().Filter(fb.JoinAnd(), fb.Arg...).Iter()
+	for {
+		micropost := MicropostResource{Mapper: r.Mapper}
+		if !iter.NextWithContext(ctx, &micropost) {
+			break
+		}
+		result.Scanned++
+
+		likes, err := table.
+			Get(r.Mapper.PKName, micropost.PK()).
+			Range(r.Mapper.SKName, dynamo.BeginsWith, likeEntityName+"#").
+			CountWithContext(ctx)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		replies, err := r.countReplies(ctx, table, &micropost)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if int(likes) == micropost.LikeCount && replies == micropost.ReplyCount {
+			continue
+		}
+		err = r.setCounters(ctx, table, &micropost, dryRun, result, map[string]int{
+			"LikeCount":  int(likes),
+			"ReplyCount": replies,
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return result, nil
+}
+
+// countReplies 返信のレコードを辿って返信をまとめて取得し、返信数に含めるものを数える
+func (r *DynamoCounterReconciler) countReplies(ctx context.Context, table *dynamo.Table, micropost *MicropostResource) (int, error) {
+	var replyResources []ReplyResource
+	err := table.
+		Get(r.Mapper.PKName, micropost.PK()).
+		Range(r.Mapper.SKName, dynamo.BeginsWith, replyEntityName+"#").
+		AllWithContext(ctx, &replyResources)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if len(replyResources) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]dynamo.Keyed, len(replyResources))
+	for i := range replyResources {
+		reply := NewMicropostResource(&domain.MicropostModel{ID: replyResources[i].MicropostID}, r.Mapper)
+		keys[i] = dynamo.Keys{reply.PK(), reply.SK()}
+	}
+
+	var replies []MicropostResource
+	err = table.
+		Batch(r.Mapper.PKName, r.Mapper.SKName).
+		Get(keys...).
+		AllWithContext(ctx, &replies)
+	if err != nil && !errors.Is(err, dynamo.ErrNotFound) {
+		return 0, errors.WithStack(err)
+	}
+
+	count := 0
+	for i := range replies {
+		if replies[i].DeletedAt().IsZero() || replies[i].DeletedWithUser {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// setCounters 数え直した件数を書き込む。走査した後に件数が変わっていた場合は、数え直した結果も古い可能性があるため書き込まない。
+// 件数はバージョンに含めないため、走査した時の件数のままであることを条件にする
+func (r *DynamoCounterReconciler) setCounters(ctx context.Context, table *dynamo.Table, resource DynamoResource, dryRun bool, result *domain.CounterReconcileResult, counters map[string]int) error {
+	if dryRun {
+		result.Fixed++
+		return nil
+	}
+
+	// 走査した後に物理削除されていた場合に、件数だけのレコードを作らないようにする
+	fb := nomof.NewBuilder()
+	fb.AttributeExists(r.Mapper.PKName)
+	if counted, ok := resource.(SystemAttributesResource); ok {
+		appendUnchangedConditions(fb, counted.SystemAttributes())
+	}
+
+	query := table.
+		Update(r.Mapper.PKName, resource.PK()).
+		Range(r.Mapper.SKName, resource.SK())
+	for attr, count := range counters {
+		query.Set(attr, count)
+	}
+	err := query.
+		Set("UpdatedAt", time.Now()).
+		If(fb.JoinAnd(), fb.Arg...).
+		RunWithContext(ctx)
+	if err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			result.Conflicts++
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+
+	result.Fixed++
+	return nil
+}
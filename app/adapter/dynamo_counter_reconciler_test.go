@@ -0,0 +1,91 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/registry"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// breakCounter 件数の属性だけを書き換えて、件数がずれた状態を再現する
+func breakCounter(t *testing.T, resource adapter.DynamoResource, attr string, value int) {
+	f := registry.GetFactory()
+	table, err := f.BuildResourceTableOperator().ConnectTable()
+	require.NoError(t, err)
+	mapper := f.BuildDynamoModelMapper()
+
+	err = table.
+		Update(mapper.PKName, resource.PK()).
+		Range(mapper.SKName, resource.SK()).
+		Set(attr, value).
+		Run()
+	require.NoError(t, err)
+}
+
+func TestDynamoCounterReconciler(t *testing.T) {
+	// テスト用のローカルDynamoDBを作成・接続
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	f := registry.GetFactory()
+	mapper := f.BuildDynamoModelMapper()
+
+	alice, err := tables.UserOperator.CreateUser(domain.NewUserModel("alice", "alice@example.com"))
+	require.NoError(t, err)
+	bob, err := tables.UserOperator.CreateUser(domain.NewUserModel("bob", "bob@example.com"))
+	require.NoError(t, err)
+	require.NoError(t, f.BuildFollowRepository().Follow(alice.ID, bob.ID))
+
+	post, err := tables.MicropostOperator.CreateMicropost(domain.NewMicropostModel("Content", alice.ID))
+	require.NoError(t, err)
+	reply := domain.NewMicropostModel("Reply", bob.ID)
+	reply.InReplyToID = post.ID
+	_, err = tables.MicropostOperator.CreateMicropost(reply)
+	require.NoError(t, err)
+	require.NoError(t, f.BuildLikeRepository().Like(post.ID, bob.ID))
+
+	breakCounter(t, adapter.NewUserResource(&domain.UserModel{ID: alice.ID}, mapper), "FollowingCount", 5)
+	breakCounter(t, adapter.NewUserResource(&domain.UserModel{ID: bob.ID}, mapper), "FollowerCount", 0)
+	breakCounter(t, adapter.NewMicropostResource(&domain.MicropostModel{ID: post.ID}, mapper), "LikeCount", 3)
+	breakCounter(t, adapter.NewMicropostResource(&domain.MicropostModel{ID: post.ID}, mapper), "ReplyCount", 0)
+
+	reconciler := f.BuildCounterReconciler()
+
+	// DryRunでは書き込まれないこと
+	users, err := reconciler.ReconcileUserCounters(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, users.Scanned)
+	assert.Equal(t, 2, users.Fixed)
+	got, err := tables.UserOperator.GetUserByID(alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, got.FollowingCount)
+
+	users, err = reconciler.ReconcileUserCounters(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, users.Fixed)
+	got, err = tables.UserOperator.GetUserByID(alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.FollowingCount)
+	got, err = tables.UserOperator.GetUserByID(bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.FollowerCount)
+
+	microposts, err := reconciler.ReconcileMicropostCounters(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, microposts.Scanned)
+	assert.Equal(t, 1, microposts.Fixed)
+	gotPost, err := tables.MicropostOperator.GetMicropostByID(post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, gotPost.LikeCount)
+	assert.Equal(t, 1, gotPost.ReplyCount)
+
+	// 件数が合っている場合は書き込まないこと
+	users, err = reconciler.ReconcileUserCounters(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, users.Fixed)
+}
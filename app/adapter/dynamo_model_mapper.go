@@ -1,8 +1,11 @@
 package adapter
 
 import (
+	"clean-serverless-book-sample/domain"
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"time"
 
@@ -27,8 +30,32 @@ type DynamoResource interface {
 	SetCreatedAt(t time.Time)
 	UpdatedAt() time.Time
 	SetUpdatedAt(t time.Time)
+	DeletedAt() time.Time
+	SetDeletedAt(t time.Time)
 }
 
+// deletedAtAttr 論理削除した時刻を保存する属性名
+const deletedAtAttr = "DeletedAt"
+
+// UserIndexedResource ユーザー単位のインデックス(GSI1)に載せるリソース
+type UserIndexedResource interface {
+	SetUserIndex()
+}
+
+// SystemAttributesResource フォロー数やいいね数のように、所有者の編集とは別にシステムが更新する属性を持つリソース。
+// バージョン(ETag)は所有者が編集できる属性が変わった場合だけ上げ、システムが更新する属性の変化ではIf-Matchを付けた更新が412にならないようにする。
+// そのためBuildQueryUpdateで丸ごと書き戻す際は、これらの属性が読み込んだ時の値のままであることも条件にする。
+// 間に変わっていた場合はdomain.ErrConflictになるため、呼び出し元はretryOnConflictで読み込みからやり直す
+type SystemAttributesResource interface {
+	SystemAttributes() map[string]interface{}
+}
+
+// writeConflictAttempts 書き込みが他の更新と競合した際に、読み込みからやり直す回数の上限
+const writeConflictAttempts = 3
+
+// userIndexTimeFormat GSI1SKの作成日時部分のフォーマット。文字列の大小が時刻の前後と一致するよう固定長にしている
+const userIndexTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
 type DynamoModelMapper struct {
 	Client    *ResourceTableOperator
 	TableName string
@@ -58,6 +85,7 @@ func (d *DynamoModelMapper) BuildQueryCreate(resource DynamoResource) (*dynamo.P
 	resource.SetVersion(1)
 	resource.SetPK()
 	resource.SetSK()
+	d.setUserIndex(resource)
 
 	fb := nomof.NewBuilder()
 	fb.AttributeNotExists(d.PKName)
@@ -79,9 +107,13 @@ func (d *DynamoModelMapper) BuildQueryUpdate(resource DynamoResource) (*dynamo.P
 
 	resource.SetUpdatedAt(time.Now())
 	resource.SetVersion(oldVersion + 1)
+	d.setUserIndex(resource)
 
 	fb := nomof.NewBuilder()
 	fb.Equal("Version", oldVersion)
+	if r, ok := resource.(SystemAttributesResource); ok {
+		appendUnchangedConditions(fb, r.SystemAttributes())
+	}
 
 	query := table.
 		Put(resource).
@@ -100,6 +132,69 @@ func (d *DynamoModelMapper) BuildQueryDelete(resource DynamoResource) (*dynamo.D
 		Delete(d.PKName, resource.PK()).
 		Range(d.SKName, resource.SK())
 
+	// バージョンが分かっている場合は、その間に更新されていないことを条件に削除する
+	if resource.Version() != 0 {
+		fb := nomof.NewBuilder()
+		fb.Equal("Version", resource.Version())
+		query.If(fb.JoinAnd(), fb.Arg...)
+	}
+
+	return query, nil
+}
+
+// BuildQuerySoftDelete 論理削除するクエリを生成する。更新と同じくバージョンが一致することを条件にする
+func (d *DynamoModelMapper) BuildQuerySoftDelete(resource DynamoResource) (*dynamo.Put, error) {
+	resource.SetDeletedAt(time.Now())
+	return d.BuildQueryUpdate(resource)
+}
+
+// BuildQueryRestore 論理削除を取り消すクエリを生成する
+func (d *DynamoModelMapper) BuildQueryRestore(resource DynamoResource) (*dynamo.Put, error) {
+	resource.SetDeletedAt(time.Time{})
+	return d.BuildQueryUpdate(resource)
+}
+
+// BuildQueryCheckExists 指定したエンティティが存在し、論理削除されていないことを確認するクエリを生成する。
+// トランザクションで他の書き込みと組み合わせて使う
+func (d *DynamoModelMapper) BuildQueryCheckExists(resource DynamoResource) (*dynamo.ConditionCheck, error) {
+	table, err := d.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeExists(d.PKName)
+	fb.AttributeNotExists(deletedAtAttr)
+
+	query := table.
+		Check(d.PKName, resource.PK()).
+		Range(d.SKName, resource.SK()).
+		If(fb.JoinAnd(), fb.Arg...)
+
+	return query, nil
+}
+
+// BuildQueryAddCounter 件数の属性をdeltaだけ増減するクエリを生成する。requireActiveの場合は論理削除されていないことも条件にする。
+// 件数はSystemAttributesResourceの属性のため、バージョンは上げない
+func (d *DynamoModelMapper) BuildQueryAddCounter(resource DynamoResource, attr string, delta int, requireActive bool) (*dynamo.Update, error) {
+	table, err := d.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeExists(d.PKName)
+	if requireActive {
+		fb.AttributeNotExists(deletedAtAttr)
+	}
+
+	query := table.
+		Update(d.PKName, resource.PK()).
+		Range(d.SKName, resource.SK()).
+		Add(attr, delta).
+		Set("UpdatedAt", time.Now()).
+		If(fb.JoinAnd(), fb.Arg...)
+
 	return query, nil
 }
 
@@ -125,7 +220,7 @@ func (d *DynamoModelMapper) UpdateResource(resource DynamoResource) error {
 
 	err = query.Run()
 	if err != nil {
-		return errors.WithStack(err)
+		return errors.WithStack(ConvertConflictError(err))
 	}
 
 	return nil
@@ -138,10 +233,25 @@ func (d *DynamoModelMapper) DeleteResource(resource DynamoResource) error {
 	}
 
 	err = query.Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	return nil
+}
+
+// SoftDeleteResource 論理削除する
+func (d *DynamoModelMapper) SoftDeleteResource(resource DynamoResource) error {
+	query, err := d.BuildQuerySoftDelete(resource)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	err = query.Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
 	return nil
 }
 
@@ -160,7 +270,78 @@ func (d *DynamoModelMapper) GetSK(resource DynamoResource) string {
 	return fmt.Sprintf("%011d", resource.ID())
 }
 
+// GetUserIndexPK ユーザー単位のインデックスのHASHキーを返す
+func (d *DynamoModelMapper) GetUserIndexPK(userID uint64) string {
+	return fmt.Sprintf("User-%011d", userID)
+}
+
+// GetUserIndexSK ユーザー単位のインデックスのRANGEキーを返す。
+// 同じインデックスに複数のエンティティを載せられるよう、先頭にエンティティ名を付けている
+func (d *DynamoModelMapper) GetUserIndexSK(resource DynamoResource) string {
+	return d.GetUserIndexSKAt(resource.EntityName(), resource.CreatedAt(), resource.ID())
+}
+
+// GetUserIndexSKAt 作成日時とIDからユーザー単位のインデックスのRANGEキーを組み立てる
+func (d *DynamoModelMapper) GetUserIndexSKAt(entityName string, createdAt time.Time, id uint64) string {
+	return fmt.Sprintf("%s#%s#%011d",
+		entityName,
+		createdAt.UTC().Format(userIndexTimeFormat),
+		id)
+}
+
+// QueryByUserIndex ユーザー単位のインデックスから指定したエンティティを新しい順に取得する。続きがある場合は次のページのトークンも返す。
+// 論理削除されたものは含まない
+func (d *DynamoModelMapper) QueryByUserIndex(userID uint64, entityName string, page *domain.Page, ret interface{}) (string, error) {
+	table, err := d.Client.ConnectTable()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	startKey, err := DecodePagingKey(page.NextToken)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeNotExists(deletedAtAttr)
+
+	lastKey, err := table.
+		Get("GSI1PK", d.GetUserIndexPK(userID)).
+		Index(UserIndexName).
+		Range("GSI1SK", dynamo.BeginsWith, entityName+"#").
+		Filter(fb.JoinAnd(), fb.Arg...).
+		Order(dynamo.Descending).
+		StartFrom(startKey).
+		Limit(int64(page.Limit)).
+		AllWithLastEvaluatedKey(ret)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	nextToken, err := EncodePagingKey(lastKey)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return nextToken, nil
+}
+
+// GetEntityByID IDからエンティティを取得する。論理削除されたものは見つからなかったものとして扱う
 func (d *DynamoModelMapper) GetEntityByID(id uint64, resource DynamoResource, ret interface{}) (interface{}, error) {
+	_, err := d.GetEntityByIDIncludingDeleted(id, resource, ret)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if r, ok := ret.(DynamoResource); ok && !r.DeletedAt().IsZero() {
+		return nil, errors.WithStack(dynamo.ErrNotFound)
+	}
+
+	return ret, nil
+}
+
+// GetEntityByIDIncludingDeleted IDからエンティティを取得する。論理削除されたものも返す
+func (d *DynamoModelMapper) GetEntityByIDIncludingDeleted(id uint64, resource DynamoResource, ret interface{}) (interface{}, error) {
 	table, err := d.Client.ConnectTable()
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -179,6 +360,100 @@ func (d *DynamoModelMapper) GetEntityByID(id uint64, resource DynamoResource, re
 	return ret, nil
 }
 
+// appendUnchangedConditions 属性が指定した値のままであることを条件に加える。
+// ゼロ値の場合は、その属性を追加する前に保存したレコードのために属性が無いことも許す
+func appendUnchangedConditions(fb *nomof.Builder, attrs map[string]interface{}) {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := attrs[name]
+		if !reflect.ValueOf(value).IsZero() {
+			fb.Equal(name, value)
+			continue
+		}
+		zero := nomof.NewBuilder()
+		zero.AttributeNotExists(name)
+		zero.Equal(name, value)
+		fb.Append("("+zero.JoinOr()+")", zero.Arg)
+	}
+}
+
+// retryOnConflict fnがdomain.ErrConflictを返した場合に、attempts回まで呼び直す。fnは読み込みからやり直すようにする
+func retryOnConflict(attempts int, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		err = fn()
+		if !errors.Is(err, domain.ErrConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// ConvertConflictError 条件付き書き込みの失敗をdomain.ErrConflictに変換する。それ以外のエラーはそのまま返す
+func ConvertConflictError(err error) error {
+	if dynamo.IsCondCheckFailed(err) {
+		return domain.ErrConflict
+	}
+	return err
+}
+
+// isTxCondCheckFailedAt トランザクションのindex番目(追加した順、0始まり)の書き込みが条件を満たさずに取り消されたかどうか
+func isTxCondCheckFailedAt(err error, index int) bool {
+	var txe *dynamodb.TransactionCanceledException
+	if !errors.As(err, &txe) || index >= len(txe.CancellationReasons) {
+		return false
+	}
+	code := txe.CancellationReasons[index].Code
+	return code != nil && *code == "ConditionalCheckFailed"
+}
+
+// PurgeDeletedEntities 指定した時刻より前に論理削除されたエンティティを物理削除する。削除した件数を返す。
+// 削除までの間に復元・更新されたものはバージョンが変わるため削除しない。ctxが取り消された場合は途中で打ち切る
+func (d *DynamoModelMapper) PurgeDeletedEntities(ctx context.Context, entityName string, deletedBefore time.Time, newResource func() DynamoResource) (int, error) {
+	table, err := d.Client.ConnectTable()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.BeginsWith(d.PKName, entityName)
+	fb.Op(deletedAtAttr, nomof.LE, deletedBefore.Unix())
+
+	purged := 0
+	iter := table.Scan().Filter(fb.JoinAnd(), fb.Arg...).Iter()
+	for {
+		resource := newResource()
+		if !iter.NextWithContext(ctx, resource) {
+			break
+		}
+
+		err := d.DeleteResource(resource)
+		if err != nil {
+			if errors.Cause(err) == domain.ErrConflict {
+				continue
+			}
+			return purged, errors.WithStack(err)
+		}
+		purged++
+	}
+	if err := iter.Err(); err != nil {
+		return purged, errors.WithStack(err)
+	}
+
+	return purged, nil
+}
+
+func (d *DynamoModelMapper) setUserIndex(resource DynamoResource) {
+	if r, ok := resource.(UserIndexedResource); ok {
+		r.SetUserIndex()
+	}
+}
+
 func (d *DynamoModelMapper) isNewEntity(resource DynamoResource) bool {
 	return resource.Version() == 0
 }
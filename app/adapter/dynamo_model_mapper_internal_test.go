@@ -0,0 +1,24 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTxCondCheckFailedAt(t *testing.T) {
+	err := errors.WithStack(&dynamodb.TransactionCanceledException{
+		CancellationReasons: []*dynamodb.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("ConditionalCheckFailed")},
+		},
+	})
+
+	assert.False(t, isTxCondCheckFailedAt(err, 0))
+	assert.True(t, isTxCondCheckFailedAt(err, 1))
+	assert.False(t, isTxCondCheckFailedAt(err, 2))
+	assert.False(t, isTxCondCheckFailedAt(errors.New("other error"), 0))
+}
@@ -10,4 +10,6 @@ type DynamoCreatedUpdated struct {
 type DynamoResourceBase struct {
 	Version int `dynamo:"Version"`
 	DynamoCreatedUpdated
+	// DeletedAt 論理削除した時刻。削除されていない場合は属性自体を持たない
+	DeletedAt time.Time `dynamo:"DeletedAt,unixtime,omitempty"`
 }
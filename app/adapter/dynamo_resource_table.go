@@ -14,9 +14,14 @@ type ResourceTableOperator struct {
 	TableOperator
 }
 
+// UserIndexName ユーザー単位で子エンティティを検索するためのGSI名
+const UserIndexName = "GSI1"
+
 type ResourceSchema struct {
-	PK string `dynamo:"PK,hash"`
-	SK string `dynamo:"SK,range"`
+	PK     string `dynamo:"PK,hash"`
+	SK     string `dynamo:"SK,range"`
+	GSI1PK string `dynamo:"GSI1PK" index:"GSI1,hash"`
+	GSI1SK string `dynamo:"GSI1SK" index:"GSI1,range"`
 }
 
 func NewResourceTableOperator(client *DynamoClient, tableName string) *ResourceTableOperator {
@@ -0,0 +1,173 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/guregu/dynamo"
+	"github.com/pkg/errors"
+)
+
+// DynamoDBストリームのイベント名
+const (
+	streamEventInsert = "INSERT"
+	streamEventModify = "MODIFY"
+	streamEventRemove = "REMOVE"
+)
+
+// DynamoStreamDecoder リソーステーブルの変更ストリームのレコードを、ドメインイベントに変換する
+type DynamoStreamDecoder struct {
+	Mapper *DynamoModelMapper
+}
+
+// Decode レコードの変更前後の内容をリソースに戻し、ドメインイベントに変換する。
+// PKの接頭辞がユーザーやマイクロポストでないレコードや、同じPKの下に置いたフォローや返信などのレコードはnilを返す
+func (d *DynamoStreamDecoder) Decode(record *events.DynamoDBEventRecord) (domain.ResourceEvent, error) {
+	pk, ok := record.Change.Keys[d.Mapper.PKName]
+	if !ok {
+		return nil, errors.Errorf("stream record has no %s: %s", d.Mapper.PKName, record.EventID)
+	}
+	at := domain.ResourceEventBase{At: record.Change.ApproximateCreationDateTime.Time}
+
+	switch {
+	case strings.HasPrefix(pk.String(), d.Mapper.GetEntityNameFromStruct(UserResource{})+"-"):
+		return d.decodeUser(record, at)
+	case strings.HasPrefix(pk.String(), d.Mapper.GetEntityNameFromStruct(MicropostResource{})+"-"):
+		return d.decodeMicropost(record, at)
+	default:
+		return nil, nil
+	}
+}
+
+func (d *DynamoStreamDecoder) decodeUser(record *events.DynamoDBEventRecord, at domain.ResourceEventBase) (domain.ResourceEvent, error) {
+	oldResource, newResource, err := d.decodeImages(record, func() (DynamoResource, *ResourceSchema) {
+		r := &UserResource{Mapper: d.Mapper}
+		return r, &r.ResourceSchema
+	})
+	if err != nil || (oldResource == nil && newResource == nil) {
+		return nil, err
+	}
+	oldUser, _ := oldResource.(*UserResource)
+	newUser, _ := newResource.(*UserResource)
+
+	switch d.change(record.EventName, oldResource, newResource) {
+	case streamEventInsert:
+		return &domain.UserCreated{ResourceEventBase: at, User: newUser.ToModel()}, nil
+	case streamEventRemove:
+		return &domain.UserPurged{ResourceEventBase: at, User: oldUser.ToModel()}, nil
+	case changeDeleted:
+		return &domain.UserDeleted{ResourceEventBase: at, User: newUser.ToModel()}, nil
+	case changeRestored:
+		return &domain.UserRestored{ResourceEventBase: at, User: newUser.ToModel()}, nil
+	default:
+		return &domain.UserUpdated{ResourceEventBase: at, Old: oldUser.ToModel(), New: newUser.ToModel()}, nil
+	}
+}
+
+func (d *DynamoStreamDecoder) decodeMicropost(record *events.DynamoDBEventRecord, at domain.ResourceEventBase) (domain.ResourceEvent, error) {
+	oldResource, newResource, err := d.decodeImages(record, func() (DynamoResource, *ResourceSchema) {
+		r := &MicropostResource{Mapper: d.Mapper}
+		return r, &r.ResourceSchema
+	})
+	if err != nil || (oldResource == nil && newResource == nil) {
+		return nil, err
+	}
+	oldMicropost, _ := oldResource.(*MicropostResource)
+	newMicropost, _ := newResource.(*MicropostResource)
+
+	switch d.change(record.EventName, oldResource, newResource) {
+	case streamEventInsert:
+		return &domain.MicropostCreated{ResourceEventBase: at, Micropost: newMicropost.ToModel()}, nil
+	case streamEventRemove:
+		return &domain.MicropostPurged{ResourceEventBase: at, Micropost: oldMicropost.ToModel()}, nil
+	case changeDeleted:
+		return &domain.MicropostDeleted{ResourceEventBase: at, Micropost: newMicropost.ToModel()}, nil
+	case changeRestored:
+		return &domain.MicropostRestored{ResourceEventBase: at, Micropost: newMicropost.ToModel()}, nil
+	default:
+		return &domain.MicropostUpdated{ResourceEventBase: at, Old: oldMicropost.ToModel(), New: newMicropost.ToModel()}, nil
+	}
+}
+
+// decodeImages 変更前後の内容をnewResourceで生成したリソースに読み込む。
+// イベントの種類に必要な内容が無い場合はエラー、リソース本体のレコードでない場合は両方nilを返す
+func (d *DynamoStreamDecoder) decodeImages(record *events.DynamoDBEventRecord, newResource func() (DynamoResource, *ResourceSchema)) (DynamoResource, DynamoResource, error) {
+	needOld := record.EventName == streamEventModify || record.EventName == streamEventRemove
+	needNew := record.EventName == streamEventModify || record.EventName == streamEventInsert
+	if !needOld && !needNew {
+		return nil, nil, errors.Errorf("unsupported stream event: %s", record.EventName)
+	}
+
+	var resources [2]DynamoResource
+	for n, img := range []struct {
+		image map[string]events.DynamoDBAttributeValue
+		need  bool
+	}{{record.Change.OldImage, needOld}, {record.Change.NewImage, needNew}} {
+		if !img.need {
+			continue
+		}
+		if len(img.image) == 0 {
+			return nil, nil, errors.Errorf("stream record has no image for %s: %s", record.EventName, record.EventID)
+		}
+		r, schema := newResource()
+		ok, err := d.unmarshalResource(img.image, r, schema)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			return nil, nil, nil
+		}
+		resources[n] = r
+	}
+	return resources[0], resources[1], nil
+}
+
+// 論理削除と復元はMODIFYとして届くため、DeletedAtの変化で見分ける
+const (
+	changeDeleted  = "DELETED"
+	changeRestored = "RESTORED"
+)
+
+// change イベント名と変更前後のDeletedAtから変更の種類を決める
+func (d *DynamoStreamDecoder) change(eventName string, oldResource, newResource DynamoResource) string {
+	if eventName != streamEventModify {
+		return eventName
+	}
+	switch {
+	case oldResource.DeletedAt().IsZero() && !newResource.DeletedAt().IsZero():
+		return changeDeleted
+	case !oldResource.DeletedAt().IsZero() && newResource.DeletedAt().IsZero():
+		return changeRestored
+	default:
+		return streamEventModify
+	}
+}
+
+// unmarshalResource ストリームの内容をリソースに読み込む。
+// SKがIDと一致しないレコードはリソース本体ではない(フォローや返信など)ため、falseを返す
+func (d *DynamoStreamDecoder) unmarshalResource(image map[string]events.DynamoDBAttributeValue, resource DynamoResource, schema *ResourceSchema) (bool, error) {
+	item, err := toDynamoItem(image)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if err := dynamo.UnmarshalItem(item, resource); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return schema.SK == d.Mapper.GetSK(resource), nil
+}
+
+// toDynamoItem Lambdaのイベントの属性値を、AWS SDKの属性値に変換する。どちらも同じJSONの表現を持つため、JSONを経由する
+func toDynamoItem(image map[string]events.DynamoDBAttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	b, err := json.Marshal(image)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	item := map[string]*dynamodb.AttributeValue{}
+	if err := json.Unmarshal(b, &item); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return item, nil
+}
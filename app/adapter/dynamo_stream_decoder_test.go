@@ -0,0 +1,167 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/guregu/dynamo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamImage リソースを保存した場合と同じ内容の、ストリームのレコードの属性値を生成する
+func streamImage(t *testing.T, resource adapter.DynamoResource) map[string]events.DynamoDBAttributeValue {
+	resource.SetPK()
+	resource.SetSK()
+	item, err := dynamo.MarshalItem(resource)
+	require.NoError(t, err)
+	return toStreamAttribute(&dynamodb.AttributeValue{M: item}).Map()
+}
+
+// toStreamAttribute AWS SDKの属性値を、Lambdaのイベントの属性値に変換する
+func toStreamAttribute(av *dynamodb.AttributeValue) events.DynamoDBAttributeValue {
+	switch {
+	case av.S != nil:
+		return events.NewStringAttribute(*av.S)
+	case av.N != nil:
+		return events.NewNumberAttribute(*av.N)
+	case av.BOOL != nil:
+		return events.NewBooleanAttribute(*av.BOOL)
+	case av.B != nil:
+		return events.NewBinaryAttribute(av.B)
+	case av.SS != nil:
+		return events.NewStringSetAttribute(aws.StringValueSlice(av.SS))
+	case av.NS != nil:
+		return events.NewNumberSetAttribute(aws.StringValueSlice(av.NS))
+	case av.L != nil:
+		list := make([]events.DynamoDBAttributeValue, len(av.L))
+		for n, v := range av.L {
+			list[n] = toStreamAttribute(v)
+		}
+		return events.NewListAttribute(list)
+	case av.M != nil:
+		m := map[string]events.DynamoDBAttributeValue{}
+		for k, v := range av.M {
+			m[k] = toStreamAttribute(v)
+		}
+		return events.NewMapAttribute(m)
+	default:
+		return events.NewNullAttribute()
+	}
+}
+
+func streamRecord(eventName string, oldImage, newImage map[string]events.DynamoDBAttributeValue) *events.DynamoDBEventRecord {
+	image := newImage
+	if image == nil {
+		image = oldImage
+	}
+	return &events.DynamoDBEventRecord{
+		EventID:   "event-1",
+		EventName: eventName,
+		Change: events.DynamoDBStreamRecord{
+			ApproximateCreationDateTime: events.SecondsEpochTime{Time: time.Unix(1700000000, 0)},
+			Keys:                        map[string]events.DynamoDBAttributeValue{"PK": image["PK"], "SK": image["SK"]},
+			OldImage:                    oldImage,
+			NewImage:                    newImage,
+			SequenceNumber:              "100",
+		},
+	}
+}
+
+func TestDynamoStreamDecoder_User(t *testing.T) {
+	mapper := &adapter.DynamoModelMapper{PKName: "PK", SKName: "SK"}
+	decoder := &adapter.DynamoStreamDecoder{Mapper: mapper}
+
+	user := adapter.NewUserResource(&domain.UserModel{ID: 1, Name: "Alice", Email: "alice@example.com", Version: 1}, mapper)
+	created := streamImage(t, user)
+
+	event, err := decoder.Decode(streamRecord("INSERT", nil, created))
+	require.NoError(t, err)
+	if assert.IsType(t, &domain.UserCreated{}, event) {
+		assert.Equal(t, "Alice", event.(*domain.UserCreated).User.Name)
+		assert.Equal(t, 1, event.(*domain.UserCreated).User.Version)
+		assert.Equal(t, time.Unix(1700000000, 0), event.OccurredAt())
+	}
+
+	updatedUser := adapter.NewUserResource(&domain.UserModel{ID: 1, Name: "Alice", Email: "alice@example.com", FollowerCount: 1, Version: 2}, mapper)
+	updated := streamImage(t, updatedUser)
+	event, err = decoder.Decode(streamRecord("MODIFY", created, updated))
+	require.NoError(t, err)
+	if assert.IsType(t, &domain.UserUpdated{}, event) {
+		assert.Equal(t, 0, event.(*domain.UserUpdated).Old.FollowerCount)
+		assert.Equal(t, 1, event.(*domain.UserUpdated).New.FollowerCount)
+	}
+
+	deletedUser := adapter.NewUserResource(&domain.UserModel{ID: 1, Name: "Alice", Email: "alice@example.com", Version: 3}, mapper)
+	deletedUser.SetDeletedAt(time.Now())
+	deleted := streamImage(t, deletedUser)
+	event, err = decoder.Decode(streamRecord("MODIFY", updated, deleted))
+	require.NoError(t, err)
+	assert.IsType(t, &domain.UserDeleted{}, event)
+
+	event, err = decoder.Decode(streamRecord("MODIFY", deleted, updated))
+	require.NoError(t, err)
+	assert.IsType(t, &domain.UserRestored{}, event)
+
+	event, err = decoder.Decode(streamRecord("REMOVE", deleted, nil))
+	require.NoError(t, err)
+	if assert.IsType(t, &domain.UserPurged{}, event) {
+		assert.Equal(t, uint64(1), event.(*domain.UserPurged).User.ID)
+	}
+
+	// 変更後の内容が無いMODIFYは、ストリームの設定の誤りとしてエラーにする
+	_, err = decoder.Decode(streamRecord("MODIFY", created, nil))
+	assert.Error(t, err)
+}
+
+func TestDynamoStreamDecoder_Micropost(t *testing.T) {
+	mapper := &adapter.DynamoModelMapper{PKName: "PK", SKName: "SK"}
+	decoder := &adapter.DynamoStreamDecoder{Mapper: mapper}
+
+	micropost := adapter.NewMicropostResource(&domain.MicropostModel{ID: 10, Content: "hello", UserID: 1, Version: 1}, mapper)
+	micropost.SetCreatedAt(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	created := streamImage(t, micropost)
+
+	event, err := decoder.Decode(streamRecord("INSERT", nil, created))
+	require.NoError(t, err)
+	if assert.IsType(t, &domain.MicropostCreated{}, event) {
+		m := event.(*domain.MicropostCreated).Micropost
+		assert.Equal(t, "hello", m.Content)
+		assert.Equal(t, uint64(1), m.UserID)
+		assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(m.CreatedAt))
+	}
+
+	micropost.SetDeletedAt(time.Now())
+	deleted := streamImage(t, micropost)
+	event, err = decoder.Decode(streamRecord("MODIFY", created, deleted))
+	require.NoError(t, err)
+	assert.IsType(t, &domain.MicropostDeleted{}, event)
+}
+
+func TestDynamoStreamDecoder_Ignored(t *testing.T) {
+	mapper := &adapter.DynamoModelMapper{PKName: "PK", SKName: "SK"}
+	decoder := &adapter.DynamoStreamDecoder{Mapper: mapper}
+
+	// ユーザーのPKの下に置いたフォローのレコードはユーザー本体ではない
+	follow := map[string]events.DynamoDBAttributeValue{
+		"PK": events.NewStringAttribute("UserResource-00000000001"),
+		"SK": events.NewStringAttribute("Follow#00000000002"),
+	}
+	event, err := decoder.Decode(streamRecord("INSERT", nil, follow))
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	// ユーザーとマイクロポスト以外のレコード
+	product := map[string]events.DynamoDBAttributeValue{
+		"PK": events.NewStringAttribute("ProductResource-00000000001"),
+		"SK": events.NewStringAttribute("00000000001"),
+	}
+	event, err = decoder.Decode(streamRecord("INSERT", nil, product))
+	require.NoError(t, err)
+	assert.Nil(t, event)
+}
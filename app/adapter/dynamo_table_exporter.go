@@ -0,0 +1,72 @@
+package adapter
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// exportEntityNamePattern PKの接頭辞のうち、項目の種類として扱う形式。構造体の名前から決めているため英数字だけになる
+var exportEntityNamePattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+const (
+	// userEmailUniqExportName メールアドレス重複チェックのレコードの種類。PKがメールアドレスそのもので接頭辞を持たない
+	userEmailUniqExportName = "UserEmailUniq"
+	// unknownExportName 接頭辞から種類がわからない項目の種類
+	unknownExportName = "Unknown"
+)
+
+// exportExcludedAttributes 書き出さない属性。分析には使わない認証情報のハッシュ値はテーブルの外に出さない
+var exportExcludedAttributes = []string{"PasswordHash", "SecretHash"}
+
+// DynamoTableExporter テーブルの全ての項目を、PKの接頭辞から決めた種類ごとに読み込む
+type DynamoTableExporter struct {
+	Client *ResourceTableOperator
+	PKName string
+}
+
+// ScanAll テーブルをsegments個に分けて並列に走査する。いずれかのセグメントが失敗した場合は残りの走査も止める
+func (e *DynamoTableExporter) ScanAll(ctx context.Context, segments int, fn func(entityType string, item map[string]interface{}) error) error {
+	table, err := e.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for segment := 0; segment < segments; segment++ {
+		eg.Go(func() error {
+			iter := table.Scan().Segment(int64(segment), int64(segments)).Iter()
+			for {
+				var item map[string]interface{}
+				if !iter.NextWithContext(ctx, &item) {
+					break
+				}
+				for _, name := range exportExcludedAttributes {
+					delete(item, name)
+				}
+				pk, _ := item[e.PKName].(string)
+				if err := fn(exportEntityType(pk), item); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			return errors.WithStack(iter.Err())
+		})
+	}
+	return eg.Wait()
+}
+
+// exportEntityType PKの接頭辞から項目の種類を決める。UserResource-00000000001 の場合は UserResource になり、
+// 連番のカウンターのように接頭辞だけのPKはそのまま種類にする
+func exportEntityType(pk string) string {
+	if strings.Contains(pk, "@") {
+		return userEmailUniqExportName
+	}
+	prefix, _, _ := strings.Cut(pk, "-")
+	if !exportEntityNamePattern.MatchString(prefix) {
+		return unknownExportName
+	}
+	return prefix
+}
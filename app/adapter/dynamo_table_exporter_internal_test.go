@@ -0,0 +1,17 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportEntityType(t *testing.T) {
+	assert.Equal(t, "UserResource", exportEntityType("UserResource-00000000001"))
+	assert.Equal(t, "ImportJob", exportEntityType("ImportJob-0123456789abcdef"))
+	assert.Equal(t, "MicropostResource", exportEntityType("MicropostResource"))
+	// メールアドレス重複チェックのレコードはPKがメールアドレスそのもの
+	assert.Equal(t, "UserEmailUniq", exportEntityType("Some-One@example.com"))
+	assert.Equal(t, "Unknown", exportEntityType("lower-case"))
+	assert.Equal(t, "Unknown", exportEntityType(""))
+}
@@ -0,0 +1,271 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/memememomo/nomof"
+	"github.com/pkg/errors"
+)
+
+// followEntityName フォロー関係のレコードのSKの接頭辞。フォローしたユーザーのPKの下に隣接リストとして保存する
+const followEntityName = "Follow"
+
+// followerIndexPrefix フォロワーを逆引きするGSI1PKの接頭辞
+const followerIndexPrefix = "Follower"
+
+// FollowResource フォロー関係のレコードを表した構造体。
+// PK=フォローしたユーザーのPK, SK=Follow#フォローされたユーザーID とし、GSI1でフォローされたユーザーから逆引きする
+type FollowResource struct {
+	ResourceSchema
+	UserID       uint64    `dynamo:"UserID"`
+	TargetUserID uint64    `dynamo:"TargetUserID"`
+	CreatedAt    time.Time `dynamo:"CreatedAt"`
+}
+
+// ToModel ドメインモデルに変換する
+func (f *FollowResource) ToModel() *domain.FollowModel {
+	return domain.NewFollowModel(f.UserID, f.TargetUserID, f.CreatedAt)
+}
+
+// FollowOperator フォロー関係を操作する構造体
+type FollowOperator struct {
+	Client *ResourceTableOperator
+	Mapper *DynamoModelMapper
+	PKName string
+	SKName string
+}
+
+func (o *FollowOperator) userPK(userID uint64) string {
+	return NewUserResource(&domain.UserModel{ID: userID}, o.Mapper).PK()
+}
+
+func (o *FollowOperator) userSK(userID uint64) string {
+	return NewUserResource(&domain.UserModel{ID: userID}, o.Mapper).SK()
+}
+
+func (o *FollowOperator) followSK(targetUserID uint64) string {
+	return fmt.Sprintf("%s#%011d", followEntityName, targetUserID)
+}
+
+// followerIndexPK フォローされたユーザーからフォロー関係のレコードを逆引きするGSI1PK
+func followerIndexPK(targetUserID uint64) string {
+	return fmt.Sprintf("%s-%011d", followerIndexPrefix, targetUserID)
+}
+
+// buildQueriesUpdateCount フォローしたユーザーのフォロー数と、フォローされたユーザーのフォロワー数を増減するクエリを生成する
+func (o *FollowOperator) buildQueriesUpdateCount(userID, targetUserID uint64, delta int, requireActive bool) ([]*dynamo.Update, error) {
+	following, err := o.Mapper.BuildQueryAddCounter(NewUserResource(&domain.UserModel{ID: userID}, o.Mapper), "FollowingCount", delta, requireActive)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	follower, err := o.Mapper.BuildQueryAddCounter(NewUserResource(&domain.UserModel{ID: targetUserID}, o.Mapper), "FollowerCount", delta, requireActive)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return []*dynamo.Update{following, follower}, nil
+}
+
+// getFollow フォロー関係のレコードを取得する
+func (o *FollowOperator) getFollow(table *dynamo.Table, userID, targetUserID uint64) (*FollowResource, error) {
+	var follow FollowResource
+	err := table.
+		Get(o.PKName, o.userPK(userID)).
+		Range(o.SKName, dynamo.Equal, o.followSK(targetUserID)).
+		Consistent(true).
+		One(&follow)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &follow, nil
+}
+
+// Follow フォローを登録する。フォロー関係のレコードと双方のユーザーの件数を1つのトランザクションで書き込む
+func (o *FollowOperator) Follow(userID, targetUserID uint64) error {
+	conn, err := o.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	now := time.Now()
+	follow := &FollowResource{
+		ResourceSchema: ResourceSchema{
+			PK:     o.userPK(userID),
+			SK:     o.followSK(targetUserID),
+			GSI1PK: followerIndexPK(targetUserID),
+			GSI1SK: fmt.Sprintf("%s#%s#%011d", followEntityName, now.UTC().Format(userIndexTimeFormat), userID),
+		},
+		UserID:       userID,
+		TargetUserID: targetUserID,
+		CreatedAt:    now,
+	}
+
+	counts, err := o.buildQueriesUpdateCount(userID, targetUserID, 1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeNotExists(o.PKName)
+
+	err = conn.WriteTx().
+		Put(table.Put(follow).If(fb.JoinAnd(), fb.Arg...)).
+		Update(counts[0]).
+		Update(counts[1]).
+		Run()
+	if err == nil {
+		return nil
+	}
+	if !dynamo.IsCondCheckFailed(err) {
+		return errors.WithStack(err)
+	}
+
+	// 条件の失敗は、すでにフォローしているか、どちらかのユーザーが存在しないことを表す
+	_, err = o.getFollow(table, userID, targetUserID)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return errors.WithStack(domain.ErrNotFound)
+		}
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// Unfollow フォローを解除する。フォロー関係のレコードの削除と双方のユーザーの件数を1つのトランザクションで書き込む。
+// 論理削除中のユーザーのフォローも解除できる
+func (o *FollowOperator) Unfollow(userID, targetUserID uint64) error {
+	conn, err := o.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	counts, err := o.buildQueriesUpdateCount(userID, targetUserID, -1, false)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeExists(o.PKName)
+
+	err = conn.WriteTx().
+		Delete(table.Delete(o.PKName, o.userPK(userID)).Range(o.SKName, o.followSK(targetUserID)).If(fb.JoinAnd(), fb.Arg...)).
+		Update(counts[0]).
+		Update(counts[1]).
+		Run()
+	if err == nil {
+		return nil
+	}
+	if !dynamo.IsCondCheckFailed(err) {
+		return errors.WithStack(err)
+	}
+
+	_, err = o.getFollow(table, userID, targetUserID)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return errors.WithStack(domain.ErrNotFound)
+		}
+		return errors.WithStack(err)
+	}
+
+	// フォローしているのに失敗した場合は、どちらかのユーザーが物理削除されている。
+	// 残っているユーザーの件数だけを減らし、フォロー関係のレコードを削除する
+	for _, q := range counts {
+		if err := q.Run(); err != nil && !dynamo.IsCondCheckFailed(err) {
+			return errors.WithStack(err)
+		}
+	}
+
+	err = table.Delete(o.PKName, o.userPK(userID)).Range(o.SKName, o.followSK(targetUserID)).Run()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// GetFollowing フォローしているユーザーをユーザーID順に取得する。続きがある場合は次のページのトークンも返す
+func (o *FollowOperator) GetFollowing(userID uint64, page *domain.Page) ([]*domain.FollowModel, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	startKey, err := DecodePagingKey(page.NextToken)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	var resources []FollowResource
+	lastKey, err := table.
+		Get(o.PKName, o.userPK(userID)).
+		Range(o.SKName, dynamo.BeginsWith, followEntityName+"#").
+		StartFrom(startKey).
+		Limit(int64(page.Limit)).
+		AllWithLastEvaluatedKey(&resources)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	return o.toModels(resources, lastKey)
+}
+
+// GetFollowers フォロワーをフォローされた時刻が新しい順に取得する。続きがある場合は次のページのトークンも返す
+func (o *FollowOperator) GetFollowers(userID uint64, page *domain.Page) ([]*domain.FollowModel, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	startKey, err := DecodePagingKey(page.NextToken)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	var resources []FollowResource
+	lastKey, err := table.
+		Get("GSI1PK", followerIndexPK(userID)).
+		Index(UserIndexName).
+		Order(dynamo.Descending).
+		StartFrom(startKey).
+		Limit(int64(page.Limit)).
+		AllWithLastEvaluatedKey(&resources)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	return o.toModels(resources, lastKey)
+}
+
+// toModels 取得したレコードをドメインモデルに変換し、次のページのトークンを生成する
+func (o *FollowOperator) toModels(resources []FollowResource, lastKey dynamo.PagingKey) ([]*domain.FollowModel, string, error) {
+	nextToken, err := EncodePagingKey(lastKey)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	follows := make([]*domain.FollowModel, len(resources))
+	for i := range resources {
+		follows[i] = resources[i].ToModel()
+	}
+
+	return follows, nextToken, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"clean-serverless-book-sample/adapter/controller"
 	"clean-serverless-book-sample/logger"
+	"clean-serverless-book-sample/registry"
 	"context"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -15,7 +16,7 @@ var ginLambda *ginadapter.GinLambda
 func init() {
 	log := logger.GetLogger()
 	log.Info("Gin Start")
-	r := controller.Routes()
+	r := controller.Routes(registry.GetFactory())
 	ginLambda = ginadapter.New(r)
 }
 
@@ -1,19 +1,150 @@
 package main
 
 import (
-	"fmt"
+	"clean-serverless-book-sample/adapter/importer"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/logger"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"context"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pkg/errors"
 )
 
+// objectCreatedEventPrefix オブジェクトの作成を表すイベント名の接頭辞。Put、Copy、マルチパートアップロードの完了などが続く
+const objectCreatedEventPrefix = "ObjectCreated:"
+
+// defaultImportLockDuration 実行期限がわからない場合に取り込みジョブを占有する時間。Lambdaのタイムアウトの上限に合わせる
+const defaultImportLockDuration = 15 * time.Minute
+
 // NOTE: S3イベントを受け取って処理するLambda関数を作成
 // NOTE: この関数が S3 イベントのトリガーを受けて実⾏され、引数 eventは events.S3Event 型で、S3 イベントの詳細情報を含んでいる
-func handler(event events.S3Event) error {
-	fmt.Printf("%+v", event)
+// NOTE: オブジェクトキーの接頭辞で処理を振り分け、エラーを返した場合はLambdaの非同期呼び出しとして再試行される
+func handler(ctx context.Context, event events.S3Event) error {
+	log := logger.GetLogger()
+	f := registry.GetFactory()
+
+	for _, record := range event.Records {
+		key := record.S3.Object.URLDecodedKey
+		log.Info("S3 event received", "event", record.EventName, "bucket", record.S3.Bucket.Name, "key", key)
+
+		if !strings.HasPrefix(record.EventName, objectCreatedEventPrefix) {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(key, domain.AttachmentKeyPrefix):
+			err := recordAttachment(f, key)
+			if err != nil {
+				return err
+			}
+		case strings.HasPrefix(key, domain.ProductImportKeyPrefix):
+			err := importProducts(ctx, f, key, record.S3.Object.ETag)
+			if err != nil {
+				return err
+			}
+		case strings.HasPrefix(key, domain.UserImportKeyPrefix):
+			err := importUsers(ctx, f, key, record.S3.Object.ETag)
+			if err != nil {
+				return err
+			}
+		default:
+			log.Info("No handler for the object key", "key", key)
+		}
+	}
+	return nil
+}
+
+// recordAttachment アップロードが完了した画像をマイクロポストに添付する。
+// マイクロポストが削除された場合や添付数の上限を超えた場合は、再試行しても添付できないためエラーにしない
+func recordAttachment(f *registry.Factory, key string) error {
+	log := logger.GetLogger()
+
+	res, err := f.BuildRecordAttachment().Execute(&usecase.RecordAttachmentRequest{Key: key})
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrTooManyAttachments) {
+			log.Warn("Attachment was not recorded", "key", key, "error", err)
+			return nil
+		}
+		log.Error("Failed to record attachment", "key", key, "error", err)
+		return err
+	}
+
+	log.Info("Attachment recorded", "micropostID", res.MicropostID, "key", key, "size", res.Attachment.Size)
+	return nil
+}
+
+// importProducts アップロードされたファイルから製品を一括で取り込む。
+// 取り込みジョブはLambdaの実行期限まで占有し、期限までに終わらない場合はエラーを返して再試行で続きから再開する
+func importProducts(ctx context.Context, f *registry.Factory, key, etag string) error {
+	log := logger.GetLogger()
+
+	res, err := f.BuildImportProducts().Execute(&usecase.ImportProductsRequest{
+		Key:         key,
+		ETag:        etag,
+		Decoder:     &importer.ProductImportDecoder{},
+		LockedUntil: importLockedUntil(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrImportInterrupted) {
+			log.Warn("Product import was interrupted and will be resumed", "key", key)
+			return err
+		}
+		log.Error("Failed to import products", "key", key, "error", err)
+		return err
+	}
+	if res.Skipped {
+		log.Info("Product import was skipped because it is completed or running", "key", key, "etag", etag)
+		return nil
+	}
+
+	log.Info("Products imported", "key", key, "jobID", res.Job.ID,
+		"processed", res.Job.Processed, "failed", res.Job.Failed, "report", res.Job.ReportKey)
 	return nil
 }
 
+// importUsers S3に置かれたファイルやAPIで受け付けたファイルから、ユーザーを一括で取り込む。
+// 製品と同じく、期限までに終わらない場合はエラーを返して再試行で続きから再開する
+func importUsers(ctx context.Context, f *registry.Factory, key, etag string) error {
+	log := logger.GetLogger()
+
+	res, err := f.BuildImportUsers().Execute(&usecase.ImportUsersRequest{
+		Key:         key,
+		ETag:        etag,
+		Decoder:     &importer.UserImportDecoder{},
+		LockedUntil: importLockedUntil(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrImportInterrupted) {
+			log.Warn("User import was interrupted and will be resumed", "key", key)
+			return err
+		}
+		log.Error("Failed to import users", "key", key, "error", err)
+		return err
+	}
+	if res.Skipped {
+		log.Info("User import was skipped because it is completed or running", "key", key, "etag", etag)
+		return nil
+	}
+
+	log.Info("Users imported", "key", key, "jobID", res.Job.ID,
+		"processed", res.Job.Processed, "failed", res.Job.Failed, "report", res.Job.ReportKey)
+	return nil
+}
+
+// importLockedUntil 取り込みジョブを占有する期限。Lambdaの実行期限に合わせ、わからない場合は既定の時間にする
+func importLockedUntil(ctx context.Context) time.Time {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return time.Now().Add(defaultImportLockDuration)
+	}
+	return deadline
+}
+
 func main() {
 	lambda.Start(handler)
 }
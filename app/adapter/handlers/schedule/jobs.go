@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// actionPurgeDeleted 保持期間を過ぎた論理削除済みリソースを物理削除する
+	actionPurgeDeleted = "purgeDeleted"
+	// actionCascadeDelete 削除したユーザーのマイクロポストのうち、削除しきれなかった分の削除を続ける
+	actionCascadeDelete = "cascadeDelete"
+	// actionExport 分析用にテーブルのスナップショットをS3に書き出す
+	actionExport = "export"
+	// actionReconcileCounters フォロー数・フォロワー数・いいね数・返信数を元のレコードから数え直す
+	actionReconcileCounters = "counters-reconcile"
+)
+
+// cascadeDeleteMaxJobs 1回の実行で処理する削除の継続ジョブの上限
+const cascadeDeleteMaxJobs = 5
+
+// exportScanSegments テーブルの書き出しで並列に走査するセグメントの数
+const exportScanSegments = 4
+
+// scheduledJob actionを指定して実行する定期処理
+type scheduledJob struct {
+	// timeout 1回の実行に許す時間。Lambdaの実行期限の方が早い場合はそちらで打ち切る
+	timeout time.Duration
+	// run paramsを解釈して処理を実行し、結果をログの属性として返す
+	run func(ctx context.Context, f *registry.Factory, params json.RawMessage) ([]any, error)
+}
+
+// newScheduledJob paramsを型Pとして受け取る処理を登録する。paramsを省略した場合はPのゼロ値を渡し、
+// 知らない項目がある場合はエラーにする
+func newScheduledJob[P any](timeout time.Duration, run func(ctx context.Context, f *registry.Factory, params *P) ([]any, error)) *scheduledJob {
+	return &scheduledJob{
+		timeout: timeout,
+		run: func(ctx context.Context, f *registry.Factory, raw json.RawMessage) ([]any, error) {
+			params := new(P)
+			if len(raw) > 0 && string(raw) != "null" {
+				decoder := json.NewDecoder(bytes.NewReader(raw))
+				decoder.DisallowUnknownFields()
+				if err := decoder.Decode(params); err != nil {
+					return nil, errors.Wrap(err, "invalid params")
+				}
+			}
+			return run(ctx, f, params)
+		},
+	}
+}
+
+// scheduledJobs actionごとの定期処理
+var scheduledJobs = map[string]*scheduledJob{
+	actionPurgeDeleted: newScheduledJob(10*time.Minute, purgeDeleted),
+	// NOTE: 5分ごとに実行するため、次の実行と重ならないようにする
+	actionCascadeDelete:     newScheduledJob(4*time.Minute, cascadeDelete),
+	actionExport:            newScheduledJob(14*time.Minute, export),
+	actionReconcileCounters: newScheduledJob(14*time.Minute, reconcileCounters),
+}
+
+// PurgeDeletedParams purgeDeletedのparams
+type PurgeDeletedParams struct {
+	// RetentionDays 論理削除したリソースを保持する日数。省略した場合は環境変数の設定を使う
+	RetentionDays *int `json:"retention_days"`
+}
+
+// purgeDeleted 保持期間を過ぎた論理削除済みのユーザーとマイクロポストを物理削除する
+func purgeDeleted(ctx context.Context, f *registry.Factory, params *PurgeDeletedParams) ([]any, error) {
+	retention := f.Envs.SoftDeleteRetention()
+	if params.RetentionDays != nil {
+		if *params.RetentionDays < 0 {
+			return nil, errors.Errorf("invalid params: retention_days must not be negative: %d", *params.RetentionDays)
+		}
+		retention = time.Duration(*params.RetentionDays) * 24 * time.Hour
+	}
+
+	res, err := f.BuildPurgeDeletedResources().Execute(ctx, &usecase.PurgeDeletedResourcesRequest{
+		DeletedBefore: time.Now().Add(-retention),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []any{"users", res.PurgedUsers, "microposts", res.PurgedMicroposts}, nil
+}
+
+// CascadeDeleteParams cascadeDeleteのparams
+type CascadeDeleteParams struct {
+	// MaxJobs 1回で処理する削除の継続ジョブの上限。省略した場合はcascadeDeleteMaxJobs
+	MaxJobs int `json:"max_jobs"`
+}
+
+// cascadeDelete 削除したユーザーのマイクロポストのうち、削除しきれなかった分の削除を続ける
+func cascadeDelete(ctx context.Context, f *registry.Factory, params *CascadeDeleteParams) ([]any, error) {
+	if params.MaxJobs < 0 {
+		return nil, errors.Errorf("invalid params: max_jobs must not be negative: %d", params.MaxJobs)
+	}
+	maxJobs := params.MaxJobs
+	if maxJobs == 0 {
+		maxJobs = cascadeDeleteMaxJobs
+	}
+
+	res, err := f.BuildContinueCascadeDelete().Execute(ctx, &usecase.ContinueCascadeDeleteRequest{
+		MaxJobs: maxJobs,
+	})
+	if res == nil {
+		return nil, err
+	}
+	// 一部のジョブが失敗した場合も、処理できた分を結果に残す
+	return []any{
+		"microposts", res.DeletedMicroposts,
+		"restoredMicroposts", res.RestoredMicroposts,
+		"completedJobs", res.CompletedJobs,
+		"pendingJobs", res.PendingJobs,
+		"failedJobs", res.FailedJobs,
+	}, err
+}
+
+// ExportParams exportのparams
+type ExportParams struct {
+	// Segments 並列に走査するセグメントの数。省略した場合はexportScanSegments
+	Segments int `json:"segments"`
+}
+
+// export 分析用にテーブルのスナップショットをS3に書き出す
+func export(ctx context.Context, f *registry.Factory, params *ExportParams) ([]any, error) {
+	if params.Segments < 0 {
+		return nil, errors.Errorf("invalid params: segments must not be negative: %d", params.Segments)
+	}
+	segments := params.Segments
+	if segments == 0 {
+		segments = exportScanSegments
+	}
+
+	res, err := f.BuildExportTable().Execute(ctx, &usecase.ExportTableRequest{
+		ExportedAt: time.Now(),
+		Segments:   segments,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []any{"prefix", res.Prefix, "manifest", res.ManifestKey, "total", res.Total, "counts", res.Counts}, nil
+}
+
+// ReconcileCountersParams counters-reconcileのparams
+type ReconcileCountersParams struct {
+	// DryRun 書き込まずに、書き直しが必要な件数だけを数える
+	DryRun bool `json:"dry_run"`
+}
+
+// reconcileCounters フォロー数・フォロワー数・いいね数・返信数を元のレコードから数え直す
+func reconcileCounters(ctx context.Context, f *registry.Factory, params *ReconcileCountersParams) ([]any, error) {
+	res, err := f.BuildReconcileCounters().Execute(ctx, &usecase.ReconcileCountersRequest{
+		DryRun: params.DryRun,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []any{
+		"dryRun", params.DryRun,
+		"scannedUsers", res.Users.Scanned,
+		"fixedUsers", res.Users.Fixed,
+		"conflictedUsers", res.Users.Conflicts,
+		"scannedMicroposts", res.Microposts.Scanned,
+		"fixedMicroposts", res.Microposts.Fixed,
+		"conflictedMicroposts", res.Microposts.Conflicts,
+	}, nil
+}
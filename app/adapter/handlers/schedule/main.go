@@ -1,21 +1,76 @@
 package main
 
 import (
-	"fmt"
+	"clean-serverless-book-sample/logger"
+	"clean-serverless-book-sample/registry"
+	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pkg/errors"
 )
 
+// EventRequest EventBridgeから渡されるペイロード。paramsの内容はactionごとに異なる
 type EventRequest struct {
-	Action string `json:"action"`
+	Action string          `json:"action"`
+	Params json.RawMessage `json:"params,omitempty"`
 }
 
-// NOTE: Lambda ハンドラー handler 関数で、EventBridge から渡されたイベントデータをログ出力
-func handler(event EventRequest) error {
-	fmt.Printf("%+v", event)
+// jobDeadlineMargin Lambdaの実行期限より前に処理を打ち切る余裕。失敗のログを出してエラーを返すために使う
+const jobDeadlineMargin = 10 * time.Second
+
+// NOTE: Lambda ハンドラー handler 関数で、EventBridge から渡されたactionに応じた定期処理を実行する
+// NOTE: エラーを返した場合はLambdaの非同期呼び出しとして再試行される
+func handler(ctx context.Context, event EventRequest) error {
+	return dispatch(ctx, scheduledJobs, registry.GetFactory(), event)
+}
+
+// dispatch actionに登録された処理を実行する。未登録のactionやparamsの誤りもエラーにして、設定の誤りに気付けるようにする
+func dispatch(ctx context.Context, jobs map[string]*scheduledJob, f *registry.Factory, event EventRequest) error {
+	log := logger.GetLogger().With("action", event.Action)
+
+	job, ok := jobs[event.Action]
+	if !ok {
+		log.Error("Unknown action")
+		return errors.Errorf("unknown action: %q", event.Action)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, jobDeadline(ctx, job.timeout))
+	defer cancel()
+
+	start := time.Now()
+	log.Info("Scheduled job started", "params", string(event.Params))
+
+	attrs, err := runJob(ctx, job, f, event.Params)
+	duration := time.Since(start)
+	if err != nil {
+		log.Error("Scheduled job failed", append([]any{"duration", duration.String(), "error", err}, attrs...)...)
+		return err
+	}
+
+	log.Info("Scheduled job finished", append([]any{"duration", duration.String()}, attrs...)...)
 	return nil
 }
 
+// jobDeadline 処理を打ち切る時刻。処理ごとのタイムアウトとLambdaの実行期限のうち早い方にする
+func jobDeadline(ctx context.Context, timeout time.Duration) time.Time {
+	deadline := time.Now().Add(timeout)
+	if lambdaDeadline, ok := ctx.Deadline(); ok && lambdaDeadline.Add(-jobDeadlineMargin).Before(deadline) {
+		return lambdaDeadline.Add(-jobDeadlineMargin)
+	}
+	return deadline
+}
+
+// runJob 処理を実行する。処理はctxの期限で打ち切るため、期限を過ぎて失敗した場合はタイムアウトのエラーにする
+func runJob(ctx context.Context, job *scheduledJob, f *registry.Factory, params json.RawMessage) ([]any, error) {
+	attrs, err := job.run(ctx, f, params)
+	if err != nil && ctx.Err() != nil {
+		return nil, errors.Wrap(ctx.Err(), "scheduled job timed out")
+	}
+	return attrs, err
+}
+
 func main() {
 	lambda.Start(handler)
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"clean-serverless-book-sample/registry"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testJobParams struct {
+	Count int `json:"count"`
+}
+
+func TestDispatch(t *testing.T) {
+	f := registry.NewFactory(registry.NewEnvs())
+
+	var received *testJobParams
+	jobs := map[string]*scheduledJob{
+		"test": newScheduledJob(time.Minute, func(ctx context.Context, f *registry.Factory, params *testJobParams) ([]any, error) {
+			received = params
+			return []any{"count", params.Count}, nil
+		}),
+		"slow": newScheduledJob(10*time.Millisecond, func(ctx context.Context, f *registry.Factory, params *testJobParams) ([]any, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+				return nil, nil
+			}
+		}),
+	}
+
+	t.Run("paramsを型に変換して渡す", func(t *testing.T) {
+		err := dispatch(context.Background(), jobs, f, EventRequest{Action: "test", Params: json.RawMessage(`{"count":3}`)})
+		require.NoError(t, err)
+		assert.Equal(t, 3, received.Count)
+	})
+
+	t.Run("paramsを省略した場合はゼロ値を渡す", func(t *testing.T) {
+		err := dispatch(context.Background(), jobs, f, EventRequest{Action: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, received.Count)
+	})
+
+	t.Run("知らない項目があるparamsはエラー", func(t *testing.T) {
+		err := dispatch(context.Background(), jobs, f, EventRequest{Action: "test", Params: json.RawMessage(`{"cnt":3}`)})
+		assert.ErrorContains(t, err, "invalid params")
+	})
+
+	t.Run("登録されていないactionはエラー", func(t *testing.T) {
+		err := dispatch(context.Background(), jobs, f, EventRequest{Action: "unknown"})
+		assert.ErrorContains(t, err, `unknown action: "unknown"`)
+
+		err = dispatch(context.Background(), jobs, f, EventRequest{})
+		assert.ErrorContains(t, err, "unknown action")
+	})
+
+	t.Run("タイムアウトを過ぎた場合はエラー", func(t *testing.T) {
+		err := dispatch(context.Background(), jobs, f, EventRequest{Action: "slow"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Lambdaの実行期限が早い場合はそれに合わせる", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), jobDeadlineMargin+time.Minute)
+		defer cancel()
+		lambdaDeadline, _ := ctx.Deadline()
+
+		assert.Equal(t, lambdaDeadline.Add(-jobDeadlineMargin), jobDeadline(ctx, time.Hour))
+		assert.True(t, jobDeadline(ctx, time.Second).Before(lambdaDeadline.Add(-jobDeadlineMargin)))
+	})
+}
+
+func TestScheduledJobs(t *testing.T) {
+	// NOTE: CDKのルールが指定しているactionが登録されていること
+	for _, action := range []string{actionPurgeDeleted, actionCascadeDelete, actionExport, actionReconcileCounters} {
+		assert.Contains(t, scheduledJobs, action)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/logger"
+	"clean-serverless-book-sample/registry"
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// NOTE: リソーステーブルのDynamoDBストリームを受け取り、変更をドメインイベントにして登録された処理に配信するLambda関数
+// NOTE: 失敗したレコードはBatchItemFailuresで返し、そのレコードから再試行させる。再試行し続けても失敗するレコードは、
+// NOTE: バッチの分割と再試行回数の上限によって取り除かれ、シャードの処理が止まらないようにしている(CDKのイベントソースの設定を参照)
+func handler(ctx context.Context, event events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
+	f := registry.GetFactory()
+	return processRecords(ctx, f.BuildDynamoStreamDecoder(), newResourceEventBus(), event.Records), nil
+}
+
+// processRecords レコードを順に配信する。同じシャードの変更の順序を保つため、失敗したレコードで処理を止め、
+// そのレコードを失敗として返す。以降のレコードは再試行の際に改めて配信する
+func processRecords(ctx context.Context, decoder *adapter.DynamoStreamDecoder, bus *domain.ResourceEventBus, records []events.DynamoDBEventRecord) events.DynamoDBEventResponse {
+	log := logger.GetLogger()
+
+	for n := range records {
+		record := &records[n]
+		event, err := decoder.Decode(record)
+		if err != nil {
+			log.Error("Failed to decode stream record", "eventID", record.EventID, "sequenceNumber", record.Change.SequenceNumber, "error", err)
+			return batchItemFailure(record)
+		}
+		if event == nil {
+			continue
+		}
+
+		if err := bus.Publish(ctx, event); err != nil {
+			log.Error("Failed to handle resource event", "event", event.EventName(), "eventID", record.EventID, "sequenceNumber", record.Change.SequenceNumber, "error", err)
+			return batchItemFailure(record)
+		}
+	}
+	return events.DynamoDBEventResponse{BatchItemFailures: []events.DynamoDBBatchItemFailure{}}
+}
+
+// batchItemFailure 指定したレコードから再試行させるレスポンス
+func batchItemFailure(record *events.DynamoDBEventRecord) events.DynamoDBEventResponse {
+	return events.DynamoDBEventResponse{
+		BatchItemFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: record.Change.SequenceNumber}},
+	}
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func userInsertRecord(id int, name string) events.DynamoDBEventRecord {
+	image := map[string]events.DynamoDBAttributeValue{
+		"PK":   events.NewStringAttribute(fmt.Sprintf("UserResource-%011d", id)),
+		"SK":   events.NewStringAttribute(fmt.Sprintf("%011d", id)),
+		"ID":   events.NewNumberAttribute(fmt.Sprint(id)),
+		"Name": events.NewStringAttribute(name),
+	}
+	return events.DynamoDBEventRecord{
+		EventID:   fmt.Sprintf("event-%d", id),
+		EventName: "INSERT",
+		Change: events.DynamoDBStreamRecord{
+			Keys:           map[string]events.DynamoDBAttributeValue{"PK": image["PK"], "SK": image["SK"]},
+			NewImage:       image,
+			SequenceNumber: fmt.Sprint(id),
+		},
+	}
+}
+
+func TestProcessRecords(t *testing.T) {
+	decoder := &adapter.DynamoStreamDecoder{Mapper: &adapter.DynamoModelMapper{PKName: "PK", SKName: "SK"}}
+
+	newBus := func(received *[]string) *domain.ResourceEventBus {
+		bus := domain.NewResourceEventBus()
+		domain.Subscribe(bus, func(ctx context.Context, event *domain.UserCreated) error {
+			if event.User.Name == "poison" {
+				return errors.New("failed")
+			}
+			*received = append(*received, event.User.Name)
+			return nil
+		})
+		return bus
+	}
+
+	t.Run("すべて配信できた場合は失敗を返さない", func(t *testing.T) {
+		var received []string
+		res := processRecords(context.Background(), decoder, newBus(&received), []events.DynamoDBEventRecord{
+			userInsertRecord(1, "alice"),
+			userInsertRecord(2, "bob"),
+		})
+		assert.Empty(t, res.BatchItemFailures)
+		assert.Equal(t, []string{"alice", "bob"}, received)
+	})
+
+	t.Run("配信に失敗したレコードで止め、そのレコードから再試行させる", func(t *testing.T) {
+		var received []string
+		res := processRecords(context.Background(), decoder, newBus(&received), []events.DynamoDBEventRecord{
+			userInsertRecord(1, "alice"),
+			userInsertRecord(2, "poison"),
+			userInsertRecord(3, "carol"),
+		})
+		assert.Equal(t, []events.DynamoDBBatchItemFailure{{ItemIdentifier: "2"}}, res.BatchItemFailures)
+		assert.Equal(t, []string{"alice"}, received)
+	})
+
+	t.Run("変換できないレコードも失敗として返す", func(t *testing.T) {
+		var received []string
+		broken := userInsertRecord(2, "bob")
+		broken.EventName = "MODIFY"
+		res := processRecords(context.Background(), decoder, newBus(&received), []events.DynamoDBEventRecord{
+			userInsertRecord(1, "alice"),
+			broken,
+		})
+		assert.Equal(t, []events.DynamoDBBatchItemFailure{{ItemIdentifier: "2"}}, res.BatchItemFailures)
+		assert.Equal(t, []string{"alice"}, received)
+	})
+}
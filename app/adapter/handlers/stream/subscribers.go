@@ -0,0 +1,21 @@
+package main
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/logger"
+	"context"
+)
+
+// newResourceEventBus ドメインイベントを受け取る処理を登録する。
+// 処理はドメインイベントの型ごとにdomain.Subscribeで登録する
+func newResourceEventBus() *domain.ResourceEventBus {
+	bus := domain.NewResourceEventBus()
+	bus.SubscribeAll(logResourceEvent)
+	return bus
+}
+
+// logResourceEvent 受け取ったイベントを記録する
+func logResourceEvent(_ context.Context, event domain.ResourceEvent) error {
+	logger.GetLogger().Info("Resource event received", "event", event.EventName(), "occurredAt", event.OccurredAt())
+	return nil
+}
@@ -0,0 +1,160 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/memememomo/nomof"
+	"github.com/pkg/errors"
+)
+
+// idempotencyKeyEntityName 冪等キーのレコードのSK。PKにも接頭辞として付けて他のレコードと衝突しないようにする
+const idempotencyKeyEntityName = "IdempotencyKey"
+
+// IdempotencyKeyResource 冪等キーのレコードを表した構造体。ExpiresAtはDynamoDBのTTLに使う
+type IdempotencyKeyResource struct {
+	PK           string    `dynamo:"PK"`
+	SK           string    `dynamo:"SK"`
+	RequestHash  string    `dynamo:"RequestHash"`
+	StatusCode   int       `dynamo:"StatusCode"`
+	ResponseBody string    `dynamo:"ResponseBody"`
+	ExpiresAt    time.Time `dynamo:"ExpiresAt,unixtime"`
+	LockedUntil  time.Time `dynamo:"LockedUntil,unixtime"`
+}
+
+// IdempotencyKeyOperator 冪等キーを操作する構造体
+type IdempotencyKeyOperator struct {
+	Client *ResourceTableOperator
+	PKName string
+	SKName string
+}
+
+// getPK 呼び出し元ごとに別のレコードになるよう、PKに呼び出し元を含める
+func (o *IdempotencyKeyOperator) getPK(owner, key string) string {
+	return fmt.Sprintf("%s-%s-%s", idempotencyKeyEntityName, owner, key)
+}
+
+func (o *IdempotencyKeyOperator) newResource(key *domain.IdempotencyKeyModel) *IdempotencyKeyResource {
+	return &IdempotencyKeyResource{
+		PK:           o.getPK(key.Owner, key.Key),
+		SK:           idempotencyKeyEntityName,
+		RequestHash:  key.RequestHash,
+		StatusCode:   key.StatusCode,
+		ResponseBody: key.ResponseBody,
+		ExpiresAt:    key.ExpiresAt,
+		LockedUntil:  key.LockedUntil,
+	}
+}
+
+// ReserveIdempotencyKey キーを処理中として登録する。
+// TTLによる削除はすぐには行われないため、有効期限切れのレコードは上書きできるようにしている。
+// 処理中のまま占有期限が過ぎたレコードも、同じリクエストであれば引き継げるよう上書きする
+func (o *IdempotencyKeyOperator) ReserveIdempotencyKey(key *domain.IdempotencyKeyModel) error {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	now := time.Now()
+	lease := nomof.NewBuilder()
+	lease.Equal("StatusCode", 0)
+	lease.Equal("RequestHash", key.RequestHash)
+	lease.Op("LockedUntil", nomof.LE, now.Unix())
+
+	fb := nomof.NewBuilder()
+	fb.AttributeNotExists(o.PKName)
+	fb.Op("ExpiresAt", nomof.LE, now.Unix())
+	fb.Append("("+lease.JoinAnd()+")", lease.Arg)
+
+	err = table.
+		Put(o.newResource(key)).
+		If(fb.JoinOr(), fb.Arg...).
+		Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	return nil
+}
+
+// GetIdempotencyKey 呼び出し元ownerのキーを取得する
+func (o *IdempotencyKeyOperator) GetIdempotencyKey(owner, key string) (*domain.IdempotencyKeyModel, error) {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var resource IdempotencyKeyResource
+	err = table.
+		Get(o.PKName, o.getPK(owner, key)).
+		Range(o.SKName, dynamo.Equal, idempotencyKeyEntityName).
+		One(&resource)
+	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	model := &domain.IdempotencyKeyModel{
+		Owner:        owner,
+		Key:          key,
+		RequestHash:  resource.RequestHash,
+		StatusCode:   resource.StatusCode,
+		ResponseBody: resource.ResponseBody,
+		ExpiresAt:    resource.ExpiresAt,
+		LockedUntil:  resource.LockedUntil,
+	}
+	if model.IsExpired(time.Now()) {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+
+	return model, nil
+}
+
+// CompleteIdempotencyKey レスポンスを保存して処理済みにする。登録した時の占有期限のままであることを条件にし、
+// 占有期限が過ぎて他のリクエストに引き継がれていた場合はdomain.ErrConflictを返す
+func (o *IdempotencyKeyOperator) CompleteIdempotencyKey(key *domain.IdempotencyKeyModel) error {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.Equal("LockedUntil", key.LockedUntil.Unix())
+
+	err = table.
+		Put(o.newResource(key)).
+		If(fb.JoinAnd(), fb.Arg...).
+		Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey キーを削除する。CompleteIdempotencyKeyと同じく登録した時の占有期限のままであることを条件にし、
+// 占有期限が過ぎて他のリクエストに引き継がれていた場合は削除せずにdomain.ErrConflictを返す
+func (o *IdempotencyKeyOperator) DeleteIdempotencyKey(key *domain.IdempotencyKeyModel) error {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.Equal("LockedUntil", key.LockedUntil.Unix())
+
+	err = table.
+		Delete(o.PKName, o.getPK(key.Owner, key.Key)).
+		Range(o.SKName, idempotencyKeyEntityName).
+		If(fb.JoinAnd(), fb.Arg...).
+		Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/memememomo/nomof"
+	"github.com/pkg/errors"
+)
+
+// importJobEntityName 取り込みジョブのレコードのSK。PKにも接頭辞として付けて他のレコードと衝突しないようにする
+const importJobEntityName = "ImportJob"
+
+// ImportJobResource 取り込みジョブのレコードを表した構造体
+type ImportJobResource struct {
+	PK          string    `dynamo:"PK"`
+	SK          string    `dynamo:"SK"`
+	Source      string    `dynamo:"Source"`
+	ETag        string    `dynamo:"ETag"`
+	Status      string    `dynamo:"Status"`
+	Processed   int       `dynamo:"Processed"`
+	Failed      int       `dynamo:"Failed"`
+	ReportKey   string    `dynamo:"ReportKey"`
+	LockedUntil time.Time `dynamo:"LockedUntil,unixtime"`
+	CreatedAt   time.Time `dynamo:"CreatedAt"`
+	UpdatedAt   time.Time `dynamo:"UpdatedAt"`
+}
+
+// ImportJobOperator 取り込みジョブを操作する構造体
+type ImportJobOperator struct {
+	Client *ResourceTableOperator
+	PKName string
+	SKName string
+}
+
+func (o *ImportJobOperator) getPK(id string) string {
+	return fmt.Sprintf("%s-%s", importJobEntityName, id)
+}
+
+func (o *ImportJobOperator) newResource(job *domain.ImportJobModel) *ImportJobResource {
+	return &ImportJobResource{
+		PK:          o.getPK(job.ID),
+		SK:          importJobEntityName,
+		Source:      job.Source,
+		ETag:        job.ETag,
+		Status:      job.Status,
+		Processed:   job.Processed,
+		Failed:      job.Failed,
+		ReportKey:   job.ReportKey,
+		LockedUntil: job.LockedUntil,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
+
+func (o *ImportJobOperator) toModel(id string, r *ImportJobResource) *domain.ImportJobModel {
+	return &domain.ImportJobModel{
+		ID:          id,
+		Source:      r.Source,
+		ETag:        r.ETag,
+		Status:      r.Status,
+		Processed:   r.Processed,
+		Failed:      r.Failed,
+		ReportKey:   r.ReportKey,
+		LockedUntil: r.LockedUntil,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// StartImportJob ジョブを開始する。同じIDのジョブが無ければ登録し、占有期限が過ぎた実行中のジョブがあれば、
+// 読み込んだ時の占有期限のままであることを条件に占有期限を書き換えて引き継ぐ
+func (o *ImportJobOperator) StartImportJob(job *domain.ImportJobModel) (*domain.ImportJobModel, error) {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	current, err := o.GetImportJob(job.ID)
+	if err != nil {
+		if err.Error() != domain.ErrNotFound.Error() {
+			return nil, errors.WithStack(err)
+		}
+
+		fb := nomof.NewBuilder()
+		fb.AttributeNotExists(o.PKName)
+		err = table.
+			Put(o.newResource(job)).
+			If(fb.JoinAnd(), fb.Arg...).
+			Run()
+		if err != nil {
+			return nil, errors.WithStack(ConvertConflictError(err))
+		}
+		return job, nil
+	}
+
+	if !current.CanTakeOver(time.Now()) {
+		return nil, errors.WithStack(domain.ErrConflict)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.Equal("Status", domain.ImportJobStatusRunning)
+	fb.Equal("LockedUntil", current.LockedUntil.Unix())
+	err = table.
+		Update(o.PKName, o.getPK(job.ID)).
+		Range(o.SKName, importJobEntityName).
+		Set("LockedUntil", job.LockedUntil.Unix()).
+		Set("UpdatedAt", job.UpdatedAt).
+		If(fb.JoinAnd(), fb.Arg...).
+		Run()
+	if err != nil {
+		return nil, errors.WithStack(ConvertConflictError(err))
+	}
+
+	current.LockedUntil = job.LockedUntil
+	current.UpdatedAt = job.UpdatedAt
+	return current, nil
+}
+
+// UpdateImportJob 進捗や状態を保存する。開始した時の占有期限のままであることを条件にし、
+// 他の実行に引き継がれていた場合はdomain.ErrConflictを返す
+func (o *ImportJobOperator) UpdateImportJob(job *domain.ImportJobModel) error {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.Equal("LockedUntil", job.LockedUntil.Unix())
+
+	err = table.
+		Put(o.newResource(job)).
+		If(fb.JoinAnd(), fb.Arg...).
+		Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	return nil
+}
+
+// GetImportJob ジョブを取得する
+func (o *ImportJobOperator) GetImportJob(id string) (*domain.ImportJobModel, error) {
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var resource ImportJobResource
+	err = table.
+		Get(o.PKName, o.getPK(id)).
+		Range(o.SKName, dynamo.Equal, importJobEntityName).
+		One(&resource)
+	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	return o.toModel(id, &resource), nil
+}
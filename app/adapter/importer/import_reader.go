@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"bufio"
+	"clean-serverless-book-sample/domain"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// importMaxLineBytes JSON Linesの1行の長さの上限
+const importMaxLineBytes = 1024 * 1024
+
+// importColumns 一括取り込みのファイルの列の定義
+type importColumns struct {
+	// all CSVの見出しに指定できる列
+	all []string
+	// required CSVの見出しに必ず指定する列
+	required []string
+	// numbers 数値の列。CSVは全て文字列として読み込むため、JSONと同じ検証ができるよう数値に変換する
+	numbers []string
+}
+
+// importRecord ファイルから読み込んだ1行の、列名ごとの値。CSVやJSONとして不正な行はerrorsを持つ
+type importRecord struct {
+	row    int
+	params map[string]interface{}
+	errors map[string]string
+}
+
+// importRecordReader 一括取り込みのファイルを1行ずつ読み込む。読み終わった場合はio.EOFを返す
+type importRecordReader interface {
+	next() (*importRecord, error)
+}
+
+// newImportRecordReader 拡張子が.csvの場合は見出しの行があるCSV、.jsonlか.ndjsonの場合はJSON Linesとして読み込む
+func newImportRecordReader(key string, r io.Reader, columns *importColumns) (importRecordReader, error) {
+	switch ext := strings.ToLower(path.Ext(key)); ext {
+	case ".csv":
+		return newCSVRecordReader(r, columns)
+	case ".jsonl", ".ndjson":
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), importMaxLineBytes)
+		return &jsonlRecordReader{scanner: scanner}, nil
+	default:
+		return nil, errors.Wrapf(domain.ErrInvalidImportFile, "対応していない拡張子です(%s)", ext)
+	}
+}
+
+// csvRecordReader 見出しの行で列名を指定したCSVを読み込む
+type csvRecordReader struct {
+	reader  *csv.Reader
+	header  []string
+	numbers []string
+	row     int
+}
+
+func newCSVRecordReader(r io.Reader, columns *importColumns) (*csvRecordReader, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.Wrap(domain.ErrInvalidImportFile, "見出しの行がありません")
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, errors.Wrap(domain.ErrInvalidImportFile, "見出しの行の形式が不正です")
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	for i, column := range header {
+		// Excelなどで保存したCSVは先頭にBOMが付くことがある
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !slices.Contains(columns.all, column) {
+			return nil, errors.Wrapf(domain.ErrInvalidImportFile, "不明な列です(%s)", column)
+		}
+		header[i] = column
+	}
+	for _, column := range columns.required {
+		if !slices.Contains(header, column) {
+			return nil, errors.Wrapf(domain.ErrInvalidImportFile, "必須の列がありません(%s)", column)
+		}
+	}
+
+	// 見出しと列の数が異なる行はエラーにする
+	reader.FieldsPerRecord = len(header)
+
+	return &csvRecordReader{reader: reader, header: header, numbers: columns.numbers}, nil
+}
+
+// next 次の行を読み込む。CSVとして不正な行は、その行のエラーとして返す
+func (c *csvRecordReader) next() (*importRecord, error) {
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	c.row++
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &importRecord{row: c.row, errors: map[string]string{"row": fmt.Sprintf("CSVの形式が不正です。(%s)", parseErr.Err)}}, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	params := map[string]interface{}{}
+	for i, column := range c.header {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+		if slices.Contains(c.numbers, column) {
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				params[column] = n
+				continue
+			}
+		}
+		params[column] = value
+	}
+
+	return &importRecord{row: c.row, params: params}, nil
+}
+
+// jsonlRecordReader 1行に1件をJSONで書いたファイルを読み込む。空行は読み飛ばす
+type jsonlRecordReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+// next 次の行を読み込む。JSONとして不正な行は、その行のエラーとして返す
+func (j *jsonlRecordReader) next() (*importRecord, error) {
+	for j.scanner.Scan() {
+		line := strings.TrimSpace(j.scanner.Text())
+		if line == "" {
+			continue
+		}
+		j.row++
+
+		var params map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &params); err != nil {
+			return &importRecord{row: j.row, errors: map[string]string{"row": "JSONの形式が不正です。"}}, nil
+		}
+		return &importRecord{row: j.row, params: params}, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return nil, io.EOF
+}
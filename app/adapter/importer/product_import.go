@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"clean-serverless-book-sample/adapter/validation"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"io"
+
+	"gopkg.in/validator.v2"
+)
+
+// productImportColumns 製品の一括取り込みの列。idは既存の製品を更新する場合だけ指定する
+var productImportColumns = &importColumns{
+	all:      []string{"id", "name", "price", "release_date"},
+	required: []string{"name", "price", "release_date"},
+	numbers:  []string{"id", "price"},
+}
+
+// ProductImportSettingValidator 取り込む行のバリデーション設定。製品APIと同じ設定に、更新する製品のIDを加える
+func ProductImportSettingValidator() *validation.Validator {
+	v := validation.ProductSettingValidator()
+	v.Settings = append(v.Settings, &validation.ValidatorSetting{ArgName: "id", ValidateTags: "int,uint"})
+	return v
+}
+
+// ProductImportDecoder 製品を一括で取り込むCSVとJSON Linesのファイルを読み込む。
+// 各行はProductImportSettingValidatorで検証し、エラーはAPIのレスポンスと同じメッセージにする
+type ProductImportDecoder struct{}
+
+// NewReader 拡張子が.csvの場合は見出しの行があるCSV、.jsonlか.ndjsonの場合はJSON Linesとして読み込む
+func (d *ProductImportDecoder) NewReader(key string, r io.Reader) (usecase.ProductImportReader, error) {
+	records, err := newImportRecordReader(key, r, productImportColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &productImportReader{records: records}, nil
+}
+
+// SaveErrorMessages 書き込みに失敗したエラーを、APIで同じエラーになった場合と同じ内容のメッセージにする
+func (d *ProductImportDecoder) SaveErrorMessages(err error) map[string]string {
+	switch err.Error() {
+	case domain.ErrNotFound.Error():
+		return validation.ConvertErrorsToMessage(map[string]error{"id": validation.ErrNotExist})
+	case domain.ErrConflict.Error():
+		return map[string]string{"id": "他の更新と競合しました。"}
+	default:
+		return map[string]string{"row": err.Error()}
+	}
+}
+
+// productImportReader 読み込んだ行を検証し、製品のモデルに変換する
+type productImportReader struct {
+	records importRecordReader
+}
+
+// Next 次の行を読み込む
+func (p *productImportReader) Next() (*usecase.ProductImportRow, error) {
+	record, err := p.records.next()
+	if err != nil {
+		return nil, err
+	}
+	if record.errors != nil {
+		return &usecase.ProductImportRow{Row: record.row, Errors: record.errors}, nil
+	}
+	return newProductImportRow(record.row, record.params), nil
+}
+
+// newProductImportRow 列名ごとの値を検証し、製品のモデルに変換する
+func newProductImportRow(row int, params map[string]interface{}) *usecase.ProductImportRow {
+	validErr := ProductImportSettingValidator().Validate(params)
+	if validErr != nil {
+		return &usecase.ProductImportRow{Row: row, Errors: validation.ConvertErrorsToMessage(validErr)}
+	}
+
+	// requiredは文字列以外の値も通すため、JSONで文字列以外が指定された場合はここで弾く
+	name, ok := params["name"].(string)
+	if !ok {
+		return &usecase.ProductImportRow{Row: row, Errors: validation.ConvertErrorsToMessage(map[string]error{"name": validator.ErrUnsupported})}
+	}
+	releaseDate, err := validation.ParseDate(params["release_date"].(string))
+	if err != nil {
+		return &usecase.ProductImportRow{Row: row, Errors: validation.ConvertErrorsToMessage(map[string]error{"release_date": validation.ErrDate})}
+	}
+
+	product := domain.NewProductModel(name, int(params["price"].(float64)), releaseDate)
+	if id, ok := params["id"].(float64); ok {
+		product.ID = uint64(id)
+	}
+
+	return &usecase.ProductImportRow{Row: row, Product: product}
+}
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/mocks/memory"
+	"clean-serverless-book-sample/registry"
+	"clean-serverless-book-sample/usecase"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupImportFactory メモリ上のリポジトリとフェイクのS3を使うFactoryを生成する
+func setupImportFactory() *registry.Factory {
+	f := registry.NewFactory(registry.NewEnvs())
+	f.ProductRepository = memory.NewProductRepository()
+	f.S3Client = mocks.NewFakeS3Client()
+	f.ImportJobRepository = memory.NewImportJobRepository()
+	return f
+}
+
+// TestImportProducts ファイルからの製品の一括取り込みと、失敗した行の報告
+func TestImportProducts(t *testing.T) {
+	const bucket = "test-bucket"
+	t.Setenv("S3_BUCKET_NAME", bucket)
+
+	releaseDate := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (*registry.Factory, *mocks.FakeS3Client) {
+		f := setupImportFactory()
+		_, err := f.ProductRepository.CreateProduct(domain.NewProductModel("既存の製品", 100, releaseDate))
+		require.NoError(t, err)
+		return f, f.S3Client.(*mocks.FakeS3Client)
+	}
+	execute := func(f *registry.Factory, key string, lockedUntil time.Time) (*usecase.ImportProductsResponse, error) {
+		return f.BuildImportProducts().Execute(&usecase.ImportProductsRequest{
+			Key:         key,
+			ETag:        "etag1",
+			Decoder:     &ProductImportDecoder{},
+			LockedUntil: lockedUntil,
+		})
+	}
+	t.Run("CSVの行を作成・更新し、失敗した行を報告する", func(t *testing.T) {
+		f, client := setup(t)
+		key := domain.ProductImportKeyPrefix + "products.csv"
+		client.PutTestObject(bucket, key, "text/csv", []byte(strings.Join([]string{
+			"\ufeffname,price,release_date,id",
+			"新製品,1000,2024-05-01,",
+			"既存の製品(更新),1500,2024-05-02,1",
+			"価格が不正,abc,2024-05-01,",
+			"存在しない製品,1000,2024-05-01,999",
+			"列が足りない,1000",
+			"",
+		}, "\n")))
+
+		res, err := execute(f, key, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.False(t, res.Skipped)
+		assert.True(t, res.Job.IsCompleted())
+		assert.Equal(t, 5, res.Job.Processed)
+		assert.Equal(t, 3, res.Job.Failed)
+
+		created, err := f.ProductRepository.GetProductByID(2)
+		require.NoError(t, err)
+		assert.Equal(t, "新製品", created.Name)
+		assert.Equal(t, 1000, created.Price)
+		updated, err := f.ProductRepository.GetProductByID(1)
+		require.NoError(t, err)
+		assert.Equal(t, "既存の製品(更新)", updated.Name)
+		assert.Equal(t, 1500, updated.Price)
+
+		report := client.ReadTestImportReport(t, bucket, res.Job.ReportKey)
+		assert.Len(t, report, 3)
+		assert.Equal(t, "価格は不正な値です。", report[3]["price"])
+		assert.Equal(t, "IDが存在しません。", report[4]["id"])
+		assert.Contains(t, report[5]["row"], "CSVの形式が不正です。")
+
+		// 同じ版のファイルのイベントが再び届いても取り込まない
+		res, err = execute(f, key, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, res.Skipped)
+		_, err = f.ProductRepository.GetProductByID(3)
+		assert.Error(t, err)
+	})
+
+	t.Run("JSON Linesの行を取り込む", func(t *testing.T) {
+		f, client := setup(t)
+		key := domain.ProductImportKeyPrefix + "products.jsonl"
+		client.PutTestObject(bucket, key, "application/x-ndjson", []byte(strings.Join([]string{
+			`{"name": "新製品", "price": 1000, "release_date": "2024-05-01"}`,
+			``,
+			`{"name": "新製品", "price": 1000`,
+			`{"name": 1, "price": 1000, "release_date": "2024-05-01"}`,
+			`{"price": 1000, "release_date": "2024-05-01"}`,
+		}, "\n")))
+
+		res, err := execute(f, key, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 4, res.Job.Processed)
+		assert.Equal(t, 3, res.Job.Failed)
+
+		report := client.ReadTestImportReport(t, bucket, res.Job.ReportKey)
+		assert.Equal(t, "JSONの形式が不正です。", report[2]["row"])
+		assert.NotEmpty(t, report[3]["name"])
+		assert.Equal(t, "名前を入力してください。", report[4]["name"])
+	})
+
+	t.Run("読み込めないファイルはファイル全体の誤りとして報告する", func(t *testing.T) {
+		f, client := setup(t)
+		for _, tc := range []struct {
+			name string
+			body string
+		}{
+			{"products.txt", "name,price,release_date\n"},
+			{"no_header.csv", ""},
+			{"unknown_column.csv", "name,price,release_date,color\n"},
+			{"missing_column.csv", "name,price\n"},
+		} {
+			key := domain.ProductImportKeyPrefix + tc.name
+			client.PutTestObject(bucket, key, "text/csv", []byte(tc.body))
+
+			res, err := execute(f, key, time.Now().Add(time.Minute))
+			require.NoError(t, err, tc.name)
+			assert.True(t, res.Job.IsCompleted(), tc.name)
+
+			report := client.ReadTestImportReport(t, bucket, res.Job.ReportKey)
+			assert.Contains(t, report[0]["file"], domain.ErrInvalidImportFile.Error(), tc.name)
+		}
+	})
+
+	t.Run("実行期限までに終わらない場合は中断し、再試行で続きから再開する", func(t *testing.T) {
+		f, client := setup(t)
+		key := domain.ProductImportKeyPrefix + "many.csv"
+		lines := []string{"name,price,release_date"}
+		for i := 1; i <= 30; i++ {
+			price := fmt.Sprint(i)
+			if i == 2 || i == 28 {
+				price = "-1"
+			}
+			lines = append(lines, fmt.Sprintf("製品%d,%s,2024-05-01", i, price))
+		}
+		client.PutTestObject(bucket, key, "text/csv", []byte(strings.Join(lines, "\n")))
+
+		// 既に期限を過ぎているため、最初のまとまりを書き込んだところで中断する
+		_, err := execute(f, key, time.Now())
+		assert.True(t, errors.Is(err, domain.ErrImportInterrupted))
+		job, err := f.BuildImportJobRepository().GetImportJob(domain.NewImportJobID(key, "etag1"))
+		require.NoError(t, err)
+		assert.Equal(t, 26, job.Processed)
+		assert.Equal(t, 1, job.Failed)
+
+		res, err := execute(f, key, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, res.Job.IsCompleted())
+		assert.Equal(t, 30, res.Job.Processed)
+		assert.Equal(t, 2, res.Job.Failed)
+
+		products, _, err := f.ProductRepository.GetProducts(domain.NewPage(domain.MaxPageLimit, ""))
+		require.NoError(t, err)
+		assert.Len(t, products, 29)
+
+		report := client.ReadTestImportReport(t, bucket, res.Job.ReportKey)
+		assert.Len(t, report, 2)
+		assert.Contains(t, report, 2)
+		assert.Contains(t, report, 28)
+	})
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"clean-serverless-book-sample/adapter/validation"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"errors"
+	"io"
+
+	"gopkg.in/validator.v2"
+)
+
+// userImportColumns ユーザーの一括取り込みの列。passwordは省略できる
+var userImportColumns = &importColumns{
+	all:      []string{"user_name", "email", "password"},
+	required: []string{"user_name", "email"},
+}
+
+// UserImportDecoder ユーザーを一括で取り込むCSVとJSON Linesのファイルを読み込む。
+// 各行はPostUserSettingValidatorで検証し、エラーはユーザー作成APIのレスポンスと同じメッセージにする
+type UserImportDecoder struct{}
+
+// NewReader 拡張子が.csvの場合は見出しの行があるCSV、.jsonlか.ndjsonの場合はJSON Linesとして読み込む
+func (d *UserImportDecoder) NewReader(key string, r io.Reader) (usecase.UserImportReader, error) {
+	records, err := newImportRecordReader(key, r, userImportColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &userImportReader{records: records}, nil
+}
+
+// SaveErrorMessages 作成できなかったエラーを、メールアドレスが重複した相手がわかるメッセージにする
+func (d *UserImportDecoder) SaveErrorMessages(err error) map[string]string {
+	switch {
+	case errors.Is(err, domain.ErrDuplicateEmail):
+		return validation.ConvertErrorsToMessage(map[string]error{"email": validation.ErrUniq})
+	case errors.Is(err, domain.ErrDuplicateEmailInFile):
+		return validation.ConvertErrorsToMessage(map[string]error{"email": validation.ErrUniqInFile})
+	default:
+		return map[string]string{"row": err.Error()}
+	}
+}
+
+// userImportReader 読み込んだ行を検証し、ユーザーのモデルに変換する
+type userImportReader struct {
+	records importRecordReader
+}
+
+// Next 次の行を読み込む
+func (u *userImportReader) Next() (*usecase.UserImportRow, error) {
+	record, err := u.records.next()
+	if err != nil {
+		return nil, err
+	}
+	if record.errors != nil {
+		return &usecase.UserImportRow{Row: record.row, Errors: record.errors}, nil
+	}
+	return newUserImportRow(record.row, record.params), nil
+}
+
+// newUserImportRow 列名ごとの値を検証し、ユーザーのモデルに変換する
+func newUserImportRow(row int, params map[string]interface{}) *usecase.UserImportRow {
+	validErr := validation.PostUserSettingValidator().Validate(params)
+	if validErr != nil {
+		return &usecase.UserImportRow{Row: row, Errors: validation.ConvertErrorsToMessage(validErr)}
+	}
+
+	// requiredは文字列以外の値も通すため、JSONで文字列以外が指定された場合はここで弾く
+	values := map[string]string{}
+	for _, column := range userImportColumns.all {
+		value, ok := params[column]
+		if !ok {
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			return &usecase.UserImportRow{Row: row, Errors: validation.ConvertErrorsToMessage(map[string]error{column: validator.ErrUnsupported})}
+		}
+		values[column] = s
+	}
+
+	return &usecase.UserImportRow{
+		Row:      row,
+		User:     domain.NewUserModel(values["user_name"], values["email"]),
+		Password: values["password"],
+	}
+}
@@ -0,0 +1,73 @@
+package adapter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// issuedJWTClaims 発行するトークンのペイロード
+type issuedJWTClaims struct {
+	Sub string `json:"sub"`
+	Iat int64  `json:"iat"`
+	Exp int64  `json:"exp"`
+}
+
+// JWTSigner ログインしたユーザーのトークンをHS256で署名して発行する。JWTVerifierと同じ共通鍵を使う
+type JWTSigner struct {
+	HMACSecret []byte
+	TTL        time.Duration
+	Now        func() time.Time
+}
+
+// NewJWTSigner JWTSignerのインスタンスを生成
+func NewJWTSigner(hmacSecret []byte, ttl time.Duration) *JWTSigner {
+	return &JWTSigner{
+		HMACSecret: hmacSecret,
+		TTL:        ttl,
+		Now:        time.Now,
+	}
+}
+
+// IssueToken subにユーザーIDを入れたトークンを発行し、有効期限と合わせて返す
+func (s *JWTSigner) IssueToken(userID uint64) (string, time.Time, error) {
+	if len(s.HMACSecret) == 0 {
+		return "", time.Time{}, errors.New("JWT HMAC secret is not set")
+	}
+
+	now := s.Now()
+	expiresAt := now.Add(s.TTL)
+
+	header, err := encodeJWTSegment(jwtHeader{Alg: "HS256"})
+	if err != nil {
+		return "", time.Time{}, errors.WithStack(err)
+	}
+	payload, err := encodeJWTSegment(issuedJWTClaims{
+		Sub: strconv.FormatUint(userID, 10),
+		Iat: now.Unix(),
+		Exp: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, errors.WithStack(err)
+	}
+
+	signingInput := header + "." + payload
+	mac := hmac.New(sha256.New, s.HMACSecret)
+	mac.Write([]byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), time.Unix(expiresAt.Unix(), 0), nil
+}
+
+// encodeJWTSegment 構造体をJSONにしてBase64URLエンコードする
+func encodeJWTSegment(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,145 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// jwtHeader JWTのヘッダーのうち検証に使う項目
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+// jwtClaims JWTのペイロードのうち検証に使う項目。subにはユーザーIDを10進数の文字列で入れる
+type jwtClaims struct {
+	Sub string `json:"sub"`
+	Exp *int64 `json:"exp"`
+	Nbf *int64 `json:"nbf"`
+}
+
+// JWTVerifier HS256またはRS256で署名されたJWTを検証する。
+// 鍵が設定されていないアルゴリズムのトークンと、algがnoneなど対応していないトークンは受け付けない
+type JWTVerifier struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	Now          func() time.Time
+}
+
+// NewJWTVerifier JWTVerifierのインスタンスを生成
+func NewJWTVerifier(hmacSecret []byte, rsaPublicKey *rsa.PublicKey) *JWTVerifier {
+	return &JWTVerifier{
+		HMACSecret:   hmacSecret,
+		RSAPublicKey: rsaPublicKey,
+		Now:          time.Now,
+	}
+}
+
+// ParseRSAPublicKeyPEM PEM形式のRSA公開鍵を読み込む。PKIX形式とPKCS#1形式に対応する
+func ParseRSAPublicKeyPEM(s string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("invalid PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA public key")
+	}
+
+	return rsaKey, nil
+}
+
+// VerifyToken 署名と有効期限を検証し、subのユーザーIDを返す。exp(有効期限)は必須
+func (v *JWTVerifier) VerifyToken(token string) (*domain.AuthClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	var header jwtHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	if !v.verifySignature(header.Alg, parts[0]+"."+parts[1], signature) {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	now := v.Now()
+	if claims.Exp == nil || !now.Before(time.Unix(*claims.Exp, 0)) {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+	if claims.Nbf != nil && now.Before(time.Unix(*claims.Nbf, 0)) {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	userID, err := strconv.ParseUint(claims.Sub, 10, 64)
+	if err != nil || userID == 0 {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	return &domain.AuthClaims{
+		UserID:    userID,
+		ExpiresAt: time.Unix(*claims.Exp, 0),
+	}, nil
+}
+
+// verifySignature ヘッダーのalgに応じて署名を検証する
+func (v *JWTVerifier) verifySignature(alg, signingInput string, signature []byte) bool {
+	switch alg {
+	case "HS256":
+		if len(v.HMACSecret) == 0 {
+			return false
+		}
+		mac := hmac.New(sha256.New, v.HMACSecret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(signature, mac.Sum(nil))
+	case "RS256":
+		if v.RSAPublicKey == nil {
+			return false
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(v.RSAPublicKey, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+// decodeJWTSegment Base64URLエンコードされたJSONを構造体に変換する
+func decodeJWTSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(json.Unmarshal(b, v))
+}
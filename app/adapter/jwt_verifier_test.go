@@ -0,0 +1,113 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub": "1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTVerifier_Valid(t *testing.T) {
+	keys := mocks.GetJWTTestKeys()
+	verifier := keys.Verifier()
+
+	for _, token := range []string{keys.SignHS256(validClaims()), keys.SignRS256(validClaims())} {
+		claims, err := verifier.VerifyToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), claims.UserID)
+	}
+
+	// PEMから読み込んだ公開鍵でも検証できる
+	publicKey, err := adapter.ParseRSAPublicKeyPEM(keys.PublicKeyPEM())
+	require.NoError(t, err)
+	_, err = adapter.NewJWTVerifier(nil, publicKey).VerifyToken(keys.SignRS256(validClaims()))
+	assert.NoError(t, err)
+}
+
+func TestJWTVerifier_Invalid(t *testing.T) {
+	keys := mocks.GetJWTTestKeys()
+	verifier := keys.Verifier()
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	notYetValid := validClaims()
+	notYetValid["nbf"] = time.Now().Add(time.Hour).Unix()
+
+	noExp := validClaims()
+	delete(noExp, "exp")
+
+	invalidSub := validClaims()
+	invalidSub["sub"] = "user-1"
+
+	tampered := keys.SignHS256(validClaims())
+	parts := strings.Split(tampered, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"2","exp":9999999999}`))
+	tampered = strings.Join(parts, ".")
+
+	none := strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1","exp":9999999999}`)),
+		"",
+	}, ".")
+
+	cases := map[string]string{
+		"期限切れ":      keys.SignHS256(expired),
+		"有効期間前":     keys.SignHS256(notYetValid),
+		"有効期限なし":    keys.SignHS256(noExp),
+		"subが数値でない": keys.SignHS256(invalidSub),
+		"改ざん":       tampered,
+		"alg=none":  none,
+		"形式不正":      "invalid",
+	}
+	for msg, token := range cases {
+		_, err := verifier.VerifyToken(token)
+		assert.Equal(t, domain.ErrUnauthorized, errors.Cause(err), msg)
+	}
+
+	// 鍵が設定されていないアルゴリズムは受け付けない
+	_, err := adapter.NewJWTVerifier(keys.HMACSecret, nil).VerifyToken(keys.SignRS256(validClaims()))
+	assert.Equal(t, domain.ErrUnauthorized, errors.Cause(err))
+	_, err = adapter.NewJWTVerifier(nil, &keys.RSAPrivateKey.PublicKey).VerifyToken(keys.SignHS256(validClaims()))
+	assert.Equal(t, domain.ErrUnauthorized, errors.Cause(err))
+}
+
+func TestJWTSigner(t *testing.T) {
+	keys := mocks.GetJWTTestKeys()
+	now := time.Now()
+	signer := adapter.NewJWTSigner(keys.HMACSecret, time.Hour)
+	signer.Now = func() time.Time { return now }
+
+	token, expiresAt, err := signer.IssueToken(42)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour).Unix(), expiresAt.Unix())
+
+	// 発行したトークンは同じ共通鍵で検証できる
+	claims, err := keys.Verifier().VerifyToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), claims.UserID)
+
+	// 有効期限を過ぎると検証できない
+	verifier := keys.Verifier()
+	verifier.Now = func() time.Time { return now.Add(time.Hour) }
+	_, err = verifier.VerifyToken(token)
+	assert.Equal(t, domain.ErrUnauthorized, errors.Cause(err))
+
+	// 共通鍵が設定されていない場合は発行しない
+	_, _, err = adapter.NewJWTSigner(nil, time.Hour).IssueToken(42)
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/memememomo/nomof"
+	"github.com/pkg/errors"
+)
+
+// likeEntityName いいねのレコードのSKの接頭辞。いいねされたマイクロポストのPKの下に保存する
+const likeEntityName = "Like"
+
+// LikeResource いいねのレコードを表した構造体。PK=マイクロポストのPK, SK=Like#いいねしたユーザーID とする
+type LikeResource struct {
+	ResourceSchema
+	MicropostID uint64    `dynamo:"MicropostID"`
+	UserID      uint64    `dynamo:"UserID"`
+	CreatedAt   time.Time `dynamo:"CreatedAt"`
+}
+
+// LikeOperator いいねを操作する構造体
+type LikeOperator struct {
+	Client *ResourceTableOperator
+	Mapper *DynamoModelMapper
+	PKName string
+	SKName string
+}
+
+func (o *LikeOperator) micropost(micropostID uint64) *MicropostResource {
+	return NewMicropostResource(&domain.MicropostModel{ID: micropostID}, o.Mapper)
+}
+
+func (o *LikeOperator) likeSK(userID uint64) string {
+	return fmt.Sprintf("%s#%011d", likeEntityName, userID)
+}
+
+// buildQueryDeleteLike いいねのレコードを削除するクエリを生成する
+func (o *LikeOperator) buildQueryDeleteLike(table *dynamo.Table, micropostID, userID uint64) *dynamo.Delete {
+	return table.
+		Delete(o.PKName, o.micropost(micropostID).PK()).
+		Range(o.SKName, o.likeSK(userID))
+}
+
+// existsLike いいねのレコードがあるかどうか
+func (o *LikeOperator) existsLike(table *dynamo.Table, micropostID, userID uint64) (bool, error) {
+	var like LikeResource
+	err := table.
+		Get(o.PKName, o.micropost(micropostID).PK()).
+		Range(o.SKName, dynamo.Equal, o.likeSK(userID)).
+		Consistent(true).
+		One(&like)
+	if err != nil {
+		if errors.Is(err, dynamo.ErrNotFound) {
+			return false, nil
+		}
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+// Like いいねを登録する。いいねのレコードとマイクロポストのいいね数を1つのトランザクションで書き込み、
+// いいねするユーザーが存在することも同じトランザクションで確認する
+func (o *LikeOperator) Like(micropostID, userID uint64) error {
+	conn, err := o.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	like := &LikeResource{
+		ResourceSchema: ResourceSchema{
+			PK: o.micropost(micropostID).PK(),
+			SK: o.likeSK(userID),
+		},
+		MicropostID: micropostID,
+		UserID:      userID,
+		CreatedAt:   time.Now(),
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeNotExists(o.PKName)
+
+	userExists, err := o.Mapper.BuildQueryCheckExists(NewUserResource(&domain.UserModel{ID: userID}, o.Mapper))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	likeCount, err := o.Mapper.BuildQueryAddCounter(o.micropost(micropostID), "LikeCount", 1, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = conn.WriteTx().
+		Put(table.Put(like).If(fb.JoinAnd(), fb.Arg...)).
+		Update(likeCount).
+		Check(userExists).
+		Run()
+	if err == nil {
+		return nil
+	}
+	if !dynamo.IsCondCheckFailed(err) {
+		return errors.WithStack(err)
+	}
+
+	// 条件の失敗は、すでにいいねしているか、マイクロポストかユーザーが存在しないことを表す
+	exists, err := o.existsLike(table, micropostID, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !exists {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Unlike いいねを取り消す。いいねのレコードの削除とマイクロポストのいいね数を1つのトランザクションで書き込む。
+// 論理削除中のマイクロポストのいいねも取り消せる
+func (o *LikeOperator) Unlike(micropostID, userID uint64) error {
+	conn, err := o.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	table, err := o.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	likeCount, err := o.Mapper.BuildQueryAddCounter(o.micropost(micropostID), "LikeCount", -1, false)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeExists(o.PKName)
+
+	err = conn.WriteTx().
+		Delete(o.buildQueryDeleteLike(table, micropostID, userID).If(fb.JoinAnd(), fb.Arg...)).
+		Update(likeCount).
+		Run()
+	if err == nil {
+		return nil
+	}
+	if !dynamo.IsCondCheckFailed(err) {
+		return errors.WithStack(err)
+	}
+
+	exists, err := o.existsLike(table, micropostID, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !exists {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+
+	// いいねしているのに失敗した場合は、マイクロポストが物理削除されている。いいねのレコードだけを削除する
+	err = o.buildQueryDeleteLike(table, micropostID, userID).Run()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
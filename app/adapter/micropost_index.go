@@ -0,0 +1,188 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/pkg/errors"
+)
+
+// ハッシュタグ・メンションの索引のPKの接頭辞
+const (
+	hashtagIndexPrefix = "Hashtag"
+	mentionIndexPrefix = "Mention"
+)
+
+// MicropostIndexResource ハッシュタグ・メンションからマイクロポストを引くための索引のレコードを表した構造体。
+// PK=Hashtag-タグ または Mention-ユーザーID, SK=投稿日時#マイクロポストID とし、PKごとに新しい順に辿る
+type MicropostIndexResource struct {
+	ResourceSchema
+	MicropostID uint64    `dynamo:"MicropostID"`
+	CreatedAt   time.Time `dynamo:"CreatedAt"`
+}
+
+func (m *MicropostOperator) hashtagIndexPK(tag string) string {
+	return fmt.Sprintf("%s-%s", hashtagIndexPrefix, tag)
+}
+
+func (m *MicropostOperator) mentionIndexPK(userID uint64) string {
+	return fmt.Sprintf("%s-%011d", mentionIndexPrefix, userID)
+}
+
+func (m *MicropostOperator) indexSK(micropost *domain.MicropostModel) string {
+	return fmt.Sprintf("%s#%011d", micropost.CreatedAt.UTC().Format(userIndexTimeFormat), micropost.ID)
+}
+
+// indexPKs マイクロポストの本文から、索引のPKを重複なく返す。
+// resolvedを指定した場合は、その中のユーザーへのメンションだけを含める。nilの場合は本文のメンションをすべて含める
+func (m *MicropostOperator) indexPKs(micropost *domain.MicropostModel, resolved map[uint64]bool) []string {
+	var pks []string
+	for _, tag := range micropost.Hashtags() {
+		pks = append(pks, m.hashtagIndexPK(tag))
+	}
+	for _, userID := range micropost.Mentions() {
+		if resolved != nil && !resolved[userID] {
+			continue
+		}
+		pks = append(pks, m.mentionIndexPK(userID))
+	}
+	return pks
+}
+
+// resolveMentions メンションされたユーザーのうち、存在して論理削除されていないユーザーのIDを返す
+func (m *MicropostOperator) resolveMentions(table *dynamo.Table, userIDs []uint64) (map[uint64]bool, error) {
+	resolved := map[uint64]bool{}
+	if len(userIDs) == 0 {
+		return resolved, nil
+	}
+
+	keys := make([]dynamo.Keyed, len(userIDs))
+	for i, userID := range userIDs {
+		user := NewUserResource(&domain.UserModel{ID: userID}, m.Mapper)
+		keys[i] = dynamo.Keys{user.PK(), user.SK()}
+	}
+
+	var users []UserResource
+	err := table.
+		Batch(m.Mapper.PKName, m.Mapper.SKName).
+		Get(keys...).
+		All(&users)
+	if err != nil && !errors.Is(err, dynamo.ErrNotFound) {
+		return nil, errors.WithStack(err)
+	}
+
+	for i := range users {
+		if users[i].DeletedAt().IsZero() {
+			resolved[users[i].UserModel.ID] = true
+		}
+	}
+	return resolved, nil
+}
+
+// addIndexQueries 更新前後の本文を比べ、増えた索引の登録と、なくなった索引の削除をトランザクションに追加する。
+// メンションは存在するユーザーへのものだけを登録する。
+// 新規作成の場合はoldにnilを指定する。作成日時とIDが確定した後に呼び出す
+func (m *MicropostOperator) addIndexQueries(tx *dynamo.WriteTx, table *dynamo.Table, old, micropost *domain.MicropostModel) error {
+	resolved, err := m.resolveMentions(table, micropost.Mentions())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// 登録した時には存在しなかったユーザーへのメンションも含めて削除する。索引が無い場合も削除は失敗しない
+	oldPKs := map[string]bool{}
+	if old != nil {
+		for _, pk := range m.indexPKs(old, nil) {
+			oldPKs[pk] = true
+		}
+	}
+
+	sk := m.indexSK(micropost)
+	for _, pk := range m.indexPKs(micropost, resolved) {
+		if oldPKs[pk] {
+			delete(oldPKs, pk)
+			continue
+		}
+		tx.Put(table.Put(&MicropostIndexResource{
+			ResourceSchema: ResourceSchema{PK: pk, SK: sk},
+			MicropostID:    micropost.ID,
+			CreatedAt:      micropost.CreatedAt,
+		}))
+	}
+	for pk := range oldPKs {
+		tx.Delete(table.Delete(m.Mapper.PKName, pk).Range(m.Mapper.SKName, sk))
+	}
+	return nil
+}
+
+// addIndexDeleteQueries マイクロポストの索引をすべて削除するクエリをトランザクションに追加する。
+// 論理削除と同じトランザクションで書き込み、削除したマイクロポストの索引が残らないようにする
+func (m *MicropostOperator) addIndexDeleteQueries(tx *dynamo.WriteTx, table *dynamo.Table, micropost *domain.MicropostModel) {
+	sk := m.indexSK(micropost)
+	for _, pk := range m.indexPKs(micropost, nil) {
+		tx.Delete(table.Delete(m.Mapper.PKName, pk).Range(m.Mapper.SKName, sk))
+	}
+}
+
+// GetMicropostsByHashtag 指定されたハッシュタグを含むマイクロポストを新しい順に取得する。続きがある場合は次のページのトークンも返す
+func (m *MicropostOperator) GetMicropostsByHashtag(tag string, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	microposts, nextToken, err := m.queryIndex(m.hashtagIndexPK(tag), page)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	return microposts, nextToken, nil
+}
+
+// GetMicropostsMentioning 指定されたユーザーをメンションしているマイクロポストを新しい順に取得する。続きがある場合は次のページのトークンも返す
+func (m *MicropostOperator) GetMicropostsMentioning(userID uint64, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	microposts, nextToken, err := m.queryIndex(m.mentionIndexPK(userID), page)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	return microposts, nextToken, nil
+}
+
+// queryIndex 索引を新しい順に辿り、マイクロポストをまとめて取得する。論理削除されたものは含まない
+func (m *MicropostOperator) queryIndex(pk string, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	startKey, err := DecodePagingKey(page.NextToken)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	var indexResources []MicropostIndexResource
+	lastKey, err := table.
+		Get(m.Mapper.PKName, pk).
+		Order(dynamo.Descending).
+		StartFrom(startKey).
+		Limit(int64(page.Limit)).
+		AllWithLastEvaluatedKey(&indexResources)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	nextToken, err := EncodePagingKey(lastKey)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	ids := make([]uint64, len(indexResources))
+	for i := range indexResources {
+		ids[i] = indexResources[i].MicropostID
+	}
+	microposts, err := m.getMicropostsByIDs(table, ids)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	return microposts, nextToken, nil
+}
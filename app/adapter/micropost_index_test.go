@@ -0,0 +1,71 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/registry"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countIndexItems 索引のPKの下に保存されているレコードの件数
+func countIndexItems(t *testing.T, pk string) int64 {
+	f := registry.GetFactory()
+	table, err := f.BuildResourceTableOperator().ConnectTable()
+	require.NoError(t, err)
+
+	count, err := table.Get(f.BuildDynamoModelMapper().PKName, pk).Count()
+	require.NoError(t, err)
+	return count
+}
+
+// TestMicropostOperator_index 論理削除したマイクロポストの索引は削除し、ユーザーと一緒に復元したものは登録し直す
+func TestMicropostOperator_index(t *testing.T) {
+	// テスト用のローカルDynamoDBを作成・接続
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	user, err := tables.UserOperator.CreateUser(domain.NewUserModel("テスト", "test@example.com"))
+	require.NoError(t, err)
+
+	mentionPK := fmt.Sprintf("Mention-%011d", user.ID)
+
+	// 存在しないユーザーへのメンションは登録しない
+	content := fmt.Sprintf("#go @%d @999", user.ID)
+	post, err := tables.MicropostOperator.CreateMicropost(domain.NewMicropostModel(content, user.ID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), countIndexItems(t, "Hashtag-go"))
+	assert.Equal(t, int64(1), countIndexItems(t, mentionPK))
+	assert.Equal(t, int64(0), countIndexItems(t, fmt.Sprintf("Mention-%011d", 999)))
+
+	// 個別に削除した場合
+	require.NoError(t, tables.MicropostOperator.DeleteMicropost(post))
+	assert.Equal(t, int64(0), countIndexItems(t, "Hashtag-go"))
+	assert.Equal(t, int64(0), countIndexItems(t, mentionPK))
+
+	// ユーザーと一緒に削除した場合
+	_, err = tables.MicropostOperator.CreateMicropost(domain.NewMicropostModel("#go", user.ID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), countIndexItems(t, "Hashtag-go"))
+
+	require.NoError(t, tables.UserOperator.DeleteUser(user))
+	deleted, remaining, err := tables.MicropostOperator.DeleteMicropostsByUserID(user.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.False(t, remaining)
+	assert.Equal(t, int64(0), countIndexItems(t, "Hashtag-go"))
+
+	// ユーザーと一緒に復元すると、ハッシュタグから再び取得できる
+	_, err = tables.UserOperator.RestoreUser(user.ID)
+	require.NoError(t, err)
+	restored, _, err := tables.MicropostOperator.RestoreMicropostsByUserID(user.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	tagged, _, err := tables.MicropostOperator.GetMicropostsByHashtag("go", nil)
+	require.NoError(t, err)
+	assert.Len(t, tagged, 1)
+}
@@ -2,18 +2,57 @@ package adapter
 
 import (
 	"clean-serverless-book-sample/domain"
+	"context"
+	"fmt"
+	"time"
 
 	"github.com/guregu/dynamo"
 	"github.com/memememomo/nomof"
 	"github.com/pkg/errors"
 )
 
+// cascadeDeleteBatchSize ユーザー削除に伴ってマイクロポストを論理削除する際に、1回の書き込みでまとめる件数
+const cascadeDeleteBatchSize = 25
+
+// maxTransactionItems DynamoDBの1回のトランザクションで書き込める件数の上限
+const maxTransactionItems = 100
+
+// replyEntityName 返信のレコードのSKの接頭辞。返信先のマイクロポストのPKの下に保存する
+const replyEntityName = "Reply"
+
+// ReplyResource 返信のレコードを表した構造体。PK=返信先のマイクロポストのPK, SK=Reply#投稿日時#返信のマイクロポストID とし、
+// 返信先から返信を投稿日時順に辿るために使う
+type ReplyResource struct {
+	ResourceSchema
+	MicropostID uint64    `dynamo:"MicropostID"`
+	InReplyToID uint64    `dynamo:"InReplyToID"`
+	CreatedAt   time.Time `dynamo:"CreatedAt"`
+}
+
 // MicropostOperator マイクロポストを操作する構造体
 type MicropostOperator struct {
 	Client *ResourceTableOperator
 	Mapper *DynamoModelMapper
 }
 
+// micropost IDだけを指定したマイクロポストのリソースを生成する。キーの組み立てに使う
+func (m *MicropostOperator) micropost(id uint64) *MicropostResource {
+	return NewMicropostResource(&domain.MicropostModel{ID: id}, m.Mapper)
+}
+
+// newReplyResource 返信のマイクロポストから返信のレコードを生成する。作成日時とIDが確定した後に呼び出す
+func (m *MicropostOperator) newReplyResource(reply *MicropostResource) *ReplyResource {
+	return &ReplyResource{
+		ResourceSchema: ResourceSchema{
+			PK: m.micropost(reply.MicropostModel.InReplyToID).PK(),
+			SK: fmt.Sprintf("%s#%s#%011d", replyEntityName, reply.CreatedAt().UTC().Format(userIndexTimeFormat), reply.ID()),
+		},
+		MicropostID: reply.ID(),
+		InReplyToID: reply.MicropostModel.InReplyToID,
+		CreatedAt:   reply.CreatedAt(),
+	}
+}
+
 func (m *MicropostOperator) getMicropostResourceByID(id uint64) (*MicropostResource, error) {
 	var micropostResource MicropostResource
 	_, err := m.Mapper.GetEntityByID(id, &MicropostResource{}, &micropostResource)
@@ -32,74 +71,698 @@ func (m *MicropostOperator) GetMicropostByID(id uint64) (*domain.MicropostModel,
 		}
 		return nil, errors.WithStack(err)
 	}
-	return &micropostResource.MicropostModel, nil
+	return micropostResource.ToModel(), nil
+}
+
+// GetMicropostsByUserID 指定されたユーザーIDに紐づいているマイクロポスト一覧を新しい順に取得する。続きがある場合は次のページのトークンも返す
+func (m *MicropostOperator) GetMicropostsByUserID(userID uint64, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
+	var micropostResource []MicropostResource
+	nextToken, err := m.Mapper.QueryByUserIndex(
+		userID,
+		m.Mapper.GetEntityNameFromStruct(MicropostResource{}),
+		page,
+		&micropostResource)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	var microposts = make([]*domain.MicropostModel, len(micropostResource))
+	for i := range micropostResource {
+		microposts[i] = micropostResource[i].ToModel()
+	}
+
+	return microposts, nextToken, nil
 }
 
-// GetMicropostsByUserID 指定されたユーザーIDに紐づいているマイクロポスト一覧を取得する
-func (m *MicropostOperator) GetMicropostsByUserID(userID uint64) ([]*domain.MicropostModel, error) {
+// GetMicropostsByUserIDBefore 指定されたユーザーのマイクロポストのうち、カーソルの位置より古いものを新しい順に最大limit件取得する
+func (m *MicropostOperator) GetMicropostsByUserIDBefore(userID uint64, before *domain.MicropostCursor, limit int) ([]*domain.MicropostModel, error) {
 	table, err := m.Client.ConnectTable()
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	entityName := m.Mapper.GetEntityNameFromStruct(MicropostResource{})
+
 	fb := nomof.NewBuilder()
-	fb.Equal("UserID", userID)
-	fb.BeginsWith("PK", m.Mapper.GetEntityNameFromStruct(MicropostResource{}))
+	fb.AttributeNotExists(deletedAtAttr)
 
-	var micropostResource []MicropostResource
-	err = table.Scan().Filter(fb.JoinAnd(), fb.Arg...).All(&micropostResource)
+	query := table.
+		Get("GSI1PK", m.Mapper.GetUserIndexPK(userID)).
+		Index(UserIndexName)
+	if before == nil {
+		query = query.Range("GSI1SK", dynamo.BeginsWith, entityName+"#")
+	} else {
+		// BETWEENは上限を含むため、カーソルの位置のマイクロポストの分を1件多く取得して取り除く
+		query = query.Range("GSI1SK", dynamo.Between,
+			entityName+"#",
+			m.Mapper.GetUserIndexSKAt(entityName, before.CreatedAt, before.ID))
+		limit++
+	}
+
+	var micropostResources []MicropostResource
+	err = query.
+		Filter(fb.JoinAnd(), fb.Arg...).
+		Order(dynamo.Descending).
+		Limit(int64(limit)).
+		All(&micropostResources)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	var microposts = make([]*domain.MicropostModel, len(micropostResource))
-	for i := range micropostResource {
-		microposts[i] = &micropostResource[i].MicropostModel
+	microposts := make([]*domain.MicropostModel, 0, len(micropostResources))
+	for i := range micropostResources {
+		micropost := micropostResources[i].ToModel()
+		if before != nil && !before.IsBefore(micropost) {
+			continue
+		}
+		microposts = append(microposts, micropost)
+	}
+	if before != nil && len(microposts) == limit {
+		microposts = microposts[:limit-1]
 	}
 
 	return microposts, nil
 }
 
-// DeleteMicropost 指定されたIDのマイクロポストを削除する
-func (m *MicropostOperator) DeleteMicropost(id uint64) error {
-	micropost, err := m.getMicropostResourceByID(id)
+// getMicropostsByIDs 指定したIDのマイクロポストをまとめて取得し、IDの順に並べて返す。存在しないものと論理削除されたものは含まない
+func (m *MicropostOperator) getMicropostsByIDs(table *dynamo.Table, ids []uint64) ([]*domain.MicropostModel, error) {
+	if len(ids) == 0 {
+		return []*domain.MicropostModel{}, nil
+	}
+
+	keys := make([]dynamo.Keyed, len(ids))
+	for i, id := range ids {
+		micropost := m.micropost(id)
+		keys[i] = dynamo.Keys{micropost.PK(), micropost.SK()}
+	}
+
+	var micropostResources []MicropostResource
+	err := table.
+		Batch(m.Mapper.PKName, m.Mapper.SKName).
+		Get(keys...).
+		All(&micropostResources)
+	if err != nil && !errors.Is(err, dynamo.ErrNotFound) {
+		return nil, errors.WithStack(err)
+	}
+
+	// まとめて取得した結果は順序が保証されないため、指定したIDの順に並べ直す
+	byID := make(map[uint64]*MicropostResource, len(micropostResources))
+	for i := range micropostResources {
+		byID[micropostResources[i].ID()] = &micropostResources[i]
+	}
+
+	microposts := make([]*domain.MicropostModel, 0, len(ids))
+	for _, id := range ids {
+		micropost, ok := byID[id]
+		if !ok || !micropost.DeletedAt().IsZero() {
+			continue
+		}
+		microposts = append(microposts, micropost.ToModel())
+	}
+
+	return microposts, nil
+}
+
+// GetRepliesByMicropostID 指定されたマイクロポストへの返信を古い順に取得する。続きがある場合は次のページのトークンも返す。
+// 返信のレコードを辿ってマイクロポストをまとめて取得し、論理削除されたものは含まない
+func (m *MicropostOperator) GetRepliesByMicropostID(micropostID uint64, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	startKey, err := DecodePagingKey(page.NextToken)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	var replyResources []ReplyResource
+	lastKey, err := table.
+		Get(m.Mapper.PKName, m.micropost(micropostID).PK()).
+		Range(m.Mapper.SKName, dynamo.BeginsWith, replyEntityName+"#").
+		StartFrom(startKey).
+		Limit(int64(page.Limit)).
+		AllWithLastEvaluatedKey(&replyResources)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	nextToken, err := EncodePagingKey(lastKey)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	ids := make([]uint64, len(replyResources))
+	for i := range replyResources {
+		ids[i] = replyResources[i].MicropostID
+	}
+	microposts, err := m.getMicropostsByIDs(table, ids)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	return microposts, nextToken, nil
+}
+
+// DeleteMicropost 指定されたマイクロポストを論理削除する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す。
+// ハッシュタグ・メンションの索引の削除と、返信の場合は返信先の返信数の減算を同じトランザクションで書き込む。返信されている場合は、削除後に返信をOrphanedにする
+func (m *MicropostOperator) DeleteMicropost(micropostModel *domain.MicropostModel) error {
+	return retryOnConflict(writeConflictAttempts, func() error {
+		return m.deleteMicropost(micropostModel)
+	})
+}
+
+func (m *MicropostOperator) deleteMicropost(micropostModel *domain.MicropostModel) error {
+	micropost, err := m.getMicropostResourceByID(micropostModel.ID)
 	if err != nil {
 		if err.Error() == dynamo.ErrNotFound.Error() {
 			return errors.WithStack(domain.ErrNotFound)
 		}
 		return errors.WithStack(err)
 	}
+	if micropostModel.Version != 0 {
+		micropost.SetVersion(micropostModel.Version)
+	}
+
+	if micropost.MicropostModel.IsReply() {
+		err = m.softDeleteReply(micropost)
+	} else {
+		err = m.softDeleteMicropost(micropost)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = m.orphanReplies(micropost)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
 
-	err = m.Mapper.DeleteResource(micropost)
+// softDeleteMicropost マイクロポストを論理削除し、同じトランザクションでハッシュタグ・メンションの索引を削除する
+func (m *MicropostOperator) softDeleteMicropost(micropost *MicropostResource) error {
+	conn, err := m.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	table, err := m.Client.ConnectTable()
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	query, err := m.Mapper.BuildQuerySoftDelete(micropost)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	tx := conn.WriteTx().Put(query)
+	m.addIndexDeleteQueries(tx, table, micropost.ToModel())
+
+	err = tx.Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
 	return nil
 }
 
-// CreateMicropost 新規作成する
+// softDeleteReply 返信を論理削除し、同じトランザクションで返信先の返信数を減らして索引を削除する。
+// 返信先がすでに物理削除されている場合は、返信と索引だけを削除する
+func (m *MicropostOperator) softDeleteReply(reply *MicropostResource) error {
+	conn, err := m.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// トランザクションが失敗した場合に返信だけを削除し直せるよう、コピーに対してクエリを組み立てる
+	r := *reply
+	query, err := m.Mapper.BuildQuerySoftDelete(&r)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	replyCount, err := m.Mapper.BuildQueryAddCounter(m.micropost(reply.MicropostModel.InReplyToID), "ReplyCount", -1, false)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// 1番目は返信のバージョンの条件、2番目は返信先が存在することの条件
+	tx := conn.WriteTx().
+		Put(query).
+		Update(replyCount)
+	m.addIndexDeleteQueries(tx, table, reply.ToModel())
+
+	err = tx.Run()
+	if err == nil {
+		return nil
+	}
+	if !isTxCondCheckFailedAt(err, 1) || isTxCondCheckFailedAt(err, 0) {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	err = m.softDeleteMicropost(reply)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// setRepliesOrphaned 指定されたマイクロポストへの返信に、返信先が削除されたかどうかの印を設定する。
+// 件数と同じくシステムが更新する属性のため、バージョンは上げない。物理削除された返信は無視する
+func (m *MicropostOperator) setRepliesOrphaned(micropostID uint64, orphaned bool) error {
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	iter := table.
+		Get(m.Mapper.PKName, m.micropost(micropostID).PK()).
+		Range(m.Mapper.SKName, dynamo.BeginsWith, replyEntityName+"#").
+		Iter()
+
+	var replyResource ReplyResource
+	for iter.Next(&replyResource) {
+		fb := nomof.NewBuilder()
+		fb.AttributeExists(m.Mapper.PKName)
+
+		reply := m.micropost(replyResource.MicropostID)
+		err := table.
+			Update(m.Mapper.PKName, reply.PK()).
+			Range(m.Mapper.SKName, reply.SK()).
+			Set("Orphaned", orphaned).
+			Set("UpdatedAt", time.Now()).
+			If(fb.JoinAnd(), fb.Arg...).
+			Run()
+		if err != nil && !dynamo.IsCondCheckFailed(err) {
+			return errors.WithStack(err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// CreateMicropost 新規作成する。投稿するユーザーが存在することを条件に書き込み、存在しない場合はdomain.ErrNotFoundを返す。
+// 返信の場合は、返信のレコードと返信先の返信数も同じトランザクションで書き込み、返信先が存在しない場合はdomain.ErrInReplyToNotFoundを返す。
+// ハッシュタグとメンションの索引も同じトランザクションで書き込む
 func (m *MicropostOperator) CreateMicropost(micropostModel *domain.MicropostModel) (*domain.MicropostModel, error) {
+	conn, err := m.Client.ConnectDB()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	micropostResource := NewMicropostResource(micropostModel, m.Mapper)
-	err := m.Mapper.PutResource(micropostResource)
+	r, err := m.Mapper.BuildQueryCreate(micropostResource)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &micropostResource.MicropostModel, nil
+
+	user := NewUserResource(&domain.UserModel{ID: micropostModel.UserID}, m.Mapper)
+	userExists, err := m.Mapper.BuildQueryCheckExists(user)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	tx := conn.WriteTx().Put(r).Check(userExists)
+	if micropostModel.IsReply() {
+		replyCount, err := m.Mapper.BuildQueryAddCounter(m.micropost(micropostModel.InReplyToID), "ReplyCount", 1, true)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		tx.
+			Update(replyCount).
+			Put(table.Put(m.newReplyResource(micropostResource)))
+	}
+	err = m.addIndexQueries(tx, table, nil, micropostResource.ToModel())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// マイクロポストのPKは採番したばかりで重複しないため、2番目の条件の失敗はユーザーが、3番目は返信先が存在しないことを表す
+	err = tx.Run()
+	if err != nil {
+		if isTxCondCheckFailedAt(err, 2) {
+			return nil, errors.WithStack(domain.ErrInReplyToNotFound)
+		}
+		if dynamo.IsCondCheckFailed(err) {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	return micropostResource.ToModel(), nil
 }
 
-// UpdateMicropost 更新する
+// UpdateMicropost 更新する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す。
+// 本文の変更で増えたハッシュタグ・メンションの索引の登録と、なくなったものの削除を同じトランザクションで書き込む
 func (m *MicropostOperator) UpdateMicropost(micropostModel *domain.MicropostModel) error {
+	return retryOnConflict(writeConflictAttempts, func() error {
+		return m.updateMicropost(micropostModel)
+	})
+}
+
+func (m *MicropostOperator) updateMicropost(micropostModel *domain.MicropostModel) error {
+	conn, err := m.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	micropostResource, err := m.getMicropostResourceByID(micropostModel.ID)
 	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return errors.WithStack(domain.ErrNotFound)
+		}
 		return errors.WithStack(err)
 	}
+	old := micropostResource.ToModel()
+
 	micropostResource.Content = micropostModel.Content
+	if micropostModel.Version != 0 {
+		micropostResource.SetVersion(micropostModel.Version)
+	}
 
-	err = m.Mapper.PutResource(micropostResource)
+	query, err := m.Mapper.BuildQueryUpdate(micropostResource)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	tx := conn.WriteTx().Put(query)
+	err = m.addIndexQueries(tx, table, old, micropostResource.ToModel())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = tx.Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	return nil
+}
+
+// AddAttachment 画像を添付する。S3のイベント通知は重複して届くことがあるため、同じキーの画像が添付済みの場合は何もしない。
+// 複数の画像のアップロードが同時に完了すると書き込みが競合するため、読み込みからやり直す
+func (m *MicropostOperator) AddAttachment(micropostID uint64, attachment *domain.AttachmentModel) error {
+	return retryOnConflict(writeConflictAttempts, func() error {
+		return m.addAttachment(micropostID, attachment)
+	})
+}
+
+func (m *MicropostOperator) addAttachment(micropostID uint64, attachment *domain.AttachmentModel) error {
+	micropostResource, err := m.getMicropostResourceByID(micropostID)
+	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return errors.WithStack(domain.ErrNotFound)
+		}
+		return errors.WithStack(err)
+	}
+	if micropostResource.HasAttachment(attachment.Key) {
+		return nil
+	}
+	if len(micropostResource.Attachments) >= domain.MaxAttachmentsPerMicropost {
+		return errors.WithStack(domain.ErrTooManyAttachments)
+	}
+	micropostResource.Attachments = append(micropostResource.Attachments, *attachment)
+
+	query, err := m.Mapper.BuildQueryUpdate(micropostResource)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = query.Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	return nil
+}
+
+// PurgeDeletedMicroposts 指定した時刻より前に論理削除したマイクロポストを物理削除する
+func (m *MicropostOperator) PurgeDeletedMicroposts(ctx context.Context, deletedBefore time.Time) (int, error) {
+	purged, err := m.Mapper.PurgeDeletedEntities(
+		ctx,
+		m.Mapper.GetEntityNameFromStruct(MicropostResource{}),
+		deletedBefore,
+		func() DynamoResource { return &MicropostResource{Mapper: m.Mapper} })
+	if err != nil {
+		return purged, errors.WithStack(err)
+	}
+	return purged, nil
+}
+
+// DeleteMicropostsByUserID 指定されたユーザーのマイクロポストを最大limit件まで論理削除し、削除した件数を返す。
+// 25件ずつトランザクションでまとめて書き込む。途中で更新されたものは削除せずにremainingをtrueにし、次の呼び出しで対象にする
+func (m *MicropostOperator) DeleteMicropostsByUserID(userID uint64, limit int) (int, bool, error) {
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeNotExists(deletedAtAttr)
+
+	iter := table.
+		Get("GSI1PK", m.Mapper.GetUserIndexPK(userID)).
+		Index(UserIndexName).
+		Range("GSI1SK", dynamo.BeginsWith, m.Mapper.GetEntityNameFromStruct(MicropostResource{})+"#").
+		Filter(fb.JoinAnd(), fb.Arg...).
+		Iter()
+
+	deleted, skipped := 0, 0
+	remaining := false
+	batch := make([]*MicropostResource, 0, cascadeDeleteBatchSize)
+	for {
+		if deleted+skipped+len(batch) >= limit {
+			remaining = true
+			break
+		}
+		micropost := &MicropostResource{Mapper: m.Mapper}
+		if !iter.Next(micropost) {
+			break
+		}
+
+		batch = append(batch, micropost)
+		if len(batch) < cascadeDeleteBatchSize {
+			continue
+		}
+
+		n, s, err := m.softDeleteBatch(batch)
+		deleted += n
+		skipped += s
+		if err != nil {
+			return deleted, true, errors.WithStack(err)
+		}
+		batch = batch[:0]
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, true, errors.WithStack(err)
+	}
+
+	n, s, err := m.softDeleteBatch(batch)
+	deleted += n
+	skipped += s
+	if err != nil {
+		return deleted, true, errors.WithStack(err)
+	}
+
+	return deleted, remaining || skipped > 0, nil
+}
+
+// softDeleteBatch まとめて論理削除し、削除した件数と、他の更新と競合して削除しなかった件数を返す。
+// ハッシュタグ・メンションの索引も同じトランザクションで削除し、書き込む件数がトランザクションの上限を超える場合は分けて書き込む。
+// 取得後に更新されたものが含まれているとトランザクション全体が失敗するため、その場合は1件ずつ削除し直す
+func (m *MicropostOperator) softDeleteBatch(microposts []*MicropostResource) (int, int, error) {
+	if len(microposts) == 0 {
+		return 0, 0, nil
+	}
+
+	items := 0
+	for _, micropost := range microposts {
+		items += 1 + len(m.indexPKs(micropost.ToModel(), nil))
+	}
+	if len(microposts) > 1 && items > maxTransactionItems {
+		half := len(microposts) / 2
+		deleted, skipped, err := m.softDeleteBatch(microposts[:half])
+		if err != nil {
+			return deleted, skipped, errors.WithStack(err)
+		}
+		n, s, err := m.softDeleteBatch(microposts[half:])
+		return deleted + n, skipped + s, err
+	}
+
+	conn, err := m.Client.ConnectDB()
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+
+	tx := conn.WriteTx()
+	for _, micropost := range microposts {
+		// トランザクションが失敗した場合に1件ずつやり直せるよう、コピーに対してクエリを組み立てる
+		r := *micropost
+		r.DeletedWithUser = true
+		query, err := m.Mapper.BuildQuerySoftDelete(&r)
+		if err != nil {
+			return 0, 0, errors.WithStack(err)
+		}
+		tx.Put(query)
+		m.addIndexDeleteQueries(tx, table, micropost.ToModel())
+	}
+
+	err = tx.Run()
+	if err == nil {
+		for _, micropost := range microposts {
+			err := m.orphanReplies(micropost)
+			if err != nil {
+				return len(microposts), 0, errors.WithStack(err)
+			}
+		}
+		return len(microposts), 0, nil
+	}
+	if !dynamo.IsCondCheckFailed(err) {
+		return 0, 0, errors.WithStack(err)
+	}
+
+	deleted, skipped := 0, 0
+	for _, micropost := range microposts {
+		micropost.DeletedWithUser = true
+		err := m.softDeleteMicropost(micropost)
+		if err != nil {
+			if errors.Cause(err) == domain.ErrConflict {
+				skipped++
+				continue
+			}
+			return deleted, skipped, errors.WithStack(err)
+		}
+		deleted++
+
+		err = m.orphanReplies(micropost)
+		if err != nil {
+			return deleted, skipped, errors.WithStack(err)
+		}
+	}
+
+	return deleted, skipped, nil
+}
+
+// orphanReplies 削除したマイクロポストが返信されている場合は、返信をOrphanedにする。
+// ユーザーと一緒に削除した返信は返信先の返信数を減らさない。ユーザーを復元すると返信も戻るため
+func (m *MicropostOperator) orphanReplies(micropost *MicropostResource) error {
+	if micropost.ReplyCount == 0 {
+		return nil
+	}
+	return m.setRepliesOrphaned(micropost.ID(), true)
+}
+
+// RestoreMicropostsByUserID ユーザーの削除に伴って論理削除したマイクロポストを最大limit件まで復元し、復元した件数を返す。
+// 1件ずつ復元する。途中で更新されたものは復元せずにremainingをtrueにし、次の呼び出しで対象にする
+func (m *MicropostOperator) RestoreMicropostsByUserID(userID uint64, limit int) (int, bool, error) {
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.AttributeExists(deletedAtAttr)
+	fb.Equal("DeletedWithUser", true)
+
+	iter := table.
+		Get("GSI1PK", m.Mapper.GetUserIndexPK(userID)).
+		Index(UserIndexName).
+		Range("GSI1SK", dynamo.BeginsWith, m.Mapper.GetEntityNameFromStruct(MicropostResource{})+"#").
+		Filter(fb.JoinAnd(), fb.Arg...).
+		Iter()
+
+	restored, skipped := 0, 0
+	for {
+		micropost := &MicropostResource{Mapper: m.Mapper}
+		if !iter.Next(micropost) {
+			break
+		}
+		if restored+skipped >= limit {
+			return restored, true, nil
+		}
+
+		err := m.restoreMicropost(micropost)
+		if err != nil {
+			if errors.Cause(err) == domain.ErrConflict {
+				skipped++
+				continue
+			}
+			return restored, true, errors.WithStack(err)
+		}
+		restored++
+	}
+	if err := iter.Err(); err != nil {
+		return restored, true, errors.WithStack(err)
+	}
+
+	return restored, skipped > 0, nil
+}
+
+// restoreMicropost 論理削除したマイクロポストを復元し、削除時に消したハッシュタグ・メンションの索引を同じトランザクションで登録し直す
+func (m *MicropostOperator) restoreMicropost(micropost *MicropostResource) error {
+	conn, err := m.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	table, err := m.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	micropost.DeletedWithUser = false
+
+	query, err := m.Mapper.BuildQueryRestore(micropost)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	tx := conn.WriteTx().Put(query)
+	err = m.addIndexQueries(tx, table, nil, micropost.ToModel())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = tx.Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	// ユーザーと一緒に削除したときにOrphanedにした返信を元に戻す
+	if micropost.ReplyCount > 0 {
+		err = m.setRepliesOrphaned(micropost.ID(), false)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	return nil
 }
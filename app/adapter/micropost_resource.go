@@ -3,6 +3,8 @@ package adapter
 import (
 	"clean-serverless-book-sample/domain"
 	"time"
+
+	"github.com/memememomo/nomof"
 )
 
 // MicropostResource DynamoDB上のデータ構造を表した構造体
@@ -10,16 +12,44 @@ type MicropostResource struct {
 	ResourceSchema
 	DynamoResourceBase
 	domain.MicropostModel
-	Mapper *DynamoModelMapper `dynamo:"-"`
+	// DeletedWithUser ユーザーの削除に伴って論理削除したかどうか。ユーザーを復元した際に一緒に復元する対象を見分ける
+	DeletedWithUser bool               `dynamo:"DeletedWithUser,omitempty"`
+	Mapper          *DynamoModelMapper `dynamo:"-"`
 }
 
 func NewMicropostResource(micropostModel *domain.MicropostModel, mapper *DynamoModelMapper) *MicropostResource {
 	return &MicropostResource{
-		MicropostModel: *micropostModel,
-		Mapper:         mapper,
+		DynamoResourceBase: DynamoResourceBase{Version: micropostModel.Version},
+		MicropostModel:     *micropostModel,
+		Mapper:             mapper,
 	}
 }
 
+// ToModel ドメインモデルに変換する
+func (m *MicropostResource) ToModel() *domain.MicropostModel {
+	model := m.MicropostModel
+	model.Version = m.Version()
+	model.CreatedAt = m.CreatedAt()
+	return &model
+}
+
+// SystemAttributes いいね数・返信数と、返信先の削除で付けるOrphanedはバージョンに含めない
+func (m *MicropostResource) SystemAttributes() map[string]interface{} {
+	return map[string]interface{}{
+		"LikeCount":  m.LikeCount,
+		"ReplyCount": m.ReplyCount,
+		"Orphaned":   m.Orphaned,
+	}
+}
+
+// filterMicropostItems スキャンの条件をマイクロポストのレコードだけに絞り込む。
+// マイクロポストのPKの下にはいいねと返信のレコードも保存しているため、SKで除外する
+func filterMicropostItems(fb *nomof.Builder, mapper *DynamoModelMapper) {
+	fb.BeginsWith("PK", mapper.GetEntityNameFromStruct(MicropostResource{}))
+	fb.Append("NOT begins_with('SK', ?)", []interface{}{likeEntityName + "#"})
+	fb.Append("NOT begins_with('SK', ?)", []interface{}{replyEntityName + "#"})
+}
+
 // DynamoResourceインタフェースの実装
 
 func (m *MicropostResource) EntityName() string {
@@ -42,6 +72,12 @@ func (m *MicropostResource) SetSK() {
 	m.ResourceSchema.SK = m.SK()
 }
 
+// SetUserIndex ユーザー単位のインデックスのキーを設定する
+func (m *MicropostResource) SetUserIndex() {
+	m.ResourceSchema.GSI1PK = m.Mapper.GetUserIndexPK(m.MicropostModel.UserID)
+	m.ResourceSchema.GSI1SK = m.Mapper.GetUserIndexSK(m)
+}
+
 func (m *MicropostResource) SetID(id uint64) {
 	m.MicropostModel.ID = id
 }
@@ -52,6 +88,7 @@ func (m *MicropostResource) ID() uint64 {
 
 func (m *MicropostResource) SetVersion(v int) {
 	m.DynamoResourceBase.Version = v
+	m.MicropostModel.Version = v
 }
 
 func (m *MicropostResource) Version() int {
@@ -64,6 +101,7 @@ func (m *MicropostResource) CreatedAt() time.Time {
 
 func (m *MicropostResource) SetCreatedAt(t time.Time) {
 	m.DynamoResourceBase.CreatedAt = t
+	m.MicropostModel.CreatedAt = t
 }
 
 func (m *MicropostResource) UpdatedAt() time.Time {
@@ -73,3 +111,11 @@ func (m *MicropostResource) UpdatedAt() time.Time {
 func (m *MicropostResource) SetUpdatedAt(t time.Time) {
 	m.DynamoResourceBase.UpdatedAt = t
 }
+
+func (m *MicropostResource) DeletedAt() time.Time {
+	return m.DynamoResourceBase.DeletedAt
+}
+
+func (m *MicropostResource) SetDeletedAt(t time.Time) {
+	m.DynamoResourceBase.DeletedAt = t
+}
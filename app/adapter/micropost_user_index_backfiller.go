@@ -0,0 +1,95 @@
+package adapter
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/guregu/dynamo"
+	"github.com/memememomo/nomof"
+	"github.com/pkg/errors"
+)
+
+// MicropostUserIndexBackfiller ユーザー単位のインデックス(GSI1)を導入する前に作成されたマイクロポストに、インデックスのキーを設定する。
+// ユーザーのマイクロポストはGSI1へのQueryで取得するため、キーを持たないマイクロポストは取得できない。導入時に一度実行する
+type MicropostUserIndexBackfiller struct {
+	Client *ResourceTableOperator
+	Mapper *DynamoModelMapper
+	PKName string
+	SKName string
+	Log    *slog.Logger
+}
+
+// MicropostUserIndexBackfillResult キー設定処理の結果
+type MicropostUserIndexBackfillResult struct {
+	// Scanned キーを持っていなかったマイクロポストの数
+	Scanned int
+	// Backfilled キーを設定した(DryRunの場合は設定が必要な)マイクロポストの数
+	Backfilled int
+}
+
+// Backfill キーを持たないマイクロポストを走査してキーを設定する。dryRunがtrueの場合は書き込みを行わない。
+// 論理削除済みのものも、復元や物理削除の対象になるよう設定する
+func (b *MicropostUserIndexBackfiller) Backfill(dryRun bool) (*MicropostUserIndexBackfillResult, error) {
+	table, err := b.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.BeginsWith(b.PKName, b.Mapper.GetEntityNameFromStruct(MicropostResource{})+"-")
+	fb.AttributeNotExists("GSI1PK")
+
+	result := &MicropostUserIndexBackfillResult{}
+
+	iter := table.Scan().Filter(fb.JoinAnd(), fb.Arg...).Iter()
+	for {
+		micropost := MicropostResource{Mapper: b.Mapper}
+		if !iter.Next(&micropost) {
+			break
+		}
+		// マイクロポストのPKの下にあるいいね・返信のレコードは対象外
+		if strings.Contains(micropost.ResourceSchema.SK, "#") {
+			continue
+		}
+		result.Scanned++
+
+		b.Log.Info("Missing user index keys", "micropostID", micropost.ID(), "dryRun", dryRun)
+		if dryRun {
+			result.Backfilled++
+			continue
+		}
+
+		err = b.backfill(table, &micropost)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		result.Backfilled++
+	}
+	if iter.Err() != nil {
+		return nil, errors.WithStack(iter.Err())
+	}
+
+	return result, nil
+}
+
+// backfill インデックスのキーだけを設定する。内容は変えないためバージョンは上げず、走査後に設定・削除された場合は何もしない
+func (b *MicropostUserIndexBackfiller) backfill(table *dynamo.Table, micropost *MicropostResource) error {
+	fb := nomof.NewBuilder()
+	fb.AttributeExists(b.PKName)
+	fb.AttributeNotExists("GSI1PK")
+
+	err := table.
+		Update(b.PKName, micropost.ResourceSchema.PK).
+		Range(b.SKName, micropost.ResourceSchema.SK).
+		Set("GSI1PK", b.Mapper.GetUserIndexPK(micropost.MicropostModel.UserID)).
+		Set("GSI1SK", b.Mapper.GetUserIndexSK(micropost)).
+		If(fb.JoinAnd(), fb.Arg...).
+		Run()
+	if err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/registry"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMicropostUserIndexBackfiller_Backfill(t *testing.T) {
+	// テスト用のローカルDynamoDBを作成・接続
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	user, err := tables.UserOperator.CreateUser(domain.NewUserModel("テスト", "test@example.com"))
+	require.NoError(t, err)
+	micropost, err := tables.MicropostOperator.CreateMicropost(domain.NewMicropostModel("Content", user.ID))
+	require.NoError(t, err)
+
+	// インデックスを導入する前に作成されたマイクロポストと同じく、キーを消しておく
+	f := registry.GetFactory()
+	table, err := f.BuildResourceTableOperator().ConnectTable()
+	require.NoError(t, err)
+	resource := adapter.NewMicropostResource(micropost, f.BuildDynamoModelMapper())
+	err = table.
+		Update(f.Envs.DynamoPKName(), resource.PK()).
+		Range(f.Envs.DynamoSKName(), resource.SK()).
+		Remove("GSI1PK", "GSI1SK").
+		Run()
+	require.NoError(t, err)
+
+	microposts, _, err := tables.MicropostOperator.GetMicropostsByUserID(user.ID, domain.NewPage(10, ""))
+	require.NoError(t, err)
+	assert.Empty(t, microposts)
+
+	backfiller := f.BuildMicropostUserIndexBackfiller()
+
+	// DryRunでは書き込まれないこと
+	result, err := backfiller.Backfill(true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Backfilled)
+
+	result, err = backfiller.Backfill(false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Scanned)
+	assert.Equal(t, 1, result.Backfilled)
+
+	microposts, _, err = tables.MicropostOperator.GetMicropostsByUserID(user.ID, domain.NewPage(10, ""))
+	require.NoError(t, err)
+	require.Len(t, microposts, 1)
+	assert.Equal(t, micropost.ID, microposts[0].ID)
+
+	// 設定済みのものは対象にしない
+	result, err = backfiller.Backfill(false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Scanned)
+}
@@ -0,0 +1,56 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/guregu/dynamo"
+	"github.com/pkg/errors"
+)
+
+// EncodePagingKey DynamoDBのLastEvaluatedKeyをクライアントに返すトークンに変換する
+func EncodePagingKey(key dynamo.PagingKey) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	var m map[string]interface{}
+	err := dynamodbattribute.UnmarshalMap(key, &m)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodePagingKey トークンからDynamoDBのExclusiveStartKeyを復元する
+func DecodePagingKey(token string) (dynamo.PagingKey, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.WithStack(domain.ErrInvalidPageToken)
+	}
+
+	var m map[string]interface{}
+	err = json.Unmarshal(b, &m)
+	if err != nil || len(m) == 0 {
+		return nil, errors.WithStack(domain.ErrInvalidPageToken)
+	}
+
+	key, err := dynamodbattribute.MarshalMap(m)
+	if err != nil {
+		return nil, errors.WithStack(domain.ErrInvalidPageToken)
+	}
+
+	return dynamo.PagingKey(key), nil
+}
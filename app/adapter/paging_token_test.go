@@ -0,0 +1,45 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/guregu/dynamo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagingKey_RoundTrip(t *testing.T) {
+	key := dynamo.PagingKey{
+		"PK": {S: aws.String("UserResource-00000000001")},
+		"SK": {S: aws.String("00000000001")},
+	}
+
+	// トークンに変換して元に戻せるか確認
+	token, err := adapter.EncodePagingKey(key)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	decoded, err := adapter.DecodePagingKey(token)
+	assert.NoError(t, err)
+	assert.Equal(t, key, decoded)
+}
+
+func TestPagingKey_Empty(t *testing.T) {
+	// 最終ページの場合は空のトークンになる
+	token, err := adapter.EncodePagingKey(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", token)
+
+	decoded, err := adapter.DecodePagingKey("")
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+}
+
+func TestPagingKey_Invalid(t *testing.T) {
+	for _, token := range []string{"!!!", "aW52YWxpZA", "e30"} {
+		_, err := adapter.DecodePagingKey(token)
+		assert.Equal(t, domain.ErrInvalidPageToken.Error(), err.Error(), token)
+	}
+}
@@ -35,15 +35,25 @@ func (p *ProductOperator) GetProductByID(id uint64) (*domain.ProductModel, error
 	}
 
 	// Productを返す
-	return &productResource.ProductModel, nil
+	return productResource.ToModel(), nil
 }
 
-// GetProducts 一覧取得処理
-func (p *ProductOperator) GetProducts() ([]*domain.ProductModel, error) {
+// GetProducts 一覧取得処理。続きがある場合は次のページのトークンも返す
+func (p *ProductOperator) GetProducts(page *domain.Page) ([]*domain.ProductModel, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
 	// DynamoDBテーブルに接続するためのクライアントを取得
 	table, err := p.Client.ConnectTable()
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, "", errors.WithStack(err)
+	}
+
+	// ページトークンから取得開始位置を復元
+	startKey, err := DecodePagingKey(page.NextToken)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
 	}
 
 	// フィルタの設定
@@ -52,71 +62,200 @@ func (p *ProductOperator) GetProducts() ([]*domain.ProductModel, error) {
 
 	// DynamoDBから一覧取得処理
 	var productResource []ProductResource
-	err = table.
+	lastKey, err := table.
 		Scan().
 		Filter(fb.JoinAnd(), fb.Arg...).
-		All(&productResource)
+		StartFrom(startKey).
+		Limit(int64(page.Limit)).
+		AllWithLastEvaluatedKey(&productResource)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, "", errors.WithStack(err)
+	}
+
+	// 次のページのトークンを作成
+	nextToken, err := EncodePagingKey(lastKey)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
 	}
 
 	// ProductResourceからProductModelに変換
 	var products = make([]*domain.ProductModel, len(productResource))
 	for i := range productResource {
-		products[i] = &productResource[i].ProductModel
+		products[i] = productResource[i].ToModel()
 	}
 
 	// 一覧を返す
-	return products, nil
+	return products, nextToken, nil
 }
 
-// CreateProduct 新規作成
-func (p *ProductOperator) CreateProduct(productModel *domain.ProductModel) (*domain.ProductModel, error) {
+// productSaveBatchSize 製品をまとめて書き込む際に、1つのトランザクションにまとめる件数
+const productSaveBatchSize = 25
+
+// buildQueryCreateProduct 新規作成のクエリを生成する。IDはこの時点で採番する
+func (p *ProductOperator) buildQueryCreateProduct(productModel *domain.ProductModel) (*dynamo.Put, *ProductResource, error) {
 	// ProductModelからProductResourceを作成する
 	productResource := NewProductResource(productModel, p.Mapper)
 
-	// DynamoDBに保存
-	err := p.Mapper.PutResource(productResource)
+	query, err := p.Mapper.BuildQueryCreate(productResource)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
 	}
-
-	// 新規作成したProductModelを返す
-	return &productResource.ProductModel, nil
+	return query, productResource, nil
 }
 
-// UpdateProduct 更新処理
-func (p *ProductOperator) UpdateProduct(productModel *domain.ProductModel) error {
+// buildQueryUpdateProduct 更新のクエリを生成する。製品が存在しない場合はdomain.ErrNotFoundを返す
+func (p *ProductOperator) buildQueryUpdateProduct(productModel *domain.ProductModel) (*dynamo.Put, error) {
 	// 既存のProductを取得する
 	productResource, err := p.getProductResourceByID(productModel.ID)
 	if err != nil {
-		return errors.WithStack(err)
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
+		return nil, errors.WithStack(err)
 	}
 
 	// 更新内容をModelに反映
 	productResource.ProductModel.Name = productModel.Name
 	productResource.ProductModel.Price = productModel.Price
 	productResource.ProductModel.ReleaseDate = productModel.ReleaseDate
+	if productModel.Version != 0 {
+		productResource.SetVersion(productModel.Version)
+	}
 
-	// 更新処理
-	err = p.Mapper.PutResource(productResource)
+	query, err := p.Mapper.BuildQueryUpdate(productResource)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return query, nil
+}
+
+// CreateProduct 新規作成
+func (p *ProductOperator) CreateProduct(productModel *domain.ProductModel) (*domain.ProductModel, error) {
+	query, productResource, err := p.buildQueryCreateProduct(productModel)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// DynamoDBに保存
+	err = query.Run()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// 新規作成したProductModelを返す
+	return productResource.ToModel(), nil
+}
+
+// UpdateProduct 更新処理。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (p *ProductOperator) UpdateProduct(productModel *domain.ProductModel) error {
+	query, err := p.buildQueryUpdateProduct(productModel)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	// 更新処理
+	err = query.Run()
+	if err != nil {
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
 	return nil
 }
 
-// DeleteProduct 削除処理
-func (p *ProductOperator) DeleteProduct(id uint64) error {
+// SaveProducts 製品をまとめて新規作成・更新する。25件ずつトランザクションで書き込み、
+// 条件を満たさずに取り消された製品はdomain.ErrConflictにして、残りを書き込み直す。
+// トランザクションでは同じ項目を2回書き込めないため、同じIDの製品が複数ある場合は後のものをdomain.ErrConflictにする
+func (p *ProductOperator) SaveProducts(products []*domain.ProductModel) ([]error, error) {
+	errs := make([]error, len(products))
+	queries := make([]*dynamo.Put, len(products))
+	seen := map[uint64]bool{}
+	for i, product := range products {
+		if product.ID != 0 && seen[product.ID] {
+			errs[i] = errors.WithStack(domain.ErrConflict)
+			continue
+		}
+		seen[product.ID] = true
+
+		var err error
+		if product.ID == 0 {
+			queries[i], _, err = p.buildQueryCreateProduct(product)
+		} else {
+			queries[i], err = p.buildQueryUpdateProduct(product)
+		}
+		if err != nil {
+			if err.Error() == domain.ErrNotFound.Error() {
+				errs[i] = err
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	for start := 0; start < len(products); start += productSaveBatchSize {
+		end := min(start+productSaveBatchSize, len(products))
+		err := p.runSaveQueries(queries[start:end], errs[start:end])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return errs, nil
+}
+
+// runSaveQueries まだエラーになっていないクエリをトランザクションで書き込む。取り消された理由が条件を満たさなかったものだけの場合は、
+// そのクエリのerrsを設定して残りで書き込み直す
+func (p *ProductOperator) runSaveQueries(queries []*dynamo.Put, errs []error) error {
+	conn, err := p.Client.ConnectDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for {
+		var pending []int
+		for i, query := range queries {
+			if query != nil && errs[i] == nil {
+				pending = append(pending, i)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		tx := conn.WriteTx()
+		for _, i := range pending {
+			tx.Put(queries[i])
+		}
+		err = tx.Run()
+		if err == nil {
+			return nil
+		}
+
+		failed := false
+		for n, i := range pending {
+			if isTxCondCheckFailedAt(err, n) {
+				errs[i] = errors.WithStack(domain.ErrConflict)
+				failed = true
+			}
+		}
+		if !failed {
+			return errors.WithStack(err)
+		}
+	}
+}
+
+// DeleteProduct 削除処理。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (p *ProductOperator) DeleteProduct(productModel *domain.ProductModel) error {
 	// 既存のProductを取得する
-	product, err := p.getProductResourceByID(id)
+	product, err := p.getProductResourceByID(productModel.ID)
 	if err != nil {
 		if err.Error() == dynamo.ErrNotFound.Error() {
 			return errors.WithStack(domain.ErrNotFound)
 		}
 		return errors.WithStack(err)
 	}
+	if productModel.Version != 0 {
+		product.SetVersion(productModel.Version)
+	}
 
 	// 削除処理
 	err = p.Mapper.DeleteResource(product)
@@ -145,7 +145,7 @@ func TestProductOperator_GetProducts(t *testing.T) {
 
 	// 一覧取得処理
 	operator := registry.GetFactory().BuildProductOperator()
-	products, err := operator.GetProducts()
+	products, _, err := operator.GetProducts(nil)
 	assert.NoError(t, err)
 
 	// 所得した一覧の内容をチェック
@@ -198,7 +198,7 @@ func TestProductOperator_DeleteProduct(t *testing.T) {
 
 	// 削除処理
 	operator := registry.GetFactory().BuildProductOperator()
-	err = operator.DeleteProduct(expected.ID())
+	err = operator.DeleteProduct(&domain.ProductModel{ID: expected.ID()})
 	assert.NoError(t, err)
 
 	// 削除されているかチェック
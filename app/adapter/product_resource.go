@@ -15,11 +15,19 @@ type ProductResource struct {
 
 func NewProductResource(productModel *domain.ProductModel, mapper *DynamoModelMapper) *ProductResource {
 	return &ProductResource{
-		ProductModel: *productModel,
-		Mapper:       mapper,
+		DynamoResourceBase: DynamoResourceBase{Version: productModel.Version},
+		ProductModel:       *productModel,
+		Mapper:             mapper,
 	}
 }
 
+// ToModel ドメインモデルに変換する
+func (p *ProductResource) ToModel() *domain.ProductModel {
+	model := p.ProductModel
+	model.Version = p.Version()
+	return &model
+}
+
 // 以下 、DynamoResourceインタフェースの実装
 // EntityName エンティティ名を返す 。構造体名をエンティティ名として返すように実装している
 func (p *ProductResource) EntityName() string {
@@ -64,6 +72,7 @@ func (p *ProductResource) Version() int {
 // SetVersion DynamoDBの作成?更新をするときに楽観的ロックを⾏うためのVersionを設定する
 func (p *ProductResource) SetVersion(v int) {
 	p.DynamoResourceBase.Version = v
+	p.ProductModel.Version = v
 }
 
 // CreatedAt レコードの作成時刻を返す
@@ -85,3 +94,13 @@ func (p *ProductResource) UpdatedAt() time.Time {
 func (p *ProductResource) SetUpdatedAt(t time.Time) {
 	p.DynamoResourceBase.UpdatedAt = t
 }
+
+// DeletedAt レコードを論理削除した時刻を返す
+func (p *ProductResource) DeletedAt() time.Time {
+	return p.DynamoResourceBase.DeletedAt
+}
+
+// SetDeletedAt レコードを論理削除した時刻を設定する
+func (p *ProductResource) SetDeletedAt(t time.Time) {
+	p.DynamoResourceBase.DeletedAt = t
+}
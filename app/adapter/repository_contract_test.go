@@ -0,0 +1,81 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/mocks/contract"
+	"clean-serverless-book-sample/registry"
+	"testing"
+)
+
+func TestUserOperator_Contract(t *testing.T) {
+	contract.RunUserRepository(t, func(t *testing.T) domain.UserRepository {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return tables.UserOperator
+	})
+}
+
+func TestMicropostOperator_Contract(t *testing.T) {
+	contract.RunMicropostRepository(t, func(t *testing.T) (domain.MicropostRepository, domain.UserRepository) {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return tables.MicropostOperator, tables.UserOperator
+	})
+}
+
+func TestProductOperator_Contract(t *testing.T) {
+	contract.RunProductRepository(t, func(t *testing.T) domain.ProductRepository {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return tables.ProductOperator
+	})
+}
+
+func TestIdempotencyKeyOperator_Contract(t *testing.T) {
+	contract.RunIdempotencyKeyRepository(t, func(t *testing.T) domain.IdempotencyKeyRepository {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return registry.GetFactory().BuildIdempotencyKeyRepository()
+	})
+}
+
+func TestCascadeDeleteJobOperator_Contract(t *testing.T) {
+	contract.RunCascadeDeleteJobRepository(t, func(t *testing.T) domain.CascadeDeleteJobRepository {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return registry.GetFactory().BuildCascadeDeleteJobRepository()
+	})
+}
+
+func TestAPIKeyOperator_Contract(t *testing.T) {
+	contract.RunAPIKeyRepository(t, func(t *testing.T) domain.APIKeyRepository {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return registry.GetFactory().BuildAPIKeyRepository()
+	})
+}
+
+func TestFollowOperator_Contract(t *testing.T) {
+	contract.RunFollowRepository(t, func(t *testing.T) (domain.FollowRepository, domain.UserRepository) {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return registry.GetFactory().BuildFollowRepository(), tables.UserOperator
+	})
+}
+
+func TestLikeOperator_Contract(t *testing.T) {
+	contract.RunLikeRepository(t, func(t *testing.T) (domain.LikeRepository, domain.MicropostRepository, domain.UserRepository) {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return registry.GetFactory().BuildLikeRepository(), tables.MicropostOperator, tables.UserOperator
+	})
+}
+
+func TestImportJobOperator_Contract(t *testing.T) {
+	contract.RunImportJobRepository(t, func(t *testing.T) domain.ImportJobRepository {
+		tables := mocks.SetupDB(t)
+		t.Cleanup(tables.Cleanup)
+		return registry.GetFactory().BuildImportJobRepository()
+	})
+}
@@ -0,0 +1,52 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// S3AttachmentStorage 添付画像をS3のバケットに保存する
+type S3AttachmentStorage struct {
+	Client S3Client
+	Bucket string
+}
+
+// PresignUpload 指定したキーへPUTでアップロードするための署名付きURLを発行する。
+// Content-Typeも署名に含めるため、クライアントは同じContent-Typeを指定してアップロードする必要がある
+func (s *S3AttachmentStorage) PresignUpload(key, contentType string, ttl time.Duration) (string, error) {
+	req, _ := s.Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	url, err := req.Presign(ttl)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return url, nil
+}
+
+// GetAttachment アップロードされたオブジェクトのメタデータを取得する
+func (s *S3AttachmentStorage) GetAttachment(key string) (*domain.AttachmentModel, error) {
+	out, err := s.Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	return &domain.AttachmentModel{
+		Key:         key,
+		Size:        aws.Int64Value(out.ContentLength),
+		ContentType: aws.StringValue(out.ContentType),
+		UploadedAt:  aws.TimeValue(out.LastModified),
+	}, nil
+}
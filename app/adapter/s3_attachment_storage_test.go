@@ -0,0 +1,32 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3AttachmentStorage_GetAttachment(t *testing.T) {
+	client := mocks.NewFakeS3Client()
+	storage := &adapter.S3AttachmentStorage{Client: client, Bucket: "test-bucket"}
+	key := domain.NewAttachmentKey(1, "image")
+
+	// アップロードされる前は存在しない
+	_, err := storage.GetAttachment(key)
+	if assert.Error(t, err) {
+		assert.Equal(t, domain.ErrNotFound.Error(), err.Error())
+	}
+
+	client.PutTestObject("test-bucket", key, "image/jpeg", []byte("jpeg"))
+	attachment, err := storage.GetAttachment(key)
+	require.NoError(t, err)
+	assert.Equal(t, key, attachment.Key)
+	assert.Equal(t, int64(4), attachment.Size)
+	assert.Equal(t, "image/jpeg", attachment.ContentType)
+	assert.WithinDuration(t, time.Now(), attachment.UploadedAt, time.Minute)
+}
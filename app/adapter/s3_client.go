@@ -0,0 +1,32 @@
+package adapter
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// S3Client 利用するS3のAPI。*s3.S3が満たし、テストではフェイクに差し替える
+type S3Client interface {
+	PutObjectRequest(input *s3.PutObjectInput) (*request.Request, *s3.PutObjectOutput)
+	HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error)
+	GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error)
+	PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error)
+}
+
+// NewS3Client S3のクライアントを生成する
+func NewS3Client(config *aws.Config) *s3.S3 {
+	return s3.New(session.Must(session.NewSession(config)))
+}
+
+// isS3NotFound オブジェクトが存在しないことを表すエラーかどうか。GetObjectではNoSuchKeyだが、HeadObjectはボディを返さないためNotFoundになる
+func isS3NotFound(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	return aerr.Code() == "NotFound" || aerr.Code() == s3.ErrCodeNoSuchKey
+}
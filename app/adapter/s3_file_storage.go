@@ -0,0 +1,45 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// S3FileStorage ファイルをS3のバケットに保存する
+type S3FileStorage struct {
+	Client S3Client
+	Bucket string
+}
+
+// OpenFile オブジェクトを読み込む。ボディはS3から順に読み込むため、大きなファイルでも全体をメモリに載せない
+func (s *S3FileStorage) OpenFile(key string) (io.ReadCloser, error) {
+	out, err := s.Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+	return out.Body, nil
+}
+
+// PutFile オブジェクトを保存し、ETagを返す
+func (s *S3FileStorage) PutFile(key, contentType string, body io.ReadSeeker) (string, error) {
+	out, err := s.Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Body:        body,
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return aws.StringValue(out.ETag), nil
+}
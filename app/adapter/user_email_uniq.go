@@ -39,6 +39,25 @@ func (u *UserEmailUniqGenerator) NewUserEmailUniqByUser(user *UserResource) *Use
 	}
 }
 
+// GetByEmail メールアドレスから重複チェック用のレコードを取得する
+func (u *UserEmailUniqGenerator) GetByEmail(email string) (*UserEmailUniq, error) {
+	table, err := u.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var uniq UserEmailUniq
+	err = table.
+		Get(u.PKName, email).
+		Range(u.SKName, dynamo.Equal, u.Mapper.GetEntityNameFromStruct(UserResource{})).
+		One(&uniq)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &uniq, nil
+}
+
 func (u *UserEmailUniqGenerator) BuildQueryCreateByUser(user *UserResource) (*dynamo.Put, error) {
 	table, err := u.Client.ConnectTable()
 	if err != nil {
@@ -58,6 +77,25 @@ func (u *UserEmailUniqGenerator) BuildQueryCreateByUser(user *UserResource) (*dy
 	return query, nil
 }
 
+// BuildQueryDeleteStale ユーザーが存在しない重複チェック用レコードを削除するクエリを生成する。
+// 読み込んだ後に別のユーザーのレコードに置き換わっていた場合は削除しない
+func (u *UserEmailUniqGenerator) BuildQueryDeleteStale(uniq *UserEmailUniq) (*dynamo.Delete, error) {
+	table, err := u.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.Equal("UserID", uniq.UserID)
+
+	query := table.
+		Delete(u.PKName, uniq.Email).
+		Range(u.SKName, uniq.EntityName).
+		If(fb.JoinAnd(), fb.Arg...)
+
+	return query, nil
+}
+
 func (u *UserEmailUniqGenerator) BuildQueryDeleteByUser(user *UserResource) (*dynamo.Delete, error) {
 	table, err := u.Client.ConnectTable()
 	if err != nil {
@@ -0,0 +1,186 @@
+package adapter
+
+import (
+	"clean-serverless-book-sample/domain"
+	"log/slog"
+
+	"github.com/guregu/dynamo"
+	"github.com/memememomo/nomof"
+	"github.com/pkg/errors"
+)
+
+// UserEmailUniqRepairer 既存のユーザーから、欠損しているメールアドレス重複チェック用のレコードを再作成する。
+// ユーザーが存在しない重複チェック用レコードは、そのメールアドレスで登録できるよう削除する
+type UserEmailUniqRepairer struct {
+	Client                 *ResourceTableOperator
+	Mapper                 *DynamoModelMapper
+	UserEmailUniqGenerator *UserEmailUniqGenerator
+	Log                    *slog.Logger
+}
+
+// UserEmailUniqRepairResult 再作成処理の結果
+type UserEmailUniqRepairResult struct {
+	// Scanned 確認したユーザー数
+	Scanned int
+	// Repaired 再作成した(DryRunの場合は再作成が必要な)レコード数
+	Repaired int
+	// Removed ユーザーが存在しない、または論理削除済みのため削除した(DryRunの場合は削除が必要な)レコード数
+	Removed int
+	// Conflicts 同じメールアドレスのレコードを別のユーザーが保持していたユーザーのID
+	Conflicts []uint64
+}
+
+// Repair 全ユーザーを走査してレコードを再作成する。dryRunがtrueの場合は書き込みを行わない。
+// 論理削除したユーザーのレコードは削除時に解放しているため、再作成しない
+func (r *UserEmailUniqRepairer) Repair(dryRun bool) (*UserEmailUniqRepairResult, error) {
+	table, err := r.Client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	filterUserItems(fb, r.Mapper)
+	fb.AttributeNotExists(deletedAtAttr)
+
+	result := &UserEmailUniqRepairResult{}
+
+	iter := table.Scan().Filter(fb.JoinAnd(), fb.Arg...).Iter()
+	for {
+		var user UserResource
+		if !iter.Next(&user) {
+			break
+		}
+		result.Scanned++
+
+		err = r.repairUser(&user, dryRun, result)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if iter.Err() != nil {
+		return nil, errors.WithStack(iter.Err())
+	}
+
+	err = r.removeStaleUniqs(dryRun, result)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return result, nil
+}
+
+// removeStaleUniqs 重複チェック用レコードを走査し、ユーザーが存在しない、または論理削除済みのレコードを削除する
+func (r *UserEmailUniqRepairer) removeStaleUniqs(dryRun bool, result *UserEmailUniqRepairResult) error {
+	table, err := r.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fb := nomof.NewBuilder()
+	fb.Equal(r.Mapper.SKName, r.Mapper.GetEntityNameFromStruct(UserResource{}))
+
+	iter := table.Scan().Filter(fb.JoinAnd(), fb.Arg...).Iter()
+	for {
+		var uniq UserEmailUniq
+		if !iter.Next(&uniq) {
+			break
+		}
+
+		err = r.removeStaleUniq(&uniq, dryRun, result)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if iter.Err() != nil {
+		return errors.WithStack(iter.Err())
+	}
+
+	return nil
+}
+
+// removeStaleUniq ユーザーが存在しない、または論理削除済みの場合にレコードを削除する。
+// ユーザーと重複チェック用レコードは同じトランザクションで書き込むため、強い整合性の読み込みでユーザーが見つからなければ残骸と判断できる。
+// 論理削除済みのユーザーは復元時にレコードを作り直すため、残っていても残骸として扱う。
+// 確認した後に別のユーザーがそのメールアドレスで登録した場合は削除しない
+func (r *UserEmailUniqRepairer) removeStaleUniq(uniq *UserEmailUniq, dryRun bool, result *UserEmailUniqRepairResult) error {
+	table, err := r.Client.ConnectTable()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	user := NewUserResource(&domain.UserModel{ID: uniq.UserID}, r.Mapper)
+	var existing UserResource
+	err = table.
+		Get(r.Mapper.PKName, user.PK()).
+		Range(r.Mapper.SKName, dynamo.Equal, user.SK()).
+		Consistent(true).
+		One(&existing)
+	switch {
+	case err == nil && existing.DeletedAt().IsZero():
+		return nil
+	case err == nil:
+		r.Log.Info("Email uniq record of deleted user", "userID", uniq.UserID, "dryRun", dryRun)
+	case err.Error() == dynamo.ErrNotFound.Error():
+		r.Log.Info("Stale email uniq record", "userID", uniq.UserID, "dryRun", dryRun)
+	default:
+		return errors.WithStack(err)
+	}
+
+	if dryRun {
+		result.Removed++
+		return nil
+	}
+
+	query, err := r.UserEmailUniqGenerator.BuildQueryDeleteStale(uniq)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = query.Run()
+	if err != nil {
+		if dynamo.IsCondCheckFailed(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+
+	result.Removed++
+	return nil
+}
+
+func (r *UserEmailUniqRepairer) repairUser(user *UserResource, dryRun bool, result *UserEmailUniqRepairResult) error {
+	uniq, err := r.UserEmailUniqGenerator.GetByEmail(user.Email)
+	if err == nil {
+		if uniq.UserID != user.ID() {
+			r.Log.Warn("Email is held by another user", "userID", user.ID(), "holderID", uniq.UserID)
+			result.Conflicts = append(result.Conflicts, user.ID())
+		}
+		return nil
+	}
+	if err.Error() != dynamo.ErrNotFound.Error() {
+		return errors.WithStack(err)
+	}
+
+	r.Log.Info("Missing email uniq record", "userID", user.ID(), "dryRun", dryRun)
+	if dryRun {
+		result.Repaired++
+		return nil
+	}
+
+	query, err := r.UserEmailUniqGenerator.BuildQueryCreateByUser(user)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = query.Run()
+	if err != nil {
+		// 走査中に別のユーザーが同じメールアドレスで登録された場合
+		if dynamo.IsCondCheckFailed(err) {
+			result.Conflicts = append(result.Conflicts, user.ID())
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+
+	result.Repaired++
+	return nil
+}
@@ -0,0 +1,166 @@
+package adapter_test
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/registry"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// createUserResourceWithoutUniq 重複チェック用レコードを作らずにユーザーだけを保存する
+func createUserResourceWithoutUniq(newUser *domain.UserModel) (*adapter.UserResource, error) {
+	client := registry.GetFactory().BuildResourceTableOperator()
+	table, err := client.ConnectTable()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	userResource := adapter.NewUserResource(
+		newUser,
+		registry.GetFactory().BuildDynamoModelMapper())
+	userResource.SetPK()
+	userResource.SetSK()
+	userResource.SetVersion(1)
+
+	err = table.Put(userResource).Run()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return userResource, nil
+}
+
+func TestUserOperator_GetUserByEmail(t *testing.T) {
+	// テスト用のローカルDynamoDBを作成・接続
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	expected, err := tables.UserOperator.CreateUser(domain.NewUserModel("テスト", "test@example.com"))
+	assert.NoError(t, err)
+
+	// 重複チェック用レコード経由で取得できること
+	user, err := tables.UserOperator.GetUserByEmail("test@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.ID, user.ID)
+
+	// 存在しないメールアドレスの場合
+	_, err = tables.UserOperator.GetUserByEmail("none@example.com")
+	assert.Equal(t, domain.ErrNotFound.Error(), err.Error())
+}
+
+func TestUserEmailUniqRepairer_Repair(t *testing.T) {
+	// テスト用のローカルDynamoDBを作成・接続
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	// 重複チェック用レコードが存在するユーザー
+	_, err := tables.UserOperator.CreateUser(domain.NewUserModel("テスト1", "test1@example.com"))
+	assert.NoError(t, err)
+
+	// 重複チェック用レコードが欠損しているユーザー
+	broken, err := createUserResourceWithoutUniq(&domain.UserModel{
+		ID:    100,
+		Name:  "テスト2",
+		Email: "test2@example.com",
+	})
+	assert.NoError(t, err)
+
+	_, err = tables.UserOperator.GetUserByEmail(broken.Email)
+	assert.Equal(t, domain.ErrNotFound.Error(), err.Error())
+
+	repairer := registry.GetFactory().BuildUserEmailUniqRepairer()
+
+	// DryRunでは書き込まれないこと
+	result, err := repairer.Repair(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Scanned)
+	assert.Equal(t, 1, result.Repaired)
+
+	_, err = tables.UserOperator.GetUserByEmail(broken.Email)
+	assert.Equal(t, domain.ErrNotFound.Error(), err.Error())
+
+	// 再作成後はメールアドレスから取得できること
+	result, err = repairer.Repair(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Repaired)
+	assert.Empty(t, result.Conflicts)
+
+	user, err := tables.UserOperator.GetUserByEmail(broken.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, broken.ID(), user.ID)
+}
+
+// TestUserEmailUniqRepairer_Repair_staleUniq ユーザーが存在しない重複チェック用レコードは、取得では削除せず修復で削除する
+func TestUserEmailUniqRepairer_Repair_staleUniq(t *testing.T) {
+	// テスト用のローカルDynamoDBを作成・接続
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	f := registry.GetFactory()
+	missing := adapter.NewUserResource(&domain.UserModel{ID: 999, Email: "stale@example.com"}, f.BuildDynamoModelMapper())
+	query, err := f.BuildUserEmailUniqGenerator().BuildQueryCreateByUser(missing)
+	assert.NoError(t, err)
+	assert.NoError(t, query.Run())
+
+	// 取得してもレコードは残るため、そのメールアドレスではまだ登録できない
+	_, err = tables.UserOperator.GetUserByEmail("stale@example.com")
+	assert.Equal(t, domain.ErrNotFound, errors.Cause(err))
+	_, err = tables.UserOperator.CreateUser(domain.NewUserModel("テスト", "stale@example.com"))
+	assert.Equal(t, domain.ErrDuplicateEmail, errors.Cause(err))
+
+	repairer := f.BuildUserEmailUniqRepairer()
+	result, err := repairer.Repair(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Removed)
+
+	result, err = repairer.Repair(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Removed)
+
+	user, err := tables.UserOperator.CreateUser(domain.NewUserModel("テスト", "stale@example.com"))
+	assert.NoError(t, err)
+
+	// 他のユーザーが使っているメールアドレスでは登録できない
+	_, err = tables.UserOperator.CreateUser(domain.NewUserModel("テスト2", user.Email))
+	assert.Equal(t, domain.ErrDuplicateEmail, errors.Cause(err))
+}
+
+// TestUserEmailUniqRepairer_Repair_deletedUser 論理削除したユーザーの重複チェック用レコードは再作成せず、残っていれば削除する
+func TestUserEmailUniqRepairer_Repair_deletedUser(t *testing.T) {
+	// テスト用のローカルDynamoDBを作成・接続
+	tables := mocks.SetupDB(t)
+	defer tables.Cleanup()
+
+	f := registry.GetFactory()
+
+	deleted, err := tables.UserOperator.CreateUser(domain.NewUserModel("削除済み", "deleted@example.com"))
+	assert.NoError(t, err)
+	assert.NoError(t, tables.UserOperator.DeleteUser(deleted))
+
+	// 削除時に解放したレコードを再作成しないこと
+	repairer := f.BuildUserEmailUniqRepairer()
+	result, err := repairer.Repair(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Scanned)
+	assert.Equal(t, 0, result.Repaired)
+
+	_, err = tables.UserOperator.GetUserByEmail(deleted.Email)
+	assert.Equal(t, domain.ErrNotFound, errors.Cause(err))
+
+	// 論理削除済みのユーザーを指すレコードが残っている場合は削除すること
+	resource := adapter.NewUserResource(deleted, f.BuildDynamoModelMapper())
+	query, err := f.BuildUserEmailUniqGenerator().BuildQueryCreateByUser(resource)
+	assert.NoError(t, err)
+	assert.NoError(t, query.Run())
+
+	result, err = repairer.Repair(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Removed)
+
+	_, err = tables.UserOperator.CreateUser(domain.NewUserModel("テスト", deleted.Email))
+	assert.NoError(t, err)
+}
@@ -2,6 +2,8 @@ package adapter
 
 import (
 	"clean-serverless-book-sample/domain"
+	"context"
+	"time"
 
 	"github.com/guregu/dynamo"
 	"github.com/memememomo/nomof"
@@ -24,28 +26,24 @@ func (u *UserOperator) getUserResourceByID(id uint64) (*UserResource, error) {
 	return &user, nil
 }
 
-// GetUserByEmail メールアドレスからユーザー情報を取得する
+// GetUserByEmail メールアドレスからユーザー情報を取得する。
+// メールアドレス重複チェック用のレコードからユーザーIDを引くため、テーブル全体をスキャンしない。
+// ユーザーが存在しない重複チェック用レコードが残っている場合もErrNotFoundを返し、レコードの削除は修復コマンドで行う
 func (u *UserOperator) GetUserByEmail(email string) (*domain.UserModel, error) {
-	table, err := u.Client.ConnectTable()
+	uniq, err := u.UserEmailUniqGenerator.GetByEmail(email)
 	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
 		return nil, errors.WithStack(err)
 	}
 
-	fb := nomof.NewBuilder()
-	fb.Equal("Email", email)
-	fb.BeginsWith("PK", u.Mapper.GetEntityNameFromStruct(UserResource{}))
-
-	var usersDynamo []UserResource
-	err = table.Scan().Filter(fb.JoinAnd(), fb.Arg...).All(&usersDynamo)
+	user, err := u.GetUserByID(uniq.UserID)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if len(usersDynamo) == 0 {
-		return nil, errors.WithStack(domain.ErrNotFound)
-	}
-
-	return &usersDynamo[0].UserModel, nil
+	return user, nil
 }
 
 // Execute IDからユーザー情報を取得する
@@ -57,31 +55,51 @@ func (u *UserOperator) GetUserByID(id uint64) (*domain.UserModel, error) {
 		}
 		return nil, errors.WithStack(err)
 	}
-	return &userResource.UserModel, nil
+	return userResource.ToModel(), nil
 }
 
-// Execute ユーザー一覧を取得する
-func (u *UserOperator) GetUsers() ([]*domain.UserModel, error) {
+// GetUsers ユーザー一覧を取得する。続きがある場合は次のページのトークンも返す
+func (u *UserOperator) GetUsers(page *domain.Page) ([]*domain.UserModel, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
 	table, err := u.Client.ConnectTable()
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, "", errors.WithStack(err)
+	}
+
+	startKey, err := DecodePagingKey(page.NextToken)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
 	}
 
 	fb := nomof.NewBuilder()
-	fb.BeginsWith("PK", u.Mapper.GetEntityNameFromStruct(UserResource{}))
+	filterUserItems(fb, u.Mapper)
+	fb.AttributeNotExists(deletedAtAttr)
 
 	var userDynamo []UserResource
-	err = table.Scan().Filter(fb.JoinAnd(), fb.Arg...).All(&userDynamo)
+	lastKey, err := table.
+		Scan().
+		Filter(fb.JoinAnd(), fb.Arg...).
+		StartFrom(startKey).
+		Limit(int64(page.Limit)).
+		AllWithLastEvaluatedKey(&userDynamo)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, "", errors.WithStack(err)
+	}
+
+	nextToken, err := EncodePagingKey(lastKey)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
 	}
 
 	var users = make([]*domain.UserModel, len(userDynamo))
 	for i := range userDynamo {
-		users[i] = &userDynamo[i].UserModel
+		users[i] = userDynamo[i].ToModel()
 	}
 
-	return users, nil
+	return users, nextToken, nil
 }
 
 // CreateUser ユーザーを新規作成する
@@ -107,14 +125,25 @@ func (u *UserOperator) CreateUser(userModel *domain.UserModel) (*domain.UserMode
 
 	err = tx.Put(r).Put(uniq).Run()
 	if err != nil {
+		// 重複チェックの後に同じメールアドレスで登録された場合
+		if isTxCondCheckFailedAt(err, 1) {
+			return nil, errors.WithStack(domain.ErrDuplicateEmail)
+		}
 		return nil, errors.WithStack(err)
 	}
 
-	return &userResource.UserModel, nil
+	return userResource.ToModel(), nil
 }
 
-// UpdateUser ユーザーを更新する
+// UpdateUser ユーザーを更新する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す。
+// 変更後のメールアドレスが他のユーザーに使われている場合はdomain.ErrDuplicateEmailを返す
 func (u *UserOperator) UpdateUser(newUserModel *domain.UserModel) error {
+	return retryOnConflict(writeConflictAttempts, func() error {
+		return u.updateUser(newUserModel)
+	})
+}
+
+func (u *UserOperator) updateUser(newUserModel *domain.UserModel) error {
 	conn, err := u.Client.ConnectDB()
 	if err != nil {
 		return errors.WithStack(err)
@@ -122,12 +151,18 @@ func (u *UserOperator) UpdateUser(newUserModel *domain.UserModel) error {
 
 	oldUserResource, err := u.getUserResourceByID(newUserModel.ID)
 	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return errors.WithStack(domain.ErrNotFound)
+		}
 		return errors.WithStack(err)
 	}
 
 	newUserResource := *oldUserResource
 	newUserResource.Email = newUserModel.Email
 	newUserResource.Name = newUserModel.Name
+	if newUserModel.Version != 0 {
+		newUserResource.SetVersion(newUserModel.Version)
+	}
 
 	tx := conn.WriteTx()
 
@@ -156,6 +191,39 @@ func (u *UserOperator) UpdateUser(newUserModel *domain.UserModel) error {
 
 	err = query.Run()
 
+	if err != nil {
+		// 1番目はバージョンの条件、2番目はメールアドレス重複チェック用レコードの作成の条件
+		if isTxCondCheckFailedAt(err, 1) {
+			return errors.WithStack(domain.ErrDuplicateEmail)
+		}
+		return errors.WithStack(ConvertConflictError(err))
+	}
+
+	return nil
+}
+
+// UpdateUserPassword パスワードのハッシュ値を更新する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (u *UserOperator) UpdateUserPassword(userModel *domain.UserModel) error {
+	return retryOnConflict(writeConflictAttempts, func() error {
+		return u.updateUserPassword(userModel)
+	})
+}
+
+func (u *UserOperator) updateUserPassword(userModel *domain.UserModel) error {
+	userResource, err := u.getUserResourceByID(userModel.ID)
+	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return errors.WithStack(domain.ErrNotFound)
+		}
+		return errors.WithStack(err)
+	}
+
+	userResource.PasswordHash = userModel.PasswordHash
+	if userModel.Version != 0 {
+		userResource.SetVersion(userModel.Version)
+	}
+
+	err = u.Mapper.UpdateResource(userResource)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -163,18 +231,34 @@ func (u *UserOperator) UpdateUser(newUserModel *domain.UserModel) error {
 	return nil
 }
 
-// DeleteUser ユーザー情報を削除する
+// DeleteUser ユーザー情報を論理削除する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す。
+// 削除したユーザーのメールアドレスは他のユーザーが使えるように解放する
 func (u *UserOperator) DeleteUser(userModel *domain.UserModel) error {
+	return retryOnConflict(writeConflictAttempts, func() error {
+		return u.deleteUser(userModel)
+	})
+}
+
+func (u *UserOperator) deleteUser(userModel *domain.UserModel) error {
 	conn, err := u.Client.ConnectDB()
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	tx := conn.WriteTx()
+	userResource, err := u.getUserResourceByID(userModel.ID)
+	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	if userModel.Version != 0 {
+		userResource.SetVersion(userModel.Version)
+	}
 
-	userResource := NewUserResource(userModel, u.Mapper)
+	tx := conn.WriteTx()
 
-	r, err := u.Mapper.BuildQueryDelete(userResource)
+	r, err := u.Mapper.BuildQuerySoftDelete(userResource)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -184,10 +268,72 @@ func (u *UserOperator) DeleteUser(userModel *domain.UserModel) error {
 		return errors.WithStack(err)
 	}
 
-	err = tx.Delete(r).Delete(uniq).Run()
+	err = tx.Put(r).Delete(uniq).Run()
 	if err != nil {
-		return errors.WithStack(err)
+		return errors.WithStack(ConvertConflictError(err))
 	}
 
 	return nil
 }
+
+// RestoreUser 論理削除したユーザーを復元する。削除されていない場合はそのまま返す
+func (u *UserOperator) RestoreUser(id uint64) (*domain.UserModel, error) {
+	var user *domain.UserModel
+	err := retryOnConflict(writeConflictAttempts, func() error {
+		var err error
+		user, err = u.restoreUser(id)
+		return err
+	})
+	return user, err
+}
+
+func (u *UserOperator) restoreUser(id uint64) (*domain.UserModel, error) {
+	conn, err := u.Client.ConnectDB()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var userResource UserResource
+	_, err = u.Mapper.GetEntityByIDIncludingDeleted(id, &UserResource{}, &userResource)
+	if err != nil {
+		if err.Error() == dynamo.ErrNotFound.Error() {
+			return nil, errors.WithStack(domain.ErrNotFound)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	if userResource.DeletedAt().IsZero() {
+		return userResource.ToModel(), nil
+	}
+
+	r, err := u.Mapper.BuildQueryRestore(&userResource)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// 削除中に他のユーザーが同じメールアドレスを使っている場合は、重複チェック用レコードの作成が失敗する
+	uniq, err := u.UserEmailUniqGenerator.BuildQueryCreateByUser(&userResource)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = conn.WriteTx().Put(r).Put(uniq).Run()
+	if err != nil {
+		return nil, errors.WithStack(ConvertConflictError(err))
+	}
+
+	return userResource.ToModel(), nil
+}
+
+// PurgeDeletedUsers 指定した時刻より前に論理削除したユーザーを物理削除する
+func (u *UserOperator) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	purged, err := u.Mapper.PurgeDeletedEntities(
+		ctx,
+		u.Mapper.GetEntityNameFromStruct(UserResource{}),
+		deletedBefore,
+		func() DynamoResource { return &UserResource{Mapper: u.Mapper} })
+	if err != nil {
+		return purged, errors.WithStack(err)
+	}
+	return purged, nil
+}
@@ -3,6 +3,8 @@ package adapter
 import (
 	"clean-serverless-book-sample/domain"
 	"time"
+
+	"github.com/memememomo/nomof"
 )
 
 // UserResource DynamoDB上のデータ構造を表した構造体
@@ -15,11 +17,34 @@ type UserResource struct {
 
 func NewUserResource(userModel *domain.UserModel, mapper *DynamoModelMapper) *UserResource {
 	return &UserResource{
-		UserModel: *userModel,
-		Mapper:    mapper,
+		DynamoResourceBase: DynamoResourceBase{Version: userModel.Version},
+		UserModel:          *userModel,
+		Mapper:             mapper,
+	}
+}
+
+// ToModel ドメインモデルに変換する。DynamoDBから読み込んだバージョンはDynamoResourceBase側に入るため、モデルにも反映する
+func (u *UserResource) ToModel() *domain.UserModel {
+	model := u.UserModel
+	model.Version = u.Version()
+	return &model
+}
+
+// SystemAttributes フォロー数・フォロワー数はフォローの登録・解除で更新するため、バージョンに含めない
+func (u *UserResource) SystemAttributes() map[string]interface{} {
+	return map[string]interface{}{
+		"FollowerCount":  u.FollowerCount,
+		"FollowingCount": u.FollowingCount,
 	}
 }
 
+// filterUserItems スキャンの条件をユーザーのレコードだけに絞り込む。
+// ユーザーのPKの下にはフォロー関係のレコードも保存しているため、SKで除外する
+func filterUserItems(fb *nomof.Builder, mapper *DynamoModelMapper) {
+	fb.BeginsWith("PK", mapper.GetEntityNameFromStruct(UserResource{}))
+	fb.Append("NOT begins_with('SK', ?)", []interface{}{followEntityName + "#"})
+}
+
 // DynamoResourceインタフェースの実装
 
 func (u *UserResource) EntityName() string {
@@ -52,6 +77,7 @@ func (u *UserResource) ID() uint64 {
 
 func (u *UserResource) SetVersion(v int) {
 	u.DynamoResourceBase.Version = v
+	u.UserModel.Version = v
 }
 
 func (u *UserResource) Version() int {
@@ -73,3 +99,11 @@ func (u *UserResource) UpdatedAt() time.Time {
 func (u *UserResource) SetUpdatedAt(t time.Time) {
 	u.DynamoResourceBase.UpdatedAt = t
 }
+
+func (u *UserResource) DeletedAt() time.Time {
+	return u.DynamoResourceBase.DeletedAt
+}
+
+func (u *UserResource) SetDeletedAt(t time.Time) {
+	u.DynamoResourceBase.DeletedAt = t
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"fmt"
+
+	"gopkg.in/validator.v2"
+)
+
+// validateErrorMessages エラ〜メッセージテンプレート
+var validateErrorMessages = map[error]string{
+	validator.ErrUnsupported: "%sは不正な値です。",
+	validator.ErrZeroValue:   "%sを入力してください。",
+	validator.ErrLen:         "%sの文字列長が不正です。",
+	validator.ErrMax:         "%sの文字数が上限を超えています。",
+	ErrRequired:              "%sを入力してください。",
+	ErrEmail:                 "%sの形式が不正です。",
+	ErrUint:                  "%sは0以上の数値を入力してください。",
+	ErrUniq:                  "すでに登録されている%sです。",
+	ErrDate:                  "%sの形式が不正です。",
+	ErrScope:                 "%sに発行できないスコープが含まれています。",
+	ErrPassword:              "%sは8文字以上72バイト以下で、英字と数字を両方含めてください。",
+	ErrNotExist:              "%sが存在しません。",
+	ErrImageType:             "%sはJPEG、PNG、GIF、WebPのいずれかを指定してください。",
+	ErrMaxAttachments:        "%sは1つのマイクロポストに4つまでです。",
+	ErrUniqInFile:            "%sがファイル内の前の行と重複しています。",
+	ErrImportFileType:        "%sはtext/csvかapplication/x-ndjsonを指定してください。",
+}
+
+// displayNames 引数名の日本語表示
+var displayNames = map[string]string{
+	"user_id":          "ユーザーID",
+	"user_name":        "ユーザー名",
+	"micropost_id":     "マイクロポストID",
+	"email":            "メールアドレス",
+	"content":          "本文",
+	"name":             "名前",
+	"product_id":       "製品ID",
+	"id":               "ID",
+	"price":            "価格",
+	"release_date":     "発売日",
+	"limit":            "取得件数",
+	"next_token":       "ページトークン",
+	"idempotency_key":  "冪等キー",
+	"api_key_id":       "APIキーID",
+	"scopes":           "スコープ",
+	"expires_at":       "有効期限",
+	"password":         "パスワード",
+	"current_password": "現在のパスワード",
+	"new_password":     "新しいパスワード",
+	"target_id":        "フォローするユーザーID",
+	"in_reply_to":      "返信先のマイクロポストID",
+	"content_type":     "画像の形式",
+	"attachments":      "添付画像",
+	"file":             "ファイル",
+	"file_type":        "ファイルの形式",
+}
+
+// ConvertErrorsToMessage エラーメッセージに変換
+func ConvertErrorsToMessage(errs map[string]error) map[string]string {
+	messages := map[string]string{}
+
+	for argName, err := range errs {
+		disp := displayNames[argName]
+		if disp == "" {
+
+		}
+		message := validateErrorMessages[err]
+		if message == "" {
+			messages[argName] = err.Error()
+		} else {
+			messages[argName] = fmt.Sprintf(message, disp)
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,29 @@
+package validation
+
+// ProductSettingValidator 製品のバリデーション設定。製品APIと製品の一括取り込みで使う
+func ProductSettingValidator() *Validator {
+	return &Validator{
+		Settings: []*ValidatorSetting{
+			{ArgName: "name", ValidateTags: "required"},
+			{ArgName: "price", ValidateTags: "required,int,uint"},
+			{ArgName: "release_date", ValidateTags: "required,date"},
+		},
+	}
+}
+
+// PostSettingValidator ユーザーのバリデーション設定。ユーザーAPIとユーザーの一括取り込みで使う
+func PostSettingValidator() *Validator {
+	return &Validator{
+		Settings: []*ValidatorSetting{
+			{ArgName: "user_name", ValidateTags: "required"},
+			{ArgName: "email", ValidateTags: "required,email"},
+		},
+	}
+}
+
+// PostUserSettingValidator 新規作成時のバリデーション設定。パスワードは省略できる
+func PostUserSettingValidator() *Validator {
+	v := PostSettingValidator()
+	v.Settings = append(v.Settings, &ValidatorSetting{ArgName: "password", ValidateTags: "password"})
+	return v
+}
@@ -0,0 +1,289 @@
+package validation
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/logger"
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/validator.v2"
+)
+
+var (
+	ErrRequired       = validator.TextErr{Err: errors.New("required")}
+	ErrUint           = validator.TextErr{Err: errors.New("invalid uint")}
+	ErrEmail          = validator.TextErr{Err: errors.New("invalid email")}
+	ErrUniq           = validator.TextErr{Err: errors.New("unique email")}
+	ErrDate           = validator.TextErr{Err: errors.New("invalid date")}
+	ErrScope          = validator.TextErr{Err: errors.New("invalid scope")}
+	ErrPassword       = validator.TextErr{Err: errors.New("weak password")}
+	ErrNotExist       = validator.TextErr{Err: errors.New("not exist")}
+	ErrImageType      = validator.TextErr{Err: errors.New("unsupported image type")}
+	ErrMaxAttachments = validator.TextErr{Err: errors.New("too many attachments")}
+	ErrUniqInFile     = validator.TextErr{Err: errors.New("duplicate email in file")}
+	ErrImportFileType = validator.TextErr{Err: errors.New("unsupported import file type")}
+)
+
+// パスワードの長さの制限。bcryptは先頭72バイトまでしか使わないため、それを超えるパスワードは受け付けない
+const (
+	passwordMinLength = 8
+	passwordMaxBytes  = 72
+)
+
+// dateLayouts 日付として受け付けるISO-8601の形式
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
+type ValidatorSetting struct {
+	ArgName      string
+	ValidateTags string
+}
+
+type Validator struct {
+	Settings []*ValidatorSetting
+}
+
+func initValidator() {
+	validator.SetValidationFunc("required", requiredValidator)
+	validator.SetValidationFunc("uint", uintValidator)
+	validator.SetValidationFunc("email", emailValidator)
+	validator.SetValidationFunc("int", intValidator)
+	validator.SetValidationFunc("date", dateValidator)
+	validator.SetValidationFunc("scopes", scopesValidator)
+	validator.SetValidationFunc("password", passwordValidator)
+	validator.SetValidationFunc("image_type", imageTypeValidator)
+}
+
+func (v *Validator) Validate(params map[string]interface{}) map[string]error {
+	initValidator()
+
+	errs := map[string]error{}
+	for _, setting := range v.Settings {
+		err := validator.Valid(params[setting.ArgName], setting.ValidateTags)
+		if err != nil {
+			arr := err.(validator.ErrorArray)
+			errs[setting.ArgName] = arr[0]
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (v *Validator) ValidateBody(body string) map[string]error {
+	var b map[string]interface{}
+	err := json.Unmarshal([]byte(body), &b)
+	if err != nil {
+		return map[string]error{}
+	}
+	return v.Validate(b)
+}
+
+func requiredValidator(v interface{}, param string) error {
+	if v == nil {
+		return ErrRequired
+	}
+
+	st := reflect.ValueOf(v)
+
+	if st.String() == "" {
+		return ErrRequired
+	}
+
+	return nil
+}
+
+func uintValidator(v interface{}, param string) error {
+	if v == nil {
+		return nil
+	}
+
+	st := reflect.ValueOf(v)
+
+	if st.String() == "" {
+		return nil
+	}
+
+	var n int
+
+	switch st.Kind() {
+	case reflect.String:
+		n64, err := strconv.ParseInt(st.String(), 10, 64)
+		if err != nil {
+			logger.GetLogger().Warn("%s:%s", param, err.Error())
+			return validator.ErrUnsupported
+		}
+		n = int(n64)
+	case reflect.Int:
+		n = v.(int)
+	case reflect.Float64:
+		n = int(v.(float64))
+	default:
+		logger.GetLogger().Warn("%s:%s", param, st.Kind())
+		return validator.ErrUnsupported
+	}
+
+	if n < 0 {
+		return ErrUint
+	}
+
+	return nil
+}
+
+func emailValidator(v interface{}, param string) error {
+	if v == nil {
+		return nil
+	}
+
+	st := reflect.ValueOf(v)
+
+	if st.String() == "" {
+		return nil
+	}
+
+	_, err := mail.ParseAddress(st.String())
+	if err != nil {
+		logger.GetLogger().Warn("failed to parse %s: %s", st.String(), err.Error())
+		return ErrEmail
+	}
+
+	return nil
+}
+
+// intValidator JSONの数値が整数であることをチェックする
+func intValidator(v interface{}, param string) error {
+	if v == nil {
+		return nil
+	}
+
+	f, ok := v.(float64)
+	if !ok || f != float64(int64(f)) {
+		return validator.ErrUnsupported
+	}
+
+	return nil
+}
+
+func dateValidator(v interface{}, param string) error {
+	if v == nil {
+		return nil
+	}
+
+	st := reflect.ValueOf(v)
+
+	if st.Kind() != reflect.String {
+		return ErrDate
+	}
+
+	if st.String() == "" {
+		return nil
+	}
+
+	_, err := ParseDate(st.String())
+	if err != nil {
+		logger.GetLogger().Warn("failed to parse date", "value", st.String(), "error", err.Error())
+		return ErrDate
+	}
+
+	return nil
+}
+
+// scopesValidator 発行できるスコープを1つ以上含む文字列の配列であることをチェックする
+func scopesValidator(v interface{}, param string) error {
+	if v == nil {
+		return ErrRequired
+	}
+
+	scopes, ok := v.([]interface{})
+	if !ok {
+		return validator.ErrUnsupported
+	}
+	if len(scopes) == 0 {
+		return ErrRequired
+	}
+
+	for _, s := range scopes {
+		scope, ok := s.(string)
+		if !ok || !domain.IsValidScope(scope) {
+			return ErrScope
+		}
+	}
+
+	return nil
+}
+
+// imageTypeValidator 添付できる画像の形式のContent-Typeであることをチェックする
+func imageTypeValidator(v interface{}, param string) error {
+	if v == nil {
+		return nil
+	}
+
+	contentType, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+	if contentType == "" {
+		return nil
+	}
+
+	if !domain.IsAttachmentContentType(contentType) {
+		return ErrImageType
+	}
+
+	return nil
+}
+
+// passwordValidator パスワードの強度をチェックする。8文字以上72バイト以下で、英字と数字を両方含む必要がある
+func passwordValidator(v interface{}, param string) error {
+	if v == nil {
+		return nil
+	}
+
+	password, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+	if password == "" {
+		return nil
+	}
+
+	if utf8.RuneCountInString(password) < passwordMinLength || len(password) > passwordMaxBytes {
+		return ErrPassword
+	}
+
+	hasLetter, hasDigit := false, false
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return ErrPassword
+	}
+
+	return nil
+}
+
+// ParseDate ISO-8601形式(RFC3339 または YYYY-MM-DD)の日付文字列をパースする
+func ParseDate(str string) (time.Time, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		t, err = time.Parse(layout, str)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"clean-serverless-book-sample/logger"
+	"clean-serverless-book-sample/registry"
+	"flag"
+	"os"
+)
+
+// NOTE: ユーザー単位のインデックス(GSI1)を導入する前に作成されたマイクロポストに、インデックスのキーを設定するコマンド
+// NOTE: キーを持たないマイクロポストはユーザーごとの一覧などに出てこないため、GSI1を追加したデプロイの直後に一度実行する
+// NOTE: 例) go run ./cmd/backfill-micropost-user-index -dry-run
+func main() {
+	dryRun := flag.Bool("dry-run", false, "書き込みを行わずにキーの設定が必要なマイクロポスト数だけを表示する")
+	flag.Parse()
+
+	log := logger.GetLogger()
+
+	backfiller := registry.GetFactory().BuildMicropostUserIndexBackfiller()
+	result, err := backfiller.Backfill(*dryRun)
+	if err != nil {
+		log.Error("Failed to backfill micropost user index", "error", err)
+		os.Exit(1)
+	}
+
+	log.Info("Backfilled micropost user index",
+		"dryRun", *dryRun,
+		"scanned", result.Scanned,
+		"backfilled", result.Backfilled)
+}
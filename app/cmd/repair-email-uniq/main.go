@@ -0,0 +1,36 @@
+package main
+
+import (
+	"clean-serverless-book-sample/logger"
+	"clean-serverless-book-sample/registry"
+	"flag"
+	"os"
+)
+
+// NOTE: 既存のユーザーから欠損しているメールアドレス重複チェック用のレコード(UserEmailUniq)を再作成し、
+// NOTE: ユーザーが存在しないレコードを削除するコマンド
+// NOTE: 例) go run ./cmd/repair-email-uniq -dry-run
+func main() {
+	dryRun := flag.Bool("dry-run", false, "書き込みを行わずに再作成・削除が必要なレコード数だけを表示する")
+	flag.Parse()
+
+	log := logger.GetLogger()
+
+	repairer := registry.GetFactory().BuildUserEmailUniqRepairer()
+	result, err := repairer.Repair(*dryRun)
+	if err != nil {
+		log.Error("Failed to repair email uniq records", "error", err)
+		os.Exit(1)
+	}
+
+	log.Info("Repaired email uniq records",
+		"dryRun", *dryRun,
+		"scanned", result.Scanned,
+		"repaired", result.Repaired,
+		"removed", result.Removed,
+		"conflicts", result.Conflicts)
+
+	if len(result.Conflicts) > 0 {
+		os.Exit(2)
+	}
+}
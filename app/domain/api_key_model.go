@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIKeyの権限を表すスコープ
+const (
+	ScopeUsersRead       = "users:read"
+	ScopeUsersWrite      = "users:write"
+	ScopeMicropostsRead  = "microposts:read"
+	ScopeMicropostsWrite = "microposts:write"
+	ScopeProductsRead    = "products:read"
+	ScopeProductsWrite   = "products:write"
+	ScopeAPIKeysAdmin    = "apikeys:admin"
+)
+
+// Scopes 発行できるスコープの一覧
+var Scopes = []string{
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeMicropostsRead,
+	ScopeMicropostsWrite,
+	ScopeProductsRead,
+	ScopeProductsWrite,
+	ScopeAPIKeysAdmin,
+}
+
+// apiKeyPrefix APIキーの先頭に付ける文字列。キーの種類を見分けやすくするためのもの
+const apiKeyPrefix = "csk_"
+
+// APIKeyModel サービス間の呼び出しに使うAPIキーを表すModel。
+// キーそのものは発行時にだけ返し、保存するのはシークレット部分のハッシュ値だけにする
+type APIKeyModel struct {
+	ID         uint64
+	Name       string
+	SecretHash string
+	Scopes     []string
+	ExpiresAt  time.Time
+	Version    int
+}
+
+func NewAPIKeyModel(name, secretHash string, scopes []string, expiresAt time.Time) *APIKeyModel {
+	return &APIKeyModel{
+		Name:       name,
+		SecretHash: secretHash,
+		Scopes:     scopes,
+		ExpiresAt:  expiresAt,
+	}
+}
+
+// HasScope 指定したスコープを持っているかどうか
+func (m *APIKeyModel) HasScope(scope string) bool {
+	for _, s := range m.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired 有効期限が切れているかどうか
+func (m *APIKeyModel) IsExpired(now time.Time) bool {
+	return !now.Before(m.ExpiresAt)
+}
+
+// IsValidScope 発行できるスコープかどうか
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HashAPIKeySecret 保存用にシークレットのハッシュ値を計算する。
+// シークレットは十分な長さの乱数なので、パスワードのようなストレッチングはしない
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// FormatAPIKey IDとシークレットからクライアントに渡すキーを組み立てる。IDを含めることで検索用のインデックスを不要にしている
+func FormatAPIKey(id uint64, secret string) string {
+	return fmt.Sprintf("%s%d_%s", apiKeyPrefix, id, secret)
+}
+
+// ParseAPIKey キーからIDとシークレットを取り出す
+func ParseAPIKey(key string) (uint64, string, bool) {
+	rest, ok := strings.CutPrefix(key, apiKeyPrefix)
+	if !ok {
+		return 0, "", false
+	}
+
+	idStr, secret, ok := strings.Cut(rest, "_")
+	if !ok || secret == "" {
+		return 0, "", false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+
+	return id, secret, true
+}
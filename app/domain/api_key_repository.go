@@ -0,0 +1,10 @@
+package domain
+
+// APIKeyRepository APIキーのリポジトリ
+type APIKeyRepository interface {
+	CreateAPIKey(newAPIKey *APIKeyModel) (*APIKeyModel, error)
+	GetAPIKeyByID(id uint64) (*APIKeyModel, error)
+	GetAPIKeys(page *Page) ([]*APIKeyModel, string, error)
+	// DeleteAPIKey キーを失効させる。バージョンが指定されている場合は、一致しなければErrConflictを返す
+	DeleteAPIKey(apiKey *APIKeyModel) error
+}
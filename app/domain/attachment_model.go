@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxAttachmentsPerMicropost 1つのマイクロポストに添付できる画像の数の上限
+const MaxAttachmentsPerMicropost = 4
+
+// AttachmentKeyPrefix 添付画像を保存するオブジェクトキーの接頭辞。S3のイベント通知はこの接頭辞のものだけを添付画像として扱う
+const AttachmentKeyPrefix = "attachments/microposts/"
+
+// attachmentContentTypes 添付できる画像の形式
+var attachmentContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// AttachmentModel マイクロポストに添付した画像のメタデータ。画像そのものはS3に保存する
+type AttachmentModel struct {
+	Key         string
+	Size        int64
+	ContentType string
+	UploadedAt  time.Time
+}
+
+// IsAttachmentContentType 添付できる画像の形式かどうか
+func IsAttachmentContentType(contentType string) bool {
+	return attachmentContentTypes[contentType]
+}
+
+// NewAttachmentKey 添付画像を保存するオブジェクトキーを生成する。キーからどのマイクロポストの添付かわかるように、マイクロポストIDを含める
+func NewAttachmentKey(micropostID uint64, name string) string {
+	return fmt.Sprintf("%s%011d/%s", AttachmentKeyPrefix, micropostID, name)
+}
+
+// ParseAttachmentKey オブジェクトキーからマイクロポストIDを取り出す。添付画像のキーでない場合はfalseを返す
+func ParseAttachmentKey(key string) (uint64, bool) {
+	rest, ok := strings.CutPrefix(key, AttachmentKeyPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, name, ok := strings.Cut(rest, "/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return 0, false
+	}
+	micropostID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || micropostID == 0 {
+		return 0, false
+	}
+	return micropostID, true
+}
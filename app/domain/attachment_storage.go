@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// AttachmentStorage 添付画像を保存するストレージ。クライアントは発行したURLへ直接アップロードする
+type AttachmentStorage interface {
+	// PresignUpload 指定したキーにcontentTypeの画像をPUTでアップロードするための、ttlの間だけ有効なURLを発行する
+	PresignUpload(key, contentType string, ttl time.Duration) (string, error)
+	// GetAttachment アップロードされた画像のメタデータを取得する。存在しない場合はErrNotFoundを返す
+	GetAttachment(key string) (*AttachmentModel, error)
+}
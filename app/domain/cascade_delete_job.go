@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// CascadeDeleteJobModel 削除したユーザーのマイクロポストの削除が1回で終わらなかった場合に、続きを実行するためのジョブ
+type CascadeDeleteJobModel struct {
+	UserID    uint64
+	CreatedAt time.Time
+}
+
+// NewCascadeDeleteJobModel CascadeDeleteJobModelを生成する
+func NewCascadeDeleteJobModel(userID uint64, now time.Time) *CascadeDeleteJobModel {
+	return &CascadeDeleteJobModel{
+		UserID:    userID,
+		CreatedAt: now,
+	}
+}
@@ -0,0 +1,11 @@
+package domain
+
+// CascadeDeleteJobRepository 削除の継続ジョブのリポジトリ
+type CascadeDeleteJobRepository interface {
+	// PutCascadeDeleteJob ジョブを登録する。同じユーザーのジョブが既にある場合は上書きする
+	PutCascadeDeleteJob(job *CascadeDeleteJobModel) error
+	// GetCascadeDeleteJobs 登録が古い順にジョブを最大limit件取得する
+	GetCascadeDeleteJobs(limit int) ([]*CascadeDeleteJobModel, error)
+	// DeleteCascadeDeleteJob 完了したジョブを削除する
+	DeleteCascadeDeleteJob(userID uint64) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "context"
+
+// CounterReconcileResult 件数の数え直しの結果
+type CounterReconcileResult struct {
+	// Scanned 数え直したレコード数
+	Scanned int
+	// Fixed 保存している件数が異なっていたため書き直した(DryRunの場合は書き直しが必要な)レコード数
+	Fixed int
+	// Conflicts 数え直している間に更新されたため書き直さなかったレコード数。次の実行で数え直す
+	Conflicts int
+}
+
+// CounterReconciler 非正規化して保存している件数を、元のレコードから数え直す
+type CounterReconciler interface {
+	// ReconcileUserCounters ユーザーのフォロー数とフォロワー数を、フォロー関係のレコードから数え直す。
+	// ctxが取り消された場合は途中で打ち切る
+	ReconcileUserCounters(ctx context.Context, dryRun bool) (*CounterReconcileResult, error)
+	// ReconcileMicropostCounters マイクロポストのいいね数と返信数を、いいねと返信のレコードから数え直す。
+	// ctxが取り消された場合は途中で打ち切る
+	ReconcileMicropostCounters(ctx context.Context, dryRun bool) (*CounterReconcileResult, error)
+}
@@ -3,5 +3,20 @@ package domain
 import "github.com/pkg/errors"
 
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound         = errors.New("not found")
+	ErrInvalidPageToken = errors.New("invalid page token")
+	ErrConflict         = errors.New("conflict")
+	ErrUnauthorized     = errors.New("unauthorized")
+	// ErrDuplicateEmail メールアドレスが他のユーザーに使われているため書き込めなかった
+	ErrDuplicateEmail = errors.New("duplicate email")
+	// ErrDuplicateEmailInFile 一括取り込みのファイル内で、前の行と同じメールアドレスが使われている
+	ErrDuplicateEmailInFile = errors.New("duplicate email in file")
+	// ErrInReplyToNotFound 返信先のマイクロポストが存在しないため投稿できなかった
+	ErrInReplyToNotFound = errors.New("in reply to not found")
+	// ErrTooManyAttachments 添付できる画像の数の上限に達している
+	ErrTooManyAttachments = errors.New("too many attachments")
+	// ErrInvalidImportFile 取り込むファイルの形式が不正なため、1行も読み込めなかった
+	ErrInvalidImportFile = errors.New("invalid import file")
+	// ErrImportInterrupted 実行時間の期限が近づいたため、進捗を保存して取り込みを中断した。再実行すると続きから再開する
+	ErrImportInterrupted = errors.New("import interrupted")
 )
@@ -0,0 +1,11 @@
+package domain
+
+import "io"
+
+// FileStorage 取り込むファイルや、取り込み結果の報告などのファイルを保存するストレージ
+type FileStorage interface {
+	// OpenFile ファイルを読み込む。読み終わったら呼び出し側で閉じる。存在しない場合はErrNotFoundを返す
+	OpenFile(key string) (io.ReadCloser, error)
+	// PutFile ファイルを保存し、保存したファイルの版を表すETagを返す。同じキーのファイルがある場合は上書きする
+	PutFile(key, contentType string, body io.ReadSeeker) (string, error)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// FollowModel ユーザー間のフォロー関係を表すModel。UserIDのユーザーがTargetUserIDのユーザーをフォローしている
+type FollowModel struct {
+	UserID       uint64
+	TargetUserID uint64
+	CreatedAt    time.Time
+}
+
+func NewFollowModel(userID, targetUserID uint64, createdAt time.Time) *FollowModel {
+	return &FollowModel{
+		UserID:       userID,
+		TargetUserID: targetUserID,
+		CreatedAt:    createdAt,
+	}
+}
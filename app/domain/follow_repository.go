@@ -0,0 +1,14 @@
+package domain
+
+// FollowRepository フォロー関係のリポジトリ
+type FollowRepository interface {
+	// Follow フォローを登録し、双方のフォロー数とフォロワー数を増やす。
+	// すでにフォローしている場合は何もしない。どちらかのユーザーが存在しない場合はErrNotFoundを返す
+	Follow(userID, targetUserID uint64) error
+	// Unfollow フォローを解除し、双方のフォロー数とフォロワー数を減らす。フォローしていない場合はErrNotFoundを返す
+	Unfollow(userID, targetUserID uint64) error
+	// GetFollowing フォローしているユーザーをユーザーID順に取得する
+	GetFollowing(userID uint64, page *Page) ([]*FollowModel, string, error)
+	// GetFollowers フォロワーをフォローされた時刻が新しい順に取得する
+	GetFollowers(userID uint64, page *Page) ([]*FollowModel, string, error)
+}
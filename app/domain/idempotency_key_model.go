@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// IdempotencyKeyTTL 冪等キーを保持する期間
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyKeyLease 処理中のキーを占有する期間。APIのLambdaのタイムアウト(30秒)より長くし、
+// タイムアウトやクラッシュで完了しなかったキーは、この期間が過ぎた後の同じリクエストで引き継げるようにする
+const IdempotencyKeyLease = time.Minute
+
+// IdempotencyKeyModel 冪等キー(Idempotency-Key)で受け付けたリクエストと、そのレスポンスを表すモデル。
+// キーは呼び出し元(Owner)ごとに区別し、他の呼び出し元が同じキーを使っても影響しないようにする
+type IdempotencyKeyModel struct {
+	// Owner キーを登録した呼び出し元。認証したユーザーやAPIキーを表す
+	Owner        string
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ResponseBody string
+	ExpiresAt    time.Time
+	// LockedUntil 処理中のキーを占有している期限
+	LockedUntil time.Time
+}
+
+// NewIdempotencyKeyModel 処理中の状態で冪等キーのモデルを生成する
+func NewIdempotencyKeyModel(owner, key, requestHash string, now time.Time) *IdempotencyKeyModel {
+	return &IdempotencyKeyModel{
+		Owner:       owner,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(IdempotencyKeyTTL),
+		LockedUntil: now.Add(IdempotencyKeyLease),
+	}
+}
+
+// IsCompleted レスポンスまで保存済みかどうか。処理中の場合はStatusCodeが0になっている
+func (m *IdempotencyKeyModel) IsCompleted() bool {
+	return m.StatusCode != 0
+}
+
+// IsExpired 有効期限が切れているかどうか
+func (m *IdempotencyKeyModel) IsExpired(now time.Time) bool {
+	return !now.Before(m.ExpiresAt)
+}
+
+// CanTakeOver 処理中のまま占有期限が過ぎたキーを、同じリクエストで引き継げるかどうか
+func (m *IdempotencyKeyModel) CanTakeOver(requestHash string, now time.Time) bool {
+	return !m.IsCompleted() && m.RequestHash == requestHash && !now.Before(m.LockedUntil)
+}
@@ -0,0 +1,14 @@
+package domain
+
+// IdempotencyKeyRepository 冪等キーのリポジトリ
+type IdempotencyKeyRepository interface {
+	// ReserveIdempotencyKey キーを処理中として登録する。有効期限内の同じキーが既にある場合はErrConflictを返す。
+	// ただし同じリクエストで、処理中のまま占有期限が過ぎている場合は引き継ぐ
+	ReserveIdempotencyKey(key *IdempotencyKeyModel) error
+	// GetIdempotencyKey 呼び出し元ownerのキーを取得する。存在しない場合と有効期限が切れている場合はErrNotFoundを返す
+	GetIdempotencyKey(owner, key string) (*IdempotencyKeyModel, error)
+	// CompleteIdempotencyKey レスポンスを保存して処理済みにする。占有期限が過ぎて他のリクエストに引き継がれていた場合はErrConflictを返す
+	CompleteIdempotencyKey(key *IdempotencyKeyModel) error
+	// DeleteIdempotencyKey キーを削除して、同じキーで再実行できるようにする。占有期限が過ぎて他のリクエストに引き継がれていた場合は削除せずにErrConflictを返す
+	DeleteIdempotencyKey(key *IdempotencyKeyModel) error
+}
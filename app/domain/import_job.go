@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// 取り込みジョブの状態
+const (
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+)
+
+// 一括で取り込むファイルを置くオブジェクトキーの接頭辞。接頭辞で取り込むリソースを判別する
+const (
+	ProductImportKeyPrefix = "imports/products/"
+	UserImportKeyPrefix    = "imports/users/"
+)
+
+// importReportKeyPrefix 取り込み結果の報告を置くオブジェクトキーの接頭辞。取り込むファイルの接頭辞と重ならないようにし、報告を書き込んでも取り込みが始まらないようにする
+const importReportKeyPrefix = "import-reports/"
+
+// ImportJobModel ファイルからの一括取り込みの進捗。S3のイベント通知は重複して届くことがあるため、
+// 取り込むファイルとその版(ETag)からIDを決め、同じファイルを二重に取り込まないようにする
+type ImportJobModel struct {
+	ID     string
+	Source string
+	ETag   string
+	Status string
+	// Processed 処理した行数。失敗した行も含む。途中で中断した場合は次の行から再開する
+	Processed int
+	// Failed 検証や書き込みに失敗した行数
+	Failed int
+	// ReportKey 失敗した行を報告するファイルのキー
+	ReportKey string
+	// LockedUntil 実行中のジョブを占有している期限。期限が過ぎたジョブは、次の実行が進捗を引き継いで再開する
+	LockedUntil time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewImportJobID 取り込むファイルとその版からジョブのIDを生成する。
+// ETagはS3のイベントでは引用符が無く、オブジェクトの保存時には引用符が付くため、取り除いて同じIDにする
+func NewImportJobID(source, etag string) string {
+	sum := sha256.Sum256([]byte(source + "\n" + strings.Trim(etag, `"`)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ImportReportKey 取り込むファイルのキーから、失敗した行を報告するファイルのキーを生成する
+func ImportReportKey(source string) string {
+	return importReportKeyPrefix + strings.TrimPrefix(source, "imports/") + ".errors.jsonl"
+}
+
+// NewImportJobModel 実行中の状態で取り込みジョブを生成する
+func NewImportJobModel(source, etag string, now, lockedUntil time.Time) *ImportJobModel {
+	return &ImportJobModel{
+		ID:          NewImportJobID(source, etag),
+		Source:      source,
+		ETag:        etag,
+		Status:      ImportJobStatusRunning,
+		ReportKey:   ImportReportKey(source),
+		LockedUntil: lockedUntil,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// RequiredScope ジョブを参照するのに必要なAPIキーのスコープ。取り込み先のリソースに書き込むスコープと同じにする
+func (j *ImportJobModel) RequiredScope() string {
+	switch {
+	case strings.HasPrefix(j.Source, UserImportKeyPrefix):
+		return ScopeUsersWrite
+	case strings.HasPrefix(j.Source, ProductImportKeyPrefix):
+		return ScopeProductsWrite
+	default:
+		return ScopeAPIKeysAdmin
+	}
+}
+
+// IsCompleted 全ての行を処理し終えたかどうか
+func (j *ImportJobModel) IsCompleted() bool {
+	return j.Status == ImportJobStatusCompleted
+}
+
+// CanTakeOver 実行中のまま占有期限が過ぎたジョブを、他の実行が引き継げるかどうか
+func (j *ImportJobModel) CanTakeOver(now time.Time) bool {
+	return !j.IsCompleted() && !now.Before(j.LockedUntil)
+}
@@ -0,0 +1,13 @@
+package domain
+
+// ImportJobRepository 取り込みジョブのリポジトリ
+type ImportJobRepository interface {
+	// StartImportJob ジョブを開始する。同じIDのジョブが無い場合は登録して返し、占有期限が過ぎた実行中のジョブがある場合は
+	// 占有期限をjobのものに更新し、途中までの進捗と合わせて返す。
+	// 完了したジョブや、他の実行が占有しているジョブがある場合はErrConflictを返す
+	StartImportJob(job *ImportJobModel) (*ImportJobModel, error)
+	// UpdateImportJob 進捗や状態を保存する。開始した時の占有期限のままであることを条件にし、他の実行に引き継がれていた場合はErrConflictを返す
+	UpdateImportJob(job *ImportJobModel) error
+	// GetImportJob ジョブを取得する。存在しない場合はErrNotFoundを返す
+	GetImportJob(id string) (*ImportJobModel, error)
+}
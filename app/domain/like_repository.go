@@ -0,0 +1,10 @@
+package domain
+
+// LikeRepository マイクロポストへのいいねのリポジトリ
+type LikeRepository interface {
+	// Like いいねを登録し、マイクロポストのいいね数を増やす。
+	// すでにいいねしている場合は何もしない。マイクロポストかユーザーが存在しない場合はErrNotFoundを返す
+	Like(micropostID, userID uint64) error
+	// Unlike いいねを取り消し、マイクロポストのいいね数を減らす。いいねしていない場合はErrNotFoundを返す
+	Unlike(micropostID, userID uint64) error
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MicropostCursor 投稿日時順に並べたマイクロポストの読み出し位置。この位置より古いものが続きになる
+type MicropostCursor struct {
+	CreatedAt time.Time
+	ID        uint64
+}
+
+// NewMicropostCursor 指定したマイクロポストの位置を表すカーソルを生成する
+func NewMicropostCursor(m *MicropostModel) *MicropostCursor {
+	return &MicropostCursor{CreatedAt: m.CreatedAt, ID: m.ID}
+}
+
+// ParseMicropostCursor Encodeで生成したトークンからカーソルを復元する。不正なトークンの場合はErrInvalidPageTokenを返す
+func ParseMicropostCursor(token string) (*MicropostCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.WithStack(ErrInvalidPageToken)
+	}
+
+	nanosStr, idStr, ok := strings.Cut(string(b), ".")
+	if !ok {
+		return nil, errors.WithStack(ErrInvalidPageToken)
+	}
+	nanos, err := strconv.ParseInt(nanosStr, 10, 64)
+	if err != nil {
+		return nil, errors.WithStack(ErrInvalidPageToken)
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		return nil, errors.WithStack(ErrInvalidPageToken)
+	}
+
+	return &MicropostCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+// Encode ページトークンとして返せる文字列に変換する
+func (c *MicropostCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%d", c.CreatedAt.UnixNano(), c.ID)))
+}
+
+// IsBefore マイクロポストがカーソルの位置より古いかどうか
+func (c *MicropostCursor) IsBefore(m *MicropostModel) bool {
+	return (&MicropostModel{ID: c.ID, CreatedAt: c.CreatedAt}).IsNewerThan(m)
+}
@@ -1,12 +1,64 @@
 package domain
 
+import "time"
+
 // MicropostModel マイクロポストのモデル
 type MicropostModel struct {
-	ID      uint64
-	Content string
-	UserID  uint64
+	ID        uint64
+	Content   string
+	UserID    uint64
+	Version   int
+	CreatedAt time.Time
+	// LikeCount いいねされた数。いいねの登録・取り消しと同じトランザクションで更新する
+	LikeCount int
+	// InReplyToID 返信先のマイクロポストのID。返信でない場合は0
+	InReplyToID uint64
+	// ReplyCount 返信された数。返信の投稿・削除と同じトランザクションで更新する
+	ReplyCount int
+	// Orphaned 返信先のマイクロポストが削除されたかどうか。返信先を失った返信は削除せずにこの印を付けて残す
+	Orphaned bool
+	// Attachments 添付した画像。S3へのアップロードが完了した順に並ぶ
+	Attachments []AttachmentModel
 }
 
 func NewMicropostModel(content string, userID uint64) *MicropostModel {
 	return &MicropostModel{Content: content, UserID: userID}
 }
+
+// NewReplyMicropostModel 指定したマイクロポストへの返信を生成する
+func NewReplyMicropostModel(content string, userID, inReplyToID uint64) *MicropostModel {
+	return &MicropostModel{Content: content, UserID: userID, InReplyToID: inReplyToID}
+}
+
+// Hashtags 本文に含まれるハッシュタグ
+func (m *MicropostModel) Hashtags() []string {
+	return ExtractHashtags(m.Content)
+}
+
+// Mentions 本文でメンションされているユーザーのID
+func (m *MicropostModel) Mentions() []uint64 {
+	return ExtractMentions(m.Content)
+}
+
+// HasAttachment 指定したキーの画像が添付済みかどうか
+func (m *MicropostModel) HasAttachment(key string) bool {
+	for _, a := range m.Attachments {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReply 返信かどうか
+func (m *MicropostModel) IsReply() bool {
+	return m.InReplyToID != 0
+}
+
+// IsNewerThan 投稿日時がoより新しいかどうか。同時刻の場合はIDが大きい方を新しいものとする
+func (m *MicropostModel) IsNewerThan(o *MicropostModel) bool {
+	if !m.CreatedAt.Equal(o.CreatedAt) {
+		return m.CreatedAt.After(o.CreatedAt)
+	}
+	return m.ID > o.ID
+}
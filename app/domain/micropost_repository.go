@@ -1,10 +1,43 @@
 package domain
 
+import (
+	"context"
+	"time"
+)
+
 // MicropostRepository Micropostモデルのリポジトリ
 type MicropostRepository interface {
+	// CreateMicropost マイクロポストを作成する。投稿するユーザーが存在しない場合はErrNotFoundを返す。
+	// 返信の場合は返信先の返信数も更新し、返信先が存在しない場合はErrInReplyToNotFoundを返す。
+	// 本文のハッシュタグとメンションは検索できるように索引に登録する
 	CreateMicropost(newMicropost *MicropostModel) (*MicropostModel, error)
+	// UpdateMicropost 本文を更新する。ハッシュタグとメンションの索引も本文に合わせて追加・削除する
 	UpdateMicropost(newMicropost *MicropostModel) error
 	GetMicropostByID(id uint64) (*MicropostModel, error)
-	GetMicropostsByUserID(userID uint64) ([]*MicropostModel, error)
-	DeleteMicropost(id uint64) error
+	// AddAttachment アップロードが完了した画像をマイクロポストに添付する。同じキーの画像が添付済みの場合は何もしない。
+	// マイクロポストが存在しない場合はErrNotFound、添付数が上限に達している場合はErrTooManyAttachmentsを返す
+	AddAttachment(micropostID uint64, attachment *AttachmentModel) error
+	// GetMicropostsByUserID 指定したユーザーのマイクロポストを投稿日時が新しい順に取得する
+	GetMicropostsByUserID(userID uint64, page *Page) ([]*MicropostModel, string, error)
+	// GetMicropostsByUserIDBefore 指定したユーザーのマイクロポストのうち、カーソルの位置より古いものを新しい順に最大limit件取得する。
+	// カーソルがnilの場合は最新のものから取得する
+	GetMicropostsByUserIDBefore(userID uint64, before *MicropostCursor, limit int) ([]*MicropostModel, error)
+	// GetMicropostsByHashtag 指定したハッシュタグを含むマイクロポストを投稿日時が新しい順に取得する。タグはNormalizeHashtagで正規化したものを指定する
+	GetMicropostsByHashtag(tag string, page *Page) ([]*MicropostModel, string, error)
+	// GetMicropostsMentioning 指定したユーザーをメンションしているマイクロポストを投稿日時が新しい順に取得する
+	GetMicropostsMentioning(userID uint64, page *Page) ([]*MicropostModel, string, error)
+	// GetRepliesByMicropostID 指定したマイクロポストへの返信を投稿日時が古い順に取得する
+	GetRepliesByMicropostID(micropostID uint64, page *Page) ([]*MicropostModel, string, error)
+	// DeleteMicropost マイクロポストを論理削除する。返信の場合は返信先の返信数を減らし、
+	// 返信されている場合は返信をOrphanedにして残す
+	DeleteMicropost(micropost *MicropostModel) error
+	// DeleteMicropostsByUserID 指定したユーザーのマイクロポストを最大limit件まで論理削除し、その件数を返す。
+	// limit件に達した場合や、他の更新と競合して削除できなかったものがある場合はremainingにtrueを返す
+	DeleteMicropostsByUserID(userID uint64, limit int) (deleted int, remaining bool, err error)
+	// RestoreMicropostsByUserID DeleteMicropostsByUserIDで論理削除したマイクロポストを最大limit件まで復元し、その件数を返す。
+	// 個別に削除したマイクロポストは復元しない。
+	// 残りがある場合はremainingにtrueを返す
+	RestoreMicropostsByUserID(userID uint64, limit int) (restored int, remaining bool, err error)
+	// PurgeDeletedMicroposts 指定した時刻より前に論理削除したマイクロポストを物理削除し、その件数を返す。ctxが取り消された場合は途中で打ち切る
+	PurgeDeletedMicroposts(ctx context.Context, deletedBefore time.Time) (int, error)
 }
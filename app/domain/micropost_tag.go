@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+var (
+	// hashtagPattern #タグ形式のハッシュタグ。全角の＃も受け付け、英数字の直後の#(URLのフラグメントなど)はタグとみなさない
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/])[#＃]([\p{L}\p{M}\p{N}_]+)`)
+	// mentionPattern @ユーザーID形式のメンション。ユーザー名は一意ではないため、ユーザーIDで指定する。全角の＠と数字も受け付ける
+	mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.])[@＠]([0-9０-９]+)`)
+)
+
+// NormalizeHashtag ハッシュタグを検索用に正規化する。全角英数字を半角にし、英字を小文字にそろえる
+func NormalizeHashtag(tag string) string {
+	tag = strings.TrimLeft(tag, "#＃")
+	return strings.ToLower(width.Fold.String(tag))
+}
+
+// ExtractHashtags 本文に含まれるハッシュタグを正規化して、出現順に重複なく返す
+func ExtractHashtags(content string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(content, -1) {
+		tag := NormalizeHashtag(match[1])
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// ExtractMentions 本文でメンションされているユーザーIDを、出現順に重複なく返す
+func ExtractMentions(content string) []uint64 {
+	userIDs := []uint64{}
+	seen := map[uint64]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		userID, err := strconv.ParseUint(width.Fold.String(match[1]), 10, 64)
+		if err != nil || userID == 0 || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs
+}
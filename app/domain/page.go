@@ -0,0 +1,25 @@
+package domain
+
+const (
+	// DefaultPageLimit 一覧取得時の件数の既定値
+	DefaultPageLimit = 20
+	// MaxPageLimit 一覧取得時の件数の上限
+	MaxPageLimit = 100
+)
+
+// Page 一覧取得時のページング条件
+type Page struct {
+	Limit     int
+	NextToken string
+}
+
+// NewPage Pageインスタンスを生成する。件数は既定値と上限の範囲に丸める
+func NewPage(limit int, nextToken string) *Page {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	return &Page{Limit: limit, NextToken: nextToken}
+}
@@ -0,0 +1,8 @@
+package domain
+
+// PasswordHasher パスワードをハッシュ化して照合する
+type PasswordHasher interface {
+	HashPassword(password string) (string, error)
+	// ComparePassword ハッシュ値とパスワードを照合する。一致しない場合はErrUnauthorizedを返す
+	ComparePassword(hash, password string) error
+}
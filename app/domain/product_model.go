@@ -8,6 +8,7 @@ type ProductModel struct {
 	Name        string
 	Price       int
 	ReleaseDate time.Time
+	Version     int
 }
 
 func NewProductModel(name string, price int, releaseDate time.Time) *ProductModel {
@@ -4,7 +4,10 @@ package domain
 type ProductRepository interface {
 	CreateProduct(newProduct *ProductModel) (*ProductModel, error)
 	UpdateProduct(newProduct *ProductModel) error
+	// SaveProducts IDが0の製品は新規作成し、それ以外は更新する。まとめて書き込み、書き込めなかった製品は
+	// errsの同じ位置にエラーを返す。更新する製品が存在しない場合はErrNotFound、バージョンが一致しない場合はErrConflictになる
+	SaveProducts(products []*ProductModel) (errs []error, err error)
 	GetProductByID(id uint64) (*ProductModel, error)
-	GetProducts() ([]*ProductModel, error)
-	DeleteProduct(id uint64) error
+	GetProducts(page *Page) ([]*ProductModel, string, error)
+	DeleteProduct(product *ProductModel) error
 }
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ResourceEvent リソースの変更を表すドメインイベント。テーブルの変更ストリームから生成する
+type ResourceEvent interface {
+	// EventName イベントの種類
+	EventName() string
+	// OccurredAt 変更した時刻
+	OccurredAt() time.Time
+}
+
+// ResourceEventBase イベントに共通する項目
+type ResourceEventBase struct {
+	At time.Time
+}
+
+func (e ResourceEventBase) OccurredAt() time.Time {
+	return e.At
+}
+
+// UserCreated ユーザーが作成された
+type UserCreated struct {
+	ResourceEventBase
+	User *UserModel
+}
+
+// UserUpdated ユーザーが更新された。フォロー数の更新も含む
+type UserUpdated struct {
+	ResourceEventBase
+	Old *UserModel
+	New *UserModel
+}
+
+// UserDeleted ユーザーが論理削除された
+type UserDeleted struct {
+	ResourceEventBase
+	User *UserModel
+}
+
+// UserRestored 論理削除したユーザーが復元された
+type UserRestored struct {
+	ResourceEventBase
+	User *UserModel
+}
+
+// UserPurged ユーザーのレコードが物理削除された
+type UserPurged struct {
+	ResourceEventBase
+	User *UserModel
+}
+
+// MicropostCreated マイクロポストが投稿された
+type MicropostCreated struct {
+	ResourceEventBase
+	Micropost *MicropostModel
+}
+
+// MicropostUpdated マイクロポストが更新された。いいね数や返信数、添付画像の更新も含む
+type MicropostUpdated struct {
+	ResourceEventBase
+	Old *MicropostModel
+	New *MicropostModel
+}
+
+// MicropostDeleted マイクロポストが論理削除された
+type MicropostDeleted struct {
+	ResourceEventBase
+	Micropost *MicropostModel
+}
+
+// MicropostRestored 論理削除したマイクロポストが復元された
+type MicropostRestored struct {
+	ResourceEventBase
+	Micropost *MicropostModel
+}
+
+// MicropostPurged マイクロポストのレコードが物理削除された
+type MicropostPurged struct {
+	ResourceEventBase
+	Micropost *MicropostModel
+}
+
+func (e *UserCreated) EventName() string       { return "UserCreated" }
+func (e *UserUpdated) EventName() string       { return "UserUpdated" }
+func (e *UserDeleted) EventName() string       { return "UserDeleted" }
+func (e *UserRestored) EventName() string      { return "UserRestored" }
+func (e *UserPurged) EventName() string        { return "UserPurged" }
+func (e *MicropostCreated) EventName() string  { return "MicropostCreated" }
+func (e *MicropostUpdated) EventName() string  { return "MicropostUpdated" }
+func (e *MicropostDeleted) EventName() string  { return "MicropostDeleted" }
+func (e *MicropostRestored) EventName() string { return "MicropostRestored" }
+func (e *MicropostPurged) EventName() string   { return "MicropostPurged" }
+
+// ResourceEventSubscriber イベントを受け取る処理。エラーを返した場合はイベントを再送する
+type ResourceEventSubscriber func(ctx context.Context, event ResourceEvent) error
+
+// ResourceEventBus イベントを登録された処理に配信する
+type ResourceEventBus struct {
+	subscribers map[string][]ResourceEventSubscriber
+}
+
+func NewResourceEventBus() *ResourceEventBus {
+	return &ResourceEventBus{subscribers: map[string][]ResourceEventSubscriber{}}
+}
+
+// Subscribe 型Eのイベントを受け取る処理を登録する
+func Subscribe[E ResourceEvent](bus *ResourceEventBus, fn func(ctx context.Context, event E) error) {
+	var zero E
+	name := zero.EventName()
+	bus.subscribers[name] = append(bus.subscribers[name], func(ctx context.Context, event ResourceEvent) error {
+		return fn(ctx, event.(E))
+	})
+}
+
+// SubscribeAll すべての種類のイベントを受け取る処理を登録する
+func (b *ResourceEventBus) SubscribeAll(fn ResourceEventSubscriber) {
+	b.subscribers[""] = append(b.subscribers[""], fn)
+}
+
+// Publish イベントを登録された順に配信する。処理がエラーを返した場合は、以降の処理には配信せずにエラーを返す。
+// 再送で同じイベントを複数回受け取ることがあるため、処理は冪等にする
+func (b *ResourceEventBus) Publish(ctx context.Context, event ResourceEvent) error {
+	for _, fn := range b.subscribers[""] {
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+	}
+	for _, fn := range b.subscribers[event.EventName()] {
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ExportKeyPrefix テーブルの書き出しを置くオブジェクトキーの接頭辞
+const ExportKeyPrefix = "exports/"
+
+// TableExportSource 書き出すためにテーブルの全ての項目を読み込む
+type TableExportSource interface {
+	// ScanAll テーブルをsegments個に分けて並列に走査し、項目を種類ごとにfnへ渡す。fnは複数のgoroutineから同時に呼ばれる。
+	// fnがエラーを返した場合やctxが終了した場合は、走査を止めてそのエラーを返す
+	ScanAll(ctx context.Context, segments int, fn func(entityType string, item map[string]interface{}) error) error
+}
+
+// NewExportPrefix 書き出した日付ごとのオブジェクトキーの接頭辞を生成する。
+// 分析基盤から日付で絞り込めるよう、dt=YYYY-MM-DD の形式で分ける
+func NewExportPrefix(exportedAt time.Time) string {
+	return ExportKeyPrefix + "dt=" + exportedAt.UTC().Format("2006-01-02") + "/"
+}
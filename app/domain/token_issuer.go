@@ -0,0 +1,8 @@
+package domain
+
+import "time"
+
+// TokenIssuer ログインしたユーザーにベアラートークンを発行する。発行したトークンはTokenVerifierで検証できる
+type TokenIssuer interface {
+	IssueToken(userID uint64) (string, time.Time, error)
+}
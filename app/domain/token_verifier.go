@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// AuthClaims 検証済みのトークンから取り出した呼び出し元の情報
+type AuthClaims struct {
+	UserID    uint64
+	ExpiresAt time.Time
+}
+
+// TokenVerifier ベアラートークンを検証する。検証できない場合はErrUnauthorizedを返す
+type TokenVerifier interface {
+	VerifyToken(token string) (*AuthClaims, error)
+}
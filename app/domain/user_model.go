@@ -1,12 +1,22 @@
 package domain
 
-// UserModel ユーザーモデル
+// UserModel ユーザーモデル。PasswordHashはパスワードを設定していないユーザーでは空になる。
+// FollowerCountとFollowingCountはフォローの登録・解除と同じトランザクションで更新する
 type UserModel struct {
-	ID    uint64
-	Name  string
-	Email string
+	ID             uint64
+	Name           string
+	Email          string
+	PasswordHash   string
+	FollowerCount  int
+	FollowingCount int
+	Version        int
 }
 
 func NewUserModel(name, email string) *UserModel {
 	return &UserModel{Name: name, Email: email}
 }
+
+// HasPassword パスワードを設定しているかどうか
+func (u *UserModel) HasPassword() bool {
+	return u.PasswordHash != ""
+}
@@ -1,11 +1,24 @@
 package domain
 
+import (
+	"context"
+	"time"
+)
+
 // UserRepository ユーザーモデルのリポジトリ
 type UserRepository interface {
-	GetUsers() ([]*UserModel, error)
+	GetUsers(page *Page) ([]*UserModel, string, error)
 	GetUserByID(id uint64) (*UserModel, error)
 	GetUserByEmail(email string) (*UserModel, error)
+	// CreateUser ユーザーを新規作成する。メールアドレスが他のユーザーに使われている場合はErrDuplicateEmailを返す
 	CreateUser(newUser *UserModel) (*UserModel, error)
+	// UpdateUser ユーザーを更新する。バージョンが一致しない場合はErrConflictを、メールアドレスが他のユーザーに使われている場合はErrDuplicateEmailを返す
 	UpdateUser(newUser *UserModel) error
 	DeleteUser(targetUser *UserModel) error
+	// UpdateUserPassword パスワードのハッシュ値だけを更新する。バージョンが一致しない場合はErrConflictを返す
+	UpdateUserPassword(user *UserModel) error
+	// RestoreUser 論理削除したユーザーを復元する。メールアドレスが他のユーザーに使われている場合はErrConflictを返す
+	RestoreUser(id uint64) (*UserModel, error)
+	// PurgeDeletedUsers 指定した時刻より前に論理削除したユーザーを物理削除し、その件数を返す。ctxが取り消された場合は途中で打ち切る
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int, error)
 }
@@ -0,0 +1,6 @@
+package domain
+
+// MatchVersion 楽観的ロックのバージョンが一致するかどうか。期待するバージョンが0の場合は何もチェックしない
+func MatchVersion(expected, actual int) bool {
+	return expected == 0 || expected == actual
+}
@@ -0,0 +1,56 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"crypto/subtle"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuthenticateAPIKey APIキー認証
+type AuthenticateAPIKey struct {
+	APIKeyRepository domain.APIKeyRepository
+	// AdminKey 最初のAPIキーを発行するための管理用キー。空の場合は使わない
+	AdminKey string
+}
+
+func NewAuthenticateAPIKey(repos domain.APIKeyRepository, adminKey string) *AuthenticateAPIKey {
+	return &AuthenticateAPIKey{
+		APIKeyRepository: repos,
+		AdminKey:         adminKey,
+	}
+}
+
+// Execute キーを検証してAPIキーを返す。存在しない・シークレットが一致しない・有効期限切れの場合はdomain.ErrUnauthorizedを返す
+func (a *AuthenticateAPIKey) Execute(req *usecase.AuthenticateAPIKeyRequest) (*usecase.AuthenticateAPIKeyResponse, error) {
+	if a.AdminKey != "" && subtle.ConstantTimeCompare([]byte(req.Key), []byte(a.AdminKey)) == 1 {
+		return &usecase.AuthenticateAPIKeyResponse{
+			APIKey: &domain.APIKeyModel{Name: "admin", Scopes: []string{domain.ScopeAPIKeysAdmin}},
+		}, nil
+	}
+
+	id, secret, ok := domain.ParseAPIKey(req.Key)
+	if !ok {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	apiKey, err := a.APIKeyRepository.GetAPIKeyByID(id)
+	if err != nil {
+		if errors.Cause(err) == domain.ErrNotFound {
+			return nil, errors.WithStack(domain.ErrUnauthorized)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	hash := domain.HashAPIKeySecret(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(apiKey.SecretHash)) != 1 {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+	if apiKey.IsExpired(time.Now()) {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	return &usecase.AuthenticateAPIKeyResponse{APIKey: apiKey}, nil
+}
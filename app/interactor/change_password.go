@@ -0,0 +1,62 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrPasswordMismatch = errors.New("current password mismatch")
+)
+
+// ChangePassword パスワード変更
+type ChangePassword struct {
+	UserRepository domain.UserRepository
+	PasswordHasher domain.PasswordHasher
+}
+
+func NewChangePassword(repos domain.UserRepository, hasher domain.PasswordHasher) *ChangePassword {
+	return &ChangePassword{
+		UserRepository: repos,
+		PasswordHasher: hasher,
+	}
+}
+
+// Execute 現在のパスワードを確認してからパスワードを変更する。
+// 一致しない場合とパスワードを設定していないユーザーの場合はErrPasswordMismatchを返す
+func (c *ChangePassword) Execute(req *usecase.ChangePasswordRequest) (*usecase.ChangePasswordResponse, error) {
+	user, err := c.UserRepository.GetUserByID(req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !user.HasPassword() {
+		return nil, errors.WithStack(ErrPasswordMismatch)
+	}
+
+	err = c.PasswordHasher.ComparePassword(user.PasswordHash, req.CurrentPassword)
+	if err != nil {
+		if errors.Cause(err) == domain.ErrUnauthorized {
+			return nil, errors.WithStack(ErrPasswordMismatch)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	hash, err := c.PasswordHasher.HashPassword(req.NewPassword)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// 確認してから更新するまでの間に他の変更があった場合はdomain.ErrConflictになる
+	err = c.UserRepository.UpdateUserPassword(&domain.UserModel{
+		ID:           user.ID,
+		PasswordHash: hash,
+		Version:      user.Version,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.ChangePasswordResponse{}, nil
+}
@@ -0,0 +1,105 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"context"
+	"log/slog"
+
+	"github.com/pkg/errors"
+)
+
+// cascadeDeleteLimit 1回の呼び出しで論理削除するマイクロポストの上限。Lambdaのタイムアウトに収まる件数にしている
+const cascadeDeleteLimit = 1000
+
+// cascadeRestoreLimit 1回の呼び出しで復元するマイクロポストの上限。1件ずつ書き込むため、削除より少なくしている
+const cascadeRestoreLimit = 200
+
+// ContinueCascadeDelete 削除したユーザーのマイクロポストの削除を、登録済みのジョブから再開する。
+// 削除の途中でユーザーが復元された場合は、削除したマイクロポストの復元を続ける
+type ContinueCascadeDelete struct {
+	UserRepository             domain.UserRepository
+	MicropostRepository        domain.MicropostRepository
+	CascadeDeleteJobRepository domain.CascadeDeleteJobRepository
+	Log                        *slog.Logger
+}
+
+func NewContinueCascadeDelete(
+	userRepos domain.UserRepository,
+	micropostRepos domain.MicropostRepository,
+	jobRepos domain.CascadeDeleteJobRepository,
+	log *slog.Logger) *ContinueCascadeDelete {
+	return &ContinueCascadeDelete{
+		UserRepository:             userRepos,
+		MicropostRepository:        micropostRepos,
+		CascadeDeleteJobRepository: jobRepos,
+		Log:                        log,
+	}
+}
+
+// Execute ジョブごとにマイクロポストを削除し、残りがなくなったジョブを完了にする。
+// 他の更新と競合して削除できなかったマイクロポストがある場合も、ジョブを残して次回に削除し直す。
+// ジョブの登録後にユーザーが復元された場合は、ユーザーと一緒に削除したマイクロポストを復元する。
+// 失敗したジョブは残して次のジョブに進み、古いジョブの失敗で後続のジョブが止まらないようにする。
+// 失敗したジョブがある場合は、全てのジョブを処理した後にエラーを返して次回の実行で再試行させる。
+// ctxが取り消された場合は次のジョブに進まずに打ち切り、残りのジョブは次回に処理する
+func (c *ContinueCascadeDelete) Execute(ctx context.Context, req *usecase.ContinueCascadeDeleteRequest) (*usecase.ContinueCascadeDeleteResponse, error) {
+	jobs, err := c.CascadeDeleteJobRepository.GetCascadeDeleteJobs(req.MaxJobs)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	res := &usecase.ContinueCascadeDeleteResponse{}
+	var failedUserIDs []uint64
+	var firstErr error
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		err := c.continueJob(job, res)
+		if err != nil {
+			c.Log.Error("Cascade delete job failed", "userID", job.UserID, "error", err)
+			res.FailedJobs++
+			failedUserIDs = append(failedUserIDs, job.UserID)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return res, errors.Wrapf(firstErr, "%d cascade delete jobs failed: userIDs=%v", len(failedUserIDs), failedUserIDs)
+	}
+	return res, nil
+}
+
+// continueJob 1件のジョブについて削除または復元を続け、残りがなくなった場合はジョブを完了にする
+func (c *ContinueCascadeDelete) continueJob(job *domain.CascadeDeleteJobModel, res *usecase.ContinueCascadeDeleteResponse) error {
+	var remaining bool
+	_, err := c.UserRepository.GetUserByID(job.UserID)
+	switch {
+	case err == nil:
+		var restored int
+		restored, remaining, err = c.MicropostRepository.RestoreMicropostsByUserID(job.UserID, cascadeRestoreLimit)
+		res.RestoredMicroposts += restored
+	case errors.Cause(err) == domain.ErrNotFound:
+		var deleted int
+		deleted, remaining, err = c.MicropostRepository.DeleteMicropostsByUserID(job.UserID, cascadeDeleteLimit)
+		res.DeletedMicroposts += deleted
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if remaining {
+		res.PendingJobs++
+		return nil
+	}
+
+	err = c.CascadeDeleteJobRepository.DeleteCascadeDeleteJob(job.UserID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	res.CompletedJobs++
+	return nil
+}
@@ -9,18 +9,27 @@ import (
 
 // CreateMicropost マイクロポスト作成
 type CreateMicropost struct {
+	UserRepository      domain.UserRepository
 	MicropostRepository domain.MicropostRepository
 }
 
-func NewCreateMicropost(repos domain.MicropostRepository) *CreateMicropost {
+func NewCreateMicropost(userRepos domain.UserRepository, repos domain.MicropostRepository) *CreateMicropost {
 	return &CreateMicropost{
+		UserRepository:      userRepos,
 		MicropostRepository: repos,
 	}
 }
 
-// Execute マイクロポストを新規作成
+// Execute マイクロポストを新規作成。ユーザーが存在しない場合はdomain.ErrNotFoundを返す。
+// 確認から書き込みまでの間に削除された場合もリポジトリ側の条件付き書き込みで検出する。
+// 返信先が存在しない場合はリポジトリがdomain.ErrInReplyToNotFoundを返す
 func (m *CreateMicropost) Execute(req *usecase.CreateMicropostRequest) (*usecase.CreateMicropostResponse, error) {
-	newMicropost := domain.NewMicropostModel(req.Content, req.UserID)
+	_, err := m.UserRepository.GetUserByID(req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	newMicropost := domain.NewReplyMicropostModel(req.Content, req.UserID, req.InReplyToID)
 	micropost, err := m.MicropostRepository.CreateMicropost(newMicropost)
 	if err != nil {
 		return nil, errors.WithStack(err)
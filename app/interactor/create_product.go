@@ -0,0 +1,28 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// CreateProduct 製品新規作成
+type CreateProduct struct {
+	ProductRepository domain.ProductRepository
+}
+
+func NewCreateProduct(repos domain.ProductRepository) *CreateProduct {
+	return &CreateProduct{
+		ProductRepository: repos,
+	}
+}
+
+// Execute 製品を新規作成
+func (p *CreateProduct) Execute(req *usecase.CreateProductRequest) (*usecase.CreateProductResponse, error) {
+	product, err := p.ProductRepository.CreateProduct(req.ToProductModel())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.CreateProductResponse{Product: product}, nil
+}
@@ -15,12 +15,14 @@ var (
 type UserCreator struct {
 	UserRepository domain.UserRepository
 	UniqChecker    *domain.UserEmailUniqChecker
+	PasswordHasher domain.PasswordHasher
 }
 
-func NewCreateUser(repos domain.UserRepository, checker *domain.UserEmailUniqChecker) *UserCreator {
+func NewCreateUser(repos domain.UserRepository, checker *domain.UserEmailUniqChecker, hasher domain.PasswordHasher) *UserCreator {
 	return &UserCreator{
 		UserRepository: repos,
 		UniqChecker:    checker,
+		PasswordHasher: hasher,
 	}
 }
 
@@ -34,8 +36,20 @@ func (u *UserCreator) Execute(req *usecase.CreateUserRequest) (*usecase.CreateUs
 		return nil, errors.WithStack(ErrUniqEmail)
 	}
 
-	user, err := u.UserRepository.CreateUser(req.ToUserModel())
+	newUser := req.ToUserModel()
+	if req.Password != "" {
+		newUser.PasswordHash, err = u.PasswordHasher.HashPassword(req.Password)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	user, err := u.UserRepository.CreateUser(newUser)
 	if err != nil {
+		// 確認した後に同じメールアドレスで登録された場合
+		if errors.Cause(err) == domain.ErrDuplicateEmail {
+			return nil, errors.WithStack(ErrUniqEmail)
+		}
 		return nil, errors.WithStack(err)
 	}
 
@@ -0,0 +1,56 @@
+package interactor_test
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/interactor"
+	"clean-serverless-book-sample/mocks/memory"
+	"clean-serverless-book-sample/usecase"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCreateUser(t *testing.T) {
+	repos := memory.NewUserRepository()
+	creator := interactor.NewCreateUser(repos, domain.NewUserEmailUniqChecker(repos), adapter.NewBcryptPasswordHasher(bcrypt.MinCost))
+
+	res, err := creator.Execute(&usecase.CreateUserRequest{Name: "Name_1", Email: "test1@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), res.GetUserID())
+
+	_, err = creator.Execute(&usecase.CreateUserRequest{Name: "Name_2", Email: "test1@example.com"})
+	assert.Equal(t, interactor.ErrUniqEmail, errors.Cause(err))
+
+	// パスワードはハッシュ化して保存する
+	res, err = creator.Execute(&usecase.CreateUserRequest{Name: "Name_3", Email: "test3@example.com", Password: "passw0rd!"})
+	assert.NoError(t, err)
+	user, err := repos.GetUserByID(res.GetUserID())
+	assert.NoError(t, err)
+	assert.NotEqual(t, "passw0rd!", user.PasswordHash)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("passw0rd!")))
+}
+
+// staleCheckUserRepository 重複チェックの後に同じメールアドレスで登録された状況を再現するため、メールアドレスからは常に見つからないものとして返す
+type staleCheckUserRepository struct {
+	*memory.UserRepository
+}
+
+func (r *staleCheckUserRepository) GetUserByEmail(email string) (*domain.UserModel, error) {
+	return nil, errors.WithStack(domain.ErrNotFound)
+}
+
+// TestCreateUser_raceOnEmail 書き込み時にメールアドレスの重複が分かった場合も、重複エラーとして返す
+func TestCreateUser_raceOnEmail(t *testing.T) {
+	repos := memory.NewUserRepository()
+	_, err := repos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	assert.NoError(t, err)
+
+	stale := &staleCheckUserRepository{UserRepository: repos}
+	creator := interactor.NewCreateUser(stale, domain.NewUserEmailUniqChecker(stale), adapter.NewBcryptPasswordHasher(bcrypt.MinCost))
+
+	_, err = creator.Execute(&usecase.CreateUserRequest{Name: "Name_2", Email: "test1@example.com"})
+	assert.Equal(t, interactor.ErrUniqEmail, errors.Cause(err))
+}
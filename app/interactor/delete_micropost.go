@@ -30,7 +30,11 @@ func (m *DeleteMicropost) Execute(req *usecase.DeleteMicropostRequest) (*usecase
 		return nil, errors.WithStack(err)
 	}
 
-	err = m.MicropostRepository.DeleteMicropost(res.Micropost.ID)
+	if !domain.MatchVersion(req.Version, res.Micropost.Version) {
+		return nil, errors.WithStack(domain.ErrConflict)
+	}
+
+	err = m.MicropostRepository.DeleteMicropost(res.Micropost)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -0,0 +1,31 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// DeleteProduct 製品削除
+type DeleteProduct struct {
+	ProductRepository domain.ProductRepository
+}
+
+func NewDeleteProduct(repos domain.ProductRepository) *DeleteProduct {
+	return &DeleteProduct{
+		ProductRepository: repos,
+	}
+}
+
+// Execute 製品を削除
+func (p *DeleteProduct) Execute(req *usecase.DeleteProductRequest) (*usecase.DeleteProductResponse, error) {
+	err := p.ProductRepository.DeleteProduct(&domain.ProductModel{
+		ID:      req.ProductID,
+		Version: req.Version,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.DeleteProductResponse{}, nil
+}
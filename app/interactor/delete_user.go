@@ -3,34 +3,61 @@ package interactor
 import (
 	"clean-serverless-book-sample/domain"
 	"clean-serverless-book-sample/usecase"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
+// inlineCascadeDeleteLimit ユーザー削除のリクエストの中で論理削除するマイクロポストの上限。
+// 1回のトランザクションで書き込める件数にとどめてレスポンスを遅らせないようにし、残りは削除の継続ジョブに任せる
+const inlineCascadeDeleteLimit = 25
+
 // UserDeleter ユーザー削除
 type UserDeleter struct {
-	UserRepository domain.UserRepository
-	UserGetter     usecase.IGetUserByID
+	UserRepository             domain.UserRepository
+	MicropostRepository        domain.MicropostRepository
+	CascadeDeleteJobRepository domain.CascadeDeleteJobRepository
+	UserGetter                 usecase.IGetUserByID
 }
 
-func NewUserDeleter(repos domain.UserRepository, getter usecase.IGetUserByID) *UserDeleter {
+func NewUserDeleter(
+	repos domain.UserRepository,
+	micropostRepos domain.MicropostRepository,
+	jobRepos domain.CascadeDeleteJobRepository,
+	getter usecase.IGetUserByID) *UserDeleter {
 	return &UserDeleter{
-		UserRepository: repos,
-		UserGetter:     getter,
+		UserRepository:             repos,
+		MicropostRepository:        micropostRepos,
+		CascadeDeleteJobRepository: jobRepos,
+		UserGetter:                 getter,
 	}
 }
 
-// Execute ユーザーを削除
+// Execute ユーザーを削除し、そのユーザーのマイクロポストも削除する。
+// マイクロポストがinlineCascadeDeleteLimitより多い場合は、続きをジョブとして登録して定期実行に任せる
 func (u *UserDeleter) Execute(req *usecase.DeleteUserRequest) (*usecase.DeleteUserResponse, error) {
 	user, err := u.UserGetter.Execute(&usecase.GetUserByIDRequest{UserID: req.UserID})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	if !domain.MatchVersion(req.Version, user.User.Version) {
+		return nil, errors.WithStack(domain.ErrConflict)
+	}
+
 	err = u.UserRepository.DeleteUser(user.User)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	// ユーザーの削除は完了しているため、マイクロポストの削除に失敗した場合もジョブを登録してやり直す
+	_, remaining, err := u.MicropostRepository.DeleteMicropostsByUserID(user.User.ID, inlineCascadeDeleteLimit)
+	if err != nil || remaining {
+		err = u.CascadeDeleteJobRepository.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(user.User.ID, time.Now()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
 	return &usecase.DeleteUserResponse{}, nil
 }
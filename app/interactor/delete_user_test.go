@@ -0,0 +1,241 @@
+package interactor_test
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/interactor"
+	"clean-serverless-book-sample/mocks/memory"
+	"clean-serverless-book-sample/usecase"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserDeleter_cascade(t *testing.T) {
+	userRepos := memory.NewUserRepository()
+	micropostRepos := memory.NewMicropostRepository(userRepos)
+	jobRepos := memory.NewCascadeDeleteJobRepository()
+	deleter := interactor.NewUserDeleter(userRepos, micropostRepos, jobRepos, interactor.NewGetUserByID(userRepos))
+
+	user, err := userRepos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+	other, err := userRepos.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+	require.NoError(t, err)
+	for i := 0; i < 30; i++ {
+		_, err := micropostRepos.CreateMicropost(domain.NewMicropostModel("Content", user.ID))
+		require.NoError(t, err)
+	}
+	otherPost, err := micropostRepos.CreateMicropost(domain.NewMicropostModel("Other", other.ID))
+	require.NoError(t, err)
+
+	_, err = deleter.Execute(&usecase.DeleteUserRequest{UserID: user.ID})
+	require.NoError(t, err)
+
+	// リクエストの中では1回のトランザクション分だけを削除し、残りはジョブとして登録する
+	jobs, err := jobRepos.GetCascadeDeleteJobs(10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+
+	continuer := interactor.NewContinueCascadeDelete(userRepos, micropostRepos, jobRepos, slog.Default())
+	res, err := continuer.Execute(context.Background(), &usecase.ContinueCascadeDeleteRequest{MaxJobs: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 5, res.DeletedMicroposts)
+	assert.Equal(t, 1, res.CompletedJobs)
+
+	jobs, err = jobRepos.GetCascadeDeleteJobs(10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 0)
+
+	// 削除したユーザーのマイクロポストは取得できない
+	microposts, _, err := micropostRepos.GetMicropostsByUserID(user.ID, nil)
+	require.NoError(t, err)
+	assert.Len(t, microposts, 0)
+
+	_, err = micropostRepos.GetMicropostByID(otherPost.ID)
+	assert.NoError(t, err)
+}
+
+func TestContinueCascadeDelete(t *testing.T) {
+	userRepos := memory.NewUserRepository()
+	micropostRepos := memory.NewMicropostRepository(userRepos)
+	jobRepos := memory.NewCascadeDeleteJobRepository()
+	continuer := interactor.NewContinueCascadeDelete(userRepos, micropostRepos, jobRepos, slog.Default())
+
+	deleted, err := userRepos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+	restored, err := userRepos.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+	require.NoError(t, err)
+	for _, userID := range []uint64{deleted.ID, restored.ID} {
+		_, err := micropostRepos.CreateMicropost(domain.NewMicropostModel("Content", userID))
+		require.NoError(t, err)
+	}
+	require.NoError(t, userRepos.DeleteUser(deleted))
+	require.NoError(t, jobRepos.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(deleted.ID, time.Now())))
+	require.NoError(t, jobRepos.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(restored.ID, time.Now())))
+
+	res, err := continuer.Execute(context.Background(), &usecase.ContinueCascadeDeleteRequest{MaxJobs: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.DeletedMicroposts)
+	assert.Equal(t, 2, res.CompletedJobs)
+	assert.Equal(t, 0, res.PendingJobs)
+
+	// 削除されていないユーザーのマイクロポストは残す
+	microposts, _, err := micropostRepos.GetMicropostsByUserID(restored.ID, nil)
+	require.NoError(t, err)
+	assert.Len(t, microposts, 1)
+
+	jobs, err := jobRepos.GetCascadeDeleteJobs(10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 0)
+}
+
+// conflictingMicropostRepository 他の更新と競合して一部のマイクロポストを削除できなかった状況を再現するため、
+// 上限に達していなくても削除しきれなかったものとして返す
+type conflictingMicropostRepository struct {
+	*memory.MicropostRepository
+}
+
+func (r *conflictingMicropostRepository) DeleteMicropostsByUserID(userID uint64, limit int) (int, bool, error) {
+	deleted, _, err := r.MicropostRepository.DeleteMicropostsByUserID(userID, limit)
+	return deleted, true, err
+}
+
+// TestContinueCascadeDelete_conflict 削除できなかったマイクロポストがある間はジョブを完了にしない
+func TestContinueCascadeDelete_conflict(t *testing.T) {
+	userRepos := memory.NewUserRepository()
+	micropostRepos := &conflictingMicropostRepository{MicropostRepository: memory.NewMicropostRepository(userRepos)}
+	jobRepos := memory.NewCascadeDeleteJobRepository()
+	continuer := interactor.NewContinueCascadeDelete(userRepos, micropostRepos, jobRepos, slog.Default())
+
+	user, err := userRepos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+	_, err = micropostRepos.CreateMicropost(domain.NewMicropostModel("Content", user.ID))
+	require.NoError(t, err)
+	require.NoError(t, userRepos.DeleteUser(user))
+	require.NoError(t, jobRepos.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(user.ID, time.Now())))
+
+	res, err := continuer.Execute(context.Background(), &usecase.ContinueCascadeDeleteRequest{MaxJobs: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.DeletedMicroposts)
+	assert.Equal(t, 0, res.CompletedJobs)
+	assert.Equal(t, 1, res.PendingJobs)
+
+	jobs, err := jobRepos.GetCascadeDeleteJobs(10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+// failingMicropostRepository 指定したユーザーのマイクロポストの削除だけを失敗させる
+type failingMicropostRepository struct {
+	*memory.MicropostRepository
+	failUserID uint64
+}
+
+func (r *failingMicropostRepository) DeleteMicropostsByUserID(userID uint64, limit int) (int, bool, error) {
+	if userID == r.failUserID {
+		return 0, false, errors.New("delete failed")
+	}
+	return r.MicropostRepository.DeleteMicropostsByUserID(userID, limit)
+}
+
+// TestContinueCascadeDelete_failed 失敗したジョブは残して後続のジョブを処理し、最後にエラーを返す
+func TestContinueCascadeDelete_failed(t *testing.T) {
+	userRepos := memory.NewUserRepository()
+	micropostRepos := &failingMicropostRepository{MicropostRepository: memory.NewMicropostRepository(userRepos)}
+	jobRepos := memory.NewCascadeDeleteJobRepository()
+	continuer := interactor.NewContinueCascadeDelete(userRepos, micropostRepos, jobRepos, slog.Default())
+
+	failing, err := userRepos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+	user, err := userRepos.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+	require.NoError(t, err)
+	_, err = micropostRepos.CreateMicropost(domain.NewMicropostModel("Content", user.ID))
+	require.NoError(t, err)
+	micropostRepos.failUserID = failing.ID
+
+	// 古い方のジョブが失敗する
+	require.NoError(t, userRepos.DeleteUser(failing))
+	require.NoError(t, jobRepos.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(failing.ID, time.Now().Add(-time.Minute))))
+	require.NoError(t, userRepos.DeleteUser(user))
+	require.NoError(t, jobRepos.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(user.ID, time.Now())))
+
+	res, err := continuer.Execute(context.Background(), &usecase.ContinueCascadeDeleteRequest{MaxJobs: 10})
+	assert.Error(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, 1, res.FailedJobs)
+	assert.Equal(t, 1, res.CompletedJobs)
+	assert.Equal(t, 1, res.DeletedMicroposts)
+
+	jobs, err := jobRepos.GetCascadeDeleteJobs(10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, failing.ID, jobs[0].UserID)
+}
+
+// TestContinueCascadeDelete_canceled ctxが取り消された場合はジョブを処理せずに打ち切り、次回に残す
+func TestContinueCascadeDelete_canceled(t *testing.T) {
+	userRepos := memory.NewUserRepository()
+	micropostRepos := memory.NewMicropostRepository(userRepos)
+	jobRepos := memory.NewCascadeDeleteJobRepository()
+	continuer := interactor.NewContinueCascadeDelete(userRepos, micropostRepos, jobRepos, slog.Default())
+
+	user, err := userRepos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+	require.NoError(t, userRepos.DeleteUser(user))
+	require.NoError(t, jobRepos.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(user.ID, time.Now())))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = continuer.Execute(ctx, &usecase.ContinueCascadeDeleteRequest{MaxJobs: 10})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	jobs, err := jobRepos.GetCascadeDeleteJobs(10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+// TestRestoreUser_microposts ユーザーを復元すると、ユーザーと一緒に削除したマイクロポストも戻る
+func TestRestoreUser_microposts(t *testing.T) {
+	userRepos := memory.NewUserRepository()
+	micropostRepos := memory.NewMicropostRepository(userRepos)
+	jobRepos := memory.NewCascadeDeleteJobRepository()
+	deleter := interactor.NewUserDeleter(userRepos, micropostRepos, jobRepos, interactor.NewGetUserByID(userRepos))
+	restorer := interactor.NewRestoreUser(userRepos, micropostRepos, jobRepos)
+
+	user, err := userRepos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := micropostRepos.CreateMicropost(domain.NewMicropostModel("Content", user.ID))
+		require.NoError(t, err)
+	}
+	// 削除前に個別に削除したマイクロポストは戻さない
+	removed, err := micropostRepos.CreateMicropost(domain.NewMicropostModel("Removed", user.ID))
+	require.NoError(t, err)
+	require.NoError(t, micropostRepos.DeleteMicropost(removed))
+
+	_, err = deleter.Execute(&usecase.DeleteUserRequest{UserID: user.ID})
+	require.NoError(t, err)
+	microposts, _, err := micropostRepos.GetMicropostsByUserID(user.ID, nil)
+	require.NoError(t, err)
+	assert.Len(t, microposts, 0)
+
+	// 削除の続きのジョブが残っていても、復元すると取り消される
+	require.NoError(t, jobRepos.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(user.ID, time.Now())))
+
+	_, err = restorer.Execute(&usecase.RestoreUserRequest{UserID: user.ID})
+	require.NoError(t, err)
+
+	microposts, _, err = micropostRepos.GetMicropostsByUserID(user.ID, nil)
+	require.NoError(t, err)
+	assert.Len(t, microposts, 3)
+	_, err = micropostRepos.GetMicropostByID(removed.ID)
+	assert.Equal(t, domain.ErrNotFound, errors.Cause(err))
+
+	jobs, err := jobRepos.GetCascadeDeleteJobs(10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 0)
+}
@@ -0,0 +1,155 @@
+package interactor
+
+import (
+	"bytes"
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// exportFileContentType 書き出すファイルの形式。項目の種類ごとに、1行に1項目をJSONで書いてgzipで圧縮する
+	exportFileContentType = "application/gzip"
+	// exportFileSuffix 書き出すファイルの拡張子
+	exportFileSuffix = ".jsonl.gz"
+	// exportManifestName 書き出しの内容を記録するファイルの名前。全てのファイルを書き出した後に保存し、書き出しの完了を表す
+	exportManifestName = "manifest.json"
+)
+
+// exportManifest 書き出しの内容の記録
+type exportManifest struct {
+	ExportedAt string               `json:"exported_at"`
+	Total      int                  `json:"total"`
+	Files      []exportManifestFile `json:"files"`
+}
+
+// exportManifestFile 書き出したファイルごとの記録
+type exportManifestFile struct {
+	EntityType string `json:"entity_type"`
+	Key        string `json:"key"`
+	Count      int    `json:"count"`
+}
+
+// exportFile 項目の種類ごとに書き出している一時ファイル。複数のセグメントから同時に書き込むため排他する
+type exportFile struct {
+	mu      sync.Mutex
+	file    *os.File
+	gzip    *gzip.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+func (f *exportFile) write(item map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.encoder.Encode(item); err != nil {
+		return errors.WithStack(err)
+	}
+	f.count++
+	return nil
+}
+
+// ExportTable テーブルの書き出し
+type ExportTable struct {
+	TableExportSource domain.TableExportSource
+	FileStorage       domain.FileStorage
+}
+
+func NewExportTable(source domain.TableExportSource, storage domain.FileStorage) *ExportTable {
+	return &ExportTable{
+		TableExportSource: source,
+		FileStorage:       storage,
+	}
+}
+
+// Execute テーブルを並列に走査し、項目の種類ごとのファイルを日付の接頭辞の下に書き出す。
+// ファイルはLambdaのメモリに載せきれないことがあるため、一時ファイルに圧縮しながら書き込んでからアップロードする
+func (e *ExportTable) Execute(ctx context.Context, req *usecase.ExportTableRequest) (*usecase.ExportTableResponse, error) {
+	var mu sync.Mutex
+	files := map[string]*exportFile{}
+	defer func() {
+		for _, f := range files {
+			f.file.Close()
+			os.Remove(f.file.Name())
+		}
+	}()
+
+	err := e.TableExportSource.ScanAll(ctx, req.Segments, func(entityType string, item map[string]interface{}) error {
+		mu.Lock()
+		f, ok := files[entityType]
+		if !ok {
+			tmp, err := os.CreateTemp("", "export-*"+exportFileSuffix)
+			if err != nil {
+				mu.Unlock()
+				return errors.WithStack(err)
+			}
+			gz := gzip.NewWriter(tmp)
+			f = &exportFile{file: tmp, gzip: gz, encoder: json.NewEncoder(gz)}
+			files[entityType] = f
+		}
+		mu.Unlock()
+		return f.write(item)
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	prefix := domain.NewExportPrefix(req.ExportedAt)
+	entityTypes := make([]string, 0, len(files))
+	for entityType := range files {
+		entityTypes = append(entityTypes, entityType)
+	}
+	sort.Strings(entityTypes)
+
+	manifest := &exportManifest{ExportedAt: req.ExportedAt.UTC().Format(time.RFC3339), Files: []exportManifestFile{}}
+	counts := map[string]int{}
+	for _, entityType := range entityTypes {
+		f := files[entityType]
+		key := prefix + entityType + exportFileSuffix
+		if err := e.upload(f, key); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		manifest.Files = append(manifest.Files, exportManifestFile{EntityType: entityType, Key: key, Count: f.count})
+		manifest.Total += f.count
+		counts[entityType] = f.count
+	}
+
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	manifestKey := prefix + exportManifestName
+	if _, err := e.FileStorage.PutFile(manifestKey, "application/json", bytes.NewReader(body)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.ExportTableResponse{
+		Prefix:      prefix,
+		ManifestKey: manifestKey,
+		Counts:      counts,
+		Total:       manifest.Total,
+	}, nil
+}
+
+// upload 一時ファイルの圧縮を終えて、先頭からアップロードする
+func (e *ExportTable) upload(f *exportFile, key string) error {
+	if err := f.gzip.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := e.FileStorage.PutFile(key, exportFileContentType, f.file); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
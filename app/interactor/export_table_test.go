@@ -0,0 +1,131 @@
+package interactor_test
+
+import (
+	"bufio"
+	"bytes"
+	"clean-serverless-book-sample/adapter"
+	"clean-serverless-book-sample/interactor"
+	"clean-serverless-book-sample/mocks"
+	"clean-serverless-book-sample/usecase"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTableExportSource 種類ごとの項目を、セグメントに分けて並行に渡す
+type fakeTableExportSource struct {
+	items map[string][]map[string]interface{}
+	err   error
+}
+
+func (s *fakeTableExportSource) ScanAll(ctx context.Context, segments int, fn func(entityType string, item map[string]interface{}) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, segments)
+	for segment := 0; segment < segments; segment++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entityType, items := range s.items {
+				for i, item := range items {
+					if i%segments != segment {
+						continue
+					}
+					if err := fn(entityType, item); err != nil {
+						errs[segment] = err
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if s.err != nil {
+		return s.err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExportTable(t *testing.T) {
+	const bucket = "test-bucket"
+	client := mocks.NewFakeS3Client()
+	storage := &adapter.S3FileStorage{Client: client, Bucket: bucket}
+
+	source := &fakeTableExportSource{items: map[string][]map[string]interface{}{}}
+	for i := 1; i <= 10; i++ {
+		source.items["UserResource"] = append(source.items["UserResource"], map[string]interface{}{"PK": fmt.Sprintf("UserResource-%011d", i), "Name": fmt.Sprintf("Name_%d", i)})
+	}
+	for i := 1; i <= 3; i++ {
+		source.items["MicropostResource"] = append(source.items["MicropostResource"], map[string]interface{}{"PK": fmt.Sprintf("MicropostResource-%011d", i)})
+	}
+
+	exportedAt := time.Date(2024, 4, 1, 18, 0, 0, 0, time.UTC)
+	res, err := interactor.NewExportTable(source, storage).Execute(context.Background(), &usecase.ExportTableRequest{
+		ExportedAt: exportedAt,
+		Segments:   4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "exports/dt=2024-04-01/", res.Prefix)
+	assert.Equal(t, 13, res.Total)
+	assert.Equal(t, map[string]int{"UserResource": 10, "MicropostResource": 3}, res.Counts)
+
+	// 種類ごとのファイルは、1行に1項目をJSONで書いてgzipで圧縮する
+	obj := client.GetTestObject(bucket, "exports/dt=2024-04-01/UserResource.jsonl.gz")
+	require.NotNil(t, obj)
+	assert.Equal(t, "application/gzip", obj.ContentType)
+	gz, err := gzip.NewReader(bytes.NewReader(obj.Body))
+	require.NoError(t, err)
+	names := map[string]bool{}
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var item map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &item))
+		names[item["Name"].(string)] = true
+	}
+	assert.Len(t, names, 10)
+
+	// マニフェストに種類ごとの件数を記録する
+	obj = client.GetTestObject(bucket, res.ManifestKey)
+	require.NotNil(t, obj)
+	var manifest struct {
+		ExportedAt string `json:"exported_at"`
+		Total      int    `json:"total"`
+		Files      []struct {
+			EntityType string `json:"entity_type"`
+			Key        string `json:"key"`
+			Count      int    `json:"count"`
+		} `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal(obj.Body, &manifest))
+	assert.Equal(t, "2024-04-01T18:00:00Z", manifest.ExportedAt)
+	assert.Equal(t, 13, manifest.Total)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "MicropostResource", manifest.Files[0].EntityType)
+	assert.Equal(t, "exports/dt=2024-04-01/MicropostResource.jsonl.gz", manifest.Files[0].Key)
+	assert.Equal(t, 3, manifest.Files[0].Count)
+
+	t.Run("走査に失敗した場合はマニフェストを書き出さない", func(t *testing.T) {
+		client := mocks.NewFakeS3Client()
+		storage := &adapter.S3FileStorage{Client: client, Bucket: bucket}
+		source := &fakeTableExportSource{items: source.items, err: errors.New("scan failed")}
+
+		_, err := interactor.NewExportTable(source, storage).Execute(context.Background(), &usecase.ExportTableRequest{
+			ExportedAt: exportedAt,
+			Segments:   2,
+		})
+		assert.Error(t, err)
+		assert.Nil(t, client.GetTestObject(bucket, "exports/dt=2024-04-01/manifest.json"))
+	})
+}
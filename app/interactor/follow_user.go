@@ -0,0 +1,37 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrFollowSelf = errors.New("cannot follow yourself")
+)
+
+// FollowUser フォロー
+type FollowUser struct {
+	FollowRepository domain.FollowRepository
+}
+
+func NewFollowUser(repos domain.FollowRepository) *FollowUser {
+	return &FollowUser{
+		FollowRepository: repos,
+	}
+}
+
+// Execute フォローする。自分自身はフォローできない
+func (f *FollowUser) Execute(req *usecase.FollowUserRequest) (*usecase.FollowUserResponse, error) {
+	if req.UserID == req.TargetUserID {
+		return nil, errors.WithStack(ErrFollowSelf)
+	}
+
+	err := f.FollowRepository.Follow(req.UserID, req.TargetUserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.FollowUserResponse{}, nil
+}
@@ -0,0 +1,28 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// GetAPIKeyList APIキー一覧取得
+type GetAPIKeyList struct {
+	APIKeyRepository domain.APIKeyRepository
+}
+
+func NewGetAPIKeyList(repos domain.APIKeyRepository) *GetAPIKeyList {
+	return &GetAPIKeyList{
+		APIKeyRepository: repos,
+	}
+}
+
+// Execute APIキー一覧を取得
+func (g *GetAPIKeyList) Execute(req *usecase.GetAPIKeyListRequest) (*usecase.GetAPIKeyListResponse, error) {
+	apiKeys, nextToken, err := g.APIKeyRepository.GetAPIKeys(req.ToPage())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.GetAPIKeyListResponse{APIKeys: apiKeys, NextToken: nextToken}, nil
+}
@@ -0,0 +1,150 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"container/heap"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// feedMaxFollowees ホームタイムラインに含めるフォロー中ユーザーの上限
+	feedMaxFollowees = 1000
+	// feedFetchConcurrency フォロー中ユーザーのマイクロポストを並行して取得する数
+	feedFetchConcurrency = 8
+)
+
+// GetFeed ホームタイムライン取得
+type GetFeed struct {
+	UserRepository      domain.UserRepository
+	FollowRepository    domain.FollowRepository
+	MicropostRepository domain.MicropostRepository
+}
+
+func NewGetFeed(userRepos domain.UserRepository, followRepos domain.FollowRepository, micropostRepos domain.MicropostRepository) *GetFeed {
+	return &GetFeed{
+		UserRepository:      userRepos,
+		FollowRepository:    followRepos,
+		MicropostRepository: micropostRepos,
+	}
+}
+
+// Execute フォローしているユーザーのマイクロポストを投稿日時が新しい順に取得する。
+// ユーザーが存在しない場合はdomain.ErrNotFoundを、ページトークンが不正な場合はdomain.ErrInvalidPageTokenを返す
+func (g *GetFeed) Execute(req *usecase.GetFeedRequest) (*usecase.GetFeedResponse, error) {
+	page := req.ToPage()
+
+	var before *domain.MicropostCursor
+	if page.NextToken != "" {
+		cursor, err := domain.ParseMicropostCursor(page.NextToken)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		before = cursor
+	}
+
+	_, err := g.UserRepository.GetUserByID(req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	followees, err := g.getFollowees(req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// 続きの有無を判定するため、各ユーザーから1件多く取得する
+	timelines := make([][]*domain.MicropostModel, len(followees))
+	eg := errgroup.Group{}
+	eg.SetLimit(feedFetchConcurrency)
+	for i, followee := range followees {
+		eg.Go(func() error {
+			microposts, err := g.MicropostRepository.GetMicropostsByUserIDBefore(followee, before, page.Limit+1)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			timelines[i] = microposts
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	microposts := mergeTimelines(timelines, page.Limit+1)
+
+	nextToken := ""
+	if len(microposts) > page.Limit {
+		microposts = microposts[:page.Limit]
+		nextToken = domain.NewMicropostCursor(microposts[page.Limit-1]).Encode()
+	}
+
+	return &usecase.GetFeedResponse{Microposts: microposts, NextToken: nextToken}, nil
+}
+
+// getFollowees フォローしているユーザーのIDを最大feedMaxFollowees件まで取得する
+func (g *GetFeed) getFollowees(userID uint64) ([]uint64, error) {
+	var followees []uint64
+	nextToken := ""
+	for {
+		follows, token, err := g.FollowRepository.GetFollowing(userID, domain.NewPage(domain.MaxPageLimit, nextToken))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, f := range follows {
+			if len(followees) >= feedMaxFollowees {
+				return followees, nil
+			}
+			followees = append(followees, f.TargetUserID)
+		}
+		if token == "" {
+			return followees, nil
+		}
+		nextToken = token
+	}
+}
+
+// mergeTimelines 新しい順に並んだ複数のマイクロポスト一覧を、新しい順に最大limit件までまとめる
+func mergeTimelines(timelines [][]*domain.MicropostModel, limit int) []*domain.MicropostModel {
+	h := &timelineHeap{}
+	for _, t := range timelines {
+		if len(t) > 0 {
+			*h = append(*h, t)
+		}
+	}
+	heap.Init(h)
+
+	merged := make([]*domain.MicropostModel, 0, limit)
+	for h.Len() > 0 && len(merged) < limit {
+		head := (*h)[0]
+		merged = append(merged, head[0])
+		if len(head) > 1 {
+			(*h)[0] = head[1:]
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return merged
+}
+
+// timelineHeap 先頭のマイクロポストが最も新しい一覧を取り出せるヒープ
+type timelineHeap [][]*domain.MicropostModel
+
+func (h timelineHeap) Len() int           { return len(h) }
+func (h timelineHeap) Less(i, j int) bool { return h[i][0].IsNewerThan(h[j][0]) }
+func (h timelineHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *timelineHeap) Push(x any) {
+	*h = append(*h, x.([]*domain.MicropostModel))
+}
+
+func (h *timelineHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
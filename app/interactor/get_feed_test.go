@@ -0,0 +1,77 @@
+package interactor_test
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/interactor"
+	"clean-serverless-book-sample/mocks/memory"
+	"clean-serverless-book-sample/usecase"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFeed(t *testing.T) {
+	userRepos := memory.NewUserRepository()
+	followRepos := memory.NewFollowRepository(userRepos)
+	micropostRepos := memory.NewMicropostRepository(userRepos)
+	getFeed := interactor.NewGetFeed(userRepos, followRepos, micropostRepos)
+
+	for i := 1; i <= 4; i++ {
+		_, err := userRepos.CreateUser(domain.NewUserModel(fmt.Sprintf("Name_%d", i), fmt.Sprintf("test%d@example.com", i)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, followRepos.Follow(1, 2))
+	require.NoError(t, followRepos.Follow(1, 3))
+
+	// フォローしているユーザー2・3の投稿を交互に作成する。自分とユーザー4の投稿は含まない
+	var expected []*domain.MicropostModel
+	for i := 0; i < 5; i++ {
+		for _, userID := range []uint64{2, 3, 1, 4} {
+			m, err := micropostRepos.CreateMicropost(domain.NewMicropostModel(fmt.Sprintf("Content_%d_%d", userID, i), userID))
+			require.NoError(t, err)
+			if userID == 2 || userID == 3 {
+				expected = append([]*domain.MicropostModel{m}, expected...)
+			}
+		}
+	}
+
+	var actual []*domain.MicropostModel
+	nextToken := ""
+	for {
+		res, err := getFeed.Execute(&usecase.GetFeedRequest{UserID: 1, Limit: 3, NextToken: nextToken})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(res.Microposts), 3)
+		actual = append(actual, res.Microposts...)
+		if res.NextToken == "" {
+			break
+		}
+		nextToken = res.NextToken
+	}
+
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].ID, actual[i].ID)
+	}
+}
+
+func TestGetFeed_errors(t *testing.T) {
+	userRepos := memory.NewUserRepository()
+	getFeed := interactor.NewGetFeed(userRepos, memory.NewFollowRepository(userRepos), memory.NewMicropostRepository(userRepos))
+
+	_, err := userRepos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+
+	_, err = getFeed.Execute(&usecase.GetFeedRequest{UserID: 999})
+	assert.Equal(t, domain.ErrNotFound.Error(), err.Error())
+
+	_, err = getFeed.Execute(&usecase.GetFeedRequest{UserID: 1, NextToken: "invalid"})
+	assert.Equal(t, domain.ErrInvalidPageToken.Error(), err.Error())
+
+	// フォローしていない場合は空
+	res, err := getFeed.Execute(&usecase.GetFeedRequest{UserID: 1})
+	require.NoError(t, err)
+	assert.Len(t, res.Microposts, 0)
+	assert.Empty(t, res.NextToken)
+}
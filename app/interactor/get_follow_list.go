@@ -0,0 +1,43 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// GetFollowList フォロー・フォロワー一覧取得
+type GetFollowList struct {
+	UserRepository   domain.UserRepository
+	FollowRepository domain.FollowRepository
+}
+
+func NewGetFollowList(userRepos domain.UserRepository, followRepos domain.FollowRepository) *GetFollowList {
+	return &GetFollowList{
+		UserRepository:   userRepos,
+		FollowRepository: followRepos,
+	}
+}
+
+// Execute フォロー・フォロワー一覧を取得する。ユーザーが存在しない場合はdomain.ErrNotFoundを返す
+func (g *GetFollowList) Execute(req *usecase.GetFollowListRequest) (*usecase.GetFollowListResponse, error) {
+	_, err := g.UserRepository.GetUserByID(req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var follows []*domain.FollowModel
+	var nextToken string
+	switch req.Direction {
+	case usecase.FollowDirectionFollowers:
+		follows, nextToken, err = g.FollowRepository.GetFollowers(req.UserID, req.ToPage())
+	default:
+		follows, nextToken, err = g.FollowRepository.GetFollowing(req.UserID, req.ToPage())
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.GetFollowListResponse{Follows: follows, NextToken: nextToken}, nil
+}
@@ -0,0 +1,28 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// GetHashtagMicropostList ハッシュタグのマイクロポスト一覧取得
+type GetHashtagMicropostList struct {
+	MicropostRepository domain.MicropostRepository
+}
+
+func NewGetHashtagMicropostList(repos domain.MicropostRepository) *GetHashtagMicropostList {
+	return &GetHashtagMicropostList{
+		MicropostRepository: repos,
+	}
+}
+
+// Execute ハッシュタグを含むマイクロポストを新しい順に取得する。タグは本文から取り出す時と同じ規則で正規化してから探す
+func (g *GetHashtagMicropostList) Execute(req *usecase.GetHashtagMicropostListRequest) (*usecase.GetHashtagMicropostListResponse, error) {
+	microposts, nextToken, err := g.MicropostRepository.GetMicropostsByHashtag(domain.NormalizeHashtag(req.Tag), req.ToPage())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.GetHashtagMicropostListResponse{Microposts: microposts, NextToken: nextToken}, nil
+}
@@ -0,0 +1,27 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+type GetImportJob struct {
+	ImportJobRepository domain.ImportJobRepository
+}
+
+func NewGetImportJob(repos domain.ImportJobRepository) *GetImportJob {
+	return &GetImportJob{
+		ImportJobRepository: repos,
+	}
+}
+
+// Execute 一括取り込みのジョブを取得
+func (i *GetImportJob) Execute(req *usecase.GetImportJobRequest) (*usecase.GetImportJobResponse, error) {
+	job, err := i.ImportJobRepository.GetImportJob(req.JobID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.GetImportJobResponse{Job: job}, nil
+}
@@ -0,0 +1,35 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// GetMentionList メンション一覧取得
+type GetMentionList struct {
+	UserRepository      domain.UserRepository
+	MicropostRepository domain.MicropostRepository
+}
+
+func NewGetMentionList(userRepos domain.UserRepository, repos domain.MicropostRepository) *GetMentionList {
+	return &GetMentionList{
+		UserRepository:      userRepos,
+		MicropostRepository: repos,
+	}
+}
+
+// Execute ユーザーをメンションしているマイクロポストを新しい順に取得する。ユーザーが存在しない場合はdomain.ErrNotFoundを返す
+func (g *GetMentionList) Execute(req *usecase.GetMentionListRequest) (*usecase.GetMentionListResponse, error) {
+	_, err := g.UserRepository.GetUserByID(req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	microposts, nextToken, err := g.MicropostRepository.GetMicropostsMentioning(req.UserID, req.ToPage())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.GetMentionListResponse{Microposts: microposts, NextToken: nextToken}, nil
+}
@@ -20,9 +20,9 @@ func NewGetMicropostList(repos domain.MicropostRepository) *GetMicropostList {
 
 // Execute マイクロポスト一覧取得
 func (m *GetMicropostList) Execute(req *usecase.GetMicropostListRequest) (*usecase.GetMicropostListResponse, error) {
-	microposts, err := m.MicropostRepository.GetMicropostsByUserID(req.UserID)
+	microposts, nextToken, err := m.MicropostRepository.GetMicropostsByUserID(req.UserID, req.ToPage())
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &usecase.GetMicropostListResponse{Microposts: microposts}, nil
+	return &usecase.GetMicropostListResponse{Microposts: microposts, NextToken: nextToken}, nil
 }
@@ -0,0 +1,27 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+type GetProductByID struct {
+	ProductRepository domain.ProductRepository
+}
+
+func NewGetProductByID(repos domain.ProductRepository) *GetProductByID {
+	return &GetProductByID{
+		ProductRepository: repos,
+	}
+}
+
+// Execute 製品を取得
+func (p *GetProductByID) Execute(req *usecase.GetProductByIDRequest) (*usecase.GetProductByIDResponse, error) {
+	product, err := p.ProductRepository.GetProductByID(req.ProductID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.GetProductByIDResponse{Product: product}, nil
+}
@@ -0,0 +1,28 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// GetProductList 製品一覧取得
+type GetProductList struct {
+	ProductRepository domain.ProductRepository
+}
+
+func NewGetProductList(repos domain.ProductRepository) *GetProductList {
+	return &GetProductList{
+		ProductRepository: repos,
+	}
+}
+
+// Execute 製品一覧を取得
+func (p *GetProductList) Execute(req *usecase.GetProductListRequest) (*usecase.GetProductListResponse, error) {
+	products, nextToken, err := p.ProductRepository.GetProducts(req.ToPage())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.GetProductListResponse{Products: products, NextToken: nextToken}, nil
+}
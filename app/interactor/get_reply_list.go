@@ -0,0 +1,34 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// GetReplyList 返信一覧取得
+type GetReplyList struct {
+	MicropostRepository domain.MicropostRepository
+}
+
+func NewGetReplyList(repos domain.MicropostRepository) *GetReplyList {
+	return &GetReplyList{
+		MicropostRepository: repos,
+	}
+}
+
+// Execute マイクロポストへの返信を古い順に取得する。マイクロポストが指定したユーザーのものでない場合はdomain.ErrNotFoundを返す
+func (g *GetReplyList) Execute(req *usecase.GetReplyListRequest) (*usecase.GetReplyListResponse, error) {
+	err := checkMicropostOwner(g.MicropostRepository, req.MicropostID, req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	replies, nextToken, err := g.MicropostRepository.GetRepliesByMicropostID(req.MicropostID, req.ToPage())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.GetReplyListResponse{Replies: replies, NextToken: nextToken}, nil
+}
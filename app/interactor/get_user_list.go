@@ -20,9 +20,9 @@ func NewGetUserList(repos domain.UserRepository) *GetUserList {
 
 // Execute ユーザー一覧を取得
 func (u *GetUserList) Execute(req *usecase.GetUserListRequest) (*usecase.GetUserListResponse, error) {
-	users, err := u.UserRepository.GetUsers()
+	users, nextToken, err := u.UserRepository.GetUsers(req.ToPage())
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return &usecase.GetUserListResponse{Users: users}, nil
+	return &usecase.GetUserListResponse{Users: users, NextToken: nextToken}, nil
 }
@@ -0,0 +1,131 @@
+package interactor
+
+import (
+	"bufio"
+	"bytes"
+	"clean-serverless-book-sample/domain"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// importCheckpointSize 進捗を保存するまでに処理する行数
+const importCheckpointSize = 25
+
+// importDeadlineMargin 占有期限の手前で取り込みを中断するまでの余裕。進捗と報告の保存にかかる時間を見込む
+const importDeadlineMargin = 10 * time.Second
+
+// importReportContentType 取り込み結果の報告の形式。失敗した行を1行ずつJSONで書く
+const importReportContentType = "application/x-ndjson"
+
+// importReportLine 取り込み結果の報告の1行
+type importReportLine struct {
+	Row    int               `json:"row"`
+	Errors map[string]string `json:"errors"`
+}
+
+// importJobRunner ファイルからの一括取り込みに共通する、ジョブの開始と進捗・報告の保存を行う
+type importJobRunner struct {
+	jobs    domain.ImportJobRepository
+	storage domain.FileStorage
+}
+
+// start ジョブを開始し、中断した取り込みを再開する場合は前回までの報告を読み込む。
+// 同じファイルを取り込み済みか、他の実行が取り込み中の場合はジョブがnilになる
+func (r *importJobRunner) start(key, etag string, lockedUntil time.Time) (*domain.ImportJobModel, []importReportLine, error) {
+	job, err := r.jobs.StartImportJob(domain.NewImportJobModel(key, etag, time.Now(), lockedUntil))
+	if err != nil {
+		if err.Error() == domain.ErrConflict.Error() {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.WithStack(err)
+	}
+
+	report, err := r.loadReport(job)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return job, report, nil
+}
+
+// failFile 1行も読み込めないファイルを、ファイル全体の誤りとして報告して終える
+func (r *importJobRunner) failFile(job *domain.ImportJobModel, fileErr error) error {
+	report := []importReportLine{{Row: 0, Errors: map[string]string{"file": fileErr.Error()}}}
+	return r.complete(job, report)
+}
+
+// checkpoint 読み込んだ行数を進捗として保存する。
+// 途中で中断しても失敗した行が報告から漏れないよう、進捗より先に報告を保存する
+func (r *importJobRunner) checkpoint(job *domain.ImportJobModel, consumed int, report []importReportLine) error {
+	if consumed == 0 {
+		return nil
+	}
+	if err := r.putReport(job, report); err != nil {
+		return errors.WithStack(err)
+	}
+
+	job.Processed += consumed
+	job.UpdatedAt = time.Now()
+	return r.jobs.UpdateImportJob(job)
+}
+
+// interrupted 占有期限が近づき、取り込みを中断する必要があるかどうか
+func (r *importJobRunner) interrupted(job *domain.ImportJobModel) bool {
+	return !time.Now().Before(job.LockedUntil.Add(-importDeadlineMargin))
+}
+
+// complete 報告を保存してジョブを完了にする
+func (r *importJobRunner) complete(job *domain.ImportJobModel, report []importReportLine) error {
+	if err := r.putReport(job, report); err != nil {
+		return errors.WithStack(err)
+	}
+
+	job.Status = domain.ImportJobStatusCompleted
+	job.UpdatedAt = time.Now()
+	return r.jobs.UpdateImportJob(job)
+}
+
+// putReport 失敗した行の報告を保存する。失敗した行が無い場合も、取り込みが終わったことがわかるよう空のファイルを保存する
+func (r *importJobRunner) putReport(job *domain.ImportJobModel, report []importReportLine) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, line := range report {
+		if err := encoder.Encode(line); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	_, err := r.storage.PutFile(job.ReportKey, importReportContentType, bytes.NewReader(buf.Bytes()))
+	return err
+}
+
+// loadReport 中断した取り込みを再開する場合に、前回までに保存した報告を読み込む
+func (r *importJobRunner) loadReport(job *domain.ImportJobModel) ([]importReportLine, error) {
+	if job.Processed == 0 {
+		return nil, nil
+	}
+
+	body, err := r.storage.OpenFile(job.ReportKey)
+	if err != nil {
+		if err.Error() == domain.ErrNotFound.Error() {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	defer body.Close()
+
+	var report []importReportLine
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var line importReportLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		report = append(report, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return report, nil
+}
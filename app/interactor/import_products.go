@@ -0,0 +1,136 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ImportProducts 製品の一括取り込み
+type ImportProducts struct {
+	ProductRepository   domain.ProductRepository
+	ImportJobRepository domain.ImportJobRepository
+	FileStorage         domain.FileStorage
+}
+
+func NewImportProducts(products domain.ProductRepository, jobs domain.ImportJobRepository, storage domain.FileStorage) *ImportProducts {
+	return &ImportProducts{
+		ProductRepository:   products,
+		ImportJobRepository: jobs,
+		FileStorage:         storage,
+	}
+}
+
+// Execute ファイルを1行ずつ読み込んで検証し、IDの無い行は新規作成、ある行は更新する。
+// 失敗した行は報告のファイルに書き出す。同じファイルの同じ版は一度だけ取り込み、
+// 中断した取り込みは保存した進捗の次の行から再開する
+func (i *ImportProducts) Execute(req *usecase.ImportProductsRequest) (*usecase.ImportProductsResponse, error) {
+	runner := &importJobRunner{jobs: i.ImportJobRepository, storage: i.FileStorage}
+	job, report, err := runner.start(req.Key, req.ETag, req.LockedUntil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if job == nil {
+		return &usecase.ImportProductsResponse{Skipped: true}, nil
+	}
+
+	body, err := i.FileStorage.OpenFile(req.Key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer body.Close()
+
+	reader, err := req.Decoder.NewReader(req.Key, body)
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidImportFile) {
+			return nil, errors.WithStack(err)
+		}
+		if err := runner.failFile(job, err); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &usecase.ImportProductsResponse{Job: job}, nil
+	}
+
+	// 前回までに処理した行を読み飛ばす
+	for n := 0; n < job.Processed; n++ {
+		if _, err := reader.Next(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	var rows []*usecase.ProductImportRow
+	consumed := 0
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		consumed++
+
+		if len(row.Errors) > 0 {
+			report = append(report, importReportLine{Row: row.Row, Errors: row.Errors})
+			job.Failed++
+			continue
+		}
+		rows = append(rows, row)
+		if len(rows) < importCheckpointSize {
+			continue
+		}
+
+		report, err = i.saveRows(job, rows, report, req.Decoder)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := runner.checkpoint(job, consumed, report); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		rows, consumed = nil, 0
+
+		if runner.interrupted(job) {
+			return nil, errors.WithStack(domain.ErrImportInterrupted)
+		}
+	}
+
+	report, err = i.saveRows(job, rows, report, req.Decoder)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := runner.checkpoint(job, consumed, report); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := runner.complete(job, report); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.ImportProductsResponse{Job: job}, nil
+}
+
+// saveRows 検証済みの行をまとめて書き込み、書き込めなかった行を報告に加える
+func (i *ImportProducts) saveRows(job *domain.ImportJobModel, rows []*usecase.ProductImportRow, report []importReportLine, decoder usecase.ProductImportDecoder) ([]importReportLine, error) {
+	if len(rows) == 0 {
+		return report, nil
+	}
+
+	products := make([]*domain.ProductModel, len(rows))
+	for n, row := range rows {
+		products[n] = row.Product
+	}
+	errs, err := i.ProductRepository.SaveProducts(products)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for n, err := range errs {
+		if err == nil {
+			continue
+		}
+		report = append(report, importReportLine{Row: rows[n].Row, Errors: decoder.SaveErrorMessages(err)})
+		job.Failed++
+	}
+
+	return report, nil
+}
@@ -0,0 +1,151 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ImportUsers ユーザーの一括取り込み
+type ImportUsers struct {
+	UserRepository      domain.UserRepository
+	UniqChecker         *domain.UserEmailUniqChecker
+	PasswordHasher      domain.PasswordHasher
+	ImportJobRepository domain.ImportJobRepository
+	FileStorage         domain.FileStorage
+}
+
+func NewImportUsers(repos domain.UserRepository, checker *domain.UserEmailUniqChecker, hasher domain.PasswordHasher, jobs domain.ImportJobRepository, storage domain.FileStorage) *ImportUsers {
+	return &ImportUsers{
+		UserRepository:      repos,
+		UniqChecker:         checker,
+		PasswordHasher:      hasher,
+		ImportJobRepository: jobs,
+		FileStorage:         storage,
+	}
+}
+
+// Execute ファイルを1行ずつ読み込んで検証し、ユーザーを作成する。既存のユーザーやファイル内の前の行と
+// メールアドレスが重複する行は、ファイル全体を止めずに報告のファイルに書き出す。
+// 同じファイルの同じ版は一度だけ取り込み、中断した取り込みは保存した進捗の次の行から再開する
+func (i *ImportUsers) Execute(req *usecase.ImportUsersRequest) (*usecase.ImportUsersResponse, error) {
+	runner := &importJobRunner{jobs: i.ImportJobRepository, storage: i.FileStorage}
+	job, report, err := runner.start(req.Key, req.ETag, req.LockedUntil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if job == nil {
+		return &usecase.ImportUsersResponse{Skipped: true}, nil
+	}
+
+	body, err := i.FileStorage.OpenFile(req.Key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer body.Close()
+
+	reader, err := req.Decoder.NewReader(req.Key, body)
+	if err != nil {
+		if !errors.Is(err, domain.ErrInvalidImportFile) {
+			return nil, errors.WithStack(err)
+		}
+		if err := runner.failFile(job, err); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &usecase.ImportUsersResponse{Job: job}, nil
+	}
+
+	// 前回までに処理した行を読み飛ばす。ファイル内の重複を見つけられるよう、メールアドレスは覚えておく
+	seen := map[string]bool{}
+	for n := 0; n < job.Processed; n++ {
+		row, err := reader.Next()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if row.User != nil {
+			seen[row.User.Email] = true
+		}
+	}
+
+	consumed := 0
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		consumed++
+
+		if len(row.Errors) > 0 {
+			report = append(report, importReportLine{Row: row.Row, Errors: row.Errors})
+			job.Failed++
+		} else if err := i.createUser(row, seen); err != nil {
+			if !isUserImportRowError(err) {
+				return nil, errors.WithStack(err)
+			}
+			report = append(report, importReportLine{Row: row.Row, Errors: req.Decoder.SaveErrorMessages(err)})
+			job.Failed++
+		}
+		if consumed < importCheckpointSize {
+			continue
+		}
+
+		if err := runner.checkpoint(job, consumed, report); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		consumed = 0
+
+		if runner.interrupted(job) {
+			return nil, errors.WithStack(domain.ErrImportInterrupted)
+		}
+	}
+
+	if err := runner.checkpoint(job, consumed, report); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := runner.complete(job, report); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.ImportUsersResponse{Job: job}, nil
+}
+
+// createUser 検証済みの行のユーザーを作成する。ファイル内の前の行と重複する場合はdomain.ErrDuplicateEmailInFile、
+// 既存のユーザーと重複する場合はdomain.ErrDuplicateEmailを返す
+func (i *ImportUsers) createUser(row *usecase.UserImportRow, seen map[string]bool) error {
+	if seen[row.User.Email] {
+		return errors.WithStack(domain.ErrDuplicateEmailInFile)
+	}
+	seen[row.User.Email] = true
+
+	isUniq, err := i.UniqChecker.IsUniqueEmail(row.User)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !isUniq {
+		return errors.WithStack(domain.ErrDuplicateEmail)
+	}
+
+	if row.Password != "" {
+		row.User.PasswordHash, err = i.PasswordHasher.HashPassword(row.Password)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// 確認した後に同じメールアドレスで登録された場合はdomain.ErrDuplicateEmailになる
+	_, err = i.UserRepository.CreateUser(row.User)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// isUserImportRowError その行だけを失敗として報告し、取り込みを続けられるエラーかどうか
+func isUserImportRowError(err error) bool {
+	return errors.Is(err, domain.ErrDuplicateEmail) || errors.Is(err, domain.ErrDuplicateEmailInFile)
+}
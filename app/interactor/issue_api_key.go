@@ -0,0 +1,44 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+)
+
+// apiKeySecretBytes APIキーのシークレット部分のバイト数
+const apiKeySecretBytes = 32
+
+// IssueAPIKey APIキー発行
+type IssueAPIKey struct {
+	APIKeyRepository domain.APIKeyRepository
+}
+
+func NewIssueAPIKey(repos domain.APIKeyRepository) *IssueAPIKey {
+	return &IssueAPIKey{
+		APIKeyRepository: repos,
+	}
+}
+
+// Execute 乱数からシークレットを生成し、そのハッシュ値だけを保存してAPIキーを発行
+func (i *IssueAPIKey) Execute(req *usecase.IssueAPIKeyRequest) (*usecase.IssueAPIKeyResponse, error) {
+	b := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	apiKey, err := i.APIKeyRepository.CreateAPIKey(
+		domain.NewAPIKeyModel(req.Name, domain.HashAPIKeySecret(secret), req.Scopes, req.ExpiresAt))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.IssueAPIKeyResponse{
+		APIKey: apiKey,
+		Key:    domain.FormatAPIKey(apiKey.ID, secret),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// attachmentUploadTTL アップロードURLの有効期間
+const attachmentUploadTTL = 15 * time.Minute
+
+// attachmentNameBytes 添付画像のオブジェクト名にする乱数のバイト数
+const attachmentNameBytes = 16
+
+// IssueAttachmentUpload 画像添付用のアップロードURL発行
+type IssueAttachmentUpload struct {
+	MicropostRepository domain.MicropostRepository
+	AttachmentStorage   domain.AttachmentStorage
+}
+
+func NewIssueAttachmentUpload(repos domain.MicropostRepository, storage domain.AttachmentStorage) *IssueAttachmentUpload {
+	return &IssueAttachmentUpload{
+		MicropostRepository: repos,
+		AttachmentStorage:   storage,
+	}
+}
+
+// Execute 添付画像のキーを乱数から生成し、そのキーへアップロードするための署名付きURLを発行する。
+// マイクロポストが指定したユーザーのものでない場合はdomain.ErrNotFound、
+// 添付数が上限に達している場合はdomain.ErrTooManyAttachmentsを返す
+func (i *IssueAttachmentUpload) Execute(req *usecase.IssueAttachmentUploadRequest) (*usecase.IssueAttachmentUploadResponse, error) {
+	micropost, err := i.MicropostRepository.GetMicropostByID(req.MicropostID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if micropost.UserID != req.UserID {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	if len(micropost.Attachments) >= domain.MaxAttachmentsPerMicropost {
+		return nil, errors.WithStack(domain.ErrTooManyAttachments)
+	}
+
+	b := make([]byte, attachmentNameBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	key := domain.NewAttachmentKey(micropost.ID, hex.EncodeToString(b))
+
+	expiresAt := time.Now().Add(attachmentUploadTTL)
+	url, err := i.AttachmentStorage.PresignUpload(key, req.ContentType, attachmentUploadTTL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.IssueAttachmentUploadResponse{
+		Key:       key,
+		UploadURL: url,
+		ExpiresAt: expiresAt,
+	}, nil
+}
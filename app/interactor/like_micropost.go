@@ -0,0 +1,48 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// LikeMicropost いいね
+type LikeMicropost struct {
+	MicropostRepository domain.MicropostRepository
+	LikeRepository      domain.LikeRepository
+}
+
+func NewLikeMicropost(micropostRepos domain.MicropostRepository, likeRepos domain.LikeRepository) *LikeMicropost {
+	return &LikeMicropost{
+		MicropostRepository: micropostRepos,
+		LikeRepository:      likeRepos,
+	}
+}
+
+// Execute マイクロポストにいいねする。マイクロポストが指定したユーザーのものでない場合はdomain.ErrNotFoundを返す
+func (l *LikeMicropost) Execute(req *usecase.LikeMicropostRequest) (*usecase.LikeMicropostResponse, error) {
+	err := checkMicropostOwner(l.MicropostRepository, req.MicropostID, req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = l.LikeRepository.Like(req.MicropostID, req.LikerID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.LikeMicropostResponse{}, nil
+}
+
+// checkMicropostOwner マイクロポストが存在し、指定したユーザーのものであることを確認する。そうでない場合はdomain.ErrNotFoundを返す
+func checkMicropostOwner(repos domain.MicropostRepository, micropostID, userID uint64) error {
+	micropost, err := repos.GetMicropostByID(micropostID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if micropost.UserID != userID {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// Login メールアドレスとパスワードでログインし、トークンを発行する
+type Login struct {
+	UserRepository domain.UserRepository
+	PasswordHasher domain.PasswordHasher
+	TokenIssuer    domain.TokenIssuer
+}
+
+func NewLogin(repos domain.UserRepository, hasher domain.PasswordHasher, issuer domain.TokenIssuer) *Login {
+	return &Login{
+		UserRepository: repos,
+		PasswordHasher: hasher,
+		TokenIssuer:    issuer,
+	}
+}
+
+// Execute ユーザーが存在しない・パスワードを設定していない・パスワードが一致しない場合は、区別せずにdomain.ErrUnauthorizedを返す
+func (l *Login) Execute(req *usecase.LoginRequest) (*usecase.LoginResponse, error) {
+	user, err := l.UserRepository.GetUserByEmail(req.Email)
+	if err != nil {
+		if errors.Cause(err) == domain.ErrNotFound {
+			return nil, errors.WithStack(domain.ErrUnauthorized)
+		}
+		return nil, errors.WithStack(err)
+	}
+	if !user.HasPassword() {
+		return nil, errors.WithStack(domain.ErrUnauthorized)
+	}
+
+	err = l.PasswordHasher.ComparePassword(user.PasswordHash, req.Password)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	token, expiresAt, err := l.TokenIssuer.IssueToken(user.ID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.LoginResponse{
+		UserID:    user.ID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// PurgeDeletedResources 論理削除したリソースの物理削除
+type PurgeDeletedResources struct {
+	UserRepository      domain.UserRepository
+	MicropostRepository domain.MicropostRepository
+}
+
+func NewPurgeDeletedResources(userRepos domain.UserRepository, micropostRepos domain.MicropostRepository) *PurgeDeletedResources {
+	return &PurgeDeletedResources{
+		UserRepository:      userRepos,
+		MicropostRepository: micropostRepos,
+	}
+}
+
+// Execute 保持期間を過ぎたユーザーとマイクロポストを物理削除。ctxが取り消された場合は途中で打ち切る
+func (p *PurgeDeletedResources) Execute(ctx context.Context, req *usecase.PurgeDeletedResourcesRequest) (*usecase.PurgeDeletedResourcesResponse, error) {
+	users, err := p.UserRepository.PurgeDeletedUsers(ctx, req.DeletedBefore)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	microposts, err := p.MicropostRepository.PurgeDeletedMicroposts(ctx, req.DeletedBefore)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.PurgeDeletedResourcesResponse{
+		PurgedUsers:      users,
+		PurgedMicroposts: microposts,
+	}, nil
+}
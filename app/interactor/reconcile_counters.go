@@ -0,0 +1,38 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ReconcileCounters 非正規化した件数の数え直し
+type ReconcileCounters struct {
+	CounterReconciler domain.CounterReconciler
+}
+
+func NewReconcileCounters(reconciler domain.CounterReconciler) *ReconcileCounters {
+	return &ReconcileCounters{
+		CounterReconciler: reconciler,
+	}
+}
+
+// Execute ユーザーのフォロー数・フォロワー数と、マイクロポストのいいね数・返信数を数え直す
+func (r *ReconcileCounters) Execute(ctx context.Context, req *usecase.ReconcileCountersRequest) (*usecase.ReconcileCountersResponse, error) {
+	users, err := r.CounterReconciler.ReconcileUserCounters(ctx, req.DryRun)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	microposts, err := r.CounterReconciler.ReconcileMicropostCounters(ctx, req.DryRun)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.ReconcileCountersResponse{
+		Users:      users,
+		Microposts: microposts,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// RecordAttachment 添付画像の記録
+type RecordAttachment struct {
+	MicropostRepository domain.MicropostRepository
+	AttachmentStorage   domain.AttachmentStorage
+}
+
+func NewRecordAttachment(repos domain.MicropostRepository, storage domain.AttachmentStorage) *RecordAttachment {
+	return &RecordAttachment{
+		MicropostRepository: repos,
+		AttachmentStorage:   storage,
+	}
+}
+
+// Execute アップロードが完了した画像のメタデータをストレージから取得し、キーに含まれるマイクロポストに添付する。
+// 添付画像のキーでない場合や、オブジェクトかマイクロポストが存在しない場合はdomain.ErrNotFoundを返す
+func (r *RecordAttachment) Execute(req *usecase.RecordAttachmentRequest) (*usecase.RecordAttachmentResponse, error) {
+	micropostID, ok := domain.ParseAttachmentKey(req.Key)
+	if !ok {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+
+	attachment, err := r.AttachmentStorage.GetAttachment(req.Key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = r.MicropostRepository.AddAttachment(micropostID, attachment)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.RecordAttachmentResponse{
+		MicropostID: micropostID,
+		Attachment:  attachment,
+	}, nil
+}
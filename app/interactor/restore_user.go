@@ -0,0 +1,52 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RestoreUser ユーザー復元
+type RestoreUser struct {
+	UserRepository             domain.UserRepository
+	MicropostRepository        domain.MicropostRepository
+	CascadeDeleteJobRepository domain.CascadeDeleteJobRepository
+}
+
+func NewRestoreUser(
+	repos domain.UserRepository,
+	micropostRepos domain.MicropostRepository,
+	jobRepos domain.CascadeDeleteJobRepository) *RestoreUser {
+	return &RestoreUser{
+		UserRepository:             repos,
+		MicropostRepository:        micropostRepos,
+		CascadeDeleteJobRepository: jobRepos,
+	}
+}
+
+// Execute 論理削除したユーザーを復元し、ユーザーと一緒に削除したマイクロポストも復元する。削除中にメールアドレスが他のユーザーに使われた場合は復元できない。
+// マイクロポストが多く1回で復元しきれない場合は、削除の続きのジョブを復元の続きとして登録し直して定期実行に任せる
+func (u *RestoreUser) Execute(req *usecase.RestoreUserRequest) (*usecase.RestoreUserResponse, error) {
+	user, err := u.UserRepository.RestoreUser(req.UserID)
+	if err != nil {
+		if errors.Cause(err) == domain.ErrConflict {
+			return nil, errors.WithStack(ErrUniqEmail)
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	// ユーザーの復元は完了しているため、マイクロポストの復元に失敗した場合もジョブを登録してやり直す
+	_, remaining, err := u.MicropostRepository.RestoreMicropostsByUserID(user.ID, cascadeRestoreLimit)
+	if err != nil || remaining {
+		err = u.CascadeDeleteJobRepository.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(user.ID, time.Now()))
+	} else {
+		err = u.CascadeDeleteJobRepository.DeleteCascadeDeleteJob(user.ID)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.RestoreUserResponse{User: user}, nil
+}
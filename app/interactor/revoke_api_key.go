@@ -0,0 +1,31 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// RevokeAPIKey APIキー失効
+type RevokeAPIKey struct {
+	APIKeyRepository domain.APIKeyRepository
+}
+
+func NewRevokeAPIKey(repos domain.APIKeyRepository) *RevokeAPIKey {
+	return &RevokeAPIKey{
+		APIKeyRepository: repos,
+	}
+}
+
+// Execute APIキーを失効
+func (r *RevokeAPIKey) Execute(req *usecase.RevokeAPIKeyRequest) (*usecase.RevokeAPIKeyResponse, error) {
+	err := r.APIKeyRepository.DeleteAPIKey(&domain.APIKeyModel{
+		ID:      req.APIKeyID,
+		Version: req.Version,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.RevokeAPIKeyResponse{}, nil
+}
@@ -0,0 +1,57 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// userImportNameBytes APIで受け付けたファイルの名前に使う乱数のバイト数
+const userImportNameBytes = 8
+
+// SubmitUserImport APIで受け付けたユーザーの一括取り込み
+type SubmitUserImport struct {
+	ImportJobRepository domain.ImportJobRepository
+	FileStorage         domain.FileStorage
+}
+
+func NewSubmitUserImport(jobs domain.ImportJobRepository, storage domain.FileStorage) *SubmitUserImport {
+	return &SubmitUserImport{
+		ImportJobRepository: jobs,
+		FileStorage:         storage,
+	}
+}
+
+// Execute 受け付けたファイルを、S3に直接置いたファイルと同じ接頭辞で保存する。取り込みはS3のイベントで行う。
+// 受け付けた時点で進捗を参照できるよう、占有期限を過ぎた状態でジョブを登録し、イベントで開始した実行に引き継がせる
+func (i *SubmitUserImport) Execute(req *usecase.SubmitUserImportRequest) (*usecase.SubmitUserImportResponse, error) {
+	b := make([]byte, userImportNameBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	now := time.Now()
+	key := domain.UserImportKeyPrefix + now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b) + req.Extension
+
+	etag, err := i.FileStorage.PutFile(key, req.ContentType, req.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	job, err := i.ImportJobRepository.StartImportJob(domain.NewImportJobModel(key, etag, now, now))
+	if err != nil {
+		if err.Error() != domain.ErrConflict.Error() {
+			return nil, errors.WithStack(err)
+		}
+		// 登録する前にS3のイベントで取り込みが始まっていた場合
+		job, err = i.ImportJobRepository.GetImportJob(domain.NewImportJobID(key, etag))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return &usecase.SubmitUserImportResponse{Job: job}, nil
+}
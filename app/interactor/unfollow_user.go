@@ -0,0 +1,29 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// UnfollowUser フォロー解除
+type UnfollowUser struct {
+	FollowRepository domain.FollowRepository
+}
+
+func NewUnfollowUser(repos domain.FollowRepository) *UnfollowUser {
+	return &UnfollowUser{
+		FollowRepository: repos,
+	}
+}
+
+// Execute フォローを解除する
+func (u *UnfollowUser) Execute(req *usecase.UnfollowUserRequest) (*usecase.UnfollowUserResponse, error) {
+	err := u.FollowRepository.Unfollow(req.UserID, req.TargetUserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.UnfollowUserResponse{}, nil
+}
@@ -0,0 +1,37 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// UnlikeMicropost いいね取り消し
+type UnlikeMicropost struct {
+	MicropostRepository domain.MicropostRepository
+	LikeRepository      domain.LikeRepository
+}
+
+func NewUnlikeMicropost(micropostRepos domain.MicropostRepository, likeRepos domain.LikeRepository) *UnlikeMicropost {
+	return &UnlikeMicropost{
+		MicropostRepository: micropostRepos,
+		LikeRepository:      likeRepos,
+	}
+}
+
+// Execute マイクロポストへのいいねを取り消す。マイクロポストが指定したユーザーのものでない場合と、
+// いいねしていない場合はdomain.ErrNotFoundを返す
+func (u *UnlikeMicropost) Execute(req *usecase.UnlikeMicropostRequest) (*usecase.UnlikeMicropostResponse, error) {
+	err := checkMicropostOwner(u.MicropostRepository, req.MicropostID, req.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = u.LikeRepository.Unlike(req.MicropostID, req.LikerID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &usecase.UnlikeMicropostResponse{}, nil
+}
@@ -22,6 +22,7 @@ func NewUpdateMicropost(repos domain.MicropostRepository) *UpdateMicropost {
 func (m *UpdateMicropost) Execute(req *usecase.UpdateMicropostRequest) (*usecase.UpdateMicropostResponse, error) {
 	newMicropost := domain.NewMicropostModel(req.Content, req.UserID)
 	newMicropost.ID = req.MicropostID
+	newMicropost.Version = req.Version
 	err := m.MicropostRepository.UpdateMicropost(newMicropost)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -0,0 +1,28 @@
+package interactor
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/usecase"
+
+	"github.com/pkg/errors"
+)
+
+// UpdateProduct 製品更新
+type UpdateProduct struct {
+	ProductRepository domain.ProductRepository
+}
+
+func NewUpdateProduct(repos domain.ProductRepository) *UpdateProduct {
+	return &UpdateProduct{
+		ProductRepository: repos,
+	}
+}
+
+// Execute 製品を更新
+func (p *UpdateProduct) Execute(req *usecase.UpdateProductRequest) (*usecase.UpdateProductResponse, error) {
+	err := p.ProductRepository.UpdateProduct(req.ToProductModel())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &usecase.UpdateProductResponse{}, nil
+}
@@ -32,6 +32,10 @@ func (u *UpdateUser) Execute(req *usecase.UpdateUserRequest) (*usecase.UpdateUse
 
 	err = u.UserRepository.UpdateUser(req.ToUserModel())
 	if err != nil {
+		// 確認した後に同じメールアドレスが他のユーザーに使われた場合
+		if errors.Cause(err) == domain.ErrDuplicateEmail {
+			return nil, errors.WithStack(ErrUniqEmail)
+		}
 		return nil, errors.WithStack(err)
 	}
 
@@ -0,0 +1,32 @@
+package interactor_test
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/interactor"
+	"clean-serverless-book-sample/mocks/memory"
+	"clean-serverless-book-sample/usecase"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpdateUser_raceOnEmail 書き込み時にメールアドレスの重複が分かった場合は、バージョンの競合ではなく重複エラーとして返す
+func TestUpdateUser_raceOnEmail(t *testing.T) {
+	repos := memory.NewUserRepository()
+	_, err := repos.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+	require.NoError(t, err)
+	user, err := repos.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+	require.NoError(t, err)
+
+	stale := &staleCheckUserRepository{UserRepository: repos}
+	updater := interactor.NewUpdateUser(stale, domain.NewUserEmailUniqChecker(stale))
+
+	_, err = updater.Execute(&usecase.UpdateUserRequest{ID: user.ID, Name: user.Name, Email: "test1@example.com", Version: user.Version})
+	assert.Equal(t, interactor.ErrUniqEmail, errors.Cause(err))
+
+	// バージョンが一致しない場合は競合として返す
+	_, err = updater.Execute(&usecase.UpdateUserRequest{ID: user.ID, Name: "Name_3", Email: user.Email, Version: user.Version + 1})
+	assert.Equal(t, domain.ErrConflict, errors.Cause(err))
+}
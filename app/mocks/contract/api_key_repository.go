@@ -0,0 +1,80 @@
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// APIKeyRepositoryFactory テストごとに空のAPIKeyRepositoryを生成する関数
+type APIKeyRepositoryFactory func(t *testing.T) domain.APIKeyRepository
+
+// RunAPIKeyRepository APIKeyRepositoryの契約テストを実行する
+func RunAPIKeyRepository(t *testing.T, newRepo APIKeyRepositoryFactory) {
+	t.Run("作成したAPIキーをIDで取得できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+		created, err := repo.CreateAPIKey(domain.NewAPIKeyModel(
+			"batch", domain.HashAPIKeySecret("secret"), []string{domain.ScopeUsersRead}, expiresAt))
+		require.NoError(t, err)
+		assert.NotZero(t, created.ID)
+		assert.Equal(t, 1, created.Version)
+
+		got, err := repo.GetAPIKeyByID(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "batch", got.Name)
+		assert.Equal(t, domain.HashAPIKeySecret("secret"), got.SecretHash)
+		assert.Equal(t, []string{domain.ScopeUsersRead}, got.Scopes)
+		assert.True(t, expiresAt.Equal(got.ExpiresAt))
+	})
+
+	t.Run("存在しないAPIキーはErrNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.GetAPIKeyByID(999)
+		assertNotFound(t, err)
+	})
+
+	t.Run("一覧をページングして取得できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for i := 0; i < 3; i++ {
+			_, err := repo.CreateAPIKey(domain.NewAPIKeyModel(
+				"batch", domain.HashAPIKeySecret("secret"), []string{domain.ScopeUsersRead}, time.Now().Add(time.Hour)))
+			require.NoError(t, err)
+		}
+
+		first, nextToken, err := repo.GetAPIKeys(domain.NewPage(2, ""))
+		require.NoError(t, err)
+		assert.Len(t, first, 2)
+		require.NotEmpty(t, nextToken)
+
+		second, nextToken, err := repo.GetAPIKeys(domain.NewPage(2, nextToken))
+		require.NoError(t, err)
+		assert.Len(t, second, 1)
+		assert.Empty(t, nextToken)
+	})
+
+	t.Run("削除するとバージョンが一致しない場合はErrConflict、一致すれば取得できなくなる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		created, err := repo.CreateAPIKey(domain.NewAPIKeyModel(
+			"batch", domain.HashAPIKeySecret("secret"), []string{domain.ScopeUsersRead}, time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+
+		err = repo.DeleteAPIKey(&domain.APIKeyModel{ID: created.ID, Version: created.Version + 1})
+		assertConflict(t, err)
+
+		require.NoError(t, repo.DeleteAPIKey(&domain.APIKeyModel{ID: created.ID, Version: created.Version}))
+
+		_, err = repo.GetAPIKeyByID(created.ID)
+		assertNotFound(t, err)
+
+		err = repo.DeleteAPIKey(&domain.APIKeyModel{ID: created.ID})
+		assertNotFound(t, err)
+	})
+}
@@ -0,0 +1,48 @@
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// CascadeDeleteJobRepositoryFactory テストごとに空のCascadeDeleteJobRepositoryを生成する関数
+type CascadeDeleteJobRepositoryFactory func(t *testing.T) domain.CascadeDeleteJobRepository
+
+// RunCascadeDeleteJobRepository CascadeDeleteJobRepositoryの契約テストを実行する
+func RunCascadeDeleteJobRepository(t *testing.T, newRepo CascadeDeleteJobRepositoryFactory) {
+	t.Run("登録が古い順に取得できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		now := time.Now()
+		require.NoError(t, repo.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(2, now)))
+		require.NoError(t, repo.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(1, now.Add(time.Second))))
+		require.NoError(t, repo.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(3, now.Add(2*time.Second))))
+
+		jobs, err := repo.GetCascadeDeleteJobs(2)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, uint64(2), jobs[0].UserID)
+		assert.Equal(t, uint64(1), jobs[1].UserID)
+	})
+
+	t.Run("同じユーザーのジョブは上書きされ、削除すると取得できなくなる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		require.NoError(t, repo.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(1, time.Now())))
+		require.NoError(t, repo.PutCascadeDeleteJob(domain.NewCascadeDeleteJobModel(1, time.Now())))
+
+		jobs, err := repo.GetCascadeDeleteJobs(10)
+		require.NoError(t, err)
+		assert.Len(t, jobs, 1)
+
+		require.NoError(t, repo.DeleteCascadeDeleteJob(1))
+
+		jobs, err = repo.GetCascadeDeleteJobs(10)
+		require.NoError(t, err)
+		assert.Len(t, jobs, 0)
+	})
+}
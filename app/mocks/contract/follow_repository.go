@@ -0,0 +1,122 @@
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FollowRepositoryFactory テストごとに空のFollowRepositoryと、フォローするユーザーを登録するUserRepositoryを生成する関数
+type FollowRepositoryFactory func(t *testing.T) (domain.FollowRepository, domain.UserRepository)
+
+// RunFollowRepository FollowRepositoryの契約テストを実行する
+func RunFollowRepository(t *testing.T, newRepo FollowRepositoryFactory) {
+	t.Run("フォローすると双方の件数が増え、一覧に含まれる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 3)
+
+		require.NoError(t, repo.Follow(1, 2))
+		require.NoError(t, repo.Follow(1, 3))
+		require.NoError(t, repo.Follow(3, 2))
+		// すでにフォローしている場合は何もしない
+		require.NoError(t, repo.Follow(1, 2))
+
+		user1, err := users.GetUserByID(1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, user1.FollowingCount)
+		assert.Equal(t, 0, user1.FollowerCount)
+
+		user2, err := users.GetUserByID(2)
+		require.NoError(t, err)
+		assert.Equal(t, 0, user2.FollowingCount)
+		assert.Equal(t, 2, user2.FollowerCount)
+
+		following, _, err := repo.GetFollowing(1, domain.NewPage(10, ""))
+		require.NoError(t, err)
+		require.Len(t, following, 2)
+		assert.Equal(t, uint64(2), following[0].TargetUserID)
+		assert.Equal(t, uint64(3), following[1].TargetUserID)
+
+		followers, _, err := repo.GetFollowers(2, domain.NewPage(10, ""))
+		require.NoError(t, err)
+		require.Len(t, followers, 2)
+		// 新しくフォローした順
+		assert.Equal(t, uint64(3), followers[0].UserID)
+		assert.Equal(t, uint64(1), followers[1].UserID)
+	})
+
+	t.Run("フォローを解除すると件数が減り、一覧から消える", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		require.NoError(t, repo.Follow(1, 2))
+		require.NoError(t, repo.Unfollow(1, 2))
+
+		user1, err := users.GetUserByID(1)
+		require.NoError(t, err)
+		assert.Equal(t, 0, user1.FollowingCount)
+		user2, err := users.GetUserByID(2)
+		require.NoError(t, err)
+		assert.Equal(t, 0, user2.FollowerCount)
+
+		following, _, err := repo.GetFollowing(1, domain.NewPage(10, ""))
+		require.NoError(t, err)
+		assert.Len(t, following, 0)
+
+		assertNotFound(t, repo.Unfollow(1, 2))
+	})
+
+	t.Run("存在しないユーザーはフォローできない", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 1)
+
+		assertNotFound(t, repo.Follow(1, 999))
+		assertNotFound(t, repo.Follow(999, 1))
+
+		user1, err := users.GetUserByID(1)
+		require.NoError(t, err)
+		assert.Equal(t, 0, user1.FollowingCount)
+		assert.Equal(t, 0, user1.FollowerCount)
+	})
+
+	t.Run("件数の更新ではバージョンが変わらず、取得した時のバージョンでユーザーを更新できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		before, err := users.GetUserByID(2)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Follow(1, 2))
+
+		before.Name = "Name_2_updated"
+		require.NoError(t, users.UpdateUser(before))
+
+		// ユーザーの更新でフォロワー数が書き戻されない
+		actual, err := users.GetUserByID(2)
+		require.NoError(t, err)
+		assert.Equal(t, "Name_2_updated", actual.Name)
+		assert.Equal(t, 1, actual.FollowerCount)
+		assert.Equal(t, before.Version+1, actual.Version)
+	})
+
+	t.Run("一覧をページングして取得できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 4)
+
+		for _, target := range []uint64{2, 3, 4} {
+			require.NoError(t, repo.Follow(1, target))
+		}
+
+		first, nextToken, err := repo.GetFollowing(1, domain.NewPage(2, ""))
+		require.NoError(t, err)
+		assert.Len(t, first, 2)
+		require.NotEmpty(t, nextToken)
+
+		second, nextToken, err := repo.GetFollowing(1, domain.NewPage(2, nextToken))
+		require.NoError(t, err)
+		assert.Len(t, second, 1)
+		assert.Empty(t, nextToken)
+	})
+}
@@ -0,0 +1,116 @@
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// IdempotencyKeyRepositoryFactory テストごとに空のIdempotencyKeyRepositoryを生成する関数
+type IdempotencyKeyRepositoryFactory func(t *testing.T) domain.IdempotencyKeyRepository
+
+// RunIdempotencyKeyRepository IdempotencyKeyRepositoryの契約テストを実行する
+func RunIdempotencyKeyRepository(t *testing.T, newRepo IdempotencyKeyRepositoryFactory) {
+	t.Run("同じキーは有効期限内に二重に登録できない", func(t *testing.T) {
+		repo := newRepo(t)
+
+		key := domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-1", time.Now())
+		require.NoError(t, repo.ReserveIdempotencyKey(key))
+
+		assertConflict(t, repo.ReserveIdempotencyKey(domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-2", time.Now())))
+
+		actual, err := repo.GetIdempotencyKey("owner-1", "key-1")
+		require.NoError(t, err)
+		assert.Equal(t, "hash-1", actual.RequestHash)
+		assert.False(t, actual.IsCompleted())
+	})
+
+	t.Run("レスポンスを保存できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		key := domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-1", time.Now())
+		require.NoError(t, repo.ReserveIdempotencyKey(key))
+
+		key.StatusCode = 201
+		key.ResponseBody = `{"id":"1"}`
+		require.NoError(t, repo.CompleteIdempotencyKey(key))
+
+		actual, err := repo.GetIdempotencyKey("owner-1", "key-1")
+		require.NoError(t, err)
+		assert.True(t, actual.IsCompleted())
+		assert.Equal(t, 201, actual.StatusCode)
+		assert.Equal(t, `{"id":"1"}`, actual.ResponseBody)
+	})
+
+	t.Run("削除すると同じキーで登録し直せる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		key := domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-1", time.Now())
+		require.NoError(t, repo.ReserveIdempotencyKey(key))
+		require.NoError(t, repo.DeleteIdempotencyKey(key))
+
+		_, err := repo.GetIdempotencyKey("owner-1", "key-1")
+		assertNotFound(t, err)
+
+		assert.NoError(t, repo.ReserveIdempotencyKey(domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-2", time.Now())))
+	})
+
+	t.Run("呼び出し元が異なれば同じキーを別々に登録できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		require.NoError(t, repo.ReserveIdempotencyKey(domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-1", time.Now())))
+		require.NoError(t, repo.ReserveIdempotencyKey(domain.NewIdempotencyKeyModel("owner-2", "key-1", "hash-2", time.Now())))
+
+		actual, err := repo.GetIdempotencyKey("owner-1", "key-1")
+		require.NoError(t, err)
+		assert.Equal(t, "hash-1", actual.RequestHash)
+
+		actual, err = repo.GetIdempotencyKey("owner-2", "key-1")
+		require.NoError(t, err)
+		assert.Equal(t, "hash-2", actual.RequestHash)
+	})
+
+	t.Run("有効期限が切れたキーは存在しないものとして扱う", func(t *testing.T) {
+		repo := newRepo(t)
+
+		expired := domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-1", time.Now().Add(-2*domain.IdempotencyKeyTTL))
+		require.NoError(t, repo.ReserveIdempotencyKey(expired))
+
+		_, err := repo.GetIdempotencyKey("owner-1", "key-1")
+		assertNotFound(t, err)
+
+		assert.NoError(t, repo.ReserveIdempotencyKey(domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-2", time.Now())))
+	})
+
+	t.Run("処理中のまま占有期限が過ぎたキーは同じリクエストで引き継げる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		stale := domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-1", time.Now().Add(-2*domain.IdempotencyKeyLease))
+		require.NoError(t, repo.ReserveIdempotencyKey(stale))
+
+		// 異なるリクエストでは引き継げない
+		assertConflict(t, repo.ReserveIdempotencyKey(domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-2", time.Now())))
+
+		retry := domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-1", time.Now())
+		require.NoError(t, repo.ReserveIdempotencyKey(retry))
+
+		// 引き継がれた後は元のリクエストのレスポンスで上書きできない
+		stale.StatusCode = 201
+		assertConflict(t, repo.CompleteIdempotencyKey(stale))
+
+		// 引き継がれた後は元のリクエストの失敗で削除できない
+		assertConflict(t, repo.DeleteIdempotencyKey(stale))
+		_, err := repo.GetIdempotencyKey("owner-1", "key-1")
+		require.NoError(t, err)
+
+		retry.StatusCode = 201
+		retry.ResponseBody = `{"id":"1"}`
+		require.NoError(t, repo.CompleteIdempotencyKey(retry))
+
+		// 完了したキーは占有期限に関係なく引き継げない
+		assertConflict(t, repo.ReserveIdempotencyKey(domain.NewIdempotencyKeyModel("owner-1", "key-1", "hash-1", time.Now().Add(domain.IdempotencyKeyLease))))
+	})
+}
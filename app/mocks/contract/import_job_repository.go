@@ -0,0 +1,81 @@
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ImportJobRepositoryFactory テストごとに空のImportJobRepositoryを生成する関数
+type ImportJobRepositoryFactory func(t *testing.T) domain.ImportJobRepository
+
+// RunImportJobRepository ImportJobRepositoryの契約テストを実行する
+func RunImportJobRepository(t *testing.T, newRepo ImportJobRepositoryFactory) {
+	// DynamoDBでは占有期限を秒単位で保存するため、秒未満を切り捨てておく
+	now := time.Now().Truncate(time.Second)
+
+	t.Run("開始したジョブの進捗を保存して取得できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		job, err := repo.StartImportJob(domain.NewImportJobModel("imports/products/a.csv", "etag1", now, now.Add(time.Minute)))
+		require.NoError(t, err)
+
+		job.Processed = 10
+		job.Failed = 2
+		job.Status = domain.ImportJobStatusCompleted
+		require.NoError(t, repo.UpdateImportJob(job))
+
+		actual, err := repo.GetImportJob(job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "imports/products/a.csv", actual.Source)
+		assert.Equal(t, 10, actual.Processed)
+		assert.Equal(t, 2, actual.Failed)
+		assert.True(t, actual.IsCompleted())
+
+		_, err = repo.GetImportJob(domain.NewImportJobID("imports/products/a.csv", "etag2"))
+		assertNotFound(t, err)
+	})
+
+	t.Run("完了したジョブや占有中のジョブは開始できない", func(t *testing.T) {
+		repo := newRepo(t)
+
+		job, err := repo.StartImportJob(domain.NewImportJobModel("imports/products/a.csv", "etag1", now, now.Add(time.Minute)))
+		require.NoError(t, err)
+
+		_, err = repo.StartImportJob(domain.NewImportJobModel("imports/products/a.csv", "etag1", now, now.Add(2*time.Minute)))
+		assertConflict(t, err)
+
+		job.Status = domain.ImportJobStatusCompleted
+		require.NoError(t, repo.UpdateImportJob(job))
+
+		_, err = repo.StartImportJob(domain.NewImportJobModel("imports/products/a.csv", "etag1", now, now.Add(2*time.Minute)))
+		assertConflict(t, err)
+
+		// 版が異なれば別のジョブとして開始できる
+		_, err = repo.StartImportJob(domain.NewImportJobModel("imports/products/a.csv", "etag2", now, now.Add(time.Minute)))
+		require.NoError(t, err)
+	})
+
+	t.Run("占有期限が過ぎたジョブは進捗を引き継ぎ、元の実行は保存できなくなる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		job, err := repo.StartImportJob(domain.NewImportJobModel("imports/products/a.csv", "etag1", now, now.Add(-time.Second)))
+		require.NoError(t, err)
+		job.Processed = 25
+		require.NoError(t, repo.UpdateImportJob(job))
+
+		resumed, err := repo.StartImportJob(domain.NewImportJobModel("imports/products/a.csv", "etag1", now, now.Add(time.Minute)))
+		require.NoError(t, err)
+		assert.Equal(t, 25, resumed.Processed)
+		assert.True(t, now.Add(time.Minute).Equal(resumed.LockedUntil))
+
+		job.Processed = 50
+		assertConflict(t, repo.UpdateImportJob(job))
+
+		resumed.Processed = 50
+		require.NoError(t, repo.UpdateImportJob(resumed))
+	})
+}
@@ -0,0 +1,72 @@
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// LikeRepositoryFactory テストごとに空のLikeRepositoryと、いいねするマイクロポストとユーザーを登録するリポジトリを生成する関数
+type LikeRepositoryFactory func(t *testing.T) (domain.LikeRepository, domain.MicropostRepository, domain.UserRepository)
+
+// RunLikeRepository LikeRepositoryの契約テストを実行する
+func RunLikeRepository(t *testing.T, newRepo LikeRepositoryFactory) {
+	t.Run("いいねするといいね数が増え、取り消すと減る", func(t *testing.T) {
+		repo, microposts, users := newRepo(t)
+		createUsers(t, users, 3)
+
+		m, err := microposts.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Like(m.ID, 2))
+		require.NoError(t, repo.Like(m.ID, 3))
+		// すでにいいねしている場合は何もしない
+		require.NoError(t, repo.Like(m.ID, 2))
+
+		actual, err := microposts.GetMicropostByID(m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, actual.LikeCount)
+
+		require.NoError(t, repo.Unlike(m.ID, 2))
+		assertNotFound(t, repo.Unlike(m.ID, 2))
+
+		actual, err = microposts.GetMicropostByID(m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, actual.LikeCount)
+	})
+
+	t.Run("いいね数の更新ではバージョンが変わらず、取得した時のバージョンで本文を更新できる", func(t *testing.T) {
+		repo, microposts, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		m, err := microposts.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Like(m.ID, 2))
+
+		m.Content = "Content_1_updated"
+		require.NoError(t, microposts.UpdateMicropost(m))
+
+		// 本文を更新してもいいね数は書き戻されない
+		actual, err := microposts.GetMicropostByID(m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, actual.LikeCount)
+	})
+
+	t.Run("存在しないマイクロポストやユーザーではいいねできない", func(t *testing.T) {
+		repo, microposts, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		m, err := microposts.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+
+		assertNotFound(t, repo.Like(999, 2))
+		assertNotFound(t, repo.Like(m.ID, 999))
+
+		// 論理削除したマイクロポストも存在しないものとして扱う
+		require.NoError(t, microposts.DeleteMicropost(m))
+		assertNotFound(t, repo.Like(m.ID, 2))
+	})
+}
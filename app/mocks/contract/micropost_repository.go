@@ -0,0 +1,549 @@
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MicropostRepositoryFactory テストごとに空のMicropostRepositoryと、投稿するユーザーを登録するUserRepositoryを生成する関数
+type MicropostRepositoryFactory func(t *testing.T) (domain.MicropostRepository, domain.UserRepository)
+
+// RunMicropostRepository MicropostRepositoryの契約テストを実行する
+func RunMicropostRepository(t *testing.T, newRepo MicropostRepositoryFactory) {
+	t.Run("CreateMicropostは連番でIDを採番する", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		m1, err := repo.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+		m2, err := repo.CreateMicropost(domain.NewMicropostModel("Content_2", 1))
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1), m1.ID)
+		assert.Equal(t, uint64(2), m2.ID)
+
+		actual, err := repo.GetMicropostByID(m1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, m1.Content, actual.Content)
+		assert.Equal(t, m1.UserID, actual.UserID)
+	})
+
+	t.Run("存在しないユーザーのマイクロポストは作成できない", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 1)
+
+		_, err := repo.CreateMicropost(domain.NewMicropostModel("Content_1", 999))
+		assertNotFound(t, err)
+
+		// 論理削除したユーザーも存在しないものとして扱う
+		user, err := users.GetUserByID(1)
+		require.NoError(t, err)
+		require.NoError(t, users.DeleteUser(user))
+
+		_, err = repo.CreateMicropost(domain.NewMicropostModel("Content_1", user.ID))
+		assertNotFound(t, err)
+	})
+
+	t.Run("存在しないマイクロポストはErrNotFound", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		_, err := repo.GetMicropostByID(999)
+		assertNotFound(t, err)
+
+		err = repo.UpdateMicropost(&domain.MicropostModel{ID: 999, Content: "Content", UserID: 1})
+		assertNotFound(t, err)
+
+		err = repo.DeleteMicropost(&domain.MicropostModel{ID: 999})
+		assertNotFound(t, err)
+	})
+
+	t.Run("本文を更新できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		m, err := repo.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+
+		m.Content = "Content_1_updated"
+		require.NoError(t, repo.UpdateMicropost(m))
+
+		actual, err := repo.GetMicropostByID(m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Content_1_updated", actual.Content)
+	})
+
+	t.Run("古いバージョンでは更新・削除できない", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		m, err := repo.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+		assert.Equal(t, 1, m.Version)
+
+		m.Content = "Content_1_updated"
+		require.NoError(t, repo.UpdateMicropost(m))
+
+		actual, err := repo.GetMicropostByID(m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, actual.Version)
+
+		// 取得前のバージョンのまま更新・削除する
+		m.Content = "Content_1_stale"
+		assertConflict(t, repo.UpdateMicropost(m))
+		assertConflict(t, repo.DeleteMicropost(m))
+
+		// バージョンを指定しない場合はチェックしない
+		m.Version = 0
+		require.NoError(t, repo.UpdateMicropost(m))
+		require.NoError(t, repo.DeleteMicropost(m))
+	})
+
+	t.Run("ユーザーごとの一覧を新しい順にページングして取得できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		var created []*domain.MicropostModel
+		for _, content := range []string{"Content_1", "Content_2", "Content_3"} {
+			m, err := repo.CreateMicropost(domain.NewMicropostModel(content, 1))
+			require.NoError(t, err)
+			created = append(created, m)
+		}
+		_, err := repo.CreateMicropost(domain.NewMicropostModel("Other", 2))
+		require.NoError(t, err)
+
+		page1, nextToken, err := repo.GetMicropostsByUserID(1, domain.NewPage(2, ""))
+		require.NoError(t, err)
+		require.Len(t, page1, 2)
+		assert.NotEmpty(t, nextToken)
+		assert.Equal(t, created[2].ID, page1[0].ID)
+		assert.Equal(t, created[1].ID, page1[1].ID)
+
+		page2, nextToken, err := repo.GetMicropostsByUserID(1, domain.NewPage(2, nextToken))
+		require.NoError(t, err)
+		require.Len(t, page2, 1)
+		assert.Empty(t, nextToken)
+		assert.Equal(t, created[0].ID, page2[0].ID)
+
+		_, _, err = repo.GetMicropostsByUserID(1, domain.NewPage(2, "invalid"))
+		assertInvalidPageToken(t, err)
+	})
+
+	t.Run("作成日時を保持し、取得しても変わらない", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 1)
+
+		before := time.Now()
+		m, err := repo.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+		assert.False(t, m.CreatedAt.Before(before))
+
+		m.Content = "Content_1_updated"
+		require.NoError(t, repo.UpdateMicropost(m))
+
+		actual, err := repo.GetMicropostByID(m.ID)
+		require.NoError(t, err)
+		assert.True(t, m.CreatedAt.Equal(actual.CreatedAt))
+	})
+
+	t.Run("カーソルより古いマイクロポストを新しい順に取得できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		var created []*domain.MicropostModel
+		for _, content := range []string{"Content_1", "Content_2", "Content_3", "Content_4"} {
+			m, err := repo.CreateMicropost(domain.NewMicropostModel(content, 1))
+			require.NoError(t, err)
+			created = append(created, m)
+		}
+		_, err := repo.CreateMicropost(domain.NewMicropostModel("Other", 2))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteMicropost(created[1]))
+
+		latest, err := repo.GetMicropostsByUserIDBefore(1, nil, 2)
+		require.NoError(t, err)
+		require.Len(t, latest, 2)
+		assert.Equal(t, created[3].ID, latest[0].ID)
+		assert.Equal(t, created[2].ID, latest[1].ID)
+
+		// カーソルの位置のマイクロポスト自身と、論理削除したものは含まない
+		older, err := repo.GetMicropostsByUserIDBefore(1, domain.NewMicropostCursor(latest[1]), 2)
+		require.NoError(t, err)
+		require.Len(t, older, 1)
+		assert.Equal(t, created[0].ID, older[0].ID)
+
+		none, err := repo.GetMicropostsByUserIDBefore(1, domain.NewMicropostCursor(older[0]), 2)
+		require.NoError(t, err)
+		assert.Len(t, none, 0)
+	})
+
+	t.Run("保持期間を過ぎた論理削除済みのマイクロポストだけを物理削除する", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		m1, err := repo.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+		_, err = repo.CreateMicropost(domain.NewMicropostModel("Content_2", 1))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteMicropost(m1))
+
+		purged, err := repo.PurgeDeletedMicroposts(context.Background(), time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		purged, err = repo.PurgeDeletedMicroposts(context.Background(), time.Now().Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		microposts, _, err := repo.GetMicropostsByUserID(1, nil)
+		require.NoError(t, err)
+		assert.Len(t, microposts, 1)
+	})
+
+	t.Run("ユーザーのマイクロポストを上限件数までまとめて論理削除できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		var created []*domain.MicropostModel
+		for i := 0; i < 30; i++ {
+			m, err := repo.CreateMicropost(domain.NewMicropostModel("Content", 1))
+			require.NoError(t, err)
+			created = append(created, m)
+		}
+		other, err := repo.CreateMicropost(domain.NewMicropostModel("Other", 2))
+		require.NoError(t, err)
+
+		deleted, remaining, err := repo.DeleteMicropostsByUserID(1, 27)
+		require.NoError(t, err)
+		assert.Equal(t, 27, deleted)
+		assert.True(t, remaining)
+
+		deleted, remaining, err = repo.DeleteMicropostsByUserID(1, 27)
+		require.NoError(t, err)
+		assert.Equal(t, 3, deleted)
+		assert.False(t, remaining)
+
+		deleted, remaining, err = repo.DeleteMicropostsByUserID(1, 27)
+		require.NoError(t, err)
+		assert.Equal(t, 0, deleted)
+		assert.False(t, remaining)
+
+		for _, m := range created {
+			_, err := repo.GetMicropostByID(m.ID)
+			assertNotFound(t, err)
+		}
+
+		// 他のユーザーのマイクロポストは削除しない
+		_, err = repo.GetMicropostByID(other.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ユーザー単位で削除したマイクロポストだけを復元できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 1)
+
+		post1, err := repo.CreateMicropost(domain.NewMicropostModel("Post_1", 1))
+		require.NoError(t, err)
+		post2, err := repo.CreateMicropost(domain.NewMicropostModel("Post_2", 1))
+		require.NoError(t, err)
+
+		// 個別に削除したマイクロポストは復元しない
+		removed, err := repo.CreateMicropost(domain.NewMicropostModel("Removed", 1))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteMicropost(removed))
+
+		deleted, remaining, err := repo.DeleteMicropostsByUserID(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+		assert.False(t, remaining)
+
+		restored, remaining, err := repo.RestoreMicropostsByUserID(1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, restored)
+		assert.True(t, remaining)
+
+		restored, remaining, err = repo.RestoreMicropostsByUserID(1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, restored)
+		assert.False(t, remaining)
+
+		restored, remaining, err = repo.RestoreMicropostsByUserID(1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 0, restored)
+		assert.False(t, remaining)
+
+		for _, m := range []*domain.MicropostModel{post1, post2} {
+			_, err := repo.GetMicropostByID(m.ID)
+			assert.NoError(t, err)
+		}
+		_, err = repo.GetMicropostByID(removed.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("返信を投稿すると返信先の返信数が増え、返信を古い順に取得できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		parent, err := repo.CreateMicropost(domain.NewMicropostModel("Parent", 1))
+		require.NoError(t, err)
+		_, err = repo.CreateMicropost(domain.NewMicropostModel("Other", 1))
+		require.NoError(t, err)
+
+		var replies []*domain.MicropostModel
+		for _, content := range []string{"Reply_1", "Reply_2", "Reply_3"} {
+			m, err := repo.CreateMicropost(domain.NewReplyMicropostModel(content, 2, parent.ID))
+			require.NoError(t, err)
+			assert.Equal(t, parent.ID, m.InReplyToID)
+			replies = append(replies, m)
+		}
+
+		actual, err := repo.GetMicropostByID(parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, actual.ReplyCount)
+		// 返信数の更新ではバージョンは変わらない
+		assert.Equal(t, parent.Version, actual.Version)
+
+		page1, nextToken, err := repo.GetRepliesByMicropostID(parent.ID, domain.NewPage(2, ""))
+		require.NoError(t, err)
+		require.Len(t, page1, 2)
+		assert.NotEmpty(t, nextToken)
+		assert.Equal(t, replies[0].ID, page1[0].ID)
+		assert.Equal(t, replies[1].ID, page1[1].ID)
+
+		page2, _, err := repo.GetRepliesByMicropostID(parent.ID, domain.NewPage(2, nextToken))
+		require.NoError(t, err)
+		require.Len(t, page2, 1)
+		assert.Equal(t, replies[2].ID, page2[0].ID)
+
+		// 返信を削除すると一覧から除かれ、返信数が減る
+		require.NoError(t, repo.DeleteMicropost(replies[1]))
+		all, _, err := repo.GetRepliesByMicropostID(parent.ID, nil)
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, replies[0].ID, all[0].ID)
+		assert.Equal(t, replies[2].ID, all[1].ID)
+
+		actual, err = repo.GetMicropostByID(parent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, actual.ReplyCount)
+
+		_, _, err = repo.GetRepliesByMicropostID(parent.ID, domain.NewPage(2, "invalid"))
+		assertInvalidPageToken(t, err)
+	})
+
+	t.Run("存在しないマイクロポストには返信できない", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 1)
+
+		_, err := repo.CreateMicropost(domain.NewReplyMicropostModel("Reply", 1, 999))
+		assert.ErrorIs(t, err, domain.ErrInReplyToNotFound)
+
+		// 論理削除したマイクロポストにも返信できない
+		parent, err := repo.CreateMicropost(domain.NewMicropostModel("Parent", 1))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteMicropost(parent))
+
+		_, err = repo.CreateMicropost(domain.NewReplyMicropostModel("Reply", 1, parent.ID))
+		assert.ErrorIs(t, err, domain.ErrInReplyToNotFound)
+	})
+
+	t.Run("返信先を削除しても返信はOrphanedとして残る", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		parent, err := repo.CreateMicropost(domain.NewMicropostModel("Parent", 1))
+		require.NoError(t, err)
+		reply, err := repo.CreateMicropost(domain.NewReplyMicropostModel("Reply", 2, parent.ID))
+		require.NoError(t, err)
+		assert.False(t, reply.Orphaned)
+
+		require.NoError(t, repo.DeleteMicropost(&domain.MicropostModel{ID: parent.ID}))
+
+		actual, err := repo.GetMicropostByID(reply.ID)
+		require.NoError(t, err)
+		assert.True(t, actual.Orphaned)
+		assert.Equal(t, parent.ID, actual.InReplyToID)
+
+		// 返信先が削除された返信も削除できる
+		require.NoError(t, repo.DeleteMicropost(actual))
+
+		// ユーザーと一緒に削除した返信先が復元されると、返信の印も戻る
+		parent, err = repo.CreateMicropost(domain.NewMicropostModel("Parent", 1))
+		require.NoError(t, err)
+		reply, err = repo.CreateMicropost(domain.NewReplyMicropostModel("Reply", 2, parent.ID))
+		require.NoError(t, err)
+
+		_, _, err = repo.DeleteMicropostsByUserID(1, 10)
+		require.NoError(t, err)
+		actual, err = repo.GetMicropostByID(reply.ID)
+		require.NoError(t, err)
+		assert.True(t, actual.Orphaned)
+
+		_, _, err = repo.RestoreMicropostsByUserID(1, 10)
+		require.NoError(t, err)
+		actual, err = repo.GetMicropostByID(reply.ID)
+		require.NoError(t, err)
+		assert.False(t, actual.Orphaned)
+	})
+
+	t.Run("本文のハッシュタグとメンションから新しい順に取得できる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 3)
+
+		m1, err := repo.CreateMicropost(domain.NewMicropostModel("#Go と ＃東京 の話 @2", 1))
+		require.NoError(t, err)
+		m2, err := repo.CreateMicropost(domain.NewMicropostModel("＃ｇｏ を書いた ＠２ ＠３", 1))
+		require.NoError(t, err)
+		_, err = repo.CreateMicropost(domain.NewMicropostModel("https://example.com/#go user@2.example.com", 1))
+		require.NoError(t, err)
+
+		// 全角・大文字のタグも同じタグとして扱う
+		tagged, _, err := repo.GetMicropostsByHashtag("go", nil)
+		require.NoError(t, err)
+		require.Len(t, tagged, 2)
+		assert.Equal(t, m2.ID, tagged[0].ID)
+		assert.Equal(t, m1.ID, tagged[1].ID)
+
+		tagged, _, err = repo.GetMicropostsByHashtag("東京", nil)
+		require.NoError(t, err)
+		require.Len(t, tagged, 1)
+		assert.Equal(t, m1.ID, tagged[0].ID)
+
+		page1, nextToken, err := repo.GetMicropostsMentioning(2, domain.NewPage(1, ""))
+		require.NoError(t, err)
+		require.Len(t, page1, 1)
+		assert.Equal(t, m2.ID, page1[0].ID)
+		require.NotEmpty(t, nextToken)
+
+		page2, _, err := repo.GetMicropostsMentioning(2, domain.NewPage(1, nextToken))
+		require.NoError(t, err)
+		require.Len(t, page2, 1)
+		assert.Equal(t, m1.ID, page2[0].ID)
+
+		_, _, err = repo.GetMicropostsByHashtag("go", domain.NewPage(2, "invalid"))
+		assertInvalidPageToken(t, err)
+	})
+
+	t.Run("本文を更新すると、なくなったハッシュタグとメンションからは取得できなくなる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 3)
+
+		m, err := repo.CreateMicropost(domain.NewMicropostModel("#go #dynamo @2", 1))
+		require.NoError(t, err)
+
+		m.Content = "#go #lambda @3"
+		require.NoError(t, repo.UpdateMicropost(m))
+
+		for tag, expected := range map[string]int{"go": 1, "dynamo": 0, "lambda": 1} {
+			tagged, _, err := repo.GetMicropostsByHashtag(tag, nil)
+			require.NoError(t, err)
+			assert.Len(t, tagged, expected, tag)
+		}
+		for userID, expected := range map[uint64]int{2: 0, 3: 1} {
+			mentioning, _, err := repo.GetMicropostsMentioning(userID, nil)
+			require.NoError(t, err)
+			assert.Len(t, mentioning, expected, userID)
+		}
+
+		// 削除したマイクロポストは含まない
+		actual, err := repo.GetMicropostByID(m.ID)
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteMicropost(actual))
+		tagged, _, err := repo.GetMicropostsByHashtag("go", nil)
+		require.NoError(t, err)
+		assert.Len(t, tagged, 0)
+	})
+
+	t.Run("存在しないユーザーへのメンションからは取得できない", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 1)
+
+		_, err := repo.CreateMicropost(domain.NewMicropostModel("@2 @999", 1))
+		require.NoError(t, err)
+
+		// 投稿した後に作成されたユーザーもメンションからは取得できない
+		_, err = users.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+		require.NoError(t, err)
+		mentioning, _, err := repo.GetMicropostsMentioning(2, nil)
+		require.NoError(t, err)
+		assert.Len(t, mentioning, 0)
+
+		m, err := repo.CreateMicropost(domain.NewMicropostModel("@2", 1))
+		require.NoError(t, err)
+		mentioning, _, err = repo.GetMicropostsMentioning(2, nil)
+		require.NoError(t, err)
+		require.Len(t, mentioning, 1)
+		assert.Equal(t, m.ID, mentioning[0].ID)
+	})
+
+	t.Run("AddAttachmentは同じキーの画像を重複して添付しない", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 1)
+
+		m, err := repo.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+
+		uploadedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		for i := 1; i <= domain.MaxAttachmentsPerMicropost; i++ {
+			attachment := &domain.AttachmentModel{
+				Key:         domain.NewAttachmentKey(m.ID, fmt.Sprintf("image_%d", i)),
+				Size:        int64(i * 100),
+				ContentType: "image/png",
+				UploadedAt:  uploadedAt,
+			}
+			require.NoError(t, repo.AddAttachment(m.ID, attachment))
+			// S3のイベント通知が重複して届いた場合
+			require.NoError(t, repo.AddAttachment(m.ID, attachment))
+		}
+
+		actual, err := repo.GetMicropostByID(m.ID)
+		require.NoError(t, err)
+		require.Len(t, actual.Attachments, domain.MaxAttachmentsPerMicropost)
+		assert.Equal(t, domain.NewAttachmentKey(m.ID, "image_1"), actual.Attachments[0].Key)
+		assert.Equal(t, int64(100), actual.Attachments[0].Size)
+		assert.Equal(t, "image/png", actual.Attachments[0].ContentType)
+		assert.True(t, uploadedAt.Equal(actual.Attachments[0].UploadedAt))
+		// 添付でもバージョンが上がり、古い内容での更新は競合する
+		assert.Greater(t, actual.Version, m.Version)
+
+		err = repo.AddAttachment(m.ID, &domain.AttachmentModel{Key: domain.NewAttachmentKey(m.ID, "image_5")})
+		if assert.Error(t, err) {
+			assert.Equal(t, domain.ErrTooManyAttachments.Error(), err.Error())
+		}
+
+		err = repo.AddAttachment(999, &domain.AttachmentModel{Key: domain.NewAttachmentKey(999, "image_1")})
+		assertNotFound(t, err)
+	})
+
+	t.Run("削除すると取得できなくなる", func(t *testing.T) {
+		repo, users := newRepo(t)
+		createUsers(t, users, 2)
+
+		m, err := repo.CreateMicropost(domain.NewMicropostModel("Content_1", 1))
+		require.NoError(t, err)
+
+		require.NoError(t, repo.DeleteMicropost(m))
+
+		_, err = repo.GetMicropostByID(m.ID)
+		assertNotFound(t, err)
+
+		microposts, _, err := repo.GetMicropostsByUserID(1, nil)
+		require.NoError(t, err)
+		assert.Len(t, microposts, 0)
+	})
+}
+
+// createUsers マイクロポストの投稿やフォローに使うユーザーをn人作成する。IDは1から連番になる
+func createUsers(t *testing.T, users domain.UserRepository, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		_, err := users.CreateUser(domain.NewUserModel(fmt.Sprintf("Name_%d", i), fmt.Sprintf("test%d@example.com", i)))
+		require.NoError(t, err)
+	}
+}
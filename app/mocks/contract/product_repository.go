@@ -0,0 +1,154 @@
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ProductRepositoryFactory テストごとに空のProductRepositoryを生成する関数
+type ProductRepositoryFactory func(t *testing.T) domain.ProductRepository
+
+// RunProductRepository ProductRepositoryの契約テストを実行する
+func RunProductRepository(t *testing.T, newRepo ProductRepositoryFactory) {
+	releaseDate := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("CreateProductは連番でIDを採番する", func(t *testing.T) {
+		repo := newRepo(t)
+
+		p1, err := repo.CreateProduct(domain.NewProductModel("製品1", 100, releaseDate))
+		require.NoError(t, err)
+		p2, err := repo.CreateProduct(domain.NewProductModel("製品2", 200, releaseDate))
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1), p1.ID)
+		assert.Equal(t, uint64(2), p2.ID)
+
+		actual, err := repo.GetProductByID(p2.ID)
+		require.NoError(t, err)
+		assert.Equal(t, p2.Name, actual.Name)
+		assert.Equal(t, p2.Price, actual.Price)
+		assert.True(t, p2.ReleaseDate.Equal(actual.ReleaseDate))
+	})
+
+	t.Run("存在しない製品はErrNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.GetProductByID(999)
+		assertNotFound(t, err)
+
+		err = repo.UpdateProduct(&domain.ProductModel{ID: 999, Name: "製品", ReleaseDate: releaseDate})
+		assertNotFound(t, err)
+
+		err = repo.DeleteProduct(&domain.ProductModel{ID: 999})
+		assertNotFound(t, err)
+	})
+
+	t.Run("更新できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		p, err := repo.CreateProduct(domain.NewProductModel("製品1", 100, releaseDate))
+		require.NoError(t, err)
+
+		p.Name = "製品1(更新)"
+		p.Price = 150
+		p.ReleaseDate = releaseDate.AddDate(0, 0, 1)
+		require.NoError(t, repo.UpdateProduct(p))
+
+		actual, err := repo.GetProductByID(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, p.Name, actual.Name)
+		assert.Equal(t, p.Price, actual.Price)
+		assert.True(t, p.ReleaseDate.Equal(actual.ReleaseDate))
+	})
+
+	t.Run("古いバージョンでは更新・削除できない", func(t *testing.T) {
+		repo := newRepo(t)
+
+		p, err := repo.CreateProduct(domain.NewProductModel("製品1", 100, releaseDate))
+		require.NoError(t, err)
+		assert.Equal(t, 1, p.Version)
+
+		p.Price = 150
+		require.NoError(t, repo.UpdateProduct(p))
+
+		actual, err := repo.GetProductByID(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, actual.Version)
+
+		assertConflict(t, repo.UpdateProduct(p))
+		assertConflict(t, repo.DeleteProduct(p))
+
+		require.NoError(t, repo.DeleteProduct(actual))
+	})
+
+	t.Run("一覧をページングして取得できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for _, name := range []string{"製品1", "製品2", "製品3"} {
+			_, err := repo.CreateProduct(domain.NewProductModel(name, 100, releaseDate))
+			require.NoError(t, err)
+		}
+
+		page1, nextToken, err := repo.GetProducts(domain.NewPage(2, ""))
+		require.NoError(t, err)
+		assert.Len(t, page1, 2)
+		assert.NotEmpty(t, nextToken)
+
+		page2, nextToken, err := repo.GetProducts(domain.NewPage(2, nextToken))
+		require.NoError(t, err)
+		assert.Len(t, page2, 1)
+		assert.Empty(t, nextToken)
+	})
+
+	t.Run("削除すると取得できなくなる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		p, err := repo.CreateProduct(domain.NewProductModel("製品1", 100, releaseDate))
+		require.NoError(t, err)
+
+		require.NoError(t, repo.DeleteProduct(p))
+
+		_, err = repo.GetProductByID(p.ID)
+		assertNotFound(t, err)
+	})
+
+	t.Run("SaveProductsはIDの無い製品を作成し、ある製品を更新する", func(t *testing.T) {
+		repo := newRepo(t)
+
+		p, err := repo.CreateProduct(domain.NewProductModel("製品1", 100, releaseDate))
+		require.NoError(t, err)
+
+		update := domain.NewProductModel("製品1(更新)", 150, releaseDate)
+		update.ID = p.ID
+		duplicate := domain.NewProductModel("製品1(重複)", 300, releaseDate)
+		duplicate.ID = p.ID
+		missing := domain.NewProductModel("存在しない製品", 100, releaseDate)
+		missing.ID = 999
+
+		errs, err := repo.SaveProducts([]*domain.ProductModel{
+			update,
+			domain.NewProductModel("製品2", 200, releaseDate),
+			duplicate,
+			missing,
+		})
+		require.NoError(t, err)
+		require.Len(t, errs, 4)
+		assert.NoError(t, errs[0])
+		assert.NoError(t, errs[1])
+		assertConflict(t, errs[2])
+		assertNotFound(t, errs[3])
+
+		actual, err := repo.GetProductByID(p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "製品1(更新)", actual.Name)
+		assert.Equal(t, 150, actual.Price)
+
+		created, err := repo.GetProductByID(2)
+		require.NoError(t, err)
+		assert.Equal(t, "製品2", created.Name)
+	})
+}
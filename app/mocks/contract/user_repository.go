@@ -0,0 +1,282 @@
+// Package contract ドメインのリポジトリの実装が満たすべき振る舞いをまとめたテストスイート。
+// インメモリ実装とDynamoDB実装の両方に対して同じテストを実行する
+package contract
+
+import (
+	"clean-serverless-book-sample/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UserRepositoryFactory テストごとに空のUserRepositoryを生成する関数
+type UserRepositoryFactory func(t *testing.T) domain.UserRepository
+
+// RunUserRepository UserRepositoryの契約テストを実行する
+func RunUserRepository(t *testing.T, newRepo UserRepositoryFactory) {
+	t.Run("CreateUserは連番でIDを採番する", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user1, err := repo.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+		require.NoError(t, err)
+		user2, err := repo.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1), user1.ID)
+		assert.Equal(t, uint64(2), user2.ID)
+
+		actual, err := repo.GetUserByID(user2.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user2, actual)
+	})
+
+	t.Run("存在しないユーザーはErrNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.GetUserByID(999)
+		assertNotFound(t, err)
+
+		_, err = repo.GetUserByEmail("none@example.com")
+		assertNotFound(t, err)
+
+		err = repo.UpdateUser(&domain.UserModel{ID: 999, Name: "Name", Email: "none@example.com"})
+		assertNotFound(t, err)
+	})
+
+	t.Run("メールアドレスから取得できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user, err := repo.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+		require.NoError(t, err)
+
+		actual, err := repo.GetUserByEmail("test1@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, actual.ID)
+	})
+
+	t.Run("メールアドレスは重複して登録できない", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user1, err := repo.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+		require.NoError(t, err)
+
+		_, err = repo.CreateUser(domain.NewUserModel("Name_2", "test1@example.com"))
+		assert.Equal(t, domain.ErrDuplicateEmail, errors.Cause(err))
+
+		user2, err := repo.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+		require.NoError(t, err)
+
+		user2.Email = user1.Email
+		err = repo.UpdateUser(user2)
+		assert.Equal(t, domain.ErrDuplicateEmail, errors.Cause(err))
+
+		actual, err := repo.GetUserByEmail(user1.Email)
+		require.NoError(t, err)
+		assert.Equal(t, user1.ID, actual.ID)
+	})
+
+	t.Run("メールアドレスを変更すると古いメールアドレスは解放される", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user, err := repo.CreateUser(domain.NewUserModel("Name_1", "old@example.com"))
+		require.NoError(t, err)
+
+		user.Name = "Name_1_updated"
+		user.Email = "new@example.com"
+		require.NoError(t, repo.UpdateUser(user))
+
+		actual, err := repo.GetUserByID(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Name_1_updated", actual.Name)
+		assert.Equal(t, "new@example.com", actual.Email)
+
+		_, err = repo.GetUserByEmail("old@example.com")
+		assertNotFound(t, err)
+
+		_, err = repo.CreateUser(domain.NewUserModel("Name_2", "old@example.com"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("削除するとメールアドレスも解放される", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user, err := repo.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+		require.NoError(t, err)
+
+		require.NoError(t, repo.DeleteUser(user))
+
+		_, err = repo.GetUserByID(user.ID)
+		assertNotFound(t, err)
+		_, err = repo.GetUserByEmail(user.Email)
+		assertNotFound(t, err)
+
+		_, err = repo.CreateUser(domain.NewUserModel("Name_2", "test1@example.com"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("古いバージョンでは更新・削除できない", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user, err := repo.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, user.Version)
+
+		user.Name = "Name_1_updated"
+		require.NoError(t, repo.UpdateUser(user))
+
+		actual, err := repo.GetUserByID(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, actual.Version)
+
+		user.Name = "Name_1_stale"
+		assertConflict(t, repo.UpdateUser(user))
+		assertConflict(t, repo.DeleteUser(user))
+
+		require.NoError(t, repo.DeleteUser(actual))
+	})
+
+	t.Run("パスワードのハッシュ値は保存され、ユーザーの更新では変わらない", func(t *testing.T) {
+		repo := newRepo(t)
+
+		newUser := domain.NewUserModel("Name_1", "test1@example.com")
+		newUser.PasswordHash = "hash_1"
+		user, err := repo.CreateUser(newUser)
+		require.NoError(t, err)
+
+		user.Name = "Name_1_updated"
+		user.PasswordHash = ""
+		require.NoError(t, repo.UpdateUser(user))
+
+		actual, err := repo.GetUserByEmail("test1@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "hash_1", actual.PasswordHash)
+
+		assertConflict(t, repo.UpdateUserPassword(&domain.UserModel{ID: user.ID, PasswordHash: "hash_2", Version: user.Version}))
+		require.NoError(t, repo.UpdateUserPassword(&domain.UserModel{ID: user.ID, PasswordHash: "hash_2", Version: actual.Version}))
+
+		actual, err = repo.GetUserByID(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "hash_2", actual.PasswordHash)
+		assert.Equal(t, "Name_1_updated", actual.Name)
+
+		assertNotFound(t, repo.UpdateUserPassword(&domain.UserModel{ID: 999, PasswordHash: "hash_3"}))
+	})
+
+	t.Run("論理削除したユーザーは取得できず、復元すると元に戻る", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user, err := repo.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteUser(user))
+
+		_, err = repo.GetUserByID(user.ID)
+		assertNotFound(t, err)
+		users, _, err := repo.GetUsers(nil)
+		require.NoError(t, err)
+		assert.Len(t, users, 0)
+
+		restored, err := repo.RestoreUser(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user.Email, restored.Email)
+
+		actual, err := repo.GetUserByEmail(user.Email)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, actual.ID)
+		assert.Equal(t, restored.Version, actual.Version)
+
+		_, err = repo.RestoreUser(999)
+		assertNotFound(t, err)
+	})
+
+	t.Run("削除中にメールアドレスが使われた場合は復元できない", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user, err := repo.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteUser(user))
+
+		_, err = repo.CreateUser(domain.NewUserModel("Name_2", "test1@example.com"))
+		require.NoError(t, err)
+
+		_, err = repo.RestoreUser(user.ID)
+		assertConflict(t, err)
+	})
+
+	t.Run("保持期間を過ぎた論理削除済みのユーザーだけを物理削除する", func(t *testing.T) {
+		repo := newRepo(t)
+
+		user, err := repo.CreateUser(domain.NewUserModel("Name_1", "test1@example.com"))
+		require.NoError(t, err)
+		_, err = repo.CreateUser(domain.NewUserModel("Name_2", "test2@example.com"))
+		require.NoError(t, err)
+		require.NoError(t, repo.DeleteUser(user))
+
+		purged, err := repo.PurgeDeletedUsers(context.Background(), time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		purged, err = repo.PurgeDeletedUsers(context.Background(), time.Now().Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		_, err = repo.RestoreUser(user.ID)
+		assertNotFound(t, err)
+
+		users, _, err := repo.GetUsers(nil)
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+	})
+
+	t.Run("一覧をページングして取得できる", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for _, email := range []string{"test1@example.com", "test2@example.com", "test3@example.com"} {
+			_, err := repo.CreateUser(domain.NewUserModel("Name", email))
+			require.NoError(t, err)
+		}
+
+		page1, nextToken, err := repo.GetUsers(domain.NewPage(2, ""))
+		require.NoError(t, err)
+		assert.Len(t, page1, 2)
+		assert.NotEmpty(t, nextToken)
+
+		page2, nextToken, err := repo.GetUsers(domain.NewPage(2, nextToken))
+		require.NoError(t, err)
+		assert.Len(t, page2, 1)
+		assert.Empty(t, nextToken)
+
+		ids := map[uint64]bool{}
+		for _, u := range append(page1, page2...) {
+			ids[u.ID] = true
+		}
+		assert.Len(t, ids, 3)
+
+		_, _, err = repo.GetUsers(domain.NewPage(2, "invalid"))
+		assertInvalidPageToken(t, err)
+	})
+}
+
+func assertNotFound(t *testing.T, err error) {
+	t.Helper()
+	if assert.Error(t, err) {
+		assert.Equal(t, domain.ErrNotFound.Error(), err.Error())
+	}
+}
+
+func assertConflict(t *testing.T, err error) {
+	t.Helper()
+	if assert.Error(t, err) {
+		assert.Equal(t, domain.ErrConflict.Error(), errors.Cause(err).Error())
+	}
+}
+
+func assertInvalidPageToken(t *testing.T, err error) {
+	t.Helper()
+	if assert.Error(t, err) {
+		assert.Equal(t, domain.ErrInvalidPageToken.Error(), err.Error())
+	}
+}
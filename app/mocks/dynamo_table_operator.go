@@ -14,6 +14,7 @@ type DynamoTableOperator struct {
 	Operator          *adapter.ResourceTableOperator
 	UserOperator      domain.UserRepository
 	MicropostOperator domain.MicropostRepository
+	ProductOperator   domain.ProductRepository
 }
 
 func SetupDB(t *testing.T) *DynamoTableOperator {
@@ -26,6 +27,7 @@ func SetupDB(t *testing.T) *DynamoTableOperator {
 	operator.Operator = f.BuildResourceTableOperator()
 	operator.UserOperator = f.BuildUserOperator()
 	operator.MicropostOperator = f.BuildMicropostOperator()
+	operator.ProductOperator = f.BuildProductOperator()
 
 	operator.Operator.CreateTableForTest()
 
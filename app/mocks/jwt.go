@@ -0,0 +1,102 @@
+package mocks
+
+import (
+	"clean-serverless-book-sample/adapter"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JWTTestKeys テスト用にローカルで生成したJWTの署名鍵
+type JWTTestKeys struct {
+	HMACSecret    []byte
+	RSAPrivateKey *rsa.PrivateKey
+}
+
+var (
+	jwtTestKeys     *JWTTestKeys
+	jwtTestKeysOnce sync.Once
+)
+
+// GetJWTTestKeys テスト用の署名鍵を取得する。RSA鍵の生成は重いため、プロセス内で一度だけ生成する
+func GetJWTTestKeys() *JWTTestKeys {
+	jwtTestKeysOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		jwtTestKeys = &JWTTestKeys{
+			HMACSecret:    []byte("test-hmac-secret"),
+			RSAPrivateKey: key,
+		}
+	})
+	return jwtTestKeys
+}
+
+// Verifier テスト用の鍵で署名したトークンを検証するインスタンスを生成
+func (k *JWTTestKeys) Verifier() *adapter.JWTVerifier {
+	return adapter.NewJWTVerifier(k.HMACSecret, &k.RSAPrivateKey.PublicKey)
+}
+
+// Issuer テスト用の共通鍵で1時間有効なトークンを発行するインスタンスを生成
+func (k *JWTTestKeys) Issuer() *adapter.JWTSigner {
+	return adapter.NewJWTSigner(k.HMACSecret, time.Hour)
+}
+
+// PublicKeyPEM 公開鍵をPKIX形式のPEMで返す
+func (k *JWTTestKeys) PublicKeyPEM() string {
+	b, err := x509.MarshalPKIXPublicKey(&k.RSAPrivateKey.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b}))
+}
+
+// SignHS256 クレームをHS256で署名したトークンを生成する
+func (k *JWTTestKeys) SignHS256(claims map[string]interface{}) string {
+	input := jwtSigningInput("HS256", claims)
+	mac := hmac.New(sha256.New, k.HMACSecret)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignRS256 クレームをRS256で署名したトークンを生成する
+func (k *JWTTestKeys) SignRS256(claims map[string]interface{}) string {
+	input := jwtSigningInput("RS256", claims)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, k.RSAPrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// BearerToken 指定したユーザーとして1時間有効なAuthorizationヘッダーの値を生成する
+func (k *JWTTestKeys) BearerToken(userID uint64) string {
+	return "Bearer " + k.SignHS256(map[string]interface{}{
+		"sub": fmt.Sprintf("%d", userID),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+// jwtSigningInput ヘッダーとペイロードをBase64URLエンコードして連結する
+func jwtSigningInput(alg string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	if err != nil {
+		panic(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// APIKeyRepository domain.APIKeyRepository のインメモリ実装
+type APIKeyRepository struct {
+	mu      sync.RWMutex
+	lastID  uint64
+	apiKeys map[uint64]domain.APIKeyModel
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{
+		apiKeys: map[uint64]domain.APIKeyModel{},
+	}
+}
+
+// CreateAPIKey APIキーを新規作成する。IDは1から連番で採番する
+func (r *APIKeyRepository) CreateAPIKey(newAPIKey *domain.APIKeyModel) (*domain.APIKeyModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	k := *newAPIKey
+	k.ID = r.lastID
+	k.Version = 1
+	k.Scopes = append([]string{}, newAPIKey.Scopes...)
+
+	r.apiKeys[k.ID] = k
+
+	return &k, nil
+}
+
+// GetAPIKeyByID IDからAPIキーを取得する
+func (r *APIKeyRepository) GetAPIKeyByID(id uint64) (*domain.APIKeyModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.apiKeys[id]
+	if !ok {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	return &k, nil
+}
+
+// GetAPIKeys APIキーの一覧をID順に取得する
+func (r *APIKeyRepository) GetAPIKeys(page *domain.Page) ([]*domain.APIKeyModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	apiKeys := make([]*domain.APIKeyModel, 0, len(r.apiKeys))
+	for _, k := range r.apiKeys {
+		k := k
+		apiKeys = append(apiKeys, &k)
+	}
+	sort.Slice(apiKeys, func(i, j int) bool { return apiKeys[i].ID < apiKeys[j].ID })
+
+	return paginate(apiKeys, page)
+}
+
+// DeleteAPIKey APIキーを削除する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (r *APIKeyRepository) DeleteAPIKey(apiKey *domain.APIKeyModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.apiKeys[apiKey.ID]
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if !domain.MatchVersion(apiKey.Version, k.Version) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+
+	delete(r.apiKeys, apiKey.ID)
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"sort"
+	"sync"
+)
+
+// CascadeDeleteJobRepository domain.CascadeDeleteJobRepository のインメモリ実装
+type CascadeDeleteJobRepository struct {
+	mu   sync.Mutex
+	jobs map[uint64]domain.CascadeDeleteJobModel
+}
+
+func NewCascadeDeleteJobRepository() *CascadeDeleteJobRepository {
+	return &CascadeDeleteJobRepository{
+		jobs: map[uint64]domain.CascadeDeleteJobModel{},
+	}
+}
+
+// PutCascadeDeleteJob ジョブを登録する。同じユーザーのジョブは上書きする
+func (r *CascadeDeleteJobRepository) PutCascadeDeleteJob(job *domain.CascadeDeleteJobModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.UserID] = *job
+	return nil
+}
+
+// GetCascadeDeleteJobs 登録が古い順にジョブを最大limit件取得する
+func (r *CascadeDeleteJobRepository) GetCascadeDeleteJobs(limit int) ([]*domain.CascadeDeleteJobModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := make([]*domain.CascadeDeleteJobModel, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		job := job
+		jobs = append(jobs, &job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].UserID < jobs[j].UserID
+		}
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+
+	return jobs, nil
+}
+
+// DeleteCascadeDeleteJob ジョブを削除する
+func (r *CascadeDeleteJobRepository) DeleteCascadeDeleteJob(userID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.jobs, userID)
+	return nil
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// followKey フォロー関係を一意に表すキー
+type followKey struct {
+	userID       uint64
+	targetUserID uint64
+}
+
+// FollowRepository domain.FollowRepository のインメモリ実装。
+// フォロー数とフォロワー数はusersのユーザーを直接更新する
+type FollowRepository struct {
+	users   *UserRepository
+	mu      sync.RWMutex
+	follows map[followKey]time.Time
+}
+
+func NewFollowRepository(users *UserRepository) *FollowRepository {
+	return &FollowRepository{
+		users:   users,
+		follows: map[followKey]time.Time{},
+	}
+}
+
+// addCount ユーザーのフォロー数とフォロワー数を増減する。DynamoDBの実装と同様にバージョンは上げない。users.muをロックした状態で呼び出す
+func (r *FollowRepository) addCount(userID uint64, following, followers int) {
+	u, ok := r.users.users[userID]
+	if !ok {
+		return
+	}
+	u.FollowingCount += following
+	u.FollowerCount += followers
+	r.users.users[userID] = u
+}
+
+// Follow フォローを登録する。すでにフォローしている場合は何もしない
+func (r *FollowRepository) Follow(userID, targetUserID uint64) error {
+	r.users.mu.Lock()
+	defer r.users.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := followKey{userID: userID, targetUserID: targetUserID}
+	if _, ok := r.follows[key]; ok {
+		return nil
+	}
+	if _, ok := r.users.get(userID); !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if _, ok := r.users.get(targetUserID); !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+
+	r.follows[key] = time.Now()
+	r.addCount(userID, 1, 0)
+	r.addCount(targetUserID, 0, 1)
+
+	return nil
+}
+
+// Unfollow フォローを解除する。フォローしていない場合はdomain.ErrNotFoundを返す
+func (r *FollowRepository) Unfollow(userID, targetUserID uint64) error {
+	r.users.mu.Lock()
+	defer r.users.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := followKey{userID: userID, targetUserID: targetUserID}
+	if _, ok := r.follows[key]; !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+
+	delete(r.follows, key)
+	r.addCount(userID, -1, 0)
+	r.addCount(targetUserID, 0, -1)
+
+	return nil
+}
+
+// GetFollowing フォローしているユーザーをユーザーID順に取得する
+func (r *FollowRepository) GetFollowing(userID uint64, page *domain.Page) ([]*domain.FollowModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	follows := []*domain.FollowModel{}
+	for key, createdAt := range r.follows {
+		if key.userID != userID {
+			continue
+		}
+		follows = append(follows, domain.NewFollowModel(key.userID, key.targetUserID, createdAt))
+	}
+	sort.Slice(follows, func(i, j int) bool { return follows[i].TargetUserID < follows[j].TargetUserID })
+
+	return paginate(follows, page)
+}
+
+// GetFollowers フォロワーをフォローされた時刻が新しい順に取得する
+func (r *FollowRepository) GetFollowers(userID uint64, page *domain.Page) ([]*domain.FollowModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	follows := []*domain.FollowModel{}
+	for key, createdAt := range r.follows {
+		if key.targetUserID != userID {
+			continue
+		}
+		follows = append(follows, domain.NewFollowModel(key.userID, key.targetUserID, createdAt))
+	}
+	sort.Slice(follows, func(i, j int) bool {
+		if follows[i].CreatedAt.Equal(follows[j].CreatedAt) {
+			return follows[i].UserID > follows[j].UserID
+		}
+		return follows[i].CreatedAt.After(follows[j].CreatedAt)
+	})
+
+	return paginate(follows, page)
+}
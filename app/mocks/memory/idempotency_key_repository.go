@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// IdempotencyKeyRepository domain.IdempotencyKeyRepository のインメモリ実装。キーは呼び出し元ごとに保持する
+type IdempotencyKeyRepository struct {
+	mu   sync.Mutex
+	keys map[idempotencyKeyID]domain.IdempotencyKeyModel
+}
+
+type idempotencyKeyID struct {
+	owner string
+	key   string
+}
+
+func NewIdempotencyKeyRepository() *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{
+		keys: map[idempotencyKeyID]domain.IdempotencyKeyModel{},
+	}
+}
+
+// ReserveIdempotencyKey キーを処理中として登録する
+func (r *IdempotencyKeyRepository) ReserveIdempotencyKey(key *domain.IdempotencyKeyModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKeyID{owner: key.Owner, key: key.Key}
+	now := time.Now()
+	if k, ok := r.keys[id]; ok && !k.IsExpired(now) && !k.CanTakeOver(key.RequestHash, now) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+	r.keys[id] = *key
+
+	return nil
+}
+
+// GetIdempotencyKey キーを取得する
+func (r *IdempotencyKeyRepository) GetIdempotencyKey(owner, key string) (*domain.IdempotencyKeyModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[idempotencyKeyID{owner: owner, key: key}]
+	if !ok || k.IsExpired(time.Now()) {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	return &k, nil
+}
+
+// CompleteIdempotencyKey レスポンスを保存して処理済みにする
+func (r *IdempotencyKeyRepository) CompleteIdempotencyKey(key *domain.IdempotencyKeyModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKeyID{owner: key.Owner, key: key.Key}
+	if k, ok := r.keys[id]; !ok || !k.LockedUntil.Equal(key.LockedUntil) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+	r.keys[id] = *key
+
+	return nil
+}
+
+// DeleteIdempotencyKey キーを削除する
+func (r *IdempotencyKeyRepository) DeleteIdempotencyKey(key *domain.IdempotencyKeyModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := idempotencyKeyID{owner: key.Owner, key: key.Key}
+	if k, ok := r.keys[id]; !ok || !k.LockedUntil.Equal(key.LockedUntil) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+	delete(r.keys, id)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ImportJobRepository domain.ImportJobRepository のインメモリ実装
+type ImportJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]domain.ImportJobModel
+}
+
+func NewImportJobRepository() *ImportJobRepository {
+	return &ImportJobRepository{
+		jobs: map[string]domain.ImportJobModel{},
+	}
+}
+
+// StartImportJob ジョブを開始する。占有期限が過ぎた実行中のジョブは進捗を引き継ぐ
+func (r *ImportJobRepository) StartImportJob(job *domain.ImportJobModel) (*domain.ImportJobModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	started := *job
+	if j, ok := r.jobs[job.ID]; ok {
+		if !j.CanTakeOver(time.Now()) {
+			return nil, errors.WithStack(domain.ErrConflict)
+		}
+		started = j
+		started.LockedUntil = job.LockedUntil
+		started.UpdatedAt = job.UpdatedAt
+	}
+	r.jobs[job.ID] = started
+
+	return &started, nil
+}
+
+// UpdateImportJob 進捗や状態を保存する。他の実行に引き継がれていた場合はdomain.ErrConflictを返す
+func (r *ImportJobRepository) UpdateImportJob(job *domain.ImportJobModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[job.ID]
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if !j.LockedUntil.Equal(job.LockedUntil) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+	r.jobs[job.ID] = *job
+
+	return nil
+}
+
+// GetImportJob ジョブを取得する
+func (r *ImportJobRepository) GetImportJob(id string) (*domain.ImportJobModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[id]
+	if !ok {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	return &j, nil
+}
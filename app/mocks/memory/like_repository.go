@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// likeKey いいねを一意に表すキー
+type likeKey struct {
+	micropostID uint64
+	userID      uint64
+}
+
+// LikeRepository domain.LikeRepository のインメモリ実装。
+// いいね数はmicropostsのマイクロポストを直接更新し、いいねするユーザーの存在はusersで確認する
+type LikeRepository struct {
+	microposts *MicropostRepository
+	users      domain.UserRepository
+	mu         sync.Mutex
+	likes      map[likeKey]time.Time
+}
+
+func NewLikeRepository(microposts *MicropostRepository, users domain.UserRepository) *LikeRepository {
+	return &LikeRepository{
+		microposts: microposts,
+		users:      users,
+		likes:      map[likeKey]time.Time{},
+	}
+}
+
+// addCount マイクロポストのいいね数を増減する。DynamoDBの実装と同様にバージョンは上げない。microposts.muをロックした状態で呼び出す
+func (r *LikeRepository) addCount(micropostID uint64, delta int) {
+	m, ok := r.microposts.microposts[micropostID]
+	if !ok {
+		return
+	}
+	m.LikeCount += delta
+	r.microposts.microposts[micropostID] = m
+}
+
+// Like いいねを登録する。すでにいいねしている場合は何もしない
+func (r *LikeRepository) Like(micropostID, userID uint64) error {
+	_, err := r.users.GetUserByID(userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	r.microposts.mu.Lock()
+	defer r.microposts.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := likeKey{micropostID: micropostID, userID: userID}
+	if _, ok := r.likes[key]; ok {
+		return nil
+	}
+	if _, ok := r.microposts.get(micropostID); !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+
+	r.likes[key] = time.Now()
+	r.addCount(micropostID, 1)
+
+	return nil
+}
+
+// Unlike いいねを取り消す。いいねしていない場合はdomain.ErrNotFoundを返す
+func (r *LikeRepository) Unlike(micropostID, userID uint64) error {
+	r.microposts.mu.Lock()
+	defer r.microposts.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := likeKey{micropostID: micropostID, userID: userID}
+	if _, ok := r.likes[key]; !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+
+	delete(r.likes, key)
+	r.addCount(micropostID, -1)
+
+	return nil
+}
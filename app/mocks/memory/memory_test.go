@@ -0,0 +1,89 @@
+package memory_test
+
+import (
+	"clean-serverless-book-sample/domain"
+	"clean-serverless-book-sample/mocks/contract"
+	"clean-serverless-book-sample/mocks/memory"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRepository_Contract(t *testing.T) {
+	contract.RunUserRepository(t, func(t *testing.T) domain.UserRepository {
+		return memory.NewUserRepository()
+	})
+}
+
+func TestMicropostRepository_Contract(t *testing.T) {
+	contract.RunMicropostRepository(t, func(t *testing.T) (domain.MicropostRepository, domain.UserRepository) {
+		users := memory.NewUserRepository()
+		return memory.NewMicropostRepository(users), users
+	})
+}
+
+func TestProductRepository_Contract(t *testing.T) {
+	contract.RunProductRepository(t, func(t *testing.T) domain.ProductRepository {
+		return memory.NewProductRepository()
+	})
+}
+
+// TestUserRepository_Concurrent 並行して作成してもIDとメールアドレスが重複しないこと
+func TestUserRepository_Concurrent(t *testing.T) {
+	repo := memory.NewUserRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = repo.CreateUser(domain.NewUserModel("Name", fmt.Sprintf("test%d@example.com", i%10)))
+		}(i)
+	}
+	wg.Wait()
+
+	users, _, err := repo.GetUsers(domain.NewPage(domain.MaxPageLimit, ""))
+	assert.NoError(t, err)
+	assert.Len(t, users, 10)
+}
+
+func TestIdempotencyKeyRepository_Contract(t *testing.T) {
+	contract.RunIdempotencyKeyRepository(t, func(t *testing.T) domain.IdempotencyKeyRepository {
+		return memory.NewIdempotencyKeyRepository()
+	})
+}
+
+func TestCascadeDeleteJobRepository_Contract(t *testing.T) {
+	contract.RunCascadeDeleteJobRepository(t, func(t *testing.T) domain.CascadeDeleteJobRepository {
+		return memory.NewCascadeDeleteJobRepository()
+	})
+}
+
+func TestAPIKeyRepository_Contract(t *testing.T) {
+	contract.RunAPIKeyRepository(t, func(t *testing.T) domain.APIKeyRepository {
+		return memory.NewAPIKeyRepository()
+	})
+}
+
+func TestFollowRepository_Contract(t *testing.T) {
+	contract.RunFollowRepository(t, func(t *testing.T) (domain.FollowRepository, domain.UserRepository) {
+		users := memory.NewUserRepository()
+		return memory.NewFollowRepository(users), users
+	})
+}
+
+func TestLikeRepository_Contract(t *testing.T) {
+	contract.RunLikeRepository(t, func(t *testing.T) (domain.LikeRepository, domain.MicropostRepository, domain.UserRepository) {
+		users := memory.NewUserRepository()
+		microposts := memory.NewMicropostRepository(users)
+		return memory.NewLikeRepository(microposts, users), microposts, users
+	})
+}
+
+func TestImportJobRepository_Contract(t *testing.T) {
+	contract.RunImportJobRepository(t, func(t *testing.T) domain.ImportJobRepository {
+		return memory.NewImportJobRepository()
+	})
+}
@@ -0,0 +1,353 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MicropostRepository domain.MicropostRepository のインメモリ実装。論理削除したマイクロポストはdeletedAtに削除時刻を持つ。
+// ユーザー単位で削除したものはdeletedWithUserにも記録する。投稿するユーザーの存在はusersで確認する。
+// メンションはDynamoDBの索引と同様に、書き込んだ時点で存在したユーザーへのものだけをmentionsに記録する
+type MicropostRepository struct {
+	users           domain.UserRepository
+	mu              sync.RWMutex
+	lastID          uint64
+	microposts      map[uint64]domain.MicropostModel
+	deletedAt       map[uint64]time.Time
+	deletedWithUser map[uint64]bool
+	mentions        map[uint64][]uint64
+}
+
+func NewMicropostRepository(users domain.UserRepository) *MicropostRepository {
+	return &MicropostRepository{
+		users:           users,
+		microposts:      map[uint64]domain.MicropostModel{},
+		deletedAt:       map[uint64]time.Time{},
+		deletedWithUser: map[uint64]bool{},
+		mentions:        map[uint64][]uint64{},
+	}
+}
+
+// resolveMentions 本文でメンションされたユーザーのうち、存在するユーザーのIDを返す。muをロックする前に呼び出す
+func (r *MicropostRepository) resolveMentions(content string) []uint64 {
+	var userIDs []uint64
+	for _, userID := range domain.ExtractMentions(content) {
+		if _, err := r.users.GetUserByID(userID); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs
+}
+
+// get 論理削除されていないマイクロポストを取得する
+func (r *MicropostRepository) get(id uint64) (domain.MicropostModel, bool) {
+	m, ok := r.microposts[id]
+	if !ok {
+		return domain.MicropostModel{}, false
+	}
+	if _, deleted := r.deletedAt[id]; deleted {
+		return domain.MicropostModel{}, false
+	}
+	return m, true
+}
+
+// addReplyCount 返信先の返信数を増減する。DynamoDBの実装と同様にバージョンは上げない。muをロックした状態で呼び出す
+func (r *MicropostRepository) addReplyCount(micropostID uint64, delta int) {
+	m, ok := r.microposts[micropostID]
+	if !ok {
+		return
+	}
+	m.ReplyCount += delta
+	r.microposts[micropostID] = m
+}
+
+// setRepliesOrphaned 指定したマイクロポストへの返信に、返信先が削除されたかどうかの印を設定する。件数と同じくバージョンは上げない。muをロックした状態で呼び出す
+func (r *MicropostRepository) setRepliesOrphaned(micropostID uint64, orphaned bool) {
+	for id, m := range r.microposts {
+		if m.InReplyToID != micropostID {
+			continue
+		}
+		m.Orphaned = orphaned
+		r.microposts[id] = m
+	}
+}
+
+// CreateMicropost マイクロポストを新規作成する。IDは1から連番で採番する。ユーザーが存在しない場合はdomain.ErrNotFoundを返す。
+// 返信の場合は返信先の返信数を増やし、返信先が存在しない場合はdomain.ErrInReplyToNotFoundを返す
+func (r *MicropostRepository) CreateMicropost(newMicropost *domain.MicropostModel) (*domain.MicropostModel, error) {
+	_, err := r.users.GetUserByID(newMicropost.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	mentions := r.resolveMentions(newMicropost.Content)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if newMicropost.IsReply() {
+		if _, ok := r.get(newMicropost.InReplyToID); !ok {
+			return nil, errors.WithStack(domain.ErrInReplyToNotFound)
+		}
+		r.addReplyCount(newMicropost.InReplyToID, 1)
+	}
+
+	r.lastID++
+	m := *newMicropost
+	m.ID = r.lastID
+	m.Version = 1
+	m.CreatedAt = time.Now()
+
+	r.microposts[m.ID] = m
+	r.mentions[m.ID] = mentions
+
+	return &m, nil
+}
+
+// UpdateMicropost マイクロポストの本文を更新する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (r *MicropostRepository) UpdateMicropost(newMicropost *domain.MicropostModel) error {
+	mentions := r.resolveMentions(newMicropost.Content)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.get(newMicropost.ID)
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if !domain.MatchVersion(newMicropost.Version, m.Version) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+
+	m.Content = newMicropost.Content
+	m.Version++
+	r.microposts[m.ID] = m
+	r.mentions[m.ID] = mentions
+
+	return nil
+}
+
+// AddAttachment 画像を添付する。同じキーの画像が添付済みの場合は何もしない。
+// 添付数が上限に達している場合はdomain.ErrTooManyAttachmentsを返す
+func (r *MicropostRepository) AddAttachment(micropostID uint64, attachment *domain.AttachmentModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.get(micropostID)
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if m.HasAttachment(attachment.Key) {
+		return nil
+	}
+	if len(m.Attachments) >= domain.MaxAttachmentsPerMicropost {
+		return errors.WithStack(domain.ErrTooManyAttachments)
+	}
+
+	// 取得したモデルとスライスを共有しないように複製してから追加する
+	m.Attachments = append(slices.Clone(m.Attachments), *attachment)
+	m.Version++
+	r.microposts[m.ID] = m
+
+	return nil
+}
+
+// GetMicropostByID IDからマイクロポストを取得する
+func (r *MicropostRepository) GetMicropostByID(id uint64) (*domain.MicropostModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.get(id)
+	if !ok {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	return &m, nil
+}
+
+// listByUserID 指定されたユーザーの論理削除されていないマイクロポストを投稿日時が新しい順に並べる
+func (r *MicropostRepository) listByUserID(userID uint64) []*domain.MicropostModel {
+	return r.listMatching(func(m *domain.MicropostModel) bool { return m.UserID == userID })
+}
+
+// GetMicropostsByUserID 指定されたユーザーのマイクロポストを新しい順に取得する
+func (r *MicropostRepository) GetMicropostsByUserID(userID uint64, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return paginate(r.listByUserID(userID), page)
+}
+
+// GetMicropostsByUserIDBefore 指定されたユーザーのマイクロポストのうち、カーソルの位置より古いものを新しい順に最大limit件取得する
+func (r *MicropostRepository) GetMicropostsByUserIDBefore(userID uint64, before *domain.MicropostCursor, limit int) ([]*domain.MicropostModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	microposts := []*domain.MicropostModel{}
+	for _, m := range r.listByUserID(userID) {
+		if len(microposts) >= limit {
+			break
+		}
+		if before != nil && !before.IsBefore(m) {
+			continue
+		}
+		microposts = append(microposts, m)
+	}
+
+	return microposts, nil
+}
+
+// listMatching 条件に合う論理削除されていないマイクロポストを投稿日時が新しい順に並べる
+func (r *MicropostRepository) listMatching(match func(m *domain.MicropostModel) bool) []*domain.MicropostModel {
+	microposts := []*domain.MicropostModel{}
+	for id := range r.microposts {
+		m, ok := r.get(id)
+		if !ok || !match(&m) {
+			continue
+		}
+		microposts = append(microposts, &m)
+	}
+	sort.Slice(microposts, func(i, j int) bool { return microposts[i].IsNewerThan(microposts[j]) })
+	return microposts
+}
+
+// GetMicropostsByHashtag 指定されたハッシュタグを含むマイクロポストを新しい順に取得する。索引は持たず、本文から都度判定する
+func (r *MicropostRepository) GetMicropostsByHashtag(tag string, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return paginate(r.listMatching(func(m *domain.MicropostModel) bool {
+		return slices.Contains(m.Hashtags(), tag)
+	}), page)
+}
+
+// GetMicropostsMentioning 指定されたユーザーをメンションしているマイクロポストを新しい順に取得する
+func (r *MicropostRepository) GetMicropostsMentioning(userID uint64, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return paginate(r.listMatching(func(m *domain.MicropostModel) bool {
+		return slices.Contains(r.mentions[m.ID], userID)
+	}), page)
+}
+
+// GetRepliesByMicropostID 指定されたマイクロポストへの返信を古い順に取得する
+func (r *MicropostRepository) GetRepliesByMicropostID(micropostID uint64, page *domain.Page) ([]*domain.MicropostModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	replies := []*domain.MicropostModel{}
+	for id := range r.microposts {
+		m, ok := r.get(id)
+		if !ok || m.InReplyToID != micropostID {
+			continue
+		}
+		replies = append(replies, &m)
+	}
+	sort.Slice(replies, func(i, j int) bool { return replies[j].IsNewerThan(replies[i]) })
+
+	return paginate(replies, page)
+}
+
+// DeleteMicropost マイクロポストを論理削除する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す。
+// 返信の場合は返信先の返信数を減らし、返信されている場合は返信をOrphanedにする
+func (r *MicropostRepository) DeleteMicropost(micropost *domain.MicropostModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.get(micropost.ID)
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if !domain.MatchVersion(micropost.Version, m.Version) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+	m.Version++
+	r.microposts[m.ID] = m
+	r.deletedAt[m.ID] = time.Now()
+
+	if m.IsReply() {
+		r.addReplyCount(m.InReplyToID, -1)
+	}
+	r.setRepliesOrphaned(m.ID, true)
+
+	return nil
+}
+
+// DeleteMicropostsByUserID 指定されたユーザーのマイクロポストを最大limit件まで論理削除する。削除しきれなかった場合はremainingにtrueを返す
+func (r *MicropostRepository) DeleteMicropostsByUserID(userID uint64, limit int) (int, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	now := time.Now()
+	for id := range r.microposts {
+		m, ok := r.get(id)
+		if !ok || m.UserID != userID {
+			continue
+		}
+		if deleted >= limit {
+			return deleted, true, nil
+		}
+		m.Version++
+		r.microposts[id] = m
+		r.deletedAt[id] = now
+		r.deletedWithUser[id] = true
+		r.setRepliesOrphaned(id, true)
+		deleted++
+	}
+
+	return deleted, false, nil
+}
+
+// RestoreMicropostsByUserID ユーザー単位で論理削除したマイクロポストを最大limit件まで復元する。復元しきれなかった場合はremainingにtrueを返す
+func (r *MicropostRepository) RestoreMicropostsByUserID(userID uint64, limit int) (int, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	restored := 0
+	for id := range r.deletedWithUser {
+		m := r.microposts[id]
+		if m.UserID != userID {
+			continue
+		}
+		if restored >= limit {
+			return restored, true, nil
+		}
+		delete(r.deletedAt, id)
+		delete(r.deletedWithUser, id)
+		m.Version++
+		r.microposts[id] = m
+		r.setRepliesOrphaned(id, false)
+		restored++
+	}
+
+	return restored, false, nil
+}
+
+// PurgeDeletedMicroposts 指定した時刻より前に論理削除したマイクロポストを物理削除する
+func (r *MicropostRepository) PurgeDeletedMicroposts(ctx context.Context, deletedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for id, deletedAt := range r.deletedAt {
+		if err := ctx.Err(); err != nil {
+			return purged, errors.WithStack(err)
+		}
+		if deletedAt.After(deletedBefore) {
+			continue
+		}
+		delete(r.microposts, id)
+		delete(r.deletedAt, id)
+		delete(r.deletedWithUser, id)
+		delete(r.mentions, id)
+		purged++
+	}
+
+	return purged, nil
+}
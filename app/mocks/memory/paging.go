@@ -0,0 +1,41 @@
+// Package memory DynamoDBを使わずにテストするための、ドメインのリポジトリのインメモリ実装
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"encoding/base64"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// paginate 一覧をページング条件で切り出す。トークンには次のページの開始位置を入れている
+func paginate[T any](items []T, page *domain.Page) ([]T, string, error) {
+	if page == nil {
+		page = domain.NewPage(0, "")
+	}
+
+	offset := 0
+	if page.NextToken != "" {
+		b, err := base64.RawURLEncoding.DecodeString(page.NextToken)
+		if err != nil {
+			return nil, "", errors.WithStack(domain.ErrInvalidPageToken)
+		}
+		offset, err = strconv.Atoi(string(b))
+		if err != nil || offset < 0 {
+			return nil, "", errors.WithStack(domain.ErrInvalidPageToken)
+		}
+	}
+
+	if offset >= len(items) {
+		return []T{}, "", nil
+	}
+
+	end := offset + page.Limit
+	if end >= len(items) {
+		return items[offset:], "", nil
+	}
+
+	nextToken := base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	return items[offset:end], nextToken, nil
+}
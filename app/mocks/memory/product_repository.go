@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ProductRepository domain.ProductRepository のインメモリ実装
+type ProductRepository struct {
+	mu       sync.RWMutex
+	lastID   uint64
+	products map[uint64]domain.ProductModel
+}
+
+func NewProductRepository() *ProductRepository {
+	return &ProductRepository{
+		products: map[uint64]domain.ProductModel{},
+	}
+}
+
+// CreateProduct 製品を新規作成する。IDは1から連番で採番する
+func (r *ProductRepository) CreateProduct(newProduct *domain.ProductModel) (*domain.ProductModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	p := *newProduct
+	p.ID = r.lastID
+	p.Version = 1
+
+	r.products[p.ID] = p
+
+	return &p, nil
+}
+
+// UpdateProduct 製品を更新する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (r *ProductRepository) UpdateProduct(newProduct *domain.ProductModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.products[newProduct.ID]
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if !domain.MatchVersion(newProduct.Version, p.Version) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+
+	p.Name = newProduct.Name
+	p.Price = newProduct.Price
+	p.ReleaseDate = newProduct.ReleaseDate
+	p.Version++
+	r.products[p.ID] = p
+
+	return nil
+}
+
+// SaveProducts 製品を1件ずつ新規作成・更新し、書き込めなかった製品のエラーを返す。
+// DynamoDBの実装と同様に、同じIDの製品が複数ある場合は後のものをdomain.ErrConflictにする
+func (r *ProductRepository) SaveProducts(products []*domain.ProductModel) ([]error, error) {
+	errs := make([]error, len(products))
+	seen := map[uint64]bool{}
+	for i, p := range products {
+		if p.ID != 0 && seen[p.ID] {
+			errs[i] = errors.WithStack(domain.ErrConflict)
+			continue
+		}
+		seen[p.ID] = true
+
+		if p.ID == 0 {
+			_, errs[i] = r.CreateProduct(p)
+		} else {
+			errs[i] = r.UpdateProduct(p)
+		}
+	}
+	return errs, nil
+}
+
+// GetProductByID IDから製品を取得する
+func (r *ProductRepository) GetProductByID(id uint64) (*domain.ProductModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.products[id]
+	if !ok {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	return &p, nil
+}
+
+// GetProducts 製品一覧をID順に取得する
+func (r *ProductRepository) GetProducts(page *domain.Page) ([]*domain.ProductModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]*domain.ProductModel, 0, len(r.products))
+	for _, p := range r.products {
+		p := p
+		products = append(products, &p)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+
+	return paginate(products, page)
+}
+
+// DeleteProduct 製品を削除する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (r *ProductRepository) DeleteProduct(product *domain.ProductModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.products[product.ID]
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if !domain.MatchVersion(product.Version, p.Version) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+	delete(r.products, p.ID)
+
+	return nil
+}
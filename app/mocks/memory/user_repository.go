@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"clean-serverless-book-sample/domain"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// UserRepository domain.UserRepository のインメモリ実装。論理削除したユーザーはdeletedAtに削除時刻を持つ
+type UserRepository struct {
+	mu        sync.RWMutex
+	lastID    uint64
+	users     map[uint64]domain.UserModel
+	emails    map[string]uint64
+	deletedAt map[uint64]time.Time
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users:     map[uint64]domain.UserModel{},
+		emails:    map[string]uint64{},
+		deletedAt: map[uint64]time.Time{},
+	}
+}
+
+// get 論理削除されていないユーザーを取得する
+func (r *UserRepository) get(id uint64) (domain.UserModel, bool) {
+	u, ok := r.users[id]
+	if !ok {
+		return domain.UserModel{}, false
+	}
+	if _, deleted := r.deletedAt[id]; deleted {
+		return domain.UserModel{}, false
+	}
+	return u, true
+}
+
+// GetUsers ユーザー一覧をID順に取得する
+func (r *UserRepository) GetUsers(page *domain.Page) ([]*domain.UserModel, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*domain.UserModel, 0, len(r.users))
+	for id := range r.users {
+		u, ok := r.get(id)
+		if !ok {
+			continue
+		}
+		users = append(users, &u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	return paginate(users, page)
+}
+
+// GetUserByID IDからユーザーを取得する
+func (r *UserRepository) GetUserByID(id uint64) (*domain.UserModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.get(id)
+	if !ok {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	return &u, nil
+}
+
+// GetUserByEmail メールアドレスからユーザーを取得する
+func (r *UserRepository) GetUserByEmail(email string) (*domain.UserModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.emails[email]
+	if !ok {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	u := r.users[id]
+	return &u, nil
+}
+
+// CreateUser ユーザーを新規作成する。IDは1から連番で採番する
+func (r *UserRepository) CreateUser(newUser *domain.UserModel) (*domain.UserModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.emails[newUser.Email]; ok {
+		return nil, errors.WithStack(domain.ErrDuplicateEmail)
+	}
+
+	r.lastID++
+	u := *newUser
+	u.ID = r.lastID
+	u.Version = 1
+
+	r.users[u.ID] = u
+	r.emails[u.Email] = u.ID
+
+	return &u, nil
+}
+
+// UpdateUser ユーザーを更新する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (r *UserRepository) UpdateUser(newUser *domain.UserModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.get(newUser.ID)
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if !domain.MatchVersion(newUser.Version, u.Version) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+
+	if u.Email != newUser.Email {
+		if holder, ok := r.emails[newUser.Email]; ok && holder != newUser.ID {
+			return errors.WithStack(domain.ErrDuplicateEmail)
+		}
+		delete(r.emails, u.Email)
+		r.emails[newUser.Email] = newUser.ID
+	}
+
+	u.Name = newUser.Name
+	u.Email = newUser.Email
+	u.Version++
+	r.users[u.ID] = u
+
+	return nil
+}
+
+// UpdateUserPassword パスワードのハッシュ値を更新する。バージョンが指定されている場合は、一致しなければdomain.ErrConflictを返す
+func (r *UserRepository) UpdateUserPassword(user *domain.UserModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.get(user.ID)
+	if !ok {
+		return errors.WithStack(domain.ErrNotFound)
+	}
+	if !domain.MatchVersion(user.Version, u.Version) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+
+	u.PasswordHash = user.PasswordHash
+	u.Version++
+	r.users[u.ID] = u
+
+	return nil
+}
+
+// DeleteUser ユーザーを論理削除し、メールアドレスを解放する。DynamoDBの実装と同様に、存在しない場合もエラーにしない
+func (r *UserRepository) DeleteUser(targetUser *domain.UserModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.get(targetUser.ID)
+	if !ok {
+		return nil
+	}
+	if !domain.MatchVersion(targetUser.Version, u.Version) {
+		return errors.WithStack(domain.ErrConflict)
+	}
+
+	delete(r.emails, u.Email)
+	u.Version++
+	r.users[u.ID] = u
+	r.deletedAt[u.ID] = time.Now()
+
+	return nil
+}
+
+// RestoreUser 論理削除したユーザーを復元する
+func (r *UserRepository) RestoreUser(id uint64) (*domain.UserModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, errors.WithStack(domain.ErrNotFound)
+	}
+	if _, deleted := r.deletedAt[id]; !deleted {
+		return &u, nil
+	}
+	if _, ok := r.emails[u.Email]; ok {
+		return nil, errors.WithStack(domain.ErrConflict)
+	}
+
+	delete(r.deletedAt, id)
+	r.emails[u.Email] = id
+	u.Version++
+	r.users[id] = u
+
+	return &u, nil
+}
+
+// PurgeDeletedUsers 指定した時刻より前に論理削除したユーザーを物理削除する
+func (r *UserRepository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for id, deletedAt := range r.deletedAt {
+		if err := ctx.Err(); err != nil {
+			return purged, errors.WithStack(err)
+		}
+		if deletedAt.After(deletedBefore) {
+			continue
+		}
+		delete(r.users, id)
+		delete(r.deletedAt, id)
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,139 @@
+package mocks
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// FakeS3Object フェイクのS3に保存したオブジェクト
+type FakeS3Object struct {
+	Body         []byte
+	ContentType  string
+	LastModified time.Time
+}
+
+// FakeS3Client adapter.S3Client のフェイク。オブジェクトはメモリ上に保持する。
+// 署名付きURLの発行は通信しないため、ダミーの認証情報を設定した本物のクライアントで行う
+type FakeS3Client struct {
+	*s3.S3
+	mu      sync.RWMutex
+	objects map[string]*FakeS3Object
+}
+
+// NewFakeS3Client FakeS3Clientのインスタンスを生成する
+func NewFakeS3Client() *FakeS3Client {
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("ap-northeast-1"),
+		Credentials: credentials.NewStaticCredentials("dummy", "dummy", ""),
+	}))
+	return &FakeS3Client{
+		S3:      s3.New(sess),
+		objects: map[string]*FakeS3Object{},
+	}
+}
+
+func fakeS3Path(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// PutTestObject オブジェクトを保存する。クライアントが署名付きURLへアップロードしたことにするために使う
+func (c *FakeS3Client) PutTestObject(bucket, key, contentType string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.objects[fakeS3Path(bucket, key)] = &FakeS3Object{
+		Body:         body,
+		ContentType:  contentType,
+		LastModified: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// HeadObject オブジェクトのメタデータを返す。存在しない場合は本物と同じくNotFoundのエラーを返す
+func (c *FakeS3Client) HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	obj, ok := c.objects[fakeS3Path(aws.StringValue(input.Bucket), aws.StringValue(input.Key))]
+	if !ok {
+		return nil, awserr.New("NotFound", fmt.Sprintf("%s not found", aws.StringValue(input.Key)), nil)
+	}
+	return &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(obj.Body))),
+		ContentType:   aws.String(obj.ContentType),
+		LastModified:  aws.Time(obj.LastModified),
+	}, nil
+}
+
+// GetObject オブジェクトを返す。存在しない場合は本物と同じくNoSuchKeyのエラーを返す
+func (c *FakeS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	obj, ok := c.objects[fakeS3Path(aws.StringValue(input.Bucket), aws.StringValue(input.Key))]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, fmt.Sprintf("%s not found", aws.StringValue(input.Key)), nil)
+	}
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(obj.Body)),
+		ContentLength: aws.Int64(int64(len(obj.Body))),
+		ContentType:   aws.String(obj.ContentType),
+		LastModified:  aws.Time(obj.LastModified),
+	}, nil
+}
+
+// PutObject オブジェクトを保存する。ETagは本物と同じく、引用符で囲んだ内容のMD5を返す
+func (c *FakeS3Client) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.PutTestObject(aws.StringValue(input.Bucket), aws.StringValue(input.Key), aws.StringValue(input.ContentType), body)
+	sum := md5.Sum(body)
+	return &s3.PutObjectOutput{ETag: aws.String(`"` + hex.EncodeToString(sum[:]) + `"`)}, nil
+}
+
+// GetTestObject 保存したオブジェクトを取得する。存在しない場合はnilを返す
+func (c *FakeS3Client) GetTestObject(bucket, key string) *FakeS3Object {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.objects[fakeS3Path(bucket, key)]
+}
+
+// ReadTestImportReport 一括取り込みの結果の報告を読み込み、行ごとのエラーを返す
+func (c *FakeS3Client) ReadTestImportReport(t *testing.T, bucket, key string) map[int]map[string]string {
+	t.Helper()
+	obj := c.GetTestObject(bucket, key)
+	if obj == nil {
+		t.Fatalf("report not found: %s", key)
+	}
+	if obj.ContentType != "application/x-ndjson" {
+		t.Fatalf("unexpected content type: %s", obj.ContentType)
+	}
+
+	report := map[int]map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(obj.Body))
+	for scanner.Scan() {
+		var line struct {
+			Row    int               `json:"row"`
+			Errors map[string]string `json:"errors"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf(err.Error())
+		}
+		report[line.Row] = line.Errors
+	}
+	return report
+}
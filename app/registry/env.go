@@ -4,15 +4,28 @@ import (
 	"clean-serverless-book-sample/adapter"
 	"clean-serverless-book-sample/logger"
 	"os"
+	"strconv"
+	"sync"
+	"time"
 )
 
 // Envs 環境変数を扱う。暗号化やキャッシュなどもできるようになっている
 type Envs struct {
 	KMSClient *adapter.AWSKmsClient
 	Cache     map[string]string
+	mu        sync.RWMutex
 }
 
-var envs *Envs
+// defaultSoftDeleteRetentionDays 論理削除したリソースを保持する日数のデフォルト値
+const defaultSoftDeleteRetentionDays = 30
+
+// defaultJWTTokenTTLMinutes ログインで発行するトークンの有効期間(分)のデフォルト値
+const defaultJWTTokenTTLMinutes = 60
+
+var (
+	envs     *Envs
+	envsOnce sync.Once
+)
 
 // NewEnvs Envs インスタンスを生成
 func NewEnvs() *Envs {
@@ -24,9 +37,9 @@ func NewEnvs() *Envs {
 
 // Env シングルトンを取得する
 func Env() *Envs {
-	if envs == nil {
+	envsOnce.Do(func() {
 		envs = NewEnvs()
-	}
+	})
 	return envs
 }
 
@@ -35,7 +48,9 @@ func (c *Envs) decrypt(key string) string {
 		return c.env(key)
 	}
 
+	c.mu.RLock()
 	v := c.Cache[key]
+	c.mu.RUnlock()
 	if v != "" {
 		return v
 	}
@@ -51,9 +66,11 @@ func (c *Envs) decrypt(key string) string {
 		return ""
 	}
 
+	c.mu.Lock()
 	c.Cache[key] = v
+	c.mu.Unlock()
 
-	return c.Cache[key]
+	return v
 }
 
 func (c *Envs) env(key string) string {
@@ -75,3 +92,52 @@ func (c *Envs) DynamoPKName() string {
 func (c *Envs) DynamoSKName() string {
 	return c.env("DYNAMO_SK_NAME")
 }
+
+// S3LocalEndpoint ローカルのS3互換サーバー(MinIOなど)のエンドポイント。設定されている場合は実際のS3の代わりに接続する
+func (c *Envs) S3LocalEndpoint() string {
+	return c.env("S3_LOCAL_ENDPOINT")
+}
+
+// S3BucketName 添付画像などのファイルを保存するバケットの名前
+func (c *Envs) S3BucketName() string {
+	return c.env("S3_BUCKET_NAME")
+}
+
+// JWTHMACSecret HS256の署名を検証する共通鍵。KMSで暗号化した値を復号して使う
+func (c *Envs) JWTHMACSecret() string {
+	return c.decrypt("JWT_HMAC_SECRET")
+}
+
+// JWTRSAPublicKey RS256の署名を検証するPEM形式の公開鍵。KMSで暗号化した値を復号して使う
+func (c *Envs) JWTRSAPublicKey() string {
+	return c.decrypt("JWT_RSA_PUBLIC_KEY")
+}
+
+// JWTTokenTTL ログインで発行するトークンの有効期間。未設定や不正な値の場合は60分
+func (c *Envs) JWTTokenTTL() time.Duration {
+	minutes, err := strconv.Atoi(c.env("JWT_TOKEN_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = defaultJWTTokenTTLMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// PasswordHashCost パスワードをハッシュ化するbcryptのコスト。未設定の場合は0を返し、bcryptの既定値を使う
+func (c *Envs) PasswordHashCost() int {
+	cost, _ := strconv.Atoi(c.env("PASSWORD_HASH_COST"))
+	return cost
+}
+
+// AdminAPIKey 最初のAPIキーを発行するための管理用キー。KMSで暗号化した値を復号して使う
+func (c *Envs) AdminAPIKey() string {
+	return c.decrypt("ADMIN_API_KEY")
+}
+
+// SoftDeleteRetention 論理削除したリソースを物理削除するまでの保持期間。未設定や不正な値の場合は30日
+func (c *Envs) SoftDeleteRetention() time.Duration {
+	days, err := strconv.Atoi(c.env("SOFT_DELETE_RETENTION_DAYS"))
+	if err != nil || days < 0 {
+		days = defaultSoftDeleteRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -4,35 +4,88 @@ import (
 	"clean-serverless-book-sample/adapter"
 	"clean-serverless-book-sample/domain"
 	"clean-serverless-book-sample/interactor"
+	"clean-serverless-book-sample/logger"
 	"clean-serverless-book-sample/usecase"
+	"crypto/rsa"
+
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 )
 
-// Factory 様々なインスタンスを生成する構造体
+// Factory 様々なインスタンスを生成する構造体。
+// DynamoDBのクライアントは一度生成したものを使い回すため、Lambdaのウォームスタート時はセッションが再利用される
 type Factory struct {
 	Envs *Envs
+
+	// UserRepository 設定されている場合はDynamoDBの代わりに利用する。テストでフェイクを差し込むために使う
+	UserRepository domain.UserRepository
+	// MicropostRepository 設定されている場合はDynamoDBの代わりに利用する
+	MicropostRepository domain.MicropostRepository
+	// ProductRepository 設定されている場合はDynamoDBの代わりに利用する
+	ProductRepository domain.ProductRepository
+	// IdempotencyKeyRepository 設定されている場合はDynamoDBの代わりに利用する
+	IdempotencyKeyRepository domain.IdempotencyKeyRepository
+	// CascadeDeleteJobRepository 設定されている場合はDynamoDBの代わりに利用する
+	CascadeDeleteJobRepository domain.CascadeDeleteJobRepository
+	// FollowRepository 設定されている場合はDynamoDBの代わりに利用する
+	FollowRepository domain.FollowRepository
+	// LikeRepository 設定されている場合はDynamoDBの代わりに利用する
+	LikeRepository domain.LikeRepository
+	// PasswordHasher 設定されている場合はbcryptの代わりに利用する
+	PasswordHasher domain.PasswordHasher
+	// TokenIssuer 設定されている場合はJWT_HMAC_SECRETで署名する代わりに利用する
+	TokenIssuer domain.TokenIssuer
+	// APIKeyRepository 設定されている場合はDynamoDBの代わりに利用する
+	APIKeyRepository domain.APIKeyRepository
+	// TokenVerifier 設定されている場合は環境変数の鍵の代わりに利用する。テストでローカルに生成した鍵を使うために使う
+	TokenVerifier domain.TokenVerifier
+	// S3Client 設定されている場合は実際のS3の代わりに利用する。テストでフェイクを差し込むために使う
+	S3Client adapter.S3Client
+	// ImportJobRepository 設定されている場合はDynamoDBの代わりに利用する
+	ImportJobRepository domain.ImportJobRepository
+
+	dynamoClient     *adapter.DynamoClient
+	dynamoClientOnce sync.Once
+	s3Client         adapter.S3Client
+	s3ClientOnce     sync.Once
 }
 
-// GetFactory Factoryのインスタンスを取得する
+var (
+	factory     *Factory
+	factoryOnce sync.Once
+)
+
+// GetFactory プロセス内で共有するFactoryのインスタンスを取得する
 func GetFactory() *Factory {
+	factoryOnce.Do(func() {
+		factory = NewFactory(Env())
+	})
+	return factory
+}
+
+// NewFactory Factoryのインスタンスを生成する
+func NewFactory(envs *Envs) *Factory {
 	return &Factory{
-		Envs: NewEnvs(),
+		Envs: envs,
 	}
 }
 
-// BuildDynamoClient DynamoDBに接続するためのインスタンスを生成
+// BuildDynamoClient DynamoDBに接続するためのインスタンスを取得。初回のみ生成し、以降は同じインスタンスを返す
 func (f *Factory) BuildDynamoClient() *adapter.DynamoClient {
-	config := &aws.Config{
-		Region: aws.String("ap-northeast-1"),
-	}
+	f.dynamoClientOnce.Do(func() {
+		config := &aws.Config{
+			Region: aws.String("ap-northeast-1"),
+		}
 
-	if f.Envs.DynamoLocalEndpoint() != "" {
-		config.Credentials = credentials.NewStaticCredentials("dummy", "dummy", "dummy")
-		config.Endpoint = aws.String(f.Envs.DynamoLocalEndpoint())
-	}
-	return adapter.NewClient(config)
+		if f.Envs.DynamoLocalEndpoint() != "" {
+			config.Credentials = credentials.NewStaticCredentials("dummy", "dummy", "dummy")
+			config.Endpoint = aws.String(f.Envs.DynamoLocalEndpoint())
+		}
+		f.dynamoClient = adapter.NewClient(config)
+	})
+	return f.dynamoClient
 }
 
 // BuildResourceTableOperator DynamoDBのテーブルに接続するためのインスタンスを生成
@@ -70,9 +123,38 @@ func (f *Factory) BuildUserOperator() domain.UserRepository {
 	}
 }
 
+// BuildUserRepository ユーザーのリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildUserRepository() domain.UserRepository {
+	if f.UserRepository != nil {
+		return f.UserRepository
+	}
+	return f.BuildUserOperator()
+}
+
+// BuildUserEmailUniqRepairer メールアドレス重複チェック用レコードの再作成を行うインスタンスを生成
+func (f *Factory) BuildUserEmailUniqRepairer() *adapter.UserEmailUniqRepairer {
+	return &adapter.UserEmailUniqRepairer{
+		Client:                 f.BuildResourceTableOperator(),
+		Mapper:                 f.BuildDynamoModelMapper(),
+		UserEmailUniqGenerator: f.BuildUserEmailUniqGenerator(),
+		Log:                    logger.GetLogger(),
+	}
+}
+
+// BuildMicropostUserIndexBackfiller マイクロポストのユーザー単位のインデックスのキー設定を行うインスタンスを生成
+func (f *Factory) BuildMicropostUserIndexBackfiller() *adapter.MicropostUserIndexBackfiller {
+	return &adapter.MicropostUserIndexBackfiller{
+		Client: f.BuildResourceTableOperator(),
+		Mapper: f.BuildDynamoModelMapper(),
+		PKName: f.Envs.DynamoPKName(),
+		SKName: f.Envs.DynamoSKName(),
+		Log:    logger.GetLogger(),
+	}
+}
+
 // BuildUserEmailUniqChecker ユーザーのメールアドレス重複チェックインスタンスを生成
 func (f *Factory) BuildUserEmailUniqChecker() *domain.UserEmailUniqChecker {
-	return domain.NewUserEmailUniqChecker(f.BuildUserOperator())
+	return domain.NewUserEmailUniqChecker(f.BuildUserRepository())
 }
 
 // BuildMicropostOperator マイクロポスト情報関連の操作を行うインスタンスを生成
@@ -83,75 +165,476 @@ func (f *Factory) BuildMicropostOperator() *adapter.MicropostOperator {
 	}
 }
 
+// BuildMicropostRepository マイクロポストのリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildMicropostRepository() domain.MicropostRepository {
+	if f.MicropostRepository != nil {
+		return f.MicropostRepository
+	}
+	return f.BuildMicropostOperator()
+}
+
 // BuildCreateUser ユーザー作成UseCaseインスタンスを生成
 func (f *Factory) BuildCreateUser() usecase.ICreateUser {
 	return interactor.NewCreateUser(
-		f.BuildUserOperator(),
-		f.BuildUserEmailUniqChecker())
+		f.BuildUserRepository(),
+		f.BuildUserEmailUniqChecker(),
+		f.BuildPasswordHasher())
 }
 
 // BuildUpdateUser ユーザー更新UseCaseインスタンスを生成
 func (f *Factory) BuildUpdateUser() usecase.IUpdateUser {
 	return interactor.NewUpdateUser(
-		f.BuildUserOperator(),
+		f.BuildUserRepository(),
 		f.BuildUserEmailUniqChecker())
 }
 
 // BuildGetUserList ユーザー取得UseCaseインスタンスを生成
 func (f *Factory) BuildGetUserList() usecase.IGetUserList {
-	return interactor.NewGetUserList(f.BuildUserOperator())
+	return interactor.NewGetUserList(f.BuildUserRepository())
 }
 
 // BuildGetUserByID ユーザー取得UseCaseインスタンスを生成
 func (f *Factory) BuildGetUserByID() usecase.IGetUserByID {
-	return interactor.NewGetUserByID(f.BuildUserOperator())
+	return interactor.NewGetUserByID(f.BuildUserRepository())
 }
 
 // BuildUserDeleter ユーザー削除Usecaseインスタンスを生成
 func (f *Factory) BuildUserDeleter() usecase.IDeleteUser {
 	return interactor.NewUserDeleter(
-		f.BuildUserOperator(),
+		f.BuildUserRepository(),
+		f.BuildMicropostRepository(),
+		f.BuildCascadeDeleteJobRepository(),
 		f.BuildGetUserByID())
 }
 
+// BuildContinueCascadeDelete ユーザー削除に伴うマイクロポスト削除の継続UseCaseインスタンスを生成
+func (f *Factory) BuildContinueCascadeDelete() usecase.IContinueCascadeDelete {
+	return interactor.NewContinueCascadeDelete(
+		f.BuildUserRepository(),
+		f.BuildMicropostRepository(),
+		f.BuildCascadeDeleteJobRepository(),
+		logger.GetLogger())
+}
+
+// BuildRestoreUser ユーザー復元UseCaseインスタンスを生成
+func (f *Factory) BuildRestoreUser() usecase.IRestoreUser {
+	return interactor.NewRestoreUser(
+		f.BuildUserRepository(),
+		f.BuildMicropostRepository(),
+		f.BuildCascadeDeleteJobRepository())
+}
+
+// BuildPurgeDeletedResources 論理削除したリソースの物理削除UseCaseインスタンスを生成
+func (f *Factory) BuildPurgeDeletedResources() usecase.IPurgeDeletedResources {
+	return interactor.NewPurgeDeletedResources(
+		f.BuildUserRepository(),
+		f.BuildMicropostRepository())
+}
+
 // BuildCreateMicropost マイクロポスト作成UseCaseインスタンスを生成
 func (f *Factory) BuildCreateMicropost() usecase.ICreateMicropost {
 	return interactor.NewCreateMicropost(
-		f.BuildMicropostOperator())
+		f.BuildUserRepository(),
+		f.BuildMicropostRepository())
 }
 
 // BuildGetMicropostList マイクロポスト取得UseCaseインスタンスを生成
 func (f *Factory) BuildGetMicropostList() usecase.IGetMicropostList {
 	return interactor.NewGetMicropostList(
-		f.BuildMicropostOperator())
+		f.BuildMicropostRepository())
+}
+
+// BuildGetReplyList 返信一覧取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetReplyList() usecase.IGetReplyList {
+	return interactor.NewGetReplyList(
+		f.BuildMicropostRepository())
+}
+
+// BuildGetHashtagMicropostList ハッシュタグのマイクロポスト一覧取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetHashtagMicropostList() usecase.IGetHashtagMicropostList {
+	return interactor.NewGetHashtagMicropostList(
+		f.BuildMicropostRepository())
+}
+
+// BuildGetMentionList メンション一覧取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetMentionList() usecase.IGetMentionList {
+	return interactor.NewGetMentionList(
+		f.BuildUserRepository(),
+		f.BuildMicropostRepository())
+}
+
+// BuildGetFeed ホームタイムライン取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetFeed() usecase.IGetFeed {
+	return interactor.NewGetFeed(
+		f.BuildUserRepository(),
+		f.BuildFollowRepository(),
+		f.BuildMicropostRepository())
 }
 
 // BuildGetMicropostByID マイクロポスト取得UseCaseインスタンスを生成
 func (f *Factory) BuildGetMicropostByID() usecase.IGetMicropostByID {
 	return interactor.NewGetMicropostByID(
-		f.BuildMicropostOperator())
+		f.BuildMicropostRepository())
 }
 
 // BuildUpdateMicropost マイクロポスト更新UseCaseインスタンスを生成
 func (f *Factory) BuildUpdateMicropost() usecase.IUpdateMicropost {
 	return interactor.NewUpdateMicropost(
-		f.BuildMicropostOperator())
+		f.BuildMicropostRepository())
 }
 
 // BuildDeleteMicropost マイクロポスト削除UseCaseインスタンスを生成
 func (f *Factory) BuildDeleteMicropost() usecase.IDeleteMicropost {
 	return interactor.NewDeleteMicropost(
 		f.BuildGetMicropostByID(),
-		f.BuildMicropostOperator())
+		f.BuildMicropostRepository())
 }
 
 func (f *Factory) BuildCreateHelloMessage() usecase.ICreateHelloMessage {
 	return interactor.NewCreateHelloMessage()
 }
 
+// BuildProductOperator 製品情報関連の操作を行うインスタンスを生成
 func (f *Factory) BuildProductOperator() *adapter.ProductOperator {
 	return &adapter.ProductOperator{
 		Client: f.BuildResourceTableOperator(),
 		Mapper: f.BuildDynamoModelMapper(),
 	}
 }
+
+// BuildProductRepository 製品のリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildProductRepository() domain.ProductRepository {
+	if f.ProductRepository != nil {
+		return f.ProductRepository
+	}
+	return f.BuildProductOperator()
+}
+
+// BuildCreateProduct 製品作成UseCaseインスタンスを生成
+func (f *Factory) BuildCreateProduct() usecase.ICreateProduct {
+	return interactor.NewCreateProduct(
+		f.BuildProductRepository())
+}
+
+// BuildUpdateProduct 製品更新UseCaseインスタンスを生成
+func (f *Factory) BuildUpdateProduct() usecase.IUpdateProduct {
+	return interactor.NewUpdateProduct(
+		f.BuildProductRepository())
+}
+
+// BuildGetProductList 製品一覧取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetProductList() usecase.IGetProductList {
+	return interactor.NewGetProductList(
+		f.BuildProductRepository())
+}
+
+// BuildGetProductByID 製品取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetProductByID() usecase.IGetProductByID {
+	return interactor.NewGetProductByID(
+		f.BuildProductRepository())
+}
+
+// BuildDeleteProduct 製品削除UseCaseインスタンスを生成
+func (f *Factory) BuildDeleteProduct() usecase.IDeleteProduct {
+	return interactor.NewDeleteProduct(
+		f.BuildProductRepository())
+}
+
+// BuildIdempotencyKeyRepository 冪等キーのリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildIdempotencyKeyRepository() domain.IdempotencyKeyRepository {
+	if f.IdempotencyKeyRepository != nil {
+		return f.IdempotencyKeyRepository
+	}
+	return &adapter.IdempotencyKeyOperator{
+		Client: f.BuildResourceTableOperator(),
+		PKName: f.Envs.DynamoPKName(),
+		SKName: f.Envs.DynamoSKName(),
+	}
+}
+
+// BuildCascadeDeleteJobRepository 削除の継続ジョブのリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildCascadeDeleteJobRepository() domain.CascadeDeleteJobRepository {
+	if f.CascadeDeleteJobRepository != nil {
+		return f.CascadeDeleteJobRepository
+	}
+	return &adapter.CascadeDeleteJobOperator{
+		Client: f.BuildResourceTableOperator(),
+		PKName: f.Envs.DynamoPKName(),
+		SKName: f.Envs.DynamoSKName(),
+	}
+}
+
+// BuildTokenVerifier ベアラートークンを検証するインスタンスを取得。差し込まれたものがあればそれを返す。
+// 公開鍵が読み込めない場合はRS256のトークンを受け付けない
+func (f *Factory) BuildTokenVerifier() domain.TokenVerifier {
+	if f.TokenVerifier != nil {
+		return f.TokenVerifier
+	}
+
+	var publicKey *rsa.PublicKey
+	if pem := f.Envs.JWTRSAPublicKey(); pem != "" {
+		key, err := adapter.ParseRSAPublicKeyPEM(pem)
+		if err != nil {
+			logger.GetLogger().Warn("Failed to parse JWT_RSA_PUBLIC_KEY", "error", err)
+		} else {
+			publicKey = key
+		}
+	}
+
+	return adapter.NewJWTVerifier([]byte(f.Envs.JWTHMACSecret()), publicKey)
+}
+
+// BuildPasswordHasher パスワードをハッシュ化するインスタンスを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildPasswordHasher() domain.PasswordHasher {
+	if f.PasswordHasher != nil {
+		return f.PasswordHasher
+	}
+	return adapter.NewBcryptPasswordHasher(f.Envs.PasswordHashCost())
+}
+
+// BuildTokenIssuer ログインしたユーザーにトークンを発行するインスタンスを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildTokenIssuer() domain.TokenIssuer {
+	if f.TokenIssuer != nil {
+		return f.TokenIssuer
+	}
+	return adapter.NewJWTSigner([]byte(f.Envs.JWTHMACSecret()), f.Envs.JWTTokenTTL())
+}
+
+// BuildLogin ログインUseCaseインスタンスを生成
+func (f *Factory) BuildLogin() usecase.ILogin {
+	return interactor.NewLogin(
+		f.BuildUserRepository(),
+		f.BuildPasswordHasher(),
+		f.BuildTokenIssuer())
+}
+
+// BuildChangePassword パスワード変更UseCaseインスタンスを生成
+func (f *Factory) BuildChangePassword() usecase.IChangePassword {
+	return interactor.NewChangePassword(
+		f.BuildUserRepository(),
+		f.BuildPasswordHasher())
+}
+
+// BuildAPIKeyOperator APIキー関連の操作を行うインスタンスを生成
+func (f *Factory) BuildAPIKeyOperator() *adapter.APIKeyOperator {
+	return &adapter.APIKeyOperator{
+		Client: f.BuildResourceTableOperator(),
+		Mapper: f.BuildDynamoModelMapper(),
+	}
+}
+
+// BuildAPIKeyRepository APIキーのリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildAPIKeyRepository() domain.APIKeyRepository {
+	if f.APIKeyRepository != nil {
+		return f.APIKeyRepository
+	}
+	return f.BuildAPIKeyOperator()
+}
+
+// BuildIssueAPIKey APIキー発行UseCaseインスタンスを生成
+func (f *Factory) BuildIssueAPIKey() usecase.IIssueAPIKey {
+	return interactor.NewIssueAPIKey(f.BuildAPIKeyRepository())
+}
+
+// BuildGetAPIKeyList APIキー一覧取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetAPIKeyList() usecase.IGetAPIKeyList {
+	return interactor.NewGetAPIKeyList(f.BuildAPIKeyRepository())
+}
+
+// BuildRevokeAPIKey APIキー失効UseCaseインスタンスを生成
+func (f *Factory) BuildRevokeAPIKey() usecase.IRevokeAPIKey {
+	return interactor.NewRevokeAPIKey(f.BuildAPIKeyRepository())
+}
+
+// BuildAuthenticateAPIKey APIキー認証UseCaseインスタンスを生成
+func (f *Factory) BuildAuthenticateAPIKey() usecase.IAuthenticateAPIKey {
+	return interactor.NewAuthenticateAPIKey(
+		f.BuildAPIKeyRepository(),
+		f.Envs.AdminAPIKey())
+}
+
+// BuildFollowRepository フォロー関係のリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildFollowRepository() domain.FollowRepository {
+	if f.FollowRepository != nil {
+		return f.FollowRepository
+	}
+	return &adapter.FollowOperator{
+		Client: f.BuildResourceTableOperator(),
+		Mapper: f.BuildDynamoModelMapper(),
+		PKName: f.Envs.DynamoPKName(),
+		SKName: f.Envs.DynamoSKName(),
+	}
+}
+
+// BuildFollowUser フォローUseCaseインスタンスを生成
+func (f *Factory) BuildFollowUser() usecase.IFollowUser {
+	return interactor.NewFollowUser(f.BuildFollowRepository())
+}
+
+// BuildUnfollowUser フォロー解除UseCaseインスタンスを生成
+func (f *Factory) BuildUnfollowUser() usecase.IUnfollowUser {
+	return interactor.NewUnfollowUser(f.BuildFollowRepository())
+}
+
+// BuildGetFollowList フォロー・フォロワー一覧取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetFollowList() usecase.IGetFollowList {
+	return interactor.NewGetFollowList(
+		f.BuildUserRepository(),
+		f.BuildFollowRepository())
+}
+
+// BuildLikeRepository いいねのリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildLikeRepository() domain.LikeRepository {
+	if f.LikeRepository != nil {
+		return f.LikeRepository
+	}
+	return &adapter.LikeOperator{
+		Client: f.BuildResourceTableOperator(),
+		Mapper: f.BuildDynamoModelMapper(),
+		PKName: f.Envs.DynamoPKName(),
+		SKName: f.Envs.DynamoSKName(),
+	}
+}
+
+// BuildLikeMicropost いいねUseCaseインスタンスを生成
+func (f *Factory) BuildLikeMicropost() usecase.ILikeMicropost {
+	return interactor.NewLikeMicropost(
+		f.BuildMicropostRepository(),
+		f.BuildLikeRepository())
+}
+
+// BuildUnlikeMicropost いいね取り消しUseCaseインスタンスを生成
+func (f *Factory) BuildUnlikeMicropost() usecase.IUnlikeMicropost {
+	return interactor.NewUnlikeMicropost(
+		f.BuildMicropostRepository(),
+		f.BuildLikeRepository())
+}
+
+// BuildS3Client S3に接続するためのクライアントを取得。差し込まれたものがあればそれを返す。
+// 初回のみ生成し、以降は同じインスタンスを返す
+func (f *Factory) BuildS3Client() adapter.S3Client {
+	if f.S3Client != nil {
+		return f.S3Client
+	}
+	f.s3ClientOnce.Do(func() {
+		config := &aws.Config{
+			Region: aws.String("ap-northeast-1"),
+		}
+
+		// NOTE: MinIOなどのローカルのS3互換サーバーはバケット名をパスに含める形式でしか接続できない
+		if f.Envs.S3LocalEndpoint() != "" {
+			config.Credentials = credentials.NewStaticCredentials("dummy", "dummy", "dummy")
+			config.Endpoint = aws.String(f.Envs.S3LocalEndpoint())
+			config.S3ForcePathStyle = aws.Bool(true)
+		}
+		f.s3Client = adapter.NewS3Client(config)
+	})
+	return f.s3Client
+}
+
+// BuildAttachmentStorage 添付画像を保存するストレージのインスタンスを生成
+func (f *Factory) BuildAttachmentStorage() domain.AttachmentStorage {
+	return &adapter.S3AttachmentStorage{
+		Client: f.BuildS3Client(),
+		Bucket: f.Envs.S3BucketName(),
+	}
+}
+
+// BuildIssueAttachmentUpload 画像添付用のアップロードURL発行UseCaseインスタンスを生成
+func (f *Factory) BuildIssueAttachmentUpload() usecase.IIssueAttachmentUpload {
+	return interactor.NewIssueAttachmentUpload(
+		f.BuildMicropostRepository(),
+		f.BuildAttachmentStorage())
+}
+
+// BuildRecordAttachment 添付画像の記録UseCaseインスタンスを生成
+func (f *Factory) BuildRecordAttachment() usecase.IRecordAttachment {
+	return interactor.NewRecordAttachment(
+		f.BuildMicropostRepository(),
+		f.BuildAttachmentStorage())
+}
+
+// BuildFileStorage 取り込みや書き出しのファイルを保存するストレージのインスタンスを生成
+func (f *Factory) BuildFileStorage() domain.FileStorage {
+	return &adapter.S3FileStorage{
+		Client: f.BuildS3Client(),
+		Bucket: f.Envs.S3BucketName(),
+	}
+}
+
+// BuildImportJobRepository 取り込みジョブのリポジトリを取得。差し込まれたものがあればそれを返す
+func (f *Factory) BuildImportJobRepository() domain.ImportJobRepository {
+	if f.ImportJobRepository != nil {
+		return f.ImportJobRepository
+	}
+	return &adapter.ImportJobOperator{
+		Client: f.BuildResourceTableOperator(),
+		PKName: f.Envs.DynamoPKName(),
+		SKName: f.Envs.DynamoSKName(),
+	}
+}
+
+// BuildImportUsers ユーザーの一括取り込みUseCaseインスタンスを生成
+func (f *Factory) BuildImportUsers() usecase.IImportUsers {
+	return interactor.NewImportUsers(
+		f.BuildUserRepository(),
+		f.BuildUserEmailUniqChecker(),
+		f.BuildPasswordHasher(),
+		f.BuildImportJobRepository(),
+		f.BuildFileStorage())
+}
+
+// BuildSubmitUserImport ユーザーの一括取り込みの受付UseCaseインスタンスを生成
+func (f *Factory) BuildSubmitUserImport() usecase.ISubmitUserImport {
+	return interactor.NewSubmitUserImport(
+		f.BuildImportJobRepository(),
+		f.BuildFileStorage())
+}
+
+// BuildGetImportJob 一括取り込みのジョブ取得UseCaseインスタンスを生成
+func (f *Factory) BuildGetImportJob() usecase.IGetImportJob {
+	return interactor.NewGetImportJob(f.BuildImportJobRepository())
+}
+
+// BuildImportProducts 製品の一括取り込みUseCaseインスタンスを生成
+func (f *Factory) BuildImportProducts() usecase.IImportProducts {
+	return interactor.NewImportProducts(
+		f.BuildProductRepository(),
+		f.BuildImportJobRepository(),
+		f.BuildFileStorage())
+}
+
+// BuildTableExportSource テーブルを書き出すために全ての項目を読み込むインスタンスを生成
+func (f *Factory) BuildTableExportSource() domain.TableExportSource {
+	return &adapter.DynamoTableExporter{
+		Client: f.BuildResourceTableOperator(),
+		PKName: f.Envs.DynamoPKName(),
+	}
+}
+
+// BuildExportTable テーブルの書き出しUseCaseインスタンスを生成
+func (f *Factory) BuildExportTable() usecase.IExportTable {
+	return interactor.NewExportTable(
+		f.BuildTableExportSource(),
+		f.BuildFileStorage())
+}
+
+// BuildCounterReconciler 非正規化した件数を数え直すインスタンスを生成
+func (f *Factory) BuildCounterReconciler() domain.CounterReconciler {
+	return &adapter.DynamoCounterReconciler{
+		Client: f.BuildResourceTableOperator(),
+		Mapper: f.BuildDynamoModelMapper(),
+	}
+}
+
+// BuildReconcileCounters 件数の数え直しUseCaseインスタンスを生成
+func (f *Factory) BuildReconcileCounters() usecase.IReconcileCounters {
+	return interactor.NewReconcileCounters(f.BuildCounterReconciler())
+}
+
+// BuildDynamoStreamDecoder テーブルの変更ストリームをドメインイベントに変換するインスタンスを生成
+func (f *Factory) BuildDynamoStreamDecoder() *adapter.DynamoStreamDecoder {
+	return &adapter.DynamoStreamDecoder{
+		Mapper: f.BuildDynamoModelMapper(),
+	}
+}
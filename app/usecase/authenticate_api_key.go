@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IAuthenticateAPIKey APIキー認証UseCase
+type IAuthenticateAPIKey interface {
+	Execute(req *AuthenticateAPIKeyRequest) (*AuthenticateAPIKeyResponse, error)
+}
+
+// AuthenticateAPIKeyRequest APIキー認証Request
+type AuthenticateAPIKeyRequest struct {
+	Key string
+}
+
+// AuthenticateAPIKeyResponse APIキー認証Response
+type AuthenticateAPIKeyResponse struct {
+	APIKey *domain.APIKeyModel
+}
@@ -0,0 +1,17 @@
+package usecase
+
+// IChangePassword パスワード変更UseCase
+type IChangePassword interface {
+	Execute(req *ChangePasswordRequest) (*ChangePasswordResponse, error)
+}
+
+// ChangePasswordRequest パスワード変更Request
+type ChangePasswordRequest struct {
+	UserID          uint64
+	CurrentPassword string
+	NewPassword     string
+}
+
+// ChangePasswordResponse パスワード変更Response
+type ChangePasswordResponse struct {
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import "context"
+
+// IContinueCascadeDelete ユーザー削除に伴うマイクロポスト削除の続きを実行するUseCase。削除の途中でユーザーが復元された場合は復元の続きを実行する
+type IContinueCascadeDelete interface {
+	Execute(ctx context.Context, req *ContinueCascadeDeleteRequest) (*ContinueCascadeDeleteResponse, error)
+}
+
+// ContinueCascadeDeleteRequest 削除継続Request。MaxJobsは1回で処理するジョブの上限
+type ContinueCascadeDeleteRequest struct {
+	MaxJobs int
+}
+
+// ContinueCascadeDeleteResponse 削除継続Response
+type ContinueCascadeDeleteResponse struct {
+	DeletedMicroposts  int
+	RestoredMicroposts int
+	CompletedJobs      int
+	PendingJobs        int
+	FailedJobs         int
+}
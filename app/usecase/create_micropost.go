@@ -7,6 +7,8 @@ type ICreateMicropost interface {
 type CreateMicropostRequest struct {
 	Content string
 	UserID  uint64
+	// InReplyToID 返信先のマイクロポストのID。返信でない場合は0
+	InReplyToID uint64
 }
 
 type CreateMicropostResponse struct {
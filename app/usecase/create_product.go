@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+	"time"
+)
+
+// ICreateProduct 製品新規作成UseCase
+type ICreateProduct interface {
+	Execute(req *CreateProductRequest) (*CreateProductResponse, error)
+}
+
+// CreateProductRequest 製品新規作成Request
+type CreateProductRequest struct {
+	Name        string
+	Price       int
+	ReleaseDate time.Time
+}
+
+func (c *CreateProductRequest) ToProductModel() *domain.ProductModel {
+	return domain.NewProductModel(c.Name, c.Price, c.ReleaseDate)
+}
+
+// CreateProductResponse 製品新規作成Response
+type CreateProductResponse struct {
+	Product *domain.ProductModel
+}
+
+func (c *CreateProductResponse) GetProductID() uint64 {
+	return c.Product.ID
+}
@@ -9,10 +9,11 @@ type ICreateUser interface {
 	Execute(req *CreateUserRequest) (*CreateUserResponse, error)
 }
 
-// CreateUserRequest ユーザー新規作成Request
+// CreateUserRequest ユーザー新規作成Request。Passwordは省略でき、省略した場合はログインできないユーザーになる
 type CreateUserRequest struct {
-	Name  string
-	Email string
+	Name     string
+	Email    string
+	Password string
 }
 
 func (u *CreateUserRequest) ToUserModel() *domain.UserModel {
@@ -7,6 +7,7 @@ type IDeleteMicropost interface {
 type DeleteMicropostRequest struct {
 	MicropostID uint64
 	UserID      uint64
+	Version     int
 }
 
 type DeleteMicropostResponse struct {
@@ -0,0 +1,16 @@
+package usecase
+
+// IDeleteProduct 製品削除UseCase
+type IDeleteProduct interface {
+	Execute(req *DeleteProductRequest) (*DeleteProductResponse, error)
+}
+
+// DeleteProductRequest 製品削除Request
+type DeleteProductRequest struct {
+	ProductID uint64
+	Version   int
+}
+
+// DeleteProductResponse 製品削除Response
+type DeleteProductResponse struct {
+}
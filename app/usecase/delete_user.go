@@ -7,7 +7,8 @@ type IDeleteUser interface {
 
 // DeleteUserRequest ユーザー削除Request
 type DeleteUserRequest struct {
-	UserID uint64
+	UserID  uint64
+	Version int
 }
 
 // DeleteUserResponse ユーザー削除Response
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+// IExportTable テーブルの書き出しUseCase
+type IExportTable interface {
+	Execute(ctx context.Context, req *ExportTableRequest) (*ExportTableResponse, error)
+}
+
+// ExportTableRequest テーブルの書き出しRequest。ExportedAtの日付の接頭辞に、Segments個に分けて並列に走査した結果を書き出す
+type ExportTableRequest struct {
+	ExportedAt time.Time
+	Segments   int
+}
+
+// ExportTableResponse テーブルの書き出しResponse。Countsは項目の種類ごとの件数
+type ExportTableResponse struct {
+	Prefix      string
+	ManifestKey string
+	Counts      map[string]int
+	Total       int
+}
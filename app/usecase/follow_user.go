@@ -0,0 +1,16 @@
+package usecase
+
+// IFollowUser フォローUseCase
+type IFollowUser interface {
+	Execute(req *FollowUserRequest) (*FollowUserResponse, error)
+}
+
+// FollowUserRequest フォローRequest。UserIDのユーザーがTargetUserIDのユーザーをフォローする
+type FollowUserRequest struct {
+	UserID       uint64
+	TargetUserID uint64
+}
+
+// FollowUserResponse フォローResponse
+type FollowUserResponse struct {
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IGetAPIKeyList APIキー一覧取得UseCase
+type IGetAPIKeyList interface {
+	Execute(req *GetAPIKeyListRequest) (*GetAPIKeyListResponse, error)
+}
+
+// GetAPIKeyListRequest APIキー一覧取得Request
+type GetAPIKeyListRequest struct {
+	Limit     int
+	NextToken string
+}
+
+func (g *GetAPIKeyListRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
+}
+
+// GetAPIKeyListResponse APIキー一覧取得Response
+type GetAPIKeyListResponse struct {
+	APIKeys   []*domain.APIKeyModel
+	NextToken string
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IGetFeed ホームタイムライン取得UseCase
+type IGetFeed interface {
+	Execute(req *GetFeedRequest) (*GetFeedResponse, error)
+}
+
+// GetFeedRequest ホームタイムライン取得Request
+type GetFeedRequest struct {
+	UserID    uint64
+	Limit     int
+	NextToken string
+}
+
+func (g *GetFeedRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
+}
+
+// GetFeedResponse ホームタイムライン取得Response
+type GetFeedResponse struct {
+	Microposts []*domain.MicropostModel
+	NextToken  string
+}
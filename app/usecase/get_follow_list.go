@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// FollowDirection フォロー一覧の向き
+type FollowDirection int
+
+const (
+	// FollowDirectionFollowing ユーザーがフォローしているユーザー
+	FollowDirectionFollowing FollowDirection = iota
+	// FollowDirectionFollowers ユーザーをフォローしているユーザー
+	FollowDirectionFollowers
+)
+
+// IGetFollowList フォロー・フォロワー一覧取得UseCase
+type IGetFollowList interface {
+	Execute(req *GetFollowListRequest) (*GetFollowListResponse, error)
+}
+
+// GetFollowListRequest フォロー・フォロワー一覧取得Request
+type GetFollowListRequest struct {
+	UserID    uint64
+	Direction FollowDirection
+	Limit     int
+	NextToken string
+}
+
+func (g *GetFollowListRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
+}
+
+// GetFollowListResponse フォロー・フォロワー一覧取得Response
+type GetFollowListResponse struct {
+	Follows   []*domain.FollowModel
+	NextToken string
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IGetHashtagMicropostList ハッシュタグのマイクロポスト一覧取得UseCase
+type IGetHashtagMicropostList interface {
+	Execute(req *GetHashtagMicropostListRequest) (*GetHashtagMicropostListResponse, error)
+}
+
+// GetHashtagMicropostListRequest ハッシュタグのマイクロポスト一覧取得Request。Tagは#の有無や全角・半角を問わない
+type GetHashtagMicropostListRequest struct {
+	Tag       string
+	Limit     int
+	NextToken string
+}
+
+func (g *GetHashtagMicropostListRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
+}
+
+// GetHashtagMicropostListResponse ハッシュタグのマイクロポスト一覧取得Response
+type GetHashtagMicropostListResponse struct {
+	Microposts []*domain.MicropostModel
+	NextToken  string
+}
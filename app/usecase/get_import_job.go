@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IGetImportJob 一括取り込みのジョブを取得UseCase
+type IGetImportJob interface {
+	Execute(req *GetImportJobRequest) (*GetImportJobResponse, error)
+}
+
+type GetImportJobRequest struct {
+	JobID string
+}
+
+type GetImportJobResponse struct {
+	Job *domain.ImportJobModel
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IGetMentionList メンション一覧取得UseCase
+type IGetMentionList interface {
+	Execute(req *GetMentionListRequest) (*GetMentionListResponse, error)
+}
+
+// GetMentionListRequest メンション一覧取得Request。UserIDのユーザーをメンションしているマイクロポストを取得する
+type GetMentionListRequest struct {
+	UserID    uint64
+	Limit     int
+	NextToken string
+}
+
+func (g *GetMentionListRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
+}
+
+// GetMentionListResponse メンション一覧取得Response
+type GetMentionListResponse struct {
+	Microposts []*domain.MicropostModel
+	NextToken  string
+}
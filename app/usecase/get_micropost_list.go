@@ -9,9 +9,16 @@ type IGetMicropostList interface {
 }
 
 type GetMicropostListRequest struct {
-	UserID uint64
+	UserID    uint64
+	Limit     int
+	NextToken string
+}
+
+func (g *GetMicropostListRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
 }
 
 type GetMicropostListResponse struct {
 	Microposts []*domain.MicropostModel
+	NextToken  string
 }
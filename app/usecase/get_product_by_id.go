@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IGetProductByID 指定されたIDの製品を取得UseCase
+type IGetProductByID interface {
+	Execute(req *GetProductByIDRequest) (*GetProductByIDResponse, error)
+}
+
+type GetProductByIDRequest struct {
+	ProductID uint64
+}
+
+type GetProductByIDResponse struct {
+	Product *domain.ProductModel
+}
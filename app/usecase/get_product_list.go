@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IGetProductList 製品一覧取得UseCase
+type IGetProductList interface {
+	Execute(req *GetProductListRequest) (*GetProductListResponse, error)
+}
+
+// GetProductListRequest 製品一覧取得Request
+type GetProductListRequest struct {
+	Limit     int
+	NextToken string
+}
+
+func (g *GetProductListRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
+}
+
+// GetProductListResponse 製品一覧取得Response
+type GetProductListResponse struct {
+	Products  []*domain.ProductModel
+	NextToken string
+}
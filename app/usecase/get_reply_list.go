@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IGetReplyList 返信一覧取得UseCase
+type IGetReplyList interface {
+	Execute(req *GetReplyListRequest) (*GetReplyListResponse, error)
+}
+
+// GetReplyListRequest 返信一覧取得Request。UserIDのユーザーのマイクロポストへの返信を取得する
+type GetReplyListRequest struct {
+	UserID      uint64
+	MicropostID uint64
+	Limit       int
+	NextToken   string
+}
+
+func (g *GetReplyListRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
+}
+
+// GetReplyListResponse 返信一覧取得Response
+type GetReplyListResponse struct {
+	Replies   []*domain.MicropostModel
+	NextToken string
+}
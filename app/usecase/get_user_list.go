@@ -11,11 +11,18 @@ type IGetUserList interface {
 
 // GetUserListRequest ユーザー一覧取得Request
 type GetUserListRequest struct {
+	Limit     int
+	NextToken string
+}
+
+func (g *GetUserListRequest) ToPage() *domain.Page {
+	return domain.NewPage(g.Limit, g.NextToken)
 }
 
 // GetUserListResponse ユーザー一覧取得Response
 type GetUserListResponse struct {
-	Users []*domain.UserModel
+	Users     []*domain.UserModel
+	NextToken string
 }
 
 func (g *GetUserListResponse) UserCount() int {
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+	"io"
+	"time"
+)
+
+// IImportProducts 製品の一括取り込みUseCase
+type IImportProducts interface {
+	Execute(req *ImportProductsRequest) (*ImportProductsResponse, error)
+}
+
+// ProductImportRow 取り込むファイルから読み込んだ1行。検証に失敗した場合はProductがnilで、Errorsに項目ごとのメッセージを持つ
+type ProductImportRow struct {
+	// Row 何行目のデータか。見出しの行は数えず、1から始まる
+	Row     int
+	Product *domain.ProductModel
+	Errors  map[string]string
+}
+
+// ProductImportReader 取り込むファイルを1行ずつ読み込む。読み終わった場合はio.EOFを返す
+type ProductImportReader interface {
+	Next() (*ProductImportRow, error)
+}
+
+// ProductImportDecoder 取り込むファイルの形式を扱う。行の検証やメッセージは製品APIと同じルールで行う
+type ProductImportDecoder interface {
+	// NewReader キーの拡張子に応じた形式でファイルを読み込む。形式が不正な場合はdomain.ErrInvalidImportFileを返す
+	NewReader(key string, r io.Reader) (ProductImportReader, error)
+	// SaveErrorMessages 行の書き込みに失敗したエラーを、報告用の項目ごとのメッセージに変換する
+	SaveErrorMessages(err error) map[string]string
+}
+
+// ImportProductsRequest 製品の一括取り込みRequest。Keyのファイルを、その版を表すETagとともに指定する。
+// LockedUntilまでに終わらない場合は進捗を保存して中断する
+type ImportProductsRequest struct {
+	Key         string
+	ETag        string
+	Decoder     ProductImportDecoder
+	LockedUntil time.Time
+}
+
+// ImportProductsResponse 製品の一括取り込みResponse。同じファイルを取り込み済みか、他の実行が取り込み中の場合はSkippedがtrueになる
+type ImportProductsResponse struct {
+	Job     *domain.ImportJobModel
+	Skipped bool
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+	"io"
+	"time"
+)
+
+// IImportUsers ユーザーの一括取り込みUseCase
+type IImportUsers interface {
+	Execute(req *ImportUsersRequest) (*ImportUsersResponse, error)
+}
+
+// UserImportRow 取り込むファイルから読み込んだ1行。検証に失敗した場合はUserがnilで、Errorsに項目ごとのメッセージを持つ
+type UserImportRow struct {
+	// Row 何行目のデータか。見出しの行は数えず、1から始まる
+	Row  int
+	User *domain.UserModel
+	// Password 省略した場合はパスワードを設定せずに作成する
+	Password string
+	Errors   map[string]string
+}
+
+// UserImportReader 取り込むファイルを1行ずつ読み込む。読み終わった場合はio.EOFを返す
+type UserImportReader interface {
+	Next() (*UserImportRow, error)
+}
+
+// UserImportDecoder 取り込むファイルの形式を扱う。行の検証やメッセージはユーザー作成APIと同じルールで行う
+type UserImportDecoder interface {
+	// NewReader キーの拡張子に応じた形式でファイルを読み込む。形式が不正な場合はdomain.ErrInvalidImportFileを返す
+	NewReader(key string, r io.Reader) (UserImportReader, error)
+	// SaveErrorMessages 行の書き込みに失敗したエラーを、報告用の項目ごとのメッセージに変換する
+	SaveErrorMessages(err error) map[string]string
+}
+
+// ImportUsersRequest ユーザーの一括取り込みRequest。Keyのファイルを、その版を表すETagとともに指定する。
+// LockedUntilまでに終わらない場合は進捗を保存して中断する
+type ImportUsersRequest struct {
+	Key         string
+	ETag        string
+	Decoder     UserImportDecoder
+	LockedUntil time.Time
+}
+
+// ImportUsersResponse ユーザーの一括取り込みResponse。同じファイルを取り込み済みか、他の実行が取り込み中の場合はSkippedがtrueになる
+type ImportUsersResponse struct {
+	Job     *domain.ImportJobModel
+	Skipped bool
+}
@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+	"time"
+)
+
+// IIssueAPIKey APIキー発行UseCase
+type IIssueAPIKey interface {
+	Execute(req *IssueAPIKeyRequest) (*IssueAPIKeyResponse, error)
+}
+
+// IssueAPIKeyRequest APIキー発行Request
+type IssueAPIKeyRequest struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// IssueAPIKeyResponse APIキー発行Response。Keyは保存していないため、ここでしか取得できない
+type IssueAPIKeyResponse struct {
+	APIKey *domain.APIKeyModel
+	Key    string
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import "time"
+
+// IIssueAttachmentUpload 画像添付用のアップロードURL発行UseCase
+type IIssueAttachmentUpload interface {
+	Execute(req *IssueAttachmentUploadRequest) (*IssueAttachmentUploadResponse, error)
+}
+
+// IssueAttachmentUploadRequest 画像添付用のアップロードURL発行Request。UserIDのユーザーのマイクロポストに添付する
+type IssueAttachmentUploadRequest struct {
+	UserID      uint64
+	MicropostID uint64
+	ContentType string
+}
+
+// IssueAttachmentUploadResponse 画像添付用のアップロードURL発行Response。
+// UploadURLにContentTypeを指定してPUTでアップロードすると、完了後にKeyの画像としてマイクロポストに添付される
+type IssueAttachmentUploadResponse struct {
+	Key       string
+	UploadURL string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,17 @@
+package usecase
+
+// ILikeMicropost いいねUseCase
+type ILikeMicropost interface {
+	Execute(req *LikeMicropostRequest) (*LikeMicropostResponse, error)
+}
+
+// LikeMicropostRequest いいねRequest。LikerIDのユーザーが、UserIDのユーザーのマイクロポストにいいねする
+type LikeMicropostRequest struct {
+	UserID      uint64
+	MicropostID uint64
+	LikerID     uint64
+}
+
+// LikeMicropostResponse いいねResponse
+type LikeMicropostResponse struct {
+}
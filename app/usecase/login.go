@@ -0,0 +1,21 @@
+package usecase
+
+import "time"
+
+// ILogin ログインUseCase
+type ILogin interface {
+	Execute(req *LoginRequest) (*LoginResponse, error)
+}
+
+// LoginRequest ログインRequest
+type LoginRequest struct {
+	Email    string
+	Password string
+}
+
+// LoginResponse ログインResponse
+type LoginResponse struct {
+	UserID    uint64
+	Token     string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+// IPurgeDeletedResources 論理削除したリソースの物理削除UseCase
+type IPurgeDeletedResources interface {
+	Execute(ctx context.Context, req *PurgeDeletedResourcesRequest) (*PurgeDeletedResourcesResponse, error)
+}
+
+// PurgeDeletedResourcesRequest 物理削除Request。DeletedBeforeより前に論理削除したものを対象にする
+type PurgeDeletedResourcesRequest struct {
+	DeletedBefore time.Time
+}
+
+// PurgeDeletedResourcesResponse 物理削除Response
+type PurgeDeletedResourcesResponse struct {
+	PurgedUsers      int
+	PurgedMicroposts int
+}
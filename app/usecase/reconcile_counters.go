@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+	"context"
+)
+
+// IReconcileCounters 非正規化した件数の数え直しUseCase
+type IReconcileCounters interface {
+	Execute(ctx context.Context, req *ReconcileCountersRequest) (*ReconcileCountersResponse, error)
+}
+
+// ReconcileCountersRequest 件数の数え直しRequest。DryRunの場合は書き込まずに件数だけを数える
+type ReconcileCountersRequest struct {
+	DryRun bool
+}
+
+// ReconcileCountersResponse 件数の数え直しResponse
+type ReconcileCountersResponse struct {
+	Users      *domain.CounterReconcileResult
+	Microposts *domain.CounterReconcileResult
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import "clean-serverless-book-sample/domain"
+
+// IRecordAttachment 添付画像の記録UseCase
+type IRecordAttachment interface {
+	Execute(req *RecordAttachmentRequest) (*RecordAttachmentResponse, error)
+}
+
+// RecordAttachmentRequest 添付画像の記録Request。アップロードが完了したオブジェクトのキーを指定する
+type RecordAttachmentRequest struct {
+	Key string
+}
+
+// RecordAttachmentResponse 添付画像の記録Response
+type RecordAttachmentResponse struct {
+	MicropostID uint64
+	Attachment  *domain.AttachmentModel
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+)
+
+// IRestoreUser 論理削除したユーザーの復元UseCase
+type IRestoreUser interface {
+	Execute(req *RestoreUserRequest) (*RestoreUserResponse, error)
+}
+
+// RestoreUserRequest ユーザー復元Request
+type RestoreUserRequest struct {
+	UserID uint64
+}
+
+// RestoreUserResponse ユーザー復元Response
+type RestoreUserResponse struct {
+	User *domain.UserModel
+}
@@ -0,0 +1,16 @@
+package usecase
+
+// IRevokeAPIKey APIキー失効UseCase
+type IRevokeAPIKey interface {
+	Execute(req *RevokeAPIKeyRequest) (*RevokeAPIKeyResponse, error)
+}
+
+// RevokeAPIKeyRequest APIキー失効Request
+type RevokeAPIKeyRequest struct {
+	APIKeyID uint64
+	Version  int
+}
+
+// RevokeAPIKeyResponse APIキー失効Response
+type RevokeAPIKeyResponse struct {
+}
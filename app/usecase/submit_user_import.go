@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+	"io"
+)
+
+// ISubmitUserImport ユーザーの一括取り込みの受付UseCase
+type ISubmitUserImport interface {
+	Execute(req *SubmitUserImportRequest) (*SubmitUserImportResponse, error)
+}
+
+// SubmitUserImportRequest ユーザーの一括取り込みの受付Request。
+// Extensionはファイルの形式を表す拡張子で、取り込む時にはこの拡張子で形式を判別する
+type SubmitUserImportRequest struct {
+	Extension   string
+	ContentType string
+	Body        io.ReadSeeker
+}
+
+// SubmitUserImportResponse ユーザーの一括取り込みの受付Response
+type SubmitUserImportResponse struct {
+	Job *domain.ImportJobModel
+}
@@ -0,0 +1,16 @@
+package usecase
+
+// IUnfollowUser フォロー解除UseCase
+type IUnfollowUser interface {
+	Execute(req *UnfollowUserRequest) (*UnfollowUserResponse, error)
+}
+
+// UnfollowUserRequest フォロー解除Request
+type UnfollowUserRequest struct {
+	UserID       uint64
+	TargetUserID uint64
+}
+
+// UnfollowUserResponse フォロー解除Response
+type UnfollowUserResponse struct {
+}
@@ -0,0 +1,17 @@
+package usecase
+
+// IUnlikeMicropost いいね取り消しUseCase
+type IUnlikeMicropost interface {
+	Execute(req *UnlikeMicropostRequest) (*UnlikeMicropostResponse, error)
+}
+
+// UnlikeMicropostRequest いいね取り消しRequest。LikerIDのユーザーが、UserIDのユーザーのマイクロポストへのいいねを取り消す
+type UnlikeMicropostRequest struct {
+	UserID      uint64
+	MicropostID uint64
+	LikerID     uint64
+}
+
+// UnlikeMicropostResponse いいね取り消しResponse
+type UnlikeMicropostResponse struct {
+}
@@ -8,6 +8,7 @@ type UpdateMicropostRequest struct {
 	Content     string
 	UserID      uint64
 	MicropostID uint64
+	Version     int
 }
 
 type UpdateMicropostResponse struct {
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"clean-serverless-book-sample/domain"
+	"time"
+)
+
+// IUpdateProduct 製品更新UseCase
+type IUpdateProduct interface {
+	Execute(req *UpdateProductRequest) (*UpdateProductResponse, error)
+}
+
+// UpdateProductRequest 製品更新Request
+type UpdateProductRequest struct {
+	ID          uint64
+	Name        string
+	Price       int
+	ReleaseDate time.Time
+	Version     int
+}
+
+func (u *UpdateProductRequest) ToProductModel() *domain.ProductModel {
+	return &domain.ProductModel{
+		ID:          u.ID,
+		Name:        u.Name,
+		Price:       u.Price,
+		ReleaseDate: u.ReleaseDate,
+		Version:     u.Version,
+	}
+}
+
+// UpdateProductResponse 製品更新Response
+type UpdateProductResponse struct {
+}
@@ -8,17 +8,20 @@ type IUpdateUser interface {
 	Execute(req *UpdateUserRequest) (*UpdateUserResponse, error)
 }
 
+// UpdateUserRequest ユーザー更新Request。Versionが0の場合は楽観的ロックのバージョンをチェックしない
 type UpdateUserRequest struct {
-	ID    uint64
-	Name  string
-	Email string
+	ID      uint64
+	Name    string
+	Email   string
+	Version int
 }
 
 func (u *UpdateUserRequest) ToUserModel() *domain.UserModel {
 	return &domain.UserModel{
-		ID:    u.ID,
-		Name:  u.Name,
-		Email: u.Email,
+		ID:      u.ID,
+		Name:    u.Name,
+		Email:   u.Email,
+		Version: u.Version,
 	}
 }
 
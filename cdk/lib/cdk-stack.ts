@@ -2,17 +2,28 @@ import {
   Architecture,
   DockerImageCode,
   DockerImageFunction,
+  StartingPosition,
 } from "aws-cdk-lib/aws-lambda";
 import { Duration, RemovalPolicy, Stack, type StackProps } from "aws-cdk-lib";
 import type { Construct } from "constructs";
 import { LambdaIntegration, RestApi } from "aws-cdk-lib/aws-apigateway";
-import { AttributeType, BillingMode, Table } from "aws-cdk-lib/aws-dynamodb";
+import {
+  AttributeType,
+  BillingMode,
+  StreamViewType,
+  Table,
+} from "aws-cdk-lib/aws-dynamodb";
 import { Effect, PolicyStatement } from "aws-cdk-lib/aws-iam";
 import * as dotenv from "dotenv";
 import { Bucket, EventType } from "aws-cdk-lib/aws-s3";
 import { LambdaDestination } from "aws-cdk-lib/aws-s3-notifications";
-import { Rule, Schedule } from "aws-cdk-lib/aws-events";
+import { Rule, RuleTargetInput, Schedule } from "aws-cdk-lib/aws-events";
 import { LambdaFunction } from "aws-cdk-lib/aws-events-targets";
+import {
+  DynamoEventSource,
+  SqsDlq,
+} from "aws-cdk-lib/aws-lambda-event-sources";
+import { Queue } from "aws-cdk-lib/aws-sqs";
 
 dotenv.config({ path: "../.env" });
 
@@ -27,6 +38,16 @@ export class CdkStack extends Stack {
       tableName: process.env.DYNAMO_TABLE_NAME,
       billingMode: BillingMode.PAY_PER_REQUEST,
       removalPolicy: RemovalPolicy.DESTROY,
+      // NOTE: 冪等キー(Idempotency-Key)のレコードは有効期限を過ぎたら自動で削除する
+      timeToLiveAttribute: "ExpiresAt",
+      // NOTE: 変更前後の内容をドメインイベントに変換するため、両方をストリームに流す
+      stream: StreamViewType.NEW_AND_OLD_IMAGES,
+    });
+    // NOTE: ユーザー単位で子エンティティ(マイクロポストなど)を新しい順に取得するためのGSI
+    dynamoTable.addGlobalSecondaryIndex({
+      indexName: "GSI1",
+      partitionKey: { name: "GSI1PK", type: AttributeType.STRING },
+      sortKey: { name: "GSI1SK", type: AttributeType.STRING },
     });
 
     // API Gateway
@@ -37,21 +58,38 @@ export class CdkStack extends Stack {
       },
     });
 
+    // S3 Bucket
+    const bucket = new Bucket(this, "CleanServerlessTestBucket", {
+      bucketName: "clean-serverless-test",
+    });
+
     // Lambda Functions and API Gateway Integrations
     const imagePath = "../app";
-    const createLambdaFunction = (target: string, functionName: string) => {
+    const createLambdaFunction = (
+      target: string,
+      functionName: string,
+      timeout: Duration = Duration.seconds(30)
+    ) => {
       return new DockerImageFunction(this, functionName, {
         functionName: `clean-serverless-${functionName}`,
         code: DockerImageCode.fromImageAsset(imagePath, {
           target: target,
         }),
         architecture: Architecture.ARM_64,
-        timeout: Duration.seconds(30),
+        timeout: timeout,
         memorySize: 1280,
         environment: {
           DYNAMO_TABLE_NAME: process.env.DYNAMO_TABLE_NAME || "",
           DYNAMO_PK_NAME: process.env.DYNAMO_PK_NAME || "",
           DYNAMO_SK_NAME: process.env.DYNAMO_SK_NAME || "",
+          // NOTE: JWTの検証鍵はKMSで暗号化した値を設定する
+          JWT_HMAC_SECRET: process.env.JWT_HMAC_SECRET || "",
+          JWT_RSA_PUBLIC_KEY: process.env.JWT_RSA_PUBLIC_KEY || "",
+          JWT_TOKEN_TTL_MINUTES: process.env.JWT_TOKEN_TTL_MINUTES || "60",
+          // NOTE: 最初のAPIキーを発行するための管理用キーもKMSで暗号化した値を設定する
+          ADMIN_API_KEY: process.env.ADMIN_API_KEY || "",
+          // NOTE: 添付画像などのファイルを保存するバケット
+          S3_BUCKET_NAME: bucket.bucketName,
         },
       });
     };
@@ -72,12 +110,78 @@ export class CdkStack extends Stack {
         method: "DELETE",
         apiPath: "/v1/users/{user_id}/microposts/{micropost_id}",
       },
+      {
+        name: "likeMicropost",
+        method: "POST",
+        apiPath: "/v1/users/{user_id}/microposts/{micropost_id}/likes",
+      },
+      {
+        name: "unlikeMicropost",
+        method: "DELETE",
+        apiPath: "/v1/users/{user_id}/microposts/{micropost_id}/likes",
+      },
       { name: "deleteUser", method: "DELETE", apiPath: "/v1/users/{user_id}" },
+      {
+        name: "restoreUser",
+        method: "POST",
+        apiPath: "/v1/users/{user_id}/restore",
+      },
+      {
+        name: "putPassword",
+        method: "PUT",
+        apiPath: "/v1/users/{user_id}/password",
+      },
+      { name: "login", method: "POST", apiPath: "/v1/auth/login" },
+      {
+        name: "followUser",
+        method: "POST",
+        apiPath: "/v1/users/{user_id}/following/{target_id}",
+      },
+      {
+        name: "unfollowUser",
+        method: "DELETE",
+        apiPath: "/v1/users/{user_id}/following/{target_id}",
+      },
+      {
+        name: "getFollowing",
+        method: "GET",
+        apiPath: "/v1/users/{user_id}/following",
+      },
+      {
+        name: "getFollowers",
+        method: "GET",
+        apiPath: "/v1/users/{user_id}/followers",
+      },
       {
         name: "getMicropost",
         method: "GET",
         apiPath: "/v1/users/{user_id}/microposts/{micropost_id}",
       },
+      {
+        name: "postAttachment",
+        method: "POST",
+        apiPath: "/v1/users/{user_id}/microposts/{micropost_id}/attachments",
+      },
+      {
+        name: "getReplies",
+        method: "GET",
+        apiPath: "/v1/users/{user_id}/microposts/{micropost_id}/replies",
+      },
+      {
+        name: "getMentions",
+        method: "GET",
+        apiPath: "/v1/users/{user_id}/mentions",
+      },
+      {
+        name: "getHashtagMicroposts",
+        method: "GET",
+        apiPath: "/v1/hashtags/{tag}/microposts",
+      },
+      {
+        name: "getFeed",
+        method: "GET",
+        apiPath: "/v1/users/{user_id}/feed",
+      },
       {
         name: "getMicroposts",
         method: "GET",
@@ -91,12 +195,46 @@ export class CdkStack extends Stack {
         apiPath: "/v1/users/{user_id}/microposts",
       },
       { name: "postUsers", method: "POST", apiPath: "/v1/users" },
+      {
+        name: "batchImportUsers",
+        method: "POST",
+        apiPath: "/v1/users/batch_import",
+      },
+      {
+        name: "getImportJob",
+        method: "GET",
+        apiPath: "/v1/import_jobs/{job_id}",
+      },
       {
         name: "putMicropost",
         method: "PUT",
         apiPath: "/v1/users/{user_id}/microposts/{micropost_id}",
       },
       { name: "putUser", method: "PUT", apiPath: "/v1/users/{user_id}" },
+      { name: "postProducts", method: "POST", apiPath: "/v1/products" },
+      { name: "getProducts", method: "GET", apiPath: "/v1/products" },
+      {
+        name: "getProduct",
+        method: "GET",
+        apiPath: "/v1/products/{product_id}",
+      },
+      {
+        name: "putProduct",
+        method: "PUT",
+        apiPath: "/v1/products/{product_id}",
+      },
+      {
+        name: "deleteProduct",
+        method: "DELETE",
+        apiPath: "/v1/products/{product_id}",
+      },
+      { name: "issueAPIKey", method: "POST", apiPath: "/v1/admin/api_keys" },
+      { name: "getAPIKeys", method: "GET", apiPath: "/v1/admin/api_keys" },
+      {
+        name: "revokeAPIKey",
+        method: "DELETE",
+        apiPath: "/v1/admin/api_keys/{api_key_id}",
+      },
       { name: "hello", method: "POST", apiPath: "/v1/hello" },
     ];
 
@@ -106,21 +244,29 @@ export class CdkStack extends Stack {
       dynamoTable.grantFullAccess(lambdaFunction);
       lambdaFunction.addToRolePolicy(
         new PolicyStatement({
-          actions: ["dynamodb:*", "logs:*"],
+          actions: ["dynamodb:*", "logs:*", "kms:Decrypt"],
           effect: Effect.ALLOW,
           resources: ["*"],
         })
       );
+      // NOTE: 署名付きURLでのアップロードは署名したLambdaの権限で行われる
+      if (name === "postAttachment") {
+        bucket.grantPut(lambdaFunction);
+      }
+      // NOTE: 一括取り込みで受け付けたファイルはバケットに保存し、S3のイベントで取り込む
+      if (name === "batchImportUsers") {
+        bucket.grantPut(lambdaFunction);
+      }
       addApiIntegration(apiPath, method, lambdaFunction);
     }
 
-    // S3 Bucket
-    const bucket = new Bucket(this, "CleanServerlessTestBucket", {
-      bucketName: "clean-serverless-test",
-    });
-
     // NOTE: Lambda 関数の作成と S3 バケットの紐づけ:
-    const s3HandlerFunction = createLambdaFunction("s3event", "s3Handler");
+    // NOTE: 製品の一括取り込みは時間がかかるため、タイムアウトを長めにする。期限までに終わらない場合は再試行で続きから再開する
+    const s3HandlerFunction = createLambdaFunction(
+      "s3event",
+      "s3Handler",
+      Duration.minutes(5)
+    );
     // NOTE: アクセス権限の付与
     bucket.grantReadWrite(s3HandlerFunction);
     dynamoTable.grantFullAccess(s3HandlerFunction);
@@ -144,19 +290,89 @@ export class CdkStack extends Stack {
     );
 
     // Schedule Event Handler
-    const scheduleHandler = createLambdaFunction("schedule", "scheduleHandler");
+    // NOTE: テーブルの書き出しは全件を走査するため、タイムアウトをLambdaの上限にする
+    const scheduleHandler = createLambdaFunction(
+      "schedule",
+      "scheduleHandler",
+      Duration.minutes(15)
+    );
+    // NOTE: 論理削除したリソースを物理削除するまでの保持日数
+    scheduleHandler.addEnvironment(
+      "SOFT_DELETE_RETENTION_DAYS",
+      process.env.SOFT_DELETE_RETENTION_DAYS || "30"
+    );
+    dynamoTable.grantFullAccess(scheduleHandler);
+    // NOTE: テーブルの書き出しはバケットのexports/以下に保存する
+    bucket.grantPut(scheduleHandler);
     scheduleHandler.addToRolePolicy(
       new PolicyStatement({
-        actions: ["logs:*"],
+        actions: ["dynamodb:*", "logs:*"],
         effect: Effect.ALLOW,
         resources: ["*"],
       })
     );
+    // NOTE: 定期処理が失敗した場合やactionが不明な場合はエラーを返すため、非同期呼び出しとして再試行させる
+    scheduleHandler.configureAsyncInvoke({
+      retryAttempts: 2,
+      maxEventAge: Duration.hours(1),
+    });
     // Create EventBridge Rule
     const eventRule = new Rule(this, "ScheduleRule", {
       // NOTE: 5分ごとに実行
       schedule: Schedule.rate(Duration.minutes(5)),
     });
-    eventRule.addTarget(new LambdaFunction(scheduleHandler));
+    // NOTE: ユーザー削除時に削除しきれなかったマイクロポストの削除を続ける
+    eventRule.addTarget(
+      new LambdaFunction(scheduleHandler, {
+        event: RuleTargetInput.fromObject({ action: "cascadeDelete" }),
+      })
+    );
+    // NOTE: 1日1回、保持期間を過ぎた論理削除済みのユーザーとマイクロポストを物理削除
+    const purgeDeletedRule = new Rule(this, "PurgeDeletedRule", {
+      schedule: Schedule.rate(Duration.days(1)),
+    });
+    purgeDeletedRule.addTarget(
+      new LambdaFunction(scheduleHandler, {
+        event: RuleTargetInput.fromObject({ action: "purgeDeleted" }),
+      })
+    );
+    // NOTE: 毎晩(日本時間の3時)、分析用にテーブルのスナップショットをS3に書き出す
+    const exportRule = new Rule(this, "ExportRule", {
+      schedule: Schedule.cron({ minute: "0", hour: "18" }),
+    });
+    exportRule.addTarget(
+      new LambdaFunction(scheduleHandler, {
+        event: RuleTargetInput.fromObject({ action: "export" }),
+      })
+    );
+    // NOTE: 毎週日曜日(日本時間の4時)、フォロー数・いいね数・返信数を元のレコードから数え直す
+    const reconcileCountersRule = new Rule(this, "ReconcileCountersRule", {
+      schedule: Schedule.cron({ minute: "0", hour: "19", weekDay: "SAT" }),
+    });
+    reconcileCountersRule.addTarget(
+      new LambdaFunction(scheduleHandler, {
+        event: RuleTargetInput.fromObject({ action: "counters-reconcile" }),
+      })
+    );
+
+    // Stream Event Handler
+    const streamHandler = createLambdaFunction("stream", "streamHandler");
+    dynamoTable.grantStreamRead(streamHandler);
+    // NOTE: 再試行しても処理できないレコードはキューに送り、シャードの処理が止まらないようにする
+    const streamDeadLetterQueue = new Queue(this, "StreamDeadLetterQueue", {
+      retentionPeriod: Duration.days(14),
+    });
+    // NOTE: 失敗したレコードはBatchItemFailuresで返し、そのレコードから再試行する。
+    // NOTE: 失敗が続く場合はバッチを分割して原因のレコードを絞り込み、再試行の上限を超えたらキューに送る
+    streamHandler.addEventSource(
+      new DynamoEventSource(dynamoTable, {
+        startingPosition: StartingPosition.TRIM_HORIZON,
+        batchSize: 100,
+        reportBatchItemFailures: true,
+        bisectBatchOnError: true,
+        retryAttempts: 5,
+        onFailure: new SqsDlq(streamDeadLetterQueue),
+      })
+    );
   }
 }